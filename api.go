@@ -0,0 +1,832 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/discovery"
+	"lhcontrol/internal/instance"
+	"lhcontrol/internal/station"
+	"lhcontrol/internal/usermsg"
+	"lhcontrol/internal/webui"
+	"lhcontrol/pkg/apiclient"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamKeepalive is how often an idle event stream sends a comment line,
+// so proxies and the remote client notice dead connections.
+const streamKeepalive = 15 * time.Second
+
+// generateToken returns a random hex token for API authentication.
+func generateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ensureAPIToken makes sure the config holds an API token, generating and
+// saving a new one if there is none or regenerate is set.
+func ensureAPIToken(cfg *config.Config, regenerate bool) (string, error) {
+	settings := cfg.GetAPISettings()
+	if settings.Token != "" && !regenerate {
+		return settings.Token, nil
+	}
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	settings.Token = token
+	cfg.SetAPISettings(settings)
+	if err := cfg.Save(); err != nil {
+		return "", fmt.Errorf("failed to save API token: %w", err)
+	}
+	return token, nil
+}
+
+// requireToken rejects requests without the configured API token. Without a
+// token the API is open, except in agent mode, where it rejects everything.
+// The token is accepted as a Bearer header or, for clients like EventSource
+// that can't set headers, as a "token" query parameter.
+func (a *App) requireToken(c *fiber.Ctx) error {
+	token := a.config.GetAPISettings().Token
+	if token == "" {
+		if a.agentMode {
+			return c.Status(fiber.StatusUnauthorized).JSON(apiclient.ErrorResponse{Error: "agent mode requires an API token, none is set"})
+		}
+		return c.Next()
+	}
+	provided := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if provided == "" {
+		provided = c.Query("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		return c.Status(fiber.StatusUnauthorized).JSON(apiclient.ErrorResponse{Error: "invalid or missing token"})
+	}
+	return c.Next()
+}
+
+// requireConfigToken guards the remote configuration routes, which need a
+// token even when the rest of the API is open. requireToken has checked it.
+func (a *App) requireConfigToken(c *fiber.Ctx) error {
+	if a.config.GetAPISettings().Token == "" {
+		return c.Status(fiber.StatusForbidden).JSON(apiclient.ErrorResponse{Error: "remote configuration needs an API token, set api.token first"})
+	}
+	return c.Next()
+}
+
+// requestContext gives every request a context that is canceled when the
+// handler returns or the app shuts down. Handlers pass c.UserContext() to
+// the station manager; work that outlives the request uses a.opCtx.
+func (a *App) requestContext(c *fiber.Ctx) error {
+	ctx, cancel := context.WithCancel(a.opCtx)
+	defer cancel()
+	c.SetUserContext(ctx)
+	return c.Next()
+}
+
+// runAfterScan answers a station command that has to wait for the running
+// scan with 202 Accepted and runs it in the background once the scan is over.
+func (a *App) runAfterScan(c *fiber.Ctx, op string, command func(context.Context, string, station.Source) error) error {
+	// Fiber reuses the request buffers once the handler returns
+	address, src := strings.Clone(c.Params("address")), apiSource(c)
+	src.ClientIP = strings.Clone(src.ClientIP)
+	go func() {
+		if err := command(a.opCtx, address, src); err != nil {
+			log.Printf("API %s error: %v", op, err)
+		}
+	}()
+	return c.Status(fiber.StatusAccepted).JSON(apiclient.CommandStatus{Status: apiclient.StatusWaitingForScan})
+}
+
+// runBulkAfterScan is runAfterScan for commands to several stations, whose
+// per-station results are only logged when the command ran in the background.
+func (a *App) runBulkAfterScan(c *fiber.Ctx, op string, command func(context.Context, station.Source) []station.RestoreResult) error {
+	src := apiSource(c)
+	src.ClientIP = strings.Clone(src.ClientIP)
+	go func() {
+		for _, result := range command(a.opCtx, src) {
+			if result.Status == station.RestoreFailed {
+				log.Printf("API %s error for %s: %s", op, result.Name, result.Error)
+			}
+		}
+	}()
+	return c.Status(fiber.StatusAccepted).JSON(apiclient.CommandStatus{Status: apiclient.StatusWaitingForScan})
+}
+
+// apiSource marks commands from an API request, with the client's address.
+func apiSource(c *fiber.Ctx) station.Source {
+	return station.Source{Kind: station.SourceAPI, ClientIP: c.IP()}
+}
+
+// setupAPI registers the HTTP API routes. The API always acts on this
+// machine's own stations, never on stations merged in from agents.
+func (a *App) setupAPI() {
+	a.api.Use(a.requestContext)
+
+	// The control page is public, it asks for the token itself
+	a.api.Get("/ui", func(c *fiber.Ctx) error {
+		c.Type("html")
+		return c.Send(webui.Index)
+	})
+
+	a.api.Use(a.requireToken)
+
+	a.api.Post("/allon", func(c *fiber.Ctx) error {
+		src := apiSource(c)
+		// ?wait=true runs synchronously, ?ready=true additionally waits until the stations are tracking-ready
+		waitReady := c.QueryBool("ready")
+		if c.QueryBool("wait") || waitReady {
+			results, err := a.stationManager.PowerOnAllStations(c.UserContext(), src)
+			if err != nil {
+				log.Printf("API PowerOnAllStations error: %v", err)
+				return bulkAPIError(c, err, results)
+			}
+			if !waitReady {
+				return c.JSON(results)
+			}
+			timeout := time.Duration(c.QueryInt("timeout", 90)) * time.Second
+			result := a.stationManager.WaitForReadiness(c.UserContext(), timeout)
+			if !result.Ready {
+				return c.Status(fiber.StatusGatewayTimeout).JSON(result)
+			}
+			return c.JSON(result)
+		}
+		// Use goroutine to avoid blocking API response while BT operation runs
+		go func() {
+			if _, err := a.stationManager.PowerOnAllStations(a.opCtx, src); err != nil {
+				log.Printf("API PowerOnAllStations error: %v", err)
+			}
+		}()
+		return c.SendStatus(fiber.StatusOK)
+	})
+	a.api.Post("/alloff", func(c *fiber.Ctx) error {
+		src := apiSource(c)
+		if c.QueryBool("wait") {
+			results, err := a.stationManager.PowerOffAllStations(c.UserContext(), src)
+			if err != nil {
+				log.Printf("API PowerOffAllStations error: %v", err)
+				return bulkAPIError(c, err, results)
+			}
+			return c.JSON(results)
+		}
+		// Use goroutine to avoid blocking API response while BT operation runs
+		go func() {
+			if _, err := a.stationManager.PowerOffAllStations(a.opCtx, src); err != nil {
+				log.Printf("API PowerOffAllStations error: %v", err)
+			}
+		}()
+		return c.SendStatus(fiber.StatusOK)
+	})
+	a.api.Post("/allstandby", func(c *fiber.Ctx) error {
+		src := apiSource(c)
+		if c.QueryBool("wait") {
+			results, err := a.stationManager.StandbyAllStations(c.UserContext(), src)
+			if err != nil {
+				log.Printf("API StandbyAllStations error: %v", err)
+				return bulkAPIError(c, err, results)
+			}
+			return c.JSON(results)
+		}
+		// Use goroutine to avoid blocking API response while BT operation runs
+		go func() {
+			if _, err := a.stationManager.StandbyAllStations(a.opCtx, src); err != nil {
+				log.Printf("API StandbyAllStations error: %v", err)
+			}
+		}()
+		return c.SendStatus(fiber.StatusOK)
+	})
+	a.api.Post("/station/:address/on", func(c *fiber.Ctx) error {
+		if a.stationManager.WaitsForScan() {
+			return a.runAfterScan(c, "PowerOnStation", a.stationManager.PowerOnStation)
+		}
+		if err := a.stationManager.PowerOnStation(c.UserContext(), c.Params("address"), apiSource(c)); err != nil {
+			log.Printf("API PowerOnStation error: %v", err)
+			return apiError(c, commandErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	a.api.Post("/station/:address/off", func(c *fiber.Ctx) error {
+		if a.stationManager.WaitsForScan() {
+			return a.runAfterScan(c, "PowerOffStation", a.stationManager.PowerOffStation)
+		}
+		if err := a.stationManager.PowerOffStation(c.UserContext(), c.Params("address"), apiSource(c)); err != nil {
+			log.Printf("API PowerOffStation error: %v", err)
+			return apiError(c, commandErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	a.api.Post("/station/:address/standby", func(c *fiber.Ctx) error {
+		if a.stationManager.WaitsForScan() {
+			return a.runAfterScan(c, "StandbyStation", a.stationManager.StandbyStation)
+		}
+		if err := a.stationManager.StandbyStation(c.UserContext(), c.Params("address"), apiSource(c)); err != nil {
+			log.Printf("API StandbyStation error: %v", err)
+			return apiError(c, commandErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	if a.debug {
+		a.api.Post("/debug/write", func(c *fiber.Ctx) error {
+			var req struct {
+				Address        string `json:"address"`
+				Service        string `json:"service"`
+				Characteristic string `json:"characteristic"`
+				Payload        string `json:"payload"` // Hex encoded
+				WithResponse   bool   `json:"withResponse"`
+			}
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+			}
+			log.Printf("WARNING: Debug raw write via API from %s", c.IP())
+			result, err := a.WriteRawCharacteristic(req.Address, req.Service, req.Characteristic, req.Payload, req.WithResponse)
+			if err != nil {
+				status, body := errorResponse(commandErrorStatus(err), err)
+				return c.Status(status).JSON(rawWriteErrorResponse{ErrorResponse: body, Written: result.Written})
+			}
+			return c.JSON(result)
+		})
+	}
+	a.api.Post("/essentials/on", func(c *fiber.Ctx) error {
+		if a.stationManager.WaitsForScan() {
+			return a.runBulkAfterScan(c, "PowerOnEssentials", a.stationManager.PowerOnEssentials)
+		}
+		return c.JSON(a.stationManager.PowerOnEssentials(c.UserContext(), apiSource(c)))
+	})
+	a.api.Post("/nonessentials/off", func(c *fiber.Ctx) error {
+		if a.stationManager.WaitsForScan() {
+			return a.runBulkAfterScan(c, "PowerOffNonEssentials", a.stationManager.PowerOffNonEssentials)
+		}
+		return c.JSON(a.stationManager.PowerOffNonEssentials(c.UserContext(), apiSource(c)))
+	})
+	a.api.Put("/station/:address/essential", func(c *fiber.Ctx) error {
+		var req struct {
+			Essential bool `json:"essential"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if err := a.stationManager.SetEssential(c.Params("address"), req.Essential); err != nil {
+			status := fiber.StatusInternalServerError
+			if errors.Is(err, station.ErrStationNotFound) {
+				status = fiber.StatusNotFound
+			}
+			return apiError(c, status, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Put("/station/:address/autoreconnect", func(c *fiber.Ctx) error {
+		var req struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if err := a.stationManager.SetAutoReconnect(c.Params("address"), req.Enabled); err != nil {
+			status := fiber.StatusInternalServerError
+			if errors.Is(err, station.ErrStationNotFound) {
+				status = fiber.StatusNotFound
+			}
+			return apiError(c, status, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Put("/station/:address/managed", func(c *fiber.Ctx) error {
+		var req struct {
+			Managed bool `json:"managed"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if err := a.stationManager.SetManaged(c.Params("address"), req.Managed); err != nil {
+			status := fiber.StatusInternalServerError
+			if errors.Is(err, station.ErrStationNotFound) {
+				status = fiber.StatusNotFound
+			}
+			return apiError(c, status, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Post("/station/:address/channel", func(c *fiber.Ctx) error {
+		var req struct {
+			Channel int `json:"channel"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if err := a.stationManager.SetStationChannel(c.UserContext(), c.Params("address"), req.Channel); err != nil {
+			log.Printf("API SetStationChannel error: %v", err)
+			return apiError(c, channelErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Delete("/station/:address", func(c *fiber.Ctx) error {
+		if err := a.stationManager.ForgetStation(c.UserContext(), c.Params("address")); err != nil {
+			status := fiber.StatusInternalServerError
+			if errors.Is(err, station.ErrStationNotFound) {
+				status = fiber.StatusNotFound
+			}
+			return apiError(c, status, err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Post("/station/:address/cycle", func(c *fiber.Ctx) error {
+		offSeconds := c.QueryInt("offSeconds", int(bluetooth.DefaultCycleOffDuration/time.Second))
+		if offSeconds < 1 || offSeconds > 60 {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: "offSeconds must be between 1 and 60"})
+		}
+		offDuration := time.Duration(offSeconds) * time.Second
+		if err := a.stationManager.PowerCycleStation(c.UserContext(), c.Params("address"), offDuration); err != nil {
+			log.Printf("API PowerCycleStation error: %v", err)
+			return apiError(c, commandErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	a.api.Get("/snapshots", func(c *fiber.Ctx) error {
+		return c.JSON(a.stationManager.PowerSnapshots())
+	})
+	a.api.Post("/snapshots", func(c *fiber.Ctx) error {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: "name is required"})
+		}
+		snapshot, err := a.stationManager.SnapshotStates(req.Name)
+		if err != nil {
+			return apiError(c, snapshotErrorStatus(err), err)
+		}
+		return c.Status(fiber.StatusCreated).JSON(snapshot)
+	})
+	a.api.Post("/snapshots/:name/restore", func(c *fiber.Ctx) error {
+		results, err := a.stationManager.RestoreSnapshot(c.UserContext(), c.Params("name"), apiSource(c))
+		if err != nil {
+			return apiError(c, snapshotErrorStatus(err), err)
+		}
+		return c.JSON(results)
+	})
+	a.api.Delete("/snapshots/:name", func(c *fiber.Ctx) error {
+		if err := a.stationManager.DeleteSnapshot(c.Params("name")); err != nil {
+			return apiError(c, snapshotErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Get("/groups", func(c *fiber.Ctx) error {
+		return c.JSON(a.stationManager.Groups())
+	})
+	a.api.Post("/groups", func(c *fiber.Ctx) error {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if strings.TrimSpace(req.Name) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: "name is required"})
+		}
+		if err := a.stationManager.CreateGroup(req.Name); err != nil {
+			return apiError(c, groupErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	a.api.Delete("/group/:name", func(c *fiber.Ctx) error {
+		if err := a.stationManager.DeleteGroup(c.Params("name")); err != nil {
+			return apiError(c, groupErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Post("/group/:name/on", func(c *fiber.Ctx) error {
+		results, err := a.stationManager.PowerOnGroup(c.UserContext(), c.Params("name"), apiSource(c))
+		if err != nil {
+			return apiError(c, groupErrorStatus(err), err)
+		}
+		return c.JSON(results)
+	})
+	a.api.Post("/group/:name/off", func(c *fiber.Ctx) error {
+		results, err := a.stationManager.PowerOffGroup(c.UserContext(), c.Params("name"), apiSource(c))
+		if err != nil {
+			return apiError(c, groupErrorStatus(err), err)
+		}
+		return c.JSON(results)
+	})
+	a.api.Put("/station/:address/group", func(c *fiber.Ctx) error {
+		var req struct {
+			Group string `json:"group"` // Empty to remove the station from its group
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if err := a.stationManager.AssignStationToGroup(c.Params("address"), req.Group); err != nil {
+			return apiError(c, groupErrorStatus(err), err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	a.api.Post("/session/resume", func(c *fiber.Ctx) error {
+		result, err := a.resumeLastSession(c.UserContext(), apiSource(c))
+		if err != nil {
+			return apiError(c, fiber.StatusNotFound, err)
+		}
+		return c.JSON(result)
+	})
+	a.api.Get("/config", a.requireConfigToken, func(c *fiber.Ctx) error {
+		data, err := a.config.Redacted()
+		if err != nil {
+			return apiError(c, fiber.StatusInternalServerError, err)
+		}
+		c.Type("json", "utf-8")
+		return c.Send(data)
+	})
+	a.api.Put("/config", a.requireConfigToken, func(c *fiber.Ctx) error {
+		result, err := a.updateConfig(c.Body(), apiSource(c))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		return c.JSON(result)
+	})
+	a.api.Get("/audit", func(c *fiber.Ctx) error {
+		filter, err := a.auditFilter(c.Query("station"), c.Query("from"), c.Query("to"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		filter.Limit = c.QueryInt("limit")
+		return c.JSON(a.audit.Query(filter))
+	})
+	a.api.Get("/selftest", func(c *fiber.Ctx) error {
+		report := a.stationManager.LastSelfTest()
+		if report == nil {
+			return c.Status(fiber.StatusNotFound).JSON(apiclient.ErrorResponse{Error: "no self-test has run yet"})
+		}
+		return c.JSON(report)
+	})
+	a.api.Post("/selftest", func(c *fiber.Ctx) error {
+		report, err := a.stationManager.RunSelfTest(c.UserContext(), c.Query("address"))
+		if err != nil {
+			return apiError(c, fiber.StatusConflict, err)
+		}
+		return c.JSON(report)
+	})
+	a.api.Get("/health", func(c *fiber.Ctx) error {
+		adapter := a.stationManager.AdapterStatus()
+		status := "ok"
+		if adapter.State != station.AdapterReady {
+			status = "degraded"
+		}
+		return c.JSON(fiber.Map{"status": status, "adapter": adapter})
+	})
+	// Add new GET /status endpoint
+	a.api.Get("/status", func(c *fiber.Ctx) error {
+		log.Println("API: Received GET /status request")
+		if c.QueryBool("refresh") {
+			// Concurrent refreshes share one check, see CheckAllStationStatuses
+			check, err := a.stationManager.CheckAllStationStatuses(c.UserContext())
+			if err != nil {
+				return apiError(c, fiber.StatusInternalServerError, err)
+			}
+			c.Set("X-Checked-At", check.CheckedAt.UTC().Format(time.RFC3339Nano))
+			c.Set("X-Status-Shared", strconv.FormatBool(check.Shared))
+		}
+		if list := c.Query("fields"); list != "" {
+			fields, err := selectStationFields(list)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+			}
+			return c.JSON(a.stationMaps(a.stationManager.GetStationInfo(), fields))
+		}
+		if c.QueryBool("verbose") {
+			return c.JSON(a.stationManager.VerboseStationInfo())
+		}
+		currentStations := a.stationManager.GetStationInfo() // Get current data
+		log.Printf("API: Returning status for %d stations", len(currentStations))
+		return c.JSON(currentStations)
+	})
+	a.api.Get("/export", func(c *fiber.Ctx) error {
+		format := strings.ToLower(c.Query("format", exportJSON))
+		fields, err := selectStationFields(c.Query("fields"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		data, err := a.encodeStations(format, fields)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		if format == exportCSV {
+			c.Type("csv", "utf-8")
+		} else {
+			c.Type("json", "utf-8")
+		}
+		c.Attachment(fmt.Sprintf("lhcontrol-stations-%s.%s", time.Now().Format("20060102-150405"), format))
+		return c.Send(data)
+	})
+	a.api.Post("/import", func(c *fiber.Ctx) error {
+		entries, err := station.ParseImport(c.Body())
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		report, err := a.stationManager.ImportStations(entries, c.QueryBool("overwrite"))
+		if err != nil {
+			return apiError(c, fiber.StatusInternalServerError, err)
+		}
+		return c.JSON(report)
+	})
+	// Add new POST /scan endpoint
+	a.api.Post("/scan", func(c *fiber.Ctx) error {
+		log.Println("API: Received POST /scan request")
+		// ?wait=true runs the scan synchronously and returns the stations
+		if c.QueryBool("wait") {
+			stations, err := a.stationManager.ScanAndFetchStations(c.UserContext())
+			if err != nil {
+				status := fiber.StatusInternalServerError
+				if errors.Is(err, station.ErrScanInProgress) {
+					status = fiber.StatusConflict
+				}
+				return apiError(c, status, err)
+			}
+			a.emit("external-scan-completed", stations)
+			return c.JSON(stations)
+		}
+		// Run scan in background to avoid blocking API response
+		go func() {
+			stations, err := a.stationManager.ScanAndFetchStations(a.opCtx)
+			if err != nil {
+				// Log error using standard logger (API goroutine might not have Wails context)
+				log.Printf("API: Error during background scan triggered by API: %v", err)
+			} else {
+				log.Println("API: Background scan triggered by API completed.")
+				// Emit an event to notify the frontend that a scan has completed
+				a.emit("external-scan-completed", stations)
+				log.Println("API: Emitted external-scan-completed event")
+			}
+		}()
+		// Return 202 Accepted immediately
+		return c.SendStatus(fiber.StatusAccepted)
+	})
+	a.api.Get("/stations/snapshot", func(c *fiber.Ctx) error {
+		return c.JSON(a.stationManager.StationSnapshot())
+	})
+	a.api.Get("/events/recent", func(c *fiber.Ctx) error {
+		q, err := recentQuery(c.Query("since"), c.Query("type"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(apiclient.ErrorResponse{Error: err.Error()})
+		}
+		q.Limit = c.QueryInt("limit")
+		return c.JSON(a.bus.Journal().Recent(q))
+	})
+	a.api.Get("/events/stream", a.streamEvents)
+}
+
+// streamEvents sends application events as Server-Sent Events. By default it
+// starts with a "stations" event holding the current station list and carries
+// full lists afterwards. With ?mode=delta it starts with a "stations-snapshot"
+// event and carries "stations-delta" events instead.
+func (a *App) streamEvents(c *fiber.Ctx) error {
+	deltaMode := c.Query("mode") == "delta"
+	events, unsubscribe := a.bus.Subscribe(32)
+	var initialName string
+	var initial interface{}
+	if deltaMode {
+		initialName, initial = "stations-snapshot", a.stationManager.StationSnapshot()
+	} else {
+		initialName, initial = "stations", a.stationManager.GetStationInfo()
+	}
+	remote := c.IP() // The Ctx is recycled once the handler returns
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+		log.Printf("API: Event stream opened by %s", remote)
+		// A connected client wants fresh data even while the window is hidden
+		if a.polling.StreamOpened() {
+			a.emitPollingState()
+		}
+		defer a.polling.StreamClosed()
+
+		write := func(name string, data interface{}) error {
+			payload, err := json.Marshal(data)
+			if err != nil {
+				log.Printf("API: Failed to encode event '%s': %v", name, err)
+				return nil
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+			return w.Flush()
+		}
+
+		if err := write(initialName, initial); err != nil {
+			return
+		}
+		keepalive := time.NewTicker(streamKeepalive)
+		defer keepalive.Stop()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if !streamWants(event.Name, deltaMode) {
+					continue
+				}
+				if err := write(event.Name, event.Data); err != nil {
+					log.Printf("API: Event stream closed: %v", err)
+					return
+				}
+			case <-keepalive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				if err := w.Flush(); err != nil {
+					log.Printf("API: Event stream closed: %v", err)
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// streamWants reports whether an event stream in the given mode carries an
+// event. Full-list streams skip deltas and delta streams skip full lists.
+func streamWants(name string, deltaMode bool) bool {
+	switch name {
+	case "scan-completed", "stations-updated":
+		return !deltaMode
+	case "stations-delta", "stations-snapshot":
+		return deltaMode
+	}
+	return true
+}
+
+// listenAPI binds the API address. If the port is taken, it tries up to
+// fallbackPorts following ports.
+func listenAPI(address string, fallbackPorts int) (net.Listener, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API address '%s': %w", address, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API port '%s': %w", portStr, err)
+	}
+
+	for i := 0; ; i++ {
+		candidate := net.JoinHostPort(host, strconv.Itoa(port+i))
+		listener, err := net.Listen("tcp", candidate)
+		if err == nil {
+			return listener, nil
+		}
+		if !instance.IsAddrInUse(err) || i >= fallbackPorts {
+			return nil, err
+		}
+		log.Printf("API: %s is in use, trying the next port", candidate)
+	}
+}
+
+// startAPI starts the API server in the background and announces the bound
+// address in the discovery file.
+func (a *App) startAPI(address string) {
+	listener, err := listenAPI(address, a.config.GetAPISettings().FallbackPorts)
+	if err != nil {
+		log.Printf("Error starting API server: %v", err)
+		return
+	}
+	bound := listener.Addr().String()
+	log.Printf("API: Listening on %s", bound)
+	if err := discovery.Write(bound); err != nil {
+		log.Printf("Error writing API discovery file: %v", err)
+	}
+	a.emit("api-listening", bound)
+
+	go func() {
+		if err := a.api.Listener(listener); err != nil {
+			log.Printf("Error running API server: %v", err)
+		}
+	}()
+}
+
+// apiError sends the error envelope: "error" keeps the technical message,
+// "code" and "message" are the translated, user-facing form. Failures caused
+// by the adapter are always 503, whatever status the route uses otherwise,
+// and their code tells whether it is starting, off or missing.
+func apiError(c *fiber.Ctx, status int, err error) error {
+	status, body := errorResponse(status, err)
+	return c.Status(status).JSON(body)
+}
+
+// bulkAPIError is apiError for a synchronous command on all stations. The body
+// also has the results of the stations, so clients can tell which failed.
+func bulkAPIError(c *fiber.Ctx, err error, results []station.StationOperationResult) error {
+	status, body := errorResponse(commandErrorStatus(err), err)
+	return c.Status(status).JSON(bulkErrorResponse{ErrorResponse: body, Results: results})
+}
+
+// bulkErrorResponse is the error envelope of a failed command on all stations.
+type bulkErrorResponse struct {
+	apiclient.ErrorResponse
+	Results []station.StationOperationResult `json:"results"`
+}
+
+// rawWriteErrorResponse is the error envelope of a failed debug write, with
+// the number of bytes written before it failed.
+type rawWriteErrorResponse struct {
+	apiclient.ErrorResponse
+	Written int `json:"written"`
+}
+
+// errorResponse returns the error envelope for err and the status to send it
+// with, which is status unless the adapter can't be used.
+func errorResponse(status int, err error) (int, apiclient.ErrorResponse) {
+	if adapterUnavailable(err) {
+		status = fiber.StatusServiceUnavailable
+	}
+	msg := usermsg.Translate(err)
+	return status, apiclient.ErrorResponse{
+		Error:   msg.Detail,
+		Code:    msg.Code,
+		Message: msg.Message,
+	}
+}
+
+// snapshotErrorStatus maps a power snapshot error to an HTTP status.
+func snapshotErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, station.ErrSnapshotNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, station.ErrNothingToSnapshot):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+// groupErrorStatus maps a group operation error to an HTTP status.
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, station.ErrGroupNotFound), errors.Is(err, station.ErrStationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, station.ErrGroupExists):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+// channelErrorStatus maps a channel change error to an HTTP status.
+func channelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, station.ErrStationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, bluetooth.ErrInvalidChannel):
+		return fiber.StatusBadRequest
+	case errors.Is(err, station.ErrChannelConflict):
+		return fiber.StatusConflict
+	}
+	return commandErrorStatus(err)
+}
+
+// adapterUnavailable reports whether err means Bluetooth can't be used right
+// now, as opposed to a failing station.
+func adapterUnavailable(err error) bool {
+	return errors.Is(err, station.ErrAdapterInitializing) || errors.Is(err, station.ErrAdapterUnavailable) ||
+		errors.Is(err, station.ErrNoAdapter) || errors.Is(err, bluetooth.ErrAdapter) ||
+		errors.Is(err, bluetooth.ErrNoAdapter) || errors.Is(err, bluetooth.ErrAdapterDisabled)
+}
+
+// commandErrorStatus maps a power command error to an HTTP status. A command
+// replaced by a newer one before it started gets 409 Conflict, like one for a
+// busy station. Adapter problems are 503, and stations that failed or didn't
+// answer are treated like a failing upstream server.
+func commandErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, station.ErrSuperseded), errors.Is(err, bluetooth.ErrBusy):
+		return fiber.StatusConflict
+	case errors.Is(err, station.ErrStationNotFound):
+		return fiber.StatusNotFound
+	case adapterUnavailable(err):
+		return fiber.StatusServiceUnavailable
+	case errors.Is(err, bluetooth.ErrTimeout):
+		return fiber.StatusGatewayTimeout
+	case errors.Is(err, bluetooth.ErrUnsupported):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, bluetooth.ErrWrite), errors.Is(err, bluetooth.ErrRead),
+		errors.Is(err, bluetooth.ErrConnect), errors.Is(err, bluetooth.ErrNotConnected),
+		errors.Is(err, bluetooth.ErrDiscovery), errors.Is(err, bluetooth.ErrCharacteristicMissing),
+		errors.Is(err, bluetooth.ErrStateMismatch):
+		return fiber.StatusBadGateway
+	}
+	return fiber.StatusInternalServerError
+}
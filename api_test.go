@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/station"
+	"lhcontrol/pkg/apiclient"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testStation returns a V2 station of the fake backend that starts asleep.
+func testStation(n int) bluetooth.FakeStation {
+	return bluetooth.FakeStation{
+		Address:  bluetooth.Address(fmt.Sprintf("02:00:00:00:00:%02X", n)),
+		Name:     fmt.Sprintf("LHB-0000%04X", n),
+		RawPower: bluetooth.RawPowerStateSleep,
+	}
+}
+
+// newTestApp returns an app with its API set up, whose stations are those
+// of a fake backend and known from the config. The config is saved to a
+// temporary directory.
+func newTestApp(t *testing.T, stations ...bluetooth.FakeStation) (*App, *bluetooth.FakeBackend) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(env, dir)
+	}
+
+	previous := bluetooth.ActiveBackend()
+	fake := bluetooth.NewFakeBackend(stations...)
+	bluetooth.SetBackend(fake)
+	a := NewApp()
+	a.opCtx, a.opCancel = context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		a.opCancel()
+		a.stationManager.Shutdown()
+		bluetooth.SetBackend(previous)
+	})
+
+	known := make([]config.KnownStation, 0, len(stations))
+	for _, s := range stations {
+		known = append(known, config.KnownStation{Address: s.Address.String(), OriginalName: s.Name})
+	}
+	if err := a.config.SetKnownStations(known); err != nil {
+		t.Fatal(err)
+	}
+	a.stationManager.InitializeAsync()
+	deadline := time.Now().Add(5 * time.Second)
+	for a.stationManager.AdapterStatus().State != station.AdapterReady {
+		if time.Now().After(deadline) {
+			t.Fatalf("adapter didn't initialize: %+v", a.stationManager.AdapterStatus())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	a.stationManager.SeedStations()
+	a.setupAPI()
+	return a, fake
+}
+
+// apiRequest sends a request to the app's API and returns the response
+// with its body read.
+func apiRequest(t *testing.T, a *App, method, path string, body io.Reader) (*http.Response, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, body)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.api.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return resp, data
+}
+
+// TestConcurrentRenameAndStatus renames a station while its state is read
+// through the manager and the API, and commands run on it. Run it with -race.
+func TestConcurrentRenameAndStatus(t *testing.T) {
+	s := testStation(1)
+	a, _ := newTestApp(t, s, testStation(2))
+	address := s.Address.String()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	reader := func(read func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				read()
+			}
+		}()
+	}
+	reader(func() { a.stationManager.GetStationInfo() })
+	reader(func() {
+		resp, data := apiRequest(t, a, http.MethodGet, "/status", nil)
+		var infos []apiclient.StationInfo
+		if resp.StatusCode != http.StatusOK || json.Unmarshal(data, &infos) != nil || len(infos) != 2 {
+			t.Errorf("GET /status answered %d: %s", resp.StatusCode, data)
+		}
+	})
+	// Commands on the same station are serialized by its operation lock
+	reader(func() { _ = a.stationManager.PowerOnStation(a.opCtx, address, uiSource) })
+	reader(func() { _ = a.stationManager.PowerOffStation(a.opCtx, address, uiSource) })
+
+	for i := 0; i < 10; i++ {
+		if err := a.RenameStation(s.Name, fmt.Sprintf("Station %d", i)); err != nil {
+			t.Errorf("rename %d: %v", i, err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	for _, info := range a.stationManager.GetStationInfo() {
+		if info.Address == address && info.Name != "Station 9" {
+			t.Errorf("station is named %q after the renames, want %q", info.Name, "Station 9")
+		}
+	}
+}
+
+func TestDebugWriteError(t *testing.T) {
+	a, _ := newTestApp(t, testStation(1))
+	// The debug routes are only set up with -debug
+	a.debug = true
+	a.api = fiber.New()
+	a.setupAPI()
+
+	body := `{"address":"02:00:00:00:00:99","service":"00001523-1212-efde-1523-785feabcd124","characteristic":"00001525-1212-efde-1523-785feabcd124","payload":"01"}`
+	resp, data := apiRequest(t, a, http.MethodPost, "/debug/write", strings.NewReader(body))
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("write to an unknown station answered %d, want 404", resp.StatusCode)
+	}
+	var errResp struct {
+		apiclient.ErrorResponse
+		Written *int `json:"written"`
+	}
+	if err := json.Unmarshal(data, &errResp); err != nil {
+		t.Fatal(err)
+	}
+	if errResp.Code == "" || errResp.Message == "" || errResp.Error == "" {
+		t.Errorf("error envelope is incomplete: %s", data)
+	}
+	if errResp.Written == nil || *errResp.Written != 0 {
+		t.Errorf("error envelope doesn't say nothing was written: %s", data)
+	}
+}
+
+// The essentials routes hold their commands while a scan runs, like the
+// station commands.
+func TestEssentialsWaitForScan(t *testing.T) {
+	s := testStation(1)
+	a, _ := newTestApp(t, s, testStation(2))
+	if err := a.stationManager.SetEssential(s.Address.String(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	scanCtx, stopScan := context.WithCancel(context.Background())
+	scanned := make(chan struct{})
+	go func() {
+		defer close(scanned)
+		_, _ = a.stationManager.ScanAndFetchStations(scanCtx)
+	}()
+	defer func() {
+		stopScan()
+		<-scanned
+	}()
+	for !a.stationManager.IsScanning() {
+		time.Sleep(time.Millisecond)
+	}
+
+	resp, data := apiRequest(t, a, http.MethodPost, "/essentials/on", nil)
+	var status apiclient.CommandStatus
+	if resp.StatusCode != http.StatusAccepted || json.Unmarshal(data, &status) != nil || status.Status != apiclient.StatusWaitingForScan {
+		t.Fatalf("POST /essentials/on during a scan answered %d: %s", resp.StatusCode, data)
+	}
+	resp, data = apiRequest(t, a, http.MethodPost, "/nonessentials/off", nil)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("POST /nonessentials/off during a scan answered %d: %s", resp.StatusCode, data)
+	}
+
+	// The held command runs once the scan is stopped
+	stopScan()
+	<-scanned
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var state int
+		for _, info := range a.stationManager.GetStationInfo() {
+			if info.Address == s.Address.String() {
+				state = info.PowerState
+			}
+		}
+		if bluetooth.IsPoweredOn(state) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("essential station is in state %d after the scan, want on", state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Station routes accept addresses in any case and with any separators.
+func TestStationAddressNormalized(t *testing.T) {
+	s := testStation(10)
+	a, _ := newTestApp(t, s)
+	for _, address := range []string{"02:00:00:00:00:0A", "02:00:00:00:00:0a", "02-00-00-00-00-0a", "02000000000A", s.Name} {
+		resp, data := apiRequest(t, a, http.MethodPost, "/station/"+address+"/standby", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("POST /station/%s/standby answered %d: %s", address, resp.StatusCode, data)
+		}
+	}
+	resp, _ := apiRequest(t, a, http.MethodPost, "/station/02:00:00:00:00:0B/standby", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("command to an unknown address answered %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestCommandErrorStatus(t *testing.T) {
+	stationErr := func(kind error) error {
+		return fmt.Errorf("power on: %w", &bluetooth.Error{Station: "LHB-A", Kind: kind, Err: errors.New("detail")})
+	}
+	for _, test := range []struct {
+		err  error
+		want int
+	}{
+		{stationErr(bluetooth.ErrBusy), http.StatusConflict},
+		{fmt.Errorf("power on: %w", station.ErrSuperseded), http.StatusConflict},
+		{fmt.Errorf("%w: 02:00:00:00:00:01", station.ErrStationNotFound), http.StatusNotFound},
+		{stationErr(bluetooth.ErrAdapterDisabled), http.StatusServiceUnavailable},
+		{stationErr(bluetooth.ErrNoAdapter), http.StatusServiceUnavailable},
+		{station.ErrAdapterInitializing, http.StatusServiceUnavailable},
+		{stationErr(bluetooth.ErrTimeout), http.StatusGatewayTimeout},
+		{stationErr(bluetooth.ErrUnsupported), http.StatusUnprocessableEntity},
+		{stationErr(bluetooth.ErrWrite), http.StatusBadGateway},
+		{stationErr(bluetooth.ErrConnect), http.StatusBadGateway},
+		{stationErr(bluetooth.ErrNotConnected), http.StatusBadGateway},
+		{stationErr(bluetooth.ErrCharacteristicMissing), http.StatusBadGateway},
+		{stationErr(bluetooth.ErrStateMismatch), http.StatusBadGateway},
+		{errors.New("something else"), http.StatusInternalServerError},
+	} {
+		if got := commandErrorStatus(test.err); got != test.want {
+			t.Errorf("commandErrorStatus(%v) = %d, want %d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestAllOnResults(t *testing.T) {
+	a, _ := newTestApp(t, testStation(1), testStation(2))
+	resp, data := apiRequest(t, a, http.MethodPost, "/allon?wait=true", nil)
+	var results []station.StationOperationResult
+	if resp.StatusCode != http.StatusOK || json.Unmarshal(data, &results) != nil {
+		t.Fatalf("POST /allon?wait=true answered %d: %s", resp.StatusCode, data)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2: %s", len(results), data)
+	}
+	for _, result := range results {
+		if !result.Success || result.Error != "" || result.Address == "" || result.Name == "" {
+			t.Errorf("station %s: %+v, want a success", result.Address, result)
+		}
+	}
+}
+
+// An agent's API is reachable from the network, without a token it serves
+// nothing.
+func TestAgentModeRequiresToken(t *testing.T) {
+	a, _ := newTestApp(t, testStation(1))
+	a.agentMode = true
+	if resp, data := apiRequest(t, a, http.MethodGet, "/status", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /status without any token answered %d: %s", resp.StatusCode, data)
+	}
+
+	settings := a.config.GetAPISettings()
+	settings.Token = "secret"
+	a.config.SetAPISettings(settings)
+	if resp, _ := apiRequest(t, a, http.MethodGet, "/status", nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /status without the token answered %d", resp.StatusCode)
+	}
+	if resp, data := apiRequest(t, a, http.MethodGet, "/status?token=secret", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /status with the token answered %d: %s", resp.StatusCode, data)
+	}
+}
+
+func TestSafetyAction(t *testing.T) {
+	for action, want := range map[string]int{
+		config.ActionOff:     bluetooth.PowerStateOff,
+		config.ActionStandby: bluetooth.PowerStateStandby,
+	} {
+		t.Run(action, func(t *testing.T) {
+			s := testStation(1)
+			s.RawPower = bluetooth.RawPowerStateOn
+			a, _ := newTestApp(t, s)
+			settings := a.config.GetSafetySettings()
+			settings.Action = action
+			if err := config.ValidateSafetySettings(settings); err != nil {
+				t.Fatal(err)
+			}
+			a.onSafetyAction(s.Address.String(), settings)
+			for _, info := range a.stationManager.GetStationInfo() {
+				if info.PowerState != want {
+					t.Errorf("station is in state %d after the safety action, want %d", info.PowerState, want)
+				}
+			}
+		})
+	}
+}
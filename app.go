@@ -2,144 +2,714 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"lhcontrol/internal/agent"
+	"lhcontrol/internal/audit"
+	"lhcontrol/internal/automation"
+	"lhcontrol/internal/badge"
 	"lhcontrol/internal/bluetooth"
 	"lhcontrol/internal/config"
+	"lhcontrol/internal/diagnostics"
+	"lhcontrol/internal/discovery"
+	"lhcontrol/internal/events"
+	"lhcontrol/internal/instance"
+	"lhcontrol/internal/jobs"
+	"lhcontrol/internal/notify"
+	"lhcontrol/internal/paths"
+	"lhcontrol/internal/platform"
+	"lhcontrol/internal/polling"
+	"lhcontrol/internal/powersource"
+	"lhcontrol/internal/redact"
+	"lhcontrol/internal/safety"
+	"lhcontrol/internal/session"
 	"lhcontrol/internal/station"
+	"lhcontrol/internal/stats"
+	"lhcontrol/internal/systemd"
+	"lhcontrol/internal/timers"
+	"lhcontrol/internal/usermsg"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// AboutInfo describes the running application for the About screen.
+type AboutInfo struct {
+	Name     string      `json:"name"`
+	Version  string      `json:"version"`
+	Instance string      `json:"instance,omitempty"` // Set for named instances, see -instance
+	Paths    paths.Paths `json:"paths"`
+}
+
 // App struct
 type App struct {
 	ctx            context.Context
+	opCtx          context.Context    // Derived from ctx and canceled first on shutdown, station operations run under it
+	opCancel       context.CancelFunc // Cancels opCtx
 	config         *config.Config
 	stationManager *station.Manager
 	api            *fiber.App
+	stopWatchdog   func()
+	watcher        *automation.Watcher
+	powerMonitor   *powersource.Monitor
+	stats          *stats.Tracker
+	safetyMonitor  *safety.Monitor
+	bus            *events.Bus
+	agents         *agent.Registry
+	audit          *audit.Journal
+
+	agentMode  bool               // Headless mode, driven by another lhcontrol instance
+	debug      bool               // Enables the raw characteristic write binding and API route
+	listenAddr string             // Overrides the configured API address when set
+	cancel     context.CancelFunc // Ends the headless run loop in agent mode
+
+	quitMu      sync.Mutex // Orders requestQuit against startup setting ctx
+	quitPending bool       // A quit was requested before startup, guarded by quitMu
+
+	pendingArgs []string // Command URIs from the command line, run after startup
+	badge       *badge.Updater
+	timers      *timers.Manager
+	jobs        *jobs.Manager
+
+	sessionRecorder *session.Recorder
+	polling         *polling.Governor
+	lastSession     *session.Record // Stations that were on when the app last exited, nil if unknown
+
+	shutdownOnce  sync.Once
+	exitPowerOnce sync.Once // Guards the shutdown action, which runs on window close or in cleanup
+}
+
+// AppStatus is a snapshot of application-level state for the UI.
+type AppStatus struct {
+	OnBattery        bool           `json:"onBattery"`
+	PowerSourceKnown bool           `json:"powerSourceKnown"` // False on machines without a battery or unsupported platforms
+	Polling          polling.Status `json:"polling"`          // Whether station states are being refreshed, and why not
+}
+
+// Timeouts for requests to remote agents
+const (
+	agentRequestTimeout = 10 * time.Second
+	agentCommandTimeout = 60 * time.Second
+)
+
+// agentListenPort is the API port used in agent mode unless overridden with
+// -listen. Named instances derive theirs from it, see instance.APIPort.
+const agentListenPort = 7575
+
+// agentListenAddress returns the API address used in agent mode.
+func agentListenAddress() string {
+	return fmt.Sprintf("0.0.0.0:%d", instance.APIPort(agentListenPort))
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	cfg := config.NewConfig()
-	mgr := station.NewManager(cfg)
-	return &App{
+	mgr := station.NewManager(cfg, bluetooth.ActiveBackend())
+	app := &App{
 		config:         cfg,
 		stationManager: mgr,
 		api:            fiber.New(),
+		stats:          stats.NewTracker(),
+		bus:            events.NewBus(),
+		agents:         agent.NewRegistry(cfg, 10*time.Second),
+		polling:        polling.NewGovernor(cfg),
+		audit:          audit.NewJournal(),
 	}
+	app.jobs = jobs.NewManager(app.emit)
+	mgr.SetEventEmitter(app.emit)
+	mgr.SetEmptyScanHandler(app.onEmptyScan)
+	mgr.SetStateRevertedHandler(app.onStateReverted)
+	mgr.SetCommandHandler(app.onCommand)
+	mgr.SetErrorCoder(usermsg.Code)
+	return app
+}
+
+// emit publishes an event to API stream subscribers and sends it to the
+// frontend if the Wails context is available.
+func (a *App) emit(eventName string, data ...interface{}) {
+	var payload interface{}
+	if len(data) == 1 {
+		payload = data[0]
+	} else if len(data) > 1 {
+		payload = data
+	}
+	a.bus.Publish(eventName, payload)
+
+	if a.ctx == nil || a.agentMode {
+		return
+	}
+	runtime.EventsEmit(a.ctx, eventName, data...)
 }
 
 // startup is called when the app starts.
 func (a *App) startup(ctx context.Context) {
+	a.opCtx, a.opCancel = context.WithCancel(ctx)
+	a.quitMu.Lock()
 	a.ctx = ctx
+	quit := a.quitPending
+	a.quitMu.Unlock()
+	if quit {
+		// A signal arrived before the window existed and shutdown already ran
+		log.Println("Quit requested before startup, closing.")
+		a.opCancel()
+		runtime.Quit(ctx)
+		return
+	}
 
 	// Use standard logger (already configured in main)
 	log.Println("-----------------------------------------")
 	log.Println("Application startup initiated.")
 	log.Println("-----------------------------------------")
 
-	if err := a.stationManager.Initialize(); err != nil {
-		log.Printf("Error initializing Bluetooth: %v", err)
-	}
-
 	if err := a.config.Load(); err != nil {
 		log.Printf("Error loading config: %v", err)
 	}
+	redact.SetEnabled(a.config.GetPrivacySettings().RedactAddresses)
+	if a.stationManager.NormalizeConfigAddresses() {
+		if err := a.config.Save(); err != nil {
+			log.Printf("Error saving config with normalized station addresses: %v", err)
+		}
+	}
+	a.stationManager.SeedStations()
+	a.stationManager.ApplyConnectionParams()
 
-	// Setup API routes
-	a.api.Post("/allon", func(c *fiber.Ctx) error {
-		// Use goroutine to avoid blocking API response while BT operation runs
-		go func() {
-			if err := a.stationManager.PowerOnAllStations(); err != nil {
-				log.Printf("API PowerOnAllStations error: %v", err)
-			}
-		}()
-		return c.SendStatus(fiber.StatusOK)
-	})
-	a.api.Post("/alloff", func(c *fiber.Ctx) error {
-		// Use goroutine to avoid blocking API response while BT operation runs
-		go func() {
-			if err := a.stationManager.PowerOffAllStations(); err != nil {
-				log.Printf("API PowerOffAllStations error: %v", err)
-			}
-		}()
-		return c.SendStatus(fiber.StatusOK)
-	})
-	// Add new GET /status endpoint
-	a.api.Get("/status", func(c *fiber.Ctx) error {
-		log.Println("API: Received GET /status request")
-		currentStations := a.GetCurrentStationInfo() // Get current data
-		log.Printf("API: Returning status for %d stations", len(currentStations))
-		return c.JSON(currentStations)
-	})
-	// Add new POST /scan endpoint
-	a.api.Post("/scan", func(c *fiber.Ctx) error {
-		log.Println("API: Received POST /scan request")
-		// Run scan in background to avoid blocking API response
-		go func() {
-			stations, err := a.ScanAndFetchStations()
-			if err != nil {
-				// Log error using standard logger (API goroutine might not have Wails context)
-				log.Printf("API: Error during background scan triggered by API: %v", err)
-			} else {
-				log.Println("API: Background scan triggered by API completed.")
-				// Emit an event to notify the frontend that a scan has completed
-				if a.ctx != nil {
-					runtime.EventsEmit(a.ctx, "external-scan-completed", stations)
-					log.Println("API: Emitted external-scan-completed event")
-				}
-			}
-		}()
-		// Return 202 Accepted immediately
-		return c.SendStatus(fiber.StatusAccepted)
-	})
-	// Start API server in a goroutine
-	go func() {
-		if err := a.api.Listen("127.0.0.1:7575"); err != nil {
-			log.Printf("Error starting API server: %v", err)
+	// Bring up the window and API while the adapter initializes, early
+	// requests wait for it in the station manager
+	a.stationManager.InitializeAsync()
+	// Keep the states current for API clients while the window is idle,
+	// within the same limits as the window's own polling
+	a.stationManager.StartPolling(a.polling.Allow)
+
+	if err := a.stats.Load(); err != nil {
+		log.Printf("Error loading stats: %v", err)
+	}
+	if err := a.audit.Load(); err != nil {
+		log.Printf("Error loading audit log: %v", err)
+	}
+	a.audit.Start()
+
+	// Agent mode exposes the API to the network and always requires a token
+	address := a.config.GetAPISettings().Address
+	serveAPI := true
+	if a.agentMode {
+		if _, err := ensureAPIToken(a.config, false); err != nil {
+			log.Printf("Agent mode: Not starting the API without a token: %v", err)
+			serveAPI = false
 		}
-	}()
+		a.config.RequireAPIToken()
+		address = agentListenAddress()
+	}
+	if a.listenAddr != "" {
+		address = a.listenAddr
+	}
+	a.setupAPI()
+	if serveAPI {
+		if a.agentMode {
+			log.Printf("Agent mode: API on %s, run 'lhcontrol agent-pair' to show the connection details.", address)
+		}
+		a.startAPI(address)
+	}
+
+	if a.agentMode {
+		log.Println("Agent mode: automation, power source monitoring and remote agents are disabled.")
+		a.startSafety()
+		a.notifyReady()
+		return
+	}
+
+	// Poll remote agents and merge their stations
+	a.agents.Start()
+
+	// Toasts need an AppUserModelID and the protocol handler used by their buttons
+	if err := notify.Register(appTitle); err != nil {
+		log.Printf("Error registering for notifications, using fallback notifications: %v", err)
+	}
+	// The jump list and the lhcontrol: protocol launch the executable without
+	// -instance, so they belong to the default instance
+	if instance.Name() == "" {
+		go a.updateJumpList()
+	}
 
+	// Restore one-shot timers that were pending when the app last exited
+	a.timers = timers.NewManager(a.config, a.runTimer)
+	report, err := a.timers.Restore()
+	if err != nil {
+		log.Printf("Error restoring timers: %v", err)
+	}
+	if len(report.Restored)+len(report.Fired)+len(report.Skipped) > 0 {
+		log.Printf("Timers: %d restored, %d fired late, %d skipped", len(report.Restored), len(report.Fired), len(report.Skipped))
+		a.emit("timers-restored", report)
+	}
+	a.timers.Start()
+
+	// Keep the taskbar badge in sync with station state changes
+	a.badge = badge.NewUpdater(time.Second, a.applyBadge)
+	go a.runBadge()
+
+	// Start process-trigger automation
+	a.watcher = automation.NewWatcher(a.config, 3*time.Second, a.runAutomation)
+	a.watcher.Start()
+
+	// Start AC/battery monitoring
+	a.powerMonitor = powersource.NewMonitor(5*time.Second, func() time.Duration {
+		return time.Duration(a.config.GetPowerSourceSettings().DebounceSeconds) * time.Second
+	}, a.onPowerSourceChanged)
+	a.powerMonitor.Start()
+
+	a.startSafety()
+	a.startSession()
+	a.notifyReady()
+
+	// Commands passed to the first launch, e.g. from a notification button
+	if len(a.pendingArgs) > 0 {
+		go a.runArgs(a.pendingArgs)
+	}
+}
+
+// startSafety starts tracking on-time and enforcing the safety cap.
+func (a *App) startSafety() {
+	a.safetyMonitor = safety.NewMonitor(a.config, a.stats, a.stationManager, 30*time.Second, a.onSafetyWarning, a.onSafetyAction)
+	a.safetyMonitor.Start()
+}
+
+// sessionCheckpointInterval is how often the stations that are on are recorded,
+// for resuming after a crash that skipped the shutdown sequence.
+const sessionCheckpointInterval = time.Minute
+
+// startupScanTimeout is how long the startup action waits for a running scan.
+const startupScanTimeout = time.Minute
+
+// startSession loads the record of the previous session before the new one
+// overwrites it, starts checkpointing and runs the startup action.
+func (a *App) startSession() {
+	record, ok, err := session.Load()
+	if err != nil {
+		log.Printf("Error loading last session: %v", err)
+	} else if ok {
+		if !record.Clean {
+			log.Printf("Session: The last session didn't shut down cleanly, using its checkpoint from %s", record.SavedAt.Format(time.DateTime))
+		}
+		a.lastSession = &record
+	}
+	a.sessionRecorder = session.NewRecorder(a.stationManager, sessionCheckpointInterval)
+	a.sessionRecorder.Start()
+
+	if action := a.config.GetStartupSettings().Action; action != config.StartupNone {
+		go a.runStartupAction(action)
+	}
+}
+
+// StartupProgress reports the startup action as "startup-progress" events,
+// so the window can tell which stations came up.
+type StartupProgress struct {
+	Action string   `json:"action"`           // config.StartupOn or config.StartupResume
+	Step   string   `json:"step"`             // "scanning", "running", "done" or "failed"
+	On     []string `json:"on,omitempty"`     // Names of the local stations that are on once done
+	Failed []string `json:"failed,omitempty"` // Names of the stations whose command failed
+	Error  string   `json:"error,omitempty"`
+}
+
+// runStartupAction waits for the stations to be known, scanning if no scan
+// ran yet and no station is known from earlier sessions, and then runs the
+// configured startup action. Failures are reported, startup goes on anyway.
+func (a *App) runStartupAction(action string) {
+	failed := func(err error) {
+		log.Printf("Startup: Stations unknown, not running '%s': %v", action, err)
+		a.emit("startup-progress", StartupProgress{Action: action, Step: "failed", Error: usermsg.Translate(err).Message})
+	}
+	waitScan := func() error {
+		ctx, cancel := context.WithTimeout(a.opCtx, startupScanTimeout)
+		defer cancel()
+		return a.stationManager.WaitScan(ctx)
+	}
+	if err := waitScan(); err != nil {
+		failed(err)
+		return
+	}
+	if len(a.stationManager.GetStationInfo()) == 0 {
+		a.emit("startup-progress", StartupProgress{Action: action, Step: "scanning"})
+		if _, err := a.stationManager.ScanAndFetchStations(a.opCtx); err != nil && !errors.Is(err, station.ErrScanInProgress) {
+			failed(err)
+			return
+		}
+		// Another scan may have started meanwhile
+		if err := waitScan(); err != nil {
+			failed(err)
+			return
+		}
+	}
+
+	log.Printf("Startup: Running '%s'", action)
+	a.emit("startup-progress", StartupProgress{Action: action, Step: "running"})
+	progress := StartupProgress{Action: action, Step: "done"}
+	var err error
+	switch action {
+	case config.StartupOn:
+		err = a.waitJob(a.powerJob(station.Source{Kind: station.SourceStartup}, config.ActionOn, ""))
+		var bulk *station.BulkError
+		if errors.As(err, &bulk) {
+			progress.Failed = failedNames(bulk.Results)
+		}
+	case config.StartupResume:
+		var result session.ResumeResult
+		result, err = a.resumeLastSession(a.opCtx, station.Source{Kind: station.SourceStartup})
+		progress.Failed = failedNames(result.Results)
+	}
+	for _, info := range a.stationManager.GetStationInfo() {
+		if info.PowerState == bluetooth.PowerStateOn || info.PowerState == bluetooth.PowerStateBooting {
+			progress.On = append(progress.On, info.Name)
+		}
+	}
+	sort.Strings(progress.On)
+	if err != nil {
+		log.Printf("Startup: Action '%s' failed: %v", action, err)
+		progress.Step = "failed"
+		progress.Error = usermsg.Translate(err).Message
+	}
+	log.Printf("Startup: %d station(s) on, %d failed", len(progress.On), len(progress.Failed))
+	a.emit("startup-progress", progress)
+}
+
+// failedNames returns the names of the stations whose command failed.
+func failedNames(results []station.RestoreResult) []string {
+	var names []string
+	for _, result := range results {
+		if result.Status == station.RestoreFailed {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+// notifyReady finishes startup and reports readiness when running as a systemd notify service.
+func (a *App) notifyReady() {
 	log.Println("Startup sequence complete.")
+
+	if _, err := systemd.Notify("READY=1"); err != nil {
+		log.Printf("Error notifying systemd: %v", err)
+	}
+	a.stopWatchdog = systemd.StartWatchdog()
+}
+
+// requestQuit asks Wails to close the window, which runs the regular shutdown
+// sequence. Before Wails called startup the quit is left for startup to do.
+// In agent mode it cancels the headless run loop instead.
+func (a *App) requestQuit() {
+	if a.agentMode {
+		if a.cancel != nil {
+			a.cancel()
+		}
+		return
+	}
+	a.quitMu.Lock()
+	ctx := a.ctx
+	if ctx == nil {
+		a.quitPending = true
+	}
+	a.quitMu.Unlock()
+	if ctx != nil {
+		runtime.Quit(ctx)
+	}
 }
 
 // --- Bluetooth Methods exposed to Wails --- //
 
 func (a *App) ScanAndFetchStations() ([]station.StationInfo, error) {
-	return a.stationManager.ScanAndFetchStations()
+	// Agents scan on their own, their results arrive with the next refresh
+	for _, client := range a.agents.Clients() {
+		go func(c *agent.Client) {
+			ctx, cancel := context.WithTimeout(a.opCtx, agentRequestTimeout)
+			defer cancel()
+			if err := c.Scan(ctx); err != nil {
+				log.Printf("Error starting scan on agent: %v", err)
+			}
+		}(client)
+	}
+	stations, err := a.stationManager.ScanAndFetchStations(a.opCtx)
+	return append(stations, a.agents.Stations()...), a.userError("Scan", err)
+}
+
+// GetAdapterStatus reports whether the Bluetooth adapter is still initializing, ready or failed.
+func (a *App) GetAdapterStatus() station.AdapterStatus {
+	return a.stationManager.AdapterStatus()
+}
+
+// RetryInitialize tries to enable the Bluetooth adapter again after it failed
+// or was turned off.
+func (a *App) RetryInitialize() error {
+	return a.stationManager.RetryInitialize()
+}
+
+// ListBluetoothAdapters returns the Bluetooth adapters of this machine, with
+// the one in use marked active.
+func (a *App) ListBluetoothAdapters() ([]bluetooth.AdapterInfo, error) {
+	adapters, err := a.stationManager.ListAdapters()
+	return adapters, a.userError("List adapters", err)
+}
+
+// SetBluetoothAdapter switches to another Bluetooth adapter, empty for the
+// system default, and remembers the choice.
+func (a *App) SetBluetoothAdapter(id string) error {
+	// The choice is kept even if the adapter failed to start, so a dongle
+	// plugged in later is picked up
+	err := a.stationManager.SelectAdapter(id)
+	if saveErr := a.config.Save(); saveErr != nil && err == nil {
+		return saveErr
+	}
+	return a.userError("Select adapter", err)
 }
 
 func (a *App) IsScanning() bool {
 	return a.stationManager.IsScanning()
 }
 
-func (a *App) CheckAllStationStatuses() ([]station.StationInfo, error) {
-	return a.stationManager.CheckAllStationStatuses()
+// CheckAllStationStatuses reads the station states, see
+// station.Manager.CheckAllStationStatuses. While polling is paused it returns
+// the known states with the time of the last check.
+func (a *App) CheckAllStationStatuses() (station.StatusCheck, error) {
+	if !a.polling.Allow() {
+		// Nobody is looking, keep the radio quiet and serve what we have
+		return station.StatusCheck{Stations: a.GetCurrentStationInfo(), CheckedAt: a.stationManager.LastStatusCheck(), Shared: true}, nil
+	}
+	// Agent stations are refreshed by the registry's own poll loop
+	check, err := a.stationManager.CheckAllStationStatuses(a.opCtx)
+	check.Stations = append(check.Stations, a.agents.Stations()...)
+	return check, a.userError("Status check", err)
 }
 
 func (a *App) GetCurrentStationInfo() []station.StationInfo {
-	return a.stationManager.GetStationInfo()
+	return append(a.stationManager.GetStationInfo(), a.agents.Stations()...)
+}
+
+// GetStationSnapshot returns the local station list with the sequence number of
+// the last "stations-delta" event, for resynchronizing after a missed delta.
+func (a *App) GetStationSnapshot() station.StationsSnapshot {
+	return a.stationManager.StationSnapshot()
 }
 
+// The synchronous power bindings wait for a job, so their commands show up in
+// job events just like the async variants.
+
 func (a *App) PowerOnStation(address string) error {
-	log.Printf("Requesting Power ON for address %s", address)
-	return a.stationManager.PowerOnStation(address)
+	return a.waitJob(a.PowerOnStationAsync(address))
 }
 
 func (a *App) PowerOffStation(address string) error {
+	return a.waitJob(a.PowerOffStationAsync(address))
+}
+
+// PowerOnAllStations turns all stations on and returns what happened to each
+// local station. Agent stations only show up in the error.
+func (a *App) PowerOnAllStations() ([]station.StationOperationResult, error) {
+	return a.waitBulkJob(config.ActionOn)
+}
+
+// PowerOffAllStations turns all stations off, like PowerOnAllStations.
+func (a *App) PowerOffAllStations() ([]station.StationOperationResult, error) {
+	return a.waitBulkJob(config.ActionOff)
+}
+
+// StandbyStation puts a station into standby: the rotor stops but the radio
+// stays awake, so it turns on faster than from off.
+func (a *App) StandbyStation(address string) error {
+	return a.waitJob(a.StandbyStationAsync(address))
+}
+
+// StandbyAllStations puts all stations into standby, like PowerOnAllStations.
+func (a *App) StandbyAllStations() ([]station.StationOperationResult, error) {
+	return a.waitBulkJob(config.ActionStandby)
+}
+
+// PowerOnStationAsync starts turning a station on and returns the job ID.
+// The result arrives with the "job-completed" event.
+func (a *App) PowerOnStationAsync(address string) string {
+	return a.powerJob(uiSource, config.ActionOn, address)
+}
+
+// PowerOffStationAsync starts turning a station off and returns the job ID.
+func (a *App) PowerOffStationAsync(address string) string {
+	return a.powerJob(uiSource, config.ActionOff, address)
+}
+
+// PowerOnAllStationsAsync starts turning all stations on and returns the job ID.
+func (a *App) PowerOnAllStationsAsync() string {
+	return a.powerJob(uiSource, config.ActionOn, "")
+}
+
+// PowerOffAllStationsAsync starts turning all stations off and returns the job ID.
+func (a *App) PowerOffAllStationsAsync() string {
+	return a.powerJob(uiSource, config.ActionOff, "")
+}
+
+// StandbyStationAsync starts putting a station into standby and returns the job ID.
+func (a *App) StandbyStationAsync(address string) string {
+	return a.powerJob(uiSource, config.ActionStandby, address)
+}
+
+// StandbyAllStationsAsync starts putting all stations into standby and returns the job ID.
+func (a *App) StandbyAllStationsAsync() string {
+	return a.powerJob(uiSource, config.ActionStandby, "")
+}
+
+// uiSource marks commands from the window's bindings.
+var uiSource = station.Source{Kind: station.SourceUI}
+
+// powerJob submits a power command for one station, or all stations if
+// address is empty, and returns the job ID. src is recorded in the audit log.
+// Jobs outlive the call that submitted them and are only canceled by shutdown.
+func (a *App) powerJob(src station.Source, action, address string) string {
+	ctx := a.opCtx
+	if address == "" {
+		// The job keeps the results of the local stations
+		return a.jobs.SubmitResult(action, address, func() (interface{}, error) {
+			return a.powerAllStations(ctx, action, src)
+		})
+	}
+	return a.jobs.Submit(action, address, func() error {
+		switch action {
+		case config.ActionOn:
+			return a.userError("Power on", a.powerOnStation(ctx, address, src))
+		case config.ActionStandby:
+			return a.userError("Standby", a.standbyStation(ctx, address, src))
+		}
+		return a.userError("Power off", a.powerOffStation(ctx, address, src))
+	})
+}
+
+// waitBulkJob runs a command on all stations from the window as a job, waits
+// for it and returns the results of the local stations.
+func (a *App) waitBulkJob(action string) ([]station.StationOperationResult, error) {
+	id := a.powerJob(uiSource, action, "")
+	err := a.waitJob(id)
+	// Nothing yet if waiting stopped before the job finished
+	job, _ := a.jobs.Get(id)
+	results, _ := job.Result.([]station.StationOperationResult)
+	return results, err
+}
+
+// GetJobResult returns the state of a job, for clients that missed its events.
+func (a *App) GetJobResult(id string) (jobs.Job, error) {
+	job, ok := a.jobs.Get(id)
+	if !ok {
+		return jobs.Job{}, fmt.Errorf("unknown job '%s'", id)
+	}
+	return job, nil
+}
+
+// userError logs the technical detail of err and returns its user-facing
+// translation, which is what bindings hand to the frontend.
+func (a *App) userError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := usermsg.Translate(err)
+	log.Printf("%s failed [%s]: %s", op, msg.Code, msg.Detail)
+	return msg
+}
+
+func (a *App) waitJob(id string) error {
+	return a.jobs.Wait(a.opCtx, id)
+}
+
+func (a *App) powerOnStation(ctx context.Context, address string, src station.Source) error {
+	log.Printf("Requesting Power ON for address %s", address)
+	if client := a.agents.ClientFor(address); client != nil {
+		ctx, cancel := context.WithTimeout(ctx, agentCommandTimeout)
+		defer cancel()
+		return client.PowerOn(ctx, address)
+	}
+	return a.stationManager.PowerOnStation(ctx, address, src)
+}
+
+func (a *App) powerOffStation(ctx context.Context, address string, src station.Source) error {
 	log.Printf("Requesting Power OFF for address %s", address)
-	return a.stationManager.PowerOffStation(address)
+	if client := a.agents.ClientFor(address); client != nil {
+		ctx, cancel := context.WithTimeout(ctx, agentCommandTimeout)
+		defer cancel()
+		return client.PowerOff(ctx, address)
+	}
+	return a.stationManager.PowerOffStation(ctx, address, src)
 }
 
-func (a *App) PowerOnAllStations() error {
-	return a.stationManager.PowerOnAllStations()
+func (a *App) standbyStation(ctx context.Context, address string, src station.Source) error {
+	log.Printf("Requesting STANDBY for address %s", address)
+	if client := a.agents.ClientFor(address); client != nil {
+		ctx, cancel := context.WithTimeout(ctx, agentCommandTimeout)
+		defer cancel()
+		return client.Standby(ctx, address)
+	}
+	return a.stationManager.StandbyStation(ctx, address, src)
+}
+
+// powerAllStations runs action on the local and the agents' stations and
+// returns the results of the local ones, with the user-facing error.
+func (a *App) powerAllStations(ctx context.Context, action string, src station.Source) ([]station.StationOperationResult, error) {
+	op, local, remote := "Power off all", a.stationManager.PowerOffAllStations, (*agent.Client).PowerOffAll
+	switch action {
+	case config.ActionOn:
+		op, local, remote = "Power on all", a.stationManager.PowerOnAllStations, (*agent.Client).PowerOnAll
+	case config.ActionStandby:
+		op, local, remote = "Standby all", a.stationManager.StandbyAllStations, (*agent.Client).StandbyAll
+	}
+	var results []station.StationOperationResult
+	err := a.forAllAgents(ctx,
+		func() error {
+			var err error
+			results, err = local(ctx, src)
+			return err
+		},
+		func(ctx context.Context, c *agent.Client) error { return remote(c, ctx) },
+	)
+	return results, a.userError(op, err)
 }
 
-func (a *App) PowerOffAllStations() error {
-	return a.stationManager.PowerOffAllStations()
+// WriteRawCharacteristic writes a hex encoded payload to any characteristic of a
+// local station and returns the bytes written and the value read back. It only
+// works when lhcontrol was started with -debug.
+func (a *App) WriteRawCharacteristic(address, serviceUUID, charUUID, hexPayload string, withResponse bool) (bluetooth.RawWriteResult, error) {
+	if !a.debug {
+		return bluetooth.RawWriteResult{}, fmt.Errorf("raw writes require starting lhcontrol with -debug")
+	}
+	payload, err := hex.DecodeString(strings.ReplaceAll(hexPayload, " ", ""))
+	if err != nil {
+		return bluetooth.RawWriteResult{}, fmt.Errorf("invalid hex payload: %w", err)
+	}
+	if len(payload) == 0 || len(payload) > bluetooth.MaxRawPayload {
+		return bluetooth.RawWriteResult{}, fmt.Errorf("payload must be 1 to %d bytes", bluetooth.MaxRawPayload)
+	}
+	log.Printf("WARNING: Debug raw write requested for %s, characteristic %s, payload %s", address, charUUID, hexPayload)
+	return a.stationManager.WriteRawCharacteristic(a.opCtx, address, serviceUUID, charUUID, payload, withResponse)
+}
+
+// forAllAgents runs a bulk command locally and on every agent concurrently.
+// The agent requests are canceled with ctx.
+func (a *App) forAllAgents(ctx context.Context, local func() error, remote func(context.Context, *agent.Client) error) error {
+	clients := a.agents.Clients()
+	errs := make([]error, len(clients)+1)
+
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, c *agent.Client) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, agentCommandTimeout)
+			defer cancel()
+			errs[i] = remote(ctx, c)
+		}(i, client)
+	}
+	errs[len(clients)] = local()
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
 func (a *App) RenameStation(originalName string, newName string) error {
@@ -147,24 +717,1004 @@ func (a *App) RenameStation(originalName string, newName string) error {
 	return a.stationManager.RenameStation(originalName, newName)
 }
 
+// PruneStaleStations removes the local stations no scan saw in the last
+// olderThanDays days and returns their addresses.
+func (a *App) PruneStaleStations(olderThanDays int) []string {
+	return a.stationManager.PruneStaleStations(time.Duration(olderThanDays) * 24 * time.Hour)
+}
+
 func (a *App) SaveConfig() error {
 	return a.config.Save()
 }
 
+// --- Remote agents --- //
+
+func (a *App) GetAgents() []config.AgentConfig {
+	return a.config.GetAgents()
+}
+
+// AddAgent registers a remote agent, or replaces the one with the same name.
+func (a *App) AddAgent(agentCfg config.AgentConfig) error {
+	agents := a.config.GetAgents()
+	replaced := false
+	for i := range agents {
+		if agents[i].Name == agentCfg.Name {
+			agents[i] = agentCfg
+			replaced = true
+		}
+	}
+	if !replaced {
+		agents = append(agents, agentCfg)
+	}
+	if err := a.config.SetAgents(agents); err != nil {
+		return err
+	}
+	if err := a.config.Save(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentRequestTimeout)
+	defer cancel()
+	a.agents.Refresh(ctx)
+	return nil
+}
+
+func (a *App) RemoveAgent(name string) error {
+	agents := a.config.GetAgents()
+	kept := agents[:0]
+	for _, agentCfg := range agents {
+		if agentCfg.Name != name {
+			kept = append(kept, agentCfg)
+		}
+	}
+	if len(kept) == len(agents) {
+		return fmt.Errorf("agent '%s' not found", name)
+	}
+	if err := a.config.SetAgents(kept); err != nil {
+		return err
+	}
+	if err := a.config.Save(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentRequestTimeout)
+	defer cancel()
+	a.agents.Refresh(ctx)
+	return nil
+}
+
+// --- Automation --- //
+
+// runPowerAction executes an automation action against all stations.
+func (a *App) runPowerAction(src station.Source, action string) error {
+	switch action {
+	case config.ActionOn, config.ActionOff, config.ActionStandby:
+		return a.waitJob(a.powerJob(src, action, ""))
+	}
+	return nil
+}
+
+// runAutomation executes the action of a fired process rule.
+func (a *App) runAutomation(trigger automation.Trigger) {
+	log.Printf("Automation: Rule '%s' fired on %s, running action '%s'", trigger.Rule, trigger.Event, trigger.Action)
+	a.emit("automation-triggered", trigger)
+
+	if err := a.runPowerAction(station.Source{Kind: station.SourceAutomation}, trigger.Action); err != nil {
+		log.Printf("Automation: Action '%s' for rule '%s' failed: %v", trigger.Action, trigger.Rule, err)
+	}
+}
+
+// onPowerSourceChanged runs the configured action for an AC/battery transition.
+func (a *App) onPowerSourceChanged(onBattery bool) {
+	a.emit("power-source-changed", map[string]bool{"onBattery": onBattery})
+
+	settings := a.config.GetPowerSourceSettings()
+	if !settings.Enabled {
+		return
+	}
+	action := settings.OnPluggedIn
+	if onBattery {
+		action = settings.OnUnplugged
+	}
+	log.Printf("Automation: Power source changed (on battery=%v), running action '%s'", onBattery, action)
+	if err := a.runPowerAction(station.Source{Kind: station.SourcePowerSource}, action); err != nil {
+		log.Printf("Automation: Power source action '%s' failed: %v", action, err)
+	}
+}
+
+// onSafetyWarning tells the UI that a station is about to be turned off by the safety cap.
+func (a *App) onSafetyWarning(warning safety.Warning) {
+	a.emit("safety-warning", warning)
+
+	// The ID still works if the station reappears under a new address
+	params := url.Values{"address": {warning.ID}}
+	snooze := a.config.GetSafetySettings().SnoozeMinutes
+	a.notify(notify.Notification{
+		Title:   fmt.Sprintf("%s is still on", warning.Name),
+		Message: fmt.Sprintf("On for %.1f hours, it will be turned off at %s.", warning.OnHours, warning.ActionAt.Format("15:04")),
+		Actions: []notify.Action{
+			{Label: "Turn off now", URI: notify.CommandURI("off", params)},
+			{Label: fmt.Sprintf("Snooze %d min", snooze), URI: notify.CommandURI("snooze", params)},
+		},
+	})
+}
+
+// onEmptyScan suggests the disconnect-before-scan option when a scan found
+// nothing while connections were open.
+func (a *App) onEmptyScan(openConnections int) {
+	a.emit("scan-empty-while-connected", openConnections)
+	a.notify(notify.Notification{
+		Title:   "Scan found no base stations",
+		Message: fmt.Sprintf("%d station connection(s) were open during the scan. Some adapters can't scan while connected; try enabling \"Disconnect before scan\" in the connection settings.", openConnections),
+	})
+}
+
+// onStateReverted tells the user that a station didn't stay in the commanded state.
+func (a *App) onStateReverted(event station.StateRevertedEvent) {
+	a.notify(notify.Notification{
+		Title:   fmt.Sprintf("%s was switched back %s", event.Name, powerStateName(event.Actual)),
+		Message: fmt.Sprintf("It was turned %s, but another program changed it again.", powerStateName(event.Expected)),
+	})
+}
+
+// powerStateName describes a power state for notifications, as in "turned on".
+func powerStateName(state int) string {
+	switch state {
+	case bluetooth.PowerStateOn, bluetooth.PowerStateBooting:
+		return "on"
+	case bluetooth.PowerStateStandby:
+		return "to standby"
+	}
+	return "off"
+}
+
+// onCommand records a power command in the audit log.
+func (a *App) onCommand(record station.CommandRecord) {
+	name := record.Name
+	if renamed, ok := a.config.StationName(record.Name); ok {
+		name = renamed
+	}
+	entry := audit.Entry{
+		Time:      time.Now(),
+		StationID: record.ID,
+		Station:   name,
+		Address:   record.Address,
+		Action:    record.Action,
+		Origin:    record.Source.Kind,
+		ClientIP:  record.Source.ClientIP,
+		Outcome:   audit.OutcomeOK,
+	}
+	switch {
+	case errors.Is(record.Err, station.ErrSuperseded):
+		entry.Outcome = audit.OutcomeSuperseded
+	case record.Err != nil:
+		entry.Outcome = audit.OutcomeFailed
+		entry.Error = record.Err.Error()
+	}
+	a.audit.Append(entry)
+}
+
+// updateConfig applies a config document from a remote client, see
+// config.Update, saves it and applies settings that need more than a config
+// read to take effect. The change is recorded in the audit log.
+func (a *App) updateConfig(patch []byte, src station.Source) (config.UpdateResult, error) {
+	result, err := a.config.Update(patch)
+	if err != nil {
+		return result, err
+	}
+	if len(result.Changed) == 0 {
+		return result, nil
+	}
+	if err := a.config.Save(); err != nil {
+		return result, err
+	}
+	for _, section := range result.Changed {
+		switch section {
+		case "privacy":
+			redact.SetEnabled(a.config.GetPrivacySettings().RedactAddresses)
+		case "ui":
+			a.applyUISettings(a.config.GetUISettings())
+		case "connection":
+			a.stationManager.ApplyConnectionParams()
+			if a.config.GetConnectionSettings().Prewarm {
+				go a.stationManager.Prewarm()
+			}
+		case "knownStations":
+			a.stationManager.SeedStations()
+		}
+	}
+	log.Printf("Config: Updated %s from %s %s", strings.Join(result.Changed, ", "), src.Kind, src.ClientIP)
+	a.audit.Append(audit.Entry{
+		Time:     time.Now(),
+		Action:   audit.ActionConfig,
+		Origin:   src.Kind,
+		ClientIP: src.ClientIP,
+		Outcome:  audit.OutcomeOK,
+		Changes:  result.Changed,
+	})
+	a.emit("config-changed", result)
+	return result, nil
+}
+
+// GetAuditLog returns the recorded power commands, oldest first. station is
+// an address or station ID, from and to are RFC 3339 times; empty values
+// don't filter.
+func (a *App) GetAuditLog(stationKey, from, to string) ([]audit.Entry, error) {
+	filter, err := a.auditFilter(stationKey, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return a.audit.Query(filter), nil
+}
+
+// GetRecentEvents returns the events published since start, oldest first, see
+// recentQuery for the filters.
+func (a *App) GetRecentEvents(since, types string) ([]events.Record, error) {
+	q, err := recentQuery(since, types)
+	if err != nil {
+		return nil, err
+	}
+	return a.bus.Journal().Recent(q), nil
+}
+
+// recentQuery builds an event journal query. since is a sequence number or an
+// RFC 3339 time, types a comma-separated list of event names; empty values
+// don't filter.
+func recentQuery(since, types string) (events.Query, error) {
+	var q events.Query
+	if since != "" {
+		if seq, err := strconv.ParseUint(since, 10, 64); err == nil {
+			q.AfterSeq = seq
+		} else if q.AfterTime, err = time.Parse(time.RFC3339, since); err != nil {
+			return events.Query{}, fmt.Errorf("invalid 'since' value '%s', use a sequence number or an RFC 3339 time", since)
+		}
+	}
+	for _, name := range strings.Split(types, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			q.Names = append(q.Names, name)
+		}
+	}
+	return q, nil
+}
+
+// RunSelfTest checks the adapter, a scan, a connection and a state read, see
+// station.Manager.RunSelfTest. Leave address empty to test the first station
+// that can be reached. Progress is published as "selftest-progress" events.
+func (a *App) RunSelfTest(address string) (station.SelfTestReport, error) {
+	report, err := a.stationManager.RunSelfTest(a.opCtx, strings.TrimSpace(address))
+	return report, a.userError("Self-test", err)
+}
+
+func (a *App) auditFilter(stationKey, from, to string) (audit.Filter, error) {
+	filter := audit.Filter{Station: stationKey}
+	if id, ok := a.stationManager.StationID(stationKey); ok {
+		filter.Station = id
+	}
+	var err error
+	if from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return audit.Filter{}, fmt.Errorf("invalid 'from' time '%s', use RFC 3339", from)
+		}
+	}
+	if to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return audit.Filter{}, fmt.Errorf("invalid 'to' time '%s', use RFC 3339", to)
+		}
+	}
+	return filter, nil
+}
+
+// onSafetyAction turns off a station that exceeded the maximum on-time, or
+// puts it into standby if settings.Action says so.
+func (a *App) onSafetyAction(address string, settings config.SafetySettings) {
+	src := station.Source{Kind: station.SourceSafety}
+	var err error
+	switch settings.Action {
+	case config.ActionStandby:
+		err = a.stationManager.StandbyStation(a.opCtx, address, src)
+		if errors.Is(err, bluetooth.ErrUnsupported) {
+			// 1.0 stations have no standby, they still must not stay on
+			err = a.stationManager.PowerOffStation(a.opCtx, address, src)
+		}
+	default:
+		err = a.stationManager.PowerOffStation(a.opCtx, address, src)
+	}
+	if err != nil {
+		log.Printf("Safety: Failed to %s %s: %v", settings.Action, address, err)
+	}
+	a.emit("safety-auto-off", map[string]interface{}{
+		"address": address,
+		"action":  settings.Action,
+		"success": err == nil,
+	})
+}
+
+// --- Power Snapshots --- //
+
+// SnapshotStates records which stations are on under name, see station.Manager.SnapshotStates.
+func (a *App) SnapshotStates(name string) (config.PowerSnapshot, error) {
+	snapshot, err := a.stationManager.SnapshotStates(name)
+	return snapshot, a.userError("Snapshot", err)
+}
+
+// RestoreSnapshot powers stations on or off to match a snapshot and returns the per-station results.
+func (a *App) RestoreSnapshot(name string) ([]station.RestoreResult, error) {
+	results, err := a.stationManager.RestoreSnapshot(a.opCtx, name, uiSource)
+	return results, a.userError("Restore snapshot", err)
+}
+
+func (a *App) GetPowerSnapshots() []config.PowerSnapshot {
+	return a.stationManager.PowerSnapshots()
+}
+
+func (a *App) DeleteSnapshot(name string) error {
+	return a.userError("Delete snapshot", a.stationManager.DeleteSnapshot(name))
+}
+
+// --- Groups --- //
+
+// GetGroups returns the groups with the IDs of their stations.
+func (a *App) GetGroups() map[string][]string {
+	return a.stationManager.Groups()
+}
+
+func (a *App) CreateGroup(name string) error {
+	return a.userError("Create group", a.stationManager.CreateGroup(name))
+}
+
+func (a *App) DeleteGroup(name string) error {
+	return a.userError("Delete group", a.stationManager.DeleteGroup(name))
+}
+
+// AssignStationToGroup moves a station into a group, or out of its group if
+// group is empty.
+func (a *App) AssignStationToGroup(address, group string) error {
+	return a.userError("Assign group", a.stationManager.AssignStationToGroup(address, group))
+}
+
+// PowerOnGroup turns on the stations of a group and returns the per-station results.
+func (a *App) PowerOnGroup(name string) ([]station.RestoreResult, error) {
+	results, err := a.stationManager.PowerOnGroup(a.opCtx, name, uiSource)
+	return results, a.userError("Group on", err)
+}
+
+// PowerOffGroup turns off the stations of a group and returns the per-station results.
+func (a *App) PowerOffGroup(name string) ([]station.RestoreResult, error) {
+	results, err := a.stationManager.PowerOffGroup(a.opCtx, name, uiSource)
+	return results, a.userError("Group off", err)
+}
+
+// IdentifyStation makes a local station's status LED blink, to find it.
+func (a *App) IdentifyStation(address string) error {
+	return a.userError("Identify", a.stationManager.IdentifyStation(a.opCtx, address))
+}
+
+// GetStationDetails returns a local station with its firmware and hardware
+// revision. The first call for a station connects to read them.
+func (a *App) GetStationDetails(address string) (station.StationDetails, error) {
+	details, err := a.stationManager.StationDetails(a.opCtx, address)
+	return details, a.userError("Station details", err)
+}
+
+// ForgetStation removes a local station from the list along with its name
+// and settings.
+func (a *App) ForgetStation(address string) error {
+	return a.userError("Forget", a.stationManager.ForgetStation(a.opCtx, address))
+}
+
+// ResetStationHealth clears the failure counters of a local station, see
+// GetStationDetails.
+func (a *App) ResetStationHealth(address string) error {
+	return a.userError("Reset health", a.stationManager.ResetStationHealth(address))
+}
+
+// SetStationChannel changes the channel of a local station, refusing one
+// another station is on.
+func (a *App) SetStationChannel(address string, channel int) error {
+	return a.userError("Set channel", a.stationManager.SetStationChannel(a.opCtx, address, channel))
+}
+
+// PowerCycleStation turns a local station off and back on after
+// bluetooth.DefaultCycleOffDuration. Its progress arrives with
+// "station-cycle" events.
+func (a *App) PowerCycleStation(address string) error {
+	ctx := a.opCtx
+	return a.waitJob(a.jobs.Submit("cycle", address, func() error {
+		return a.userError("Power cycle", a.stationManager.PowerCycleStation(ctx, address, 0))
+	}))
+}
+
+// PowerCycleAllStations power cycles every local station at once.
+func (a *App) PowerCycleAllStations() error {
+	ctx := a.opCtx
+	return a.waitJob(a.jobs.Submit("cycle", "", func() error {
+		return a.userError("Power cycle all", a.stationManager.PowerCycleAllStations(ctx, 0))
+	}))
+}
+
+// SetStationEssential marks a station as essential for the essentials quick actions.
+func (a *App) SetStationEssential(address string, essential bool) error {
+	return a.userError("Mark essential", a.stationManager.SetEssential(address, essential))
+}
+
+// SetDisconnectAfterCommand turns the mode that disconnects from a station
+// after every command and status read on or off.
+func (a *App) SetDisconnectAfterCommand(enabled bool) error {
+	return a.userError("Set disconnect after command", a.stationManager.SetDisconnectAfterCommand(enabled))
+}
+
+// SetStationAutoReconnect turns automatic reconnecting after a dropped
+// connection off or back on for a station.
+func (a *App) SetStationAutoReconnect(address string, enabled bool) error {
+	return a.userError("Set auto-reconnect", a.stationManager.SetAutoReconnect(address, enabled))
+}
+
+// SetStationManaged includes a station in the commands to all stations, or
+// leaves it out of them. Commands to the station itself work either way.
+func (a *App) SetStationManaged(address string, managed bool) error {
+	return a.userError("Set managed", a.stationManager.SetManaged(address, managed))
+}
+
+// PowerOnEssentials turns on the stations marked as essential and returns the
+// per-station results, which are empty if no station is marked.
+func (a *App) PowerOnEssentials() []station.RestoreResult {
+	return a.stationManager.PowerOnEssentials(a.opCtx, uiSource)
+}
+
+// PowerOffNonEssentials turns off the stations not marked as essential and
+// returns the per-station results, which are empty if no station is marked.
+func (a *App) PowerOffNonEssentials() []station.RestoreResult {
+	return a.stationManager.PowerOffNonEssentials(a.opCtx, uiSource)
+}
+
+// ResumeLastSession powers on the stations that were on when the app last
+// exited. Stations that were off then, or that aren't known any more, are left alone.
+func (a *App) ResumeLastSession() (session.ResumeResult, error) {
+	return a.resumeLastSession(a.opCtx, uiSource)
+}
+
+func (a *App) resumeLastSession(ctx context.Context, src station.Source) (session.ResumeResult, error) {
+	if a.lastSession == nil {
+		return session.ResumeResult{}, a.userError("Resume", session.ErrNoSession)
+	}
+	result := session.Resume(ctx, a.stationManager, *a.lastSession, src)
+	a.emit("session-resumed", result)
+	return result, nil
+}
+
+// SetWindowVisible is called by the frontend when the window is shown,
+// hidden, focused or blurred. Status polling slows down or pauses while the
+// window is hidden, according to the polling settings.
+func (a *App) SetWindowVisible(visible bool) {
+	if a.polling.SetWindowVisible(visible) {
+		a.emitPollingState()
+	}
+}
+
+func (a *App) emitPollingState() {
+	status := a.polling.Status()
+	log.Printf("Polling: %s %s", status.Mode, status.Reason)
+	a.emit("polling-state", status)
+}
+
+func (a *App) GetPollingSettings() config.PollingSettings {
+	return a.config.GetPollingSettings()
+}
+
+func (a *App) SetPollingSettings(settings config.PollingSettings) error {
+	if err := a.config.SetPollingSettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+func (a *App) GetStartupSettings() config.StartupSettings {
+	return a.config.GetStartupSettings()
+}
+
+func (a *App) SetStartupSettings(settings config.StartupSettings) error {
+	if err := a.config.SetStartupSettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+func (a *App) GetShutdownSettings() config.ShutdownSettings {
+	return a.config.GetShutdownSettings()
+}
+
+func (a *App) SetShutdownSettings(settings config.ShutdownSettings) error {
+	if err := a.config.SetShutdownSettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+// --- Timers --- //
+
+// runTimer executes the action of a timer that came due.
+func (a *App) runTimer(t timers.Timer) {
+	src := station.Source{Kind: station.SourceSchedule}
+	var err error
+	if t.Address == "" {
+		err = a.runPowerAction(src, t.Action)
+	} else {
+		err = a.waitJob(a.powerJob(src, t.Action, t.Address))
+	}
+	if err != nil {
+		log.Printf("Timers: Action '%s' of timer %s failed: %v", t.Action, t.ID, err)
+	}
+	a.emit("timer-fired", map[string]interface{}{
+		"timer":   t,
+		"success": err == nil,
+	})
+}
+
+// AddTimer schedules a one-shot power action in the given number of minutes.
+// An empty address targets all stations.
+func (a *App) AddTimer(action string, address string, minutes int) (timers.Timer, error) {
+	if a.timers == nil {
+		return timers.Timer{}, fmt.Errorf("timers are not running")
+	}
+	// Target local stations by ID so the timer survives address changes
+	if id, ok := a.stationManager.StationID(address); ok {
+		address = id
+	}
+	return a.timers.Add(action, address, time.Duration(minutes)*time.Minute)
+}
+
+func (a *App) CancelTimer(id string) error {
+	if a.timers == nil {
+		return fmt.Errorf("timers are not running")
+	}
+	return a.timers.Cancel(id)
+}
+
+func (a *App) GetTimers() []timers.Timer {
+	if a.timers == nil {
+		return []timers.Timer{}
+	}
+	return a.timers.List()
+}
+
+func (a *App) GetTimerSettings() config.TimerSettings {
+	return a.config.GetTimerSettings()
+}
+
+func (a *App) SetTimerSettings(settings config.TimerSettings) error {
+	if err := a.config.SetTimerSettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+// --- Notifications --- //
+
+// notify shows a desktop notification if notifications are enabled.
+func (a *App) notify(n notify.Notification) {
+	settings := a.config.GetNotificationSettings()
+	if !settings.Enabled || a.agentMode {
+		return
+	}
+	go func() {
+		// Buttons go through the lhcontrol: protocol, which reaches the default instance
+		actionButtons := settings.ActionButtons && instance.Name() == ""
+		if err := notify.Show(n, actionButtons); err != nil {
+			log.Printf("Notify: Failed to show notification: %v", err)
+		}
+	}()
+}
+
+// updateJumpList registers the taskbar jump list tasks. They launch lhcontrol
+// with a command URI, which the new process forwards to this instance.
+func (a *App) updateJumpList() {
+	exePath, err := os.Executable()
+	if err != nil {
+		log.Printf("Error updating jump list: %v", err)
+		return
+	}
+	tasks := []platform.JumpTask{
+		{Title: "All On", Arguments: notify.CommandURI("on", nil)},
+		{Title: "All Off", Arguments: notify.CommandURI("off", nil)},
+		{Title: "Resume Last Session", Arguments: notify.CommandURI("resume", nil)},
+		{Title: "Essentials On", Arguments: notify.CommandURI("essentials-on", nil)},
+		{Title: "Non-Essentials Off", Arguments: notify.CommandURI("nonessentials-off", nil)},
+	}
+	if err := platform.SetJumpListTasks(notify.AppUserModelID, exePath, tasks); err != nil {
+		log.Printf("Error updating jump list: %v", err)
+	}
+}
+
+// runBadge updates the taskbar badge whenever a station list event is published.
+func (a *App) runBadge() {
+	events, unsubscribe := a.bus.Subscribe(8)
+	defer unsubscribe()
+	for event := range events {
+		if event.Name != "stations-updated" && event.Name != "scan-completed" {
+			continue
+		}
+		if a.config.GetUISettings().TaskbarBadge {
+			a.badge.Update(a.GetCurrentStationInfo())
+		}
+	}
+}
+
+func (a *App) applyBadge(icon []byte, description string) {
+	if err := platform.SetTaskbarOverlay(instance.Title(appTitle), icon, description); err != nil {
+		log.Printf("Error updating taskbar badge: %v", err)
+	}
+}
+
+// runArgs executes lhcontrol: command URIs passed on the command line or
+// forwarded from a second instance, e.g. by notification buttons.
+func (a *App) runArgs(args []string) {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, notify.Scheme+":") {
+			continue
+		}
+		if err := a.runCommandURI(arg); err != nil {
+			log.Printf("Error running command '%s': %v", arg, err)
+			// Launched from a jump list or notification button, so there is no other feedback
+			a.notify(notify.Notification{
+				Title:   "Command failed",
+				Message: usermsg.Translate(err).Message,
+			})
+		}
+	}
+}
+
+// restoreError joins the errors of failed per-station results.
+func restoreError(results []station.RestoreResult) error {
+	var errs []error
+	for _, result := range results {
+		if result.Status == station.RestoreFailed {
+			errs = append(errs, fmt.Errorf("%s: %s", result.Name, result.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *App) runCommandURI(uri string) error {
+	command, params, err := notify.ParseCommandURI(uri)
+	if err != nil {
+		return err
+	}
+	address := params.Get("address")
+	log.Printf("Running command '%s' (address: '%s')", command, address)
+
+	src := station.Source{Kind: station.SourceCommand}
+	switch command {
+	case "on":
+		return a.waitJob(a.powerJob(src, config.ActionOn, address))
+	case "off":
+		return a.waitJob(a.powerJob(src, config.ActionOff, address))
+	case "resume":
+		_, err := a.resumeLastSession(a.opCtx, src)
+		return err
+	case "essentials-on":
+		return restoreError(a.stationManager.PowerOnEssentials(a.opCtx, src))
+	case "nonessentials-off":
+		return restoreError(a.stationManager.PowerOffNonEssentials(a.opCtx, src))
+	case "snooze":
+		if address == "" {
+			return fmt.Errorf("snooze requires an address")
+		}
+		_, err := a.SnoozeSafetyAutoOff(address)
+		return err
+	}
+	return fmt.Errorf("unknown command '%s'", command)
+}
+
+func (a *App) GetUISettings() config.UISettings {
+	return a.config.GetUISettings()
+}
+
+func (a *App) SetUISettings(settings config.UISettings) error {
+	a.config.SetUISettings(settings)
+	a.applyUISettings(settings)
+	return a.config.Save()
+}
+
+func (a *App) applyUISettings(settings config.UISettings) {
+	if a.badge != nil {
+		a.badge.Reset()
+		if settings.TaskbarBadge {
+			a.badge.Update(a.GetCurrentStationInfo())
+		} else {
+			a.badge.Stop()
+			a.applyBadge(nil, "")
+		}
+	}
+}
+
+func (a *App) GetConnectionSettings() config.ConnectionSettings {
+	return a.config.GetConnectionSettings()
+}
+
+func (a *App) SetConnectionSettings(settings config.ConnectionSettings) error {
+	if err := a.config.SetConnectionSettings(settings); err != nil {
+		return err
+	}
+	a.stationManager.ApplyConnectionParams()
+	if settings.Prewarm {
+		go a.stationManager.Prewarm()
+	}
+	return a.config.Save()
+}
+
+// GetScanFilter returns the advertised name prefixes and addresses scans accept.
+func (a *App) GetScanFilter() config.FilterConfig {
+	return a.config.GetFilterConfig()
+}
+
+// SetScanFilter replaces the scan filter. It applies from the next scan.
+func (a *App) SetScanFilter(filter config.FilterConfig) error {
+	if err := a.config.SetFilterConfig(filter); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+func (a *App) GetVerificationSettings() config.VerificationSettings {
+	return a.config.GetVerificationSettings()
+}
+
+func (a *App) SetVerificationSettings(settings config.VerificationSettings) error {
+	if err := a.config.SetVerificationSettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+func (a *App) GetPrivacySettings() config.PrivacySettings {
+	return a.config.GetPrivacySettings()
+}
+
+func (a *App) SetPrivacySettings(settings config.PrivacySettings) error {
+	a.config.SetPrivacySettings(settings)
+	redact.SetEnabled(settings.RedactAddresses)
+	return a.config.Save()
+}
+
+func (a *App) GetNotificationSettings() config.NotificationSettings {
+	return a.config.GetNotificationSettings()
+}
+
+func (a *App) SetNotificationSettings(settings config.NotificationSettings) error {
+	a.config.SetNotificationSettings(settings)
+	return a.config.Save()
+}
+
+func (a *App) GetProcessRules() []config.ProcessRule {
+	return a.config.GetProcessRules()
+}
+
+func (a *App) SetProcessRules(rules []config.ProcessRule) error {
+	if err := a.config.SetProcessRules(rules); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+func (a *App) GetPowerSourceSettings() config.PowerSourceSettings {
+	return a.config.GetPowerSourceSettings()
+}
+
+func (a *App) SetPowerSourceSettings(settings config.PowerSourceSettings) error {
+	if err := a.config.SetPowerSourceSettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+func (a *App) GetSafetySettings() config.SafetySettings {
+	return a.config.GetSafetySettings()
+}
+
+func (a *App) SetSafetySettings(settings config.SafetySettings) error {
+	if err := a.config.SetSafetySettings(settings); err != nil {
+		return err
+	}
+	return a.config.Save()
+}
+
+// SnoozeSafetyAutoOff postpones the safety auto-off for a station and returns when it will be checked again.
+func (a *App) SnoozeSafetyAutoOff(address string) (time.Time, error) {
+	if a.safetyMonitor == nil {
+		return time.Time{}, fmt.Errorf("safety monitor is not running")
+	}
+	return a.safetyMonitor.Snooze(address), nil
+}
+
+// GetStatus returns application-level state such as the current power source.
+func (a *App) GetStatus() AppStatus {
+	status := AppStatus{Polling: a.polling.Status()}
+	if a.powerMonitor != nil {
+		status.OnBattery, status.PowerSourceKnown = a.powerMonitor.OnBattery()
+	}
+	return status
+}
+
 // shutdown is called when the app terminates.
+// shutdown runs the cleanup sequence. It is called by Wails, by the signal and
+// console handlers and at the end of agent mode; only the first call does anything.
 func (a *App) shutdown(ctx context.Context) {
+	a.shutdownOnce.Do(a.cleanup)
+}
+
+// beforeClose runs the shutdown action while the window closes, as the OS
+// may end the process on logoff before Wails calls shutdown. It never keeps
+// the window open.
+func (a *App) beforeClose(ctx context.Context) bool {
+	a.powerOffOnExit()
+	return false
+}
+
+// powerOffOnExit turns off the local stations if the shutdown action says
+// so, waiting at most its timeout. The session is recorded first, so
+// resuming it brings back the stations that were on. Only the first call
+// does anything.
+func (a *App) powerOffOnExit() {
+	a.exitPowerOnce.Do(func() {
+		settings := a.config.GetShutdownSettings()
+		if a.agentMode || settings.Action != config.ShutdownOff {
+			return
+		}
+		if a.sessionRecorder != nil {
+			a.sessionRecorder.Stop()
+		}
+		var targets []config.SnapshotStation
+		for _, info := range a.stationManager.GetStationInfo() {
+			if info.PowerState != bluetooth.PowerStateOff && !info.Unmanaged {
+				targets = append(targets, config.SnapshotStation{ID: info.ID, Name: info.Name, PowerState: bluetooth.PowerStateOff})
+			}
+		}
+		if len(targets) == 0 {
+			log.Println("Shutdown: No station to turn off.")
+			return
+		}
+
+		timeout := time.Duration(settings.TimeoutSeconds) * time.Second
+		log.Printf("Shutdown: Turning off %d station(s), waiting at most %s...", len(targets), timeout)
+		// opCtx may already be canceled, the commands get their own deadline
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		results := a.stationManager.ApplyStates(ctx, targets, station.Source{Kind: station.SourceShutdown})
+
+		off := 0
+		var failed []string
+		for _, result := range results {
+			switch result.Status {
+			case station.RestoreOK:
+				off++
+			case station.RestoreFailed:
+				failed = append(failed, fmt.Sprintf("%s (%s)", result.Name, result.Error))
+			}
+		}
+		if len(failed) > 0 {
+			log.Printf("Shutdown: Turned off %d station(s), failed: %s", off, strings.Join(failed, ", "))
+		} else {
+			log.Printf("Shutdown: Turned off %d station(s).", off)
+		}
+	})
+}
+
+func (a *App) cleanup() {
 	log.Println("App shutdown requested. Cleaning up...")
+	// Stop waiting on the radio first, so operations still running don't
+	// hold up the rest of the sequence
+	if a.opCancel != nil {
+		a.opCancel()
+	}
+	a.stationManager.StopPolling()
+	if _, err := systemd.Notify("STOPPING=1"); err != nil {
+		log.Printf("Error notifying systemd: %v", err)
+	}
+	if a.stopWatchdog != nil {
+		a.stopWatchdog()
+	}
+	if a.watcher != nil {
+		a.watcher.Stop()
+	}
+	if a.powerMonitor != nil {
+		a.powerMonitor.Stop()
+	}
+	if a.safetyMonitor != nil {
+		a.safetyMonitor.Stop()
+	}
+	a.agents.Stop()
+	if a.timers != nil {
+		a.timers.Stop()
+	}
+	if a.badge != nil {
+		a.badge.Stop()
+	}
+	// Record the stations that are on before disconnecting forgets their states
+	if a.sessionRecorder != nil {
+		a.sessionRecorder.Stop()
+	}
+	a.powerOffOnExit()
+	// End open event streams so the API server can shut down
+	a.bus.Close()
 	if a.api != nil {
 		log.Println("Shutting down API server...")
 		if err := a.api.Shutdown(); err != nil {
 			log.Printf("Error shutting down API server: %v", err)
 		}
+		if err := discovery.Remove(); err != nil {
+			log.Printf("Error removing API discovery file: %v", err)
+		}
 	}
 	log.Println("Requesting disconnect for all stations...")
-	bluetooth.DisconnectAllStations()
+	a.stationManager.Shutdown()
+	a.audit.Stop()
 	log.Println("App shutdown sequence complete.")
 }
 
+// ExportDiagnostics asks for a file name and writes a diagnostics bundle with
+// the config (without tokens), the station list, the log file and the audit
+// log. It returns the path written, or an empty string if the dialog was cancelled.
+func (a *App) ExportDiagnostics(redactAddresses bool) (string, error) {
+	if a.ctx == nil || a.agentMode {
+		return "", fmt.Errorf("diagnostics export needs the window")
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export diagnostics",
+		DefaultFilename: fmt.Sprintf("lhcontrol-diagnostics-%s.zip", time.Now().Format("20060102-150405")),
+		Filters:         []runtime.FileFilter{{DisplayName: "Zip archives (*.zip)", Pattern: "*.zip"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	about, err := a.GetAbout()
+	if err != nil {
+		return "", err
+	}
+	configJSON, err := a.config.Snapshot()
+	if err != nil {
+		return "", err
+	}
+	logFile, err := paths.LogFile()
+	if err != nil {
+		return "", err
+	}
+	traceFile, err := paths.TraceFile()
+	if err != nil {
+		return "", err
+	}
+	err = diagnostics.Export(path, diagnostics.Contents{
+		About:       about,
+		Config:      configJSON,
+		Stations:    a.GetCurrentStationInfo(),
+		Connections: a.stationManager.ConnectionReport(),
+		LogFile:     logFile,
+		AuditFile:   a.audit.Path(),
+		SelfTest:    a.stationManager.LastSelfTest(),
+		Events:      a.bus.Journal().Recent(events.Query{}),
+		TraceFile:   traceFile,
+	}, redactAddresses)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("Diagnostics exported to %s (redacted: %v)", path, redactAddresses)
+	return path, nil
+}
+
+// GetAbout returns version information and the resolved data directories.
+func (a *App) GetAbout() (AboutInfo, error) {
+	resolved, err := paths.Resolve()
+	if err != nil {
+		return AboutInfo{}, err
+	}
+	return AboutInfo{
+		Name:     appTitle,
+		Version:  version,
+		Instance: instance.Name(),
+		Paths:    resolved,
+	}, nil
+}
+
 // Greet (Example method - can be kept or removed)
 func (a *App) Greet(name string) string {
 	return fmt.Sprintf("Hello %s, It's show time!", name)
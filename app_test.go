@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+// A quit requested before Wails called startup is left for startup, instead
+// of being dropped with the app half shut down.
+func TestQuitBeforeStartup(t *testing.T) {
+	a, _ := newTestApp(t)
+	a.requestQuit()
+	a.quitMu.Lock()
+	defer a.quitMu.Unlock()
+	if !a.quitPending {
+		t.Error("quit before startup wasn't recorded")
+	}
+}
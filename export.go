@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"lhcontrol/internal/station"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Station export formats
+const (
+	exportJSON = "json"
+	exportCSV  = "csv"
+)
+
+// stationRow is everything lhcontrol knows about a station, for /status field
+// selection and exports.
+type stationRow struct {
+	station.StationInfo
+	LastSeen       time.Time
+	TotalOnSeconds float64
+}
+
+// stationField is a column of the station list. The same table backs
+// GET /status?fields= and the exports, so both stay consistent.
+type stationField struct {
+	name  string
+	value func(r stationRow) interface{}
+}
+
+var stationFields = []stationField{
+	{"id", func(r stationRow) interface{} { return r.ID }},
+	{"address", func(r stationRow) interface{} { return r.Address }},
+	{"originalName", func(r stationRow) interface{} { return r.OriginalName }},
+	{"name", func(r stationRow) interface{} { return r.Name }},
+	{"powerState", func(r stationRow) interface{} { return r.PowerState }},
+	{"ready", func(r stationRow) interface{} { return r.Ready }},
+	{"connected", func(r stationRow) interface{} { return r.Connected }},
+	{"stale", func(r stationRow) interface{} { return r.Stale }},
+	{"rssi", func(r stationRow) interface{} { return r.RSSI }},
+	{"origin", func(r stationRow) interface{} { return r.Origin }},
+	{"lastSeen", func(r stationRow) interface{} {
+		if r.LastSeen.IsZero() {
+			return nil
+		}
+		return r.LastSeen.Format(time.RFC3339)
+	}},
+	{"totalOnSeconds", func(r stationRow) interface{} { return int64(r.TotalOnSeconds) }},
+}
+
+// selectStationFields returns the fields named in a comma-separated list, in
+// that order, or all fields for an empty list.
+func selectStationFields(list string) ([]stationField, error) {
+	if strings.TrimSpace(list) == "" {
+		return stationFields, nil
+	}
+	var selected []stationField
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, field := range stationFields {
+			if field.name == name {
+				selected = append(selected, field)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown field '%s'", name)
+		}
+	}
+	return selected, nil
+}
+
+// stationRows adds usage data to a station list.
+func (a *App) stationRows(infos []station.StationInfo) []stationRow {
+	lastSeen := a.stationManager.LastSeen()
+	rows := make([]stationRow, len(infos))
+	for i, info := range infos {
+		rows[i] = stationRow{StationInfo: info, LastSeen: lastSeen[info.Address]}
+		if s, ok := a.stats.Get(info.ID); ok {
+			rows[i].TotalOnSeconds = s.TotalOnSeconds
+		}
+	}
+	return rows
+}
+
+// stationMaps returns a station list as objects holding only the given fields.
+func (a *App) stationMaps(infos []station.StationInfo, fields []stationField) []map[string]interface{} {
+	rows := a.stationRows(infos)
+	result := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		m := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			m[field.name] = field.value(row)
+		}
+		result[i] = m
+	}
+	return result
+}
+
+// encodeStations renders all stations, including those of remote agents, in the given format.
+func (a *App) encodeStations(format string, fields []stationField) ([]byte, error) {
+	infos := a.GetCurrentStationInfo()
+	switch format {
+	case exportJSON:
+		return json.MarshalIndent(a.stationMaps(infos, fields), "", "  ")
+	case exportCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		header := make([]string, len(fields))
+		for i, field := range fields {
+			header[i] = field.name
+		}
+		w.Write(header)
+		for _, row := range a.stationRows(infos) {
+			record := make([]string, len(fields))
+			for i, field := range fields {
+				record[i] = csvValue(field.value(row))
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	}
+	return nil, fmt.Errorf("unsupported export format '%s'", format)
+}
+
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// ExportStations writes the station list with states and usage data as JSON
+// or CSV. Without a path it asks for one and returns an empty string if the
+// dialog was cancelled; otherwise it returns the path written.
+func (a *App) ExportStations(format, path string) (string, error) {
+	format = strings.ToLower(format)
+	if format != exportJSON && format != exportCSV {
+		return "", fmt.Errorf("unsupported export format '%s'", format)
+	}
+	if path == "" {
+		if a.ctx == nil || a.agentMode {
+			return "", fmt.Errorf("exporting without a path needs the window")
+		}
+		var err error
+		path, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export stations",
+			DefaultFilename: fmt.Sprintf("lhcontrol-stations-%s.%s", time.Now().Format("20060102-150405"), format),
+			Filters:         []runtime.FileFilter{{DisplayName: strings.ToUpper(format) + " files (*." + format + ")", Pattern: "*." + format}},
+		})
+		if err != nil || path == "" {
+			return "", err
+		}
+	}
+
+	data, err := a.encodeStations(format, stationFields)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write export '%s': %w", path, err)
+	}
+	log.Printf("Stations exported to %s", path)
+	return path, nil
+}
+
+// ImportStations seeds the known stations and their names from a JSON export.
+// Without a path it asks for one and returns an empty report if the dialog
+// was cancelled. Conflicts with known stations are skipped unless overwrite is set.
+func (a *App) ImportStations(path string, overwrite bool) (station.ImportReport, error) {
+	if path == "" {
+		if a.ctx == nil || a.agentMode {
+			return station.ImportReport{}, fmt.Errorf("importing without a path needs the window")
+		}
+		var err error
+		path, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:   "Import stations",
+			Filters: []runtime.FileFilter{{DisplayName: "JSON files (*.json)", Pattern: "*.json"}},
+		})
+		if err != nil || path == "" {
+			return station.ImportReport{}, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return station.ImportReport{}, fmt.Errorf("failed to read import '%s': %w", path, err)
+	}
+	entries, err := station.ParseImport(data)
+	if err != nil {
+		return station.ImportReport{}, err
+	}
+	return a.stationManager.ImportStations(entries, overwrite)
+}
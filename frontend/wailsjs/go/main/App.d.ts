@@ -1,25 +1,198 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {apiclient} from '../models';
+import {audit} from '../models';
+import {bluetooth} from '../models';
+import {config} from '../models';
+import {events} from '../models';
+import {jobs} from '../models';
+import {main} from '../models';
+import {session} from '../models';
 import {station} from '../models';
+import {timers} from '../models';
 
-export function CheckAllStationStatuses():Promise<Array<station.StationInfo>>;
+export function AddAgent(arg1:config.AgentConfig):Promise<void>;
 
-export function GetCurrentStationInfo():Promise<Array<station.StationInfo>>;
+export function AddTimer(arg1:string,arg2:string,arg3:number):Promise<timers.Timer>;
+
+export function AssignStationToGroup(arg1:string,arg2:string):Promise<void>;
+
+export function CancelTimer(arg1:string):Promise<void>;
+
+export function CheckAllStationStatuses():Promise<station.StatusCheck>;
+
+export function CreateGroup(arg1:string):Promise<void>;
+
+export function DeleteGroup(arg1:string):Promise<void>;
+
+export function DeleteSnapshot(arg1:string):Promise<void>;
+
+export function ExportDiagnostics(arg1:boolean):Promise<string>;
+
+export function ExportStations(arg1:string,arg2:string):Promise<string>;
+
+export function ForgetStation(arg1:string):Promise<void>;
+
+export function GetAbout():Promise<main.AboutInfo>;
+
+export function GetAdapterStatus():Promise<station.AdapterStatus>;
+
+export function GetAgents():Promise<Array<config.AgentConfig>>;
+
+export function GetAuditLog(arg1:string,arg2:string,arg3:string):Promise<Array<audit.Entry>>;
+
+export function GetConnectionSettings():Promise<config.ConnectionSettings>;
+
+export function GetCurrentStationInfo():Promise<Array<apiclient.StationInfo>>;
+
+export function GetGroups():Promise<{[key: string]: Array<string>}>;
+
+export function GetJobResult(arg1:string):Promise<jobs.Job>;
+
+export function GetNotificationSettings():Promise<config.NotificationSettings>;
+
+export function GetPollingSettings():Promise<config.PollingSettings>;
+
+export function GetPowerSnapshots():Promise<Array<config.PowerSnapshot>>;
+
+export function GetPowerSourceSettings():Promise<config.PowerSourceSettings>;
+
+export function GetPrivacySettings():Promise<config.PrivacySettings>;
+
+export function GetProcessRules():Promise<Array<config.ProcessRule>>;
+
+export function GetRecentEvents(arg1:string,arg2:string):Promise<Array<events.Record>>;
+
+export function GetSafetySettings():Promise<config.SafetySettings>;
+
+export function GetScanFilter():Promise<config.FilterConfig>;
+
+export function GetShutdownSettings():Promise<config.ShutdownSettings>;
+
+export function GetStartupSettings():Promise<config.StartupSettings>;
+
+export function GetStationDetails(arg1:string):Promise<station.StationDetails>;
+
+export function GetStationSnapshot():Promise<station.StationsSnapshot>;
+
+export function GetStatus():Promise<main.AppStatus>;
+
+export function GetTimerSettings():Promise<config.TimerSettings>;
+
+export function GetTimers():Promise<Array<timers.Timer>>;
+
+export function GetUISettings():Promise<config.UISettings>;
+
+export function GetVerificationSettings():Promise<config.VerificationSettings>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function IdentifyStation(arg1:string):Promise<void>;
+
+export function ImportStations(arg1:string,arg2:boolean):Promise<station.ImportReport>;
+
 export function IsScanning():Promise<boolean>;
 
-export function PowerOffAllStations():Promise<void>;
+export function ListBluetoothAdapters():Promise<Array<bluetooth.AdapterInfo>>;
+
+export function PowerCycleAllStations():Promise<void>;
+
+export function PowerCycleStation(arg1:string):Promise<void>;
+
+export function PowerOffAllStations():Promise<Array<station.StationOperationResult>>;
+
+export function PowerOffAllStationsAsync():Promise<string>;
+
+export function PowerOffGroup(arg1:string):Promise<Array<station.RestoreResult>>;
+
+export function PowerOffNonEssentials():Promise<Array<station.RestoreResult>>;
 
 export function PowerOffStation(arg1:string):Promise<void>;
 
-export function PowerOnAllStations():Promise<void>;
+export function PowerOffStationAsync(arg1:string):Promise<string>;
+
+export function PowerOnAllStations():Promise<Array<station.StationOperationResult>>;
+
+export function PowerOnAllStationsAsync():Promise<string>;
+
+export function PowerOnEssentials():Promise<Array<station.RestoreResult>>;
+
+export function PowerOnGroup(arg1:string):Promise<Array<station.RestoreResult>>;
 
 export function PowerOnStation(arg1:string):Promise<void>;
 
+export function PowerOnStationAsync(arg1:string):Promise<string>;
+
+export function PruneStaleStations(arg1:number):Promise<Array<string>>;
+
+export function RemoveAgent(arg1:string):Promise<void>;
+
 export function RenameStation(arg1:string,arg2:string):Promise<void>;
 
+export function ResetStationHealth(arg1:string):Promise<void>;
+
+export function RestoreSnapshot(arg1:string):Promise<Array<station.RestoreResult>>;
+
+export function ResumeLastSession():Promise<session.ResumeResult>;
+
+export function RetryInitialize():Promise<void>;
+
+export function RunSelfTest(arg1:string):Promise<station.SelfTestReport>;
+
 export function SaveConfig():Promise<void>;
 
-export function ScanAndFetchStations():Promise<Array<station.StationInfo>>;
+export function ScanAndFetchStations():Promise<Array<apiclient.StationInfo>>;
+
+export function SetBluetoothAdapter(arg1:string):Promise<void>;
+
+export function SetConnectionSettings(arg1:config.ConnectionSettings):Promise<void>;
+
+export function SetDisconnectAfterCommand(arg1:boolean):Promise<void>;
+
+export function SetNotificationSettings(arg1:config.NotificationSettings):Promise<void>;
+
+export function SetPollingSettings(arg1:config.PollingSettings):Promise<void>;
+
+export function SetPowerSourceSettings(arg1:config.PowerSourceSettings):Promise<void>;
+
+export function SetPrivacySettings(arg1:config.PrivacySettings):Promise<void>;
+
+export function SetProcessRules(arg1:Array<config.ProcessRule>):Promise<void>;
+
+export function SetSafetySettings(arg1:config.SafetySettings):Promise<void>;
+
+export function SetScanFilter(arg1:config.FilterConfig):Promise<void>;
+
+export function SetShutdownSettings(arg1:config.ShutdownSettings):Promise<void>;
+
+export function SetStartupSettings(arg1:config.StartupSettings):Promise<void>;
+
+export function SetStationAutoReconnect(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetStationChannel(arg1:string,arg2:number):Promise<void>;
+
+export function SetStationEssential(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetStationManaged(arg1:string,arg2:boolean):Promise<void>;
+
+export function SetTimerSettings(arg1:config.TimerSettings):Promise<void>;
+
+export function SetUISettings(arg1:config.UISettings):Promise<void>;
+
+export function SetVerificationSettings(arg1:config.VerificationSettings):Promise<void>;
+
+export function SetWindowVisible(arg1:boolean):Promise<void>;
+
+export function SnapshotStates(arg1:string):Promise<config.PowerSnapshot>;
+
+export function SnoozeSafetyAutoOff(arg1:string):Promise<any>;
+
+export function StandbyAllStations():Promise<Array<station.StationOperationResult>>;
+
+export function StandbyAllStationsAsync():Promise<string>;
+
+export function StandbyStation(arg1:string):Promise<void>;
+
+export function StandbyStationAsync(arg1:string):Promise<string>;
+
+export function WriteRawCharacteristic(arg1:string,arg2:string,arg3:string,arg4:string,arg5:boolean):Promise<bluetooth.RawWriteResult>;
@@ -2,42 +2,262 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddAgent(arg1) {
+  return window['go']['main']['App']['AddAgent'](arg1);
+}
+
+export function AddTimer(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddTimer'](arg1, arg2, arg3);
+}
+
+export function AssignStationToGroup(arg1, arg2) {
+  return window['go']['main']['App']['AssignStationToGroup'](arg1, arg2);
+}
+
+export function CancelTimer(arg1) {
+  return window['go']['main']['App']['CancelTimer'](arg1);
+}
+
 export function CheckAllStationStatuses() {
   return window['go']['main']['App']['CheckAllStationStatuses']();
 }
 
+export function CreateGroup(arg1) {
+  return window['go']['main']['App']['CreateGroup'](arg1);
+}
+
+export function DeleteGroup(arg1) {
+  return window['go']['main']['App']['DeleteGroup'](arg1);
+}
+
+export function DeleteSnapshot(arg1) {
+  return window['go']['main']['App']['DeleteSnapshot'](arg1);
+}
+
+export function ExportDiagnostics(arg1) {
+  return window['go']['main']['App']['ExportDiagnostics'](arg1);
+}
+
+export function ExportStations(arg1, arg2) {
+  return window['go']['main']['App']['ExportStations'](arg1, arg2);
+}
+
+export function ForgetStation(arg1) {
+  return window['go']['main']['App']['ForgetStation'](arg1);
+}
+
+export function GetAbout() {
+  return window['go']['main']['App']['GetAbout']();
+}
+
+export function GetAdapterStatus() {
+  return window['go']['main']['App']['GetAdapterStatus']();
+}
+
+export function GetAgents() {
+  return window['go']['main']['App']['GetAgents']();
+}
+
+export function GetAuditLog(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetAuditLog'](arg1, arg2, arg3);
+}
+
+export function GetConnectionSettings() {
+  return window['go']['main']['App']['GetConnectionSettings']();
+}
+
 export function GetCurrentStationInfo() {
   return window['go']['main']['App']['GetCurrentStationInfo']();
 }
 
+export function GetGroups() {
+  return window['go']['main']['App']['GetGroups']();
+}
+
+export function GetJobResult(arg1) {
+  return window['go']['main']['App']['GetJobResult'](arg1);
+}
+
+export function GetNotificationSettings() {
+  return window['go']['main']['App']['GetNotificationSettings']();
+}
+
+export function GetPollingSettings() {
+  return window['go']['main']['App']['GetPollingSettings']();
+}
+
+export function GetPowerSnapshots() {
+  return window['go']['main']['App']['GetPowerSnapshots']();
+}
+
+export function GetPowerSourceSettings() {
+  return window['go']['main']['App']['GetPowerSourceSettings']();
+}
+
+export function GetPrivacySettings() {
+  return window['go']['main']['App']['GetPrivacySettings']();
+}
+
+export function GetProcessRules() {
+  return window['go']['main']['App']['GetProcessRules']();
+}
+
+export function GetRecentEvents(arg1, arg2) {
+  return window['go']['main']['App']['GetRecentEvents'](arg1, arg2);
+}
+
+export function GetSafetySettings() {
+  return window['go']['main']['App']['GetSafetySettings']();
+}
+
+export function GetScanFilter() {
+  return window['go']['main']['App']['GetScanFilter']();
+}
+
+export function GetShutdownSettings() {
+  return window['go']['main']['App']['GetShutdownSettings']();
+}
+
+export function GetStartupSettings() {
+  return window['go']['main']['App']['GetStartupSettings']();
+}
+
+export function GetStationDetails(arg1) {
+  return window['go']['main']['App']['GetStationDetails'](arg1);
+}
+
+export function GetStationSnapshot() {
+  return window['go']['main']['App']['GetStationSnapshot']();
+}
+
+export function GetStatus() {
+  return window['go']['main']['App']['GetStatus']();
+}
+
+export function GetTimerSettings() {
+  return window['go']['main']['App']['GetTimerSettings']();
+}
+
+export function GetTimers() {
+  return window['go']['main']['App']['GetTimers']();
+}
+
+export function GetUISettings() {
+  return window['go']['main']['App']['GetUISettings']();
+}
+
+export function GetVerificationSettings() {
+  return window['go']['main']['App']['GetVerificationSettings']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function IdentifyStation(arg1) {
+  return window['go']['main']['App']['IdentifyStation'](arg1);
+}
+
+export function ImportStations(arg1, arg2) {
+  return window['go']['main']['App']['ImportStations'](arg1, arg2);
+}
+
 export function IsScanning() {
   return window['go']['main']['App']['IsScanning']();
 }
 
+export function ListBluetoothAdapters() {
+  return window['go']['main']['App']['ListBluetoothAdapters']();
+}
+
+export function PowerCycleAllStations() {
+  return window['go']['main']['App']['PowerCycleAllStations']();
+}
+
+export function PowerCycleStation(arg1) {
+  return window['go']['main']['App']['PowerCycleStation'](arg1);
+}
+
 export function PowerOffAllStations() {
   return window['go']['main']['App']['PowerOffAllStations']();
 }
 
+export function PowerOffAllStationsAsync() {
+  return window['go']['main']['App']['PowerOffAllStationsAsync']();
+}
+
+export function PowerOffGroup(arg1) {
+  return window['go']['main']['App']['PowerOffGroup'](arg1);
+}
+
+export function PowerOffNonEssentials() {
+  return window['go']['main']['App']['PowerOffNonEssentials']();
+}
+
 export function PowerOffStation(arg1) {
   return window['go']['main']['App']['PowerOffStation'](arg1);
 }
 
+export function PowerOffStationAsync(arg1) {
+  return window['go']['main']['App']['PowerOffStationAsync'](arg1);
+}
+
 export function PowerOnAllStations() {
   return window['go']['main']['App']['PowerOnAllStations']();
 }
 
+export function PowerOnAllStationsAsync() {
+  return window['go']['main']['App']['PowerOnAllStationsAsync']();
+}
+
+export function PowerOnEssentials() {
+  return window['go']['main']['App']['PowerOnEssentials']();
+}
+
+export function PowerOnGroup(arg1) {
+  return window['go']['main']['App']['PowerOnGroup'](arg1);
+}
+
 export function PowerOnStation(arg1) {
   return window['go']['main']['App']['PowerOnStation'](arg1);
 }
 
+export function PowerOnStationAsync(arg1) {
+  return window['go']['main']['App']['PowerOnStationAsync'](arg1);
+}
+
+export function PruneStaleStations(arg1) {
+  return window['go']['main']['App']['PruneStaleStations'](arg1);
+}
+
+export function RemoveAgent(arg1) {
+  return window['go']['main']['App']['RemoveAgent'](arg1);
+}
+
 export function RenameStation(arg1, arg2) {
   return window['go']['main']['App']['RenameStation'](arg1, arg2);
 }
 
+export function ResetStationHealth(arg1) {
+  return window['go']['main']['App']['ResetStationHealth'](arg1);
+}
+
+export function RestoreSnapshot(arg1) {
+  return window['go']['main']['App']['RestoreSnapshot'](arg1);
+}
+
+export function ResumeLastSession() {
+  return window['go']['main']['App']['ResumeLastSession']();
+}
+
+export function RetryInitialize() {
+  return window['go']['main']['App']['RetryInitialize']();
+}
+
+export function RunSelfTest(arg1) {
+  return window['go']['main']['App']['RunSelfTest'](arg1);
+}
+
 export function SaveConfig() {
   return window['go']['main']['App']['SaveConfig']();
 }
@@ -45,3 +265,111 @@ export function SaveConfig() {
 export function ScanAndFetchStations() {
   return window['go']['main']['App']['ScanAndFetchStations']();
 }
+
+export function SetBluetoothAdapter(arg1) {
+  return window['go']['main']['App']['SetBluetoothAdapter'](arg1);
+}
+
+export function SetConnectionSettings(arg1) {
+  return window['go']['main']['App']['SetConnectionSettings'](arg1);
+}
+
+export function SetDisconnectAfterCommand(arg1) {
+  return window['go']['main']['App']['SetDisconnectAfterCommand'](arg1);
+}
+
+export function SetNotificationSettings(arg1) {
+  return window['go']['main']['App']['SetNotificationSettings'](arg1);
+}
+
+export function SetPollingSettings(arg1) {
+  return window['go']['main']['App']['SetPollingSettings'](arg1);
+}
+
+export function SetPowerSourceSettings(arg1) {
+  return window['go']['main']['App']['SetPowerSourceSettings'](arg1);
+}
+
+export function SetPrivacySettings(arg1) {
+  return window['go']['main']['App']['SetPrivacySettings'](arg1);
+}
+
+export function SetProcessRules(arg1) {
+  return window['go']['main']['App']['SetProcessRules'](arg1);
+}
+
+export function SetSafetySettings(arg1) {
+  return window['go']['main']['App']['SetSafetySettings'](arg1);
+}
+
+export function SetScanFilter(arg1) {
+  return window['go']['main']['App']['SetScanFilter'](arg1);
+}
+
+export function SetShutdownSettings(arg1) {
+  return window['go']['main']['App']['SetShutdownSettings'](arg1);
+}
+
+export function SetStartupSettings(arg1) {
+  return window['go']['main']['App']['SetStartupSettings'](arg1);
+}
+
+export function SetStationAutoReconnect(arg1, arg2) {
+  return window['go']['main']['App']['SetStationAutoReconnect'](arg1, arg2);
+}
+
+export function SetStationChannel(arg1, arg2) {
+  return window['go']['main']['App']['SetStationChannel'](arg1, arg2);
+}
+
+export function SetStationEssential(arg1, arg2) {
+  return window['go']['main']['App']['SetStationEssential'](arg1, arg2);
+}
+
+export function SetStationManaged(arg1, arg2) {
+  return window['go']['main']['App']['SetStationManaged'](arg1, arg2);
+}
+
+export function SetTimerSettings(arg1) {
+  return window['go']['main']['App']['SetTimerSettings'](arg1);
+}
+
+export function SetUISettings(arg1) {
+  return window['go']['main']['App']['SetUISettings'](arg1);
+}
+
+export function SetVerificationSettings(arg1) {
+  return window['go']['main']['App']['SetVerificationSettings'](arg1);
+}
+
+export function SetWindowVisible(arg1) {
+  return window['go']['main']['App']['SetWindowVisible'](arg1);
+}
+
+export function SnapshotStates(arg1) {
+  return window['go']['main']['App']['SnapshotStates'](arg1);
+}
+
+export function SnoozeSafetyAutoOff(arg1) {
+  return window['go']['main']['App']['SnoozeSafetyAutoOff'](arg1);
+}
+
+export function StandbyAllStations() {
+  return window['go']['main']['App']['StandbyAllStations']();
+}
+
+export function StandbyAllStationsAsync() {
+  return window['go']['main']['App']['StandbyAllStationsAsync']();
+}
+
+export function StandbyStation(arg1) {
+  return window['go']['main']['App']['StandbyStation'](arg1);
+}
+
+export function StandbyStationAsync(arg1) {
+  return window['go']['main']['App']['StandbyStationAsync'](arg1);
+}
+
+export function WriteRawCharacteristic(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['WriteRawCharacteristic'](arg1, arg2, arg3, arg4, arg5);
+}
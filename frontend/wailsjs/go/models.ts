@@ -1,10 +1,51 @@
-export namespace station {
+export namespace apiclient {
+	
+	export class RawPowerReading {
+	    value: number;
+	    bytes?: string;
+	    at: any;
+	    source: string;
+	    error?: string;
 	
+	    static createFrom(source: any = {}) {
+	        return new RawPowerReading(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.value = source["value"];
+	        this.bytes = source["bytes"];
+	        this.at = source["at"];
+	        this.source = source["source"];
+	        this.error = source["error"];
+	    }
+	}
 	export class StationInfo {
+	    id: string;
 	    name: string;
 	    originalName: string;
 	    address: string;
 	    powerState: number;
+	    ready: boolean;
+	    connected: boolean;
+	    generation: number;
+	    channel: number;
+	    serialNumber?: string;
+	    modelNumber?: string;
+	    rssi: number;
+	    firstSeen?: any;
+	    lastSeen?: any;
+	    origin: string;
+	    essential: boolean;
+	    degraded?: boolean;
+	    reconnecting?: boolean;
+	    noReconnect?: boolean;
+	    unmanaged?: boolean;
+	    lastStateUpdate?: any;
+	    stale: boolean;
+	    channelConflict?: boolean;
+	    health?: string;
+	    group?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new StationInfo(source);
@@ -12,10 +53,1032 @@ export namespace station {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
 	        this.name = source["name"];
 	        this.originalName = source["originalName"];
 	        this.address = source["address"];
 	        this.powerState = source["powerState"];
+	        this.ready = source["ready"];
+	        this.connected = source["connected"];
+	        this.generation = source["generation"];
+	        this.channel = source["channel"];
+	        this.serialNumber = source["serialNumber"];
+	        this.modelNumber = source["modelNumber"];
+	        this.rssi = source["rssi"];
+	        this.firstSeen = source["firstSeen"];
+	        this.lastSeen = source["lastSeen"];
+	        this.origin = source["origin"];
+	        this.essential = source["essential"];
+	        this.degraded = source["degraded"];
+	        this.reconnecting = source["reconnecting"];
+	        this.noReconnect = source["noReconnect"];
+	        this.unmanaged = source["unmanaged"];
+	        this.lastStateUpdate = source["lastStateUpdate"];
+	        this.stale = source["stale"];
+	        this.channelConflict = source["channelConflict"];
+	        this.health = source["health"];
+	        this.group = source["group"];
+	    }
+	}
+
+}
+
+export namespace audit {
+	
+	export class Entry {
+	    time: any;
+	    stationId: string;
+	    station: string;
+	    address: string;
+	    action: string;
+	    origin: string;
+	    clientIp?: string;
+	    outcome: string;
+	    error?: string;
+	    changes?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.stationId = source["stationId"];
+	        this.station = source["station"];
+	        this.address = source["address"];
+	        this.action = source["action"];
+	        this.origin = source["origin"];
+	        this.clientIp = source["clientIp"];
+	        this.outcome = source["outcome"];
+	        this.error = source["error"];
+	        this.changes = source["changes"];
+	    }
+	}
+
+}
+
+export namespace bluetooth {
+	
+	export class AdapterInfo {
+	    id: string;
+	    name: string;
+	    address?: string;
+	    active: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AdapterInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.address = source["address"];
+	        this.active = source["active"];
+	    }
+	}
+	export class HealthCounters {
+	    health: string;
+	    operations: number;
+	    connectFailures: number;
+	    writeFailures: number;
+	    readFailures: number;
+	    lastError?: string;
+	    lastErrorAt?: any;
+	    since: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new HealthCounters(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.health = source["health"];
+	        this.operations = source["operations"];
+	        this.connectFailures = source["connectFailures"];
+	        this.writeFailures = source["writeFailures"];
+	        this.readFailures = source["readFailures"];
+	        this.lastError = source["lastError"];
+	        this.lastErrorAt = source["lastErrorAt"];
+	        this.since = source["since"];
+	    }
+	}
+	export class RawWriteResult {
+	    written: number;
+	    readBack?: string;
+	    readError?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RawWriteResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.written = source["written"];
+	        this.readBack = source["readBack"];
+	        this.readError = source["readError"];
+	    }
+	}
+
+}
+
+export namespace config {
+	
+	export class AgentConfig {
+	    name: string;
+	    url: string;
+	    token: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AgentConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	        this.token = source["token"];
+	    }
+	}
+	export class ConnectionParams {
+	    connectTimeoutMs: number;
+	    minIntervalMs: number;
+	    maxIntervalMs: number;
+	    supervisionTimeoutMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionParams(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.connectTimeoutMs = source["connectTimeoutMs"];
+	        this.minIntervalMs = source["minIntervalMs"];
+	        this.maxIntervalMs = source["maxIntervalMs"];
+	        this.supervisionTimeoutMs = source["supervisionTimeoutMs"];
+	    }
+	}
+	export class ConnectionSettings {
+	    prewarm: boolean;
+	    maxConcurrent: number;
+	    disconnectBeforeScan: boolean;
+	    retryFailed: boolean;
+	    waitForScan: boolean;
+	    params: ConnectionParams;
+	    operationTimeoutSeconds: number;
+	    discoveryTimeoutSeconds: number;
+	    adapter?: string;
+	    autoReconnect: boolean;
+	    reconnectAttempts: number;
+	    disconnectAfterCommand: boolean;
+	    pruneAfterMissedScans: number;
+	    retry: RetrySettings;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.prewarm = source["prewarm"];
+	        this.maxConcurrent = source["maxConcurrent"];
+	        this.disconnectBeforeScan = source["disconnectBeforeScan"];
+	        this.retryFailed = source["retryFailed"];
+	        this.waitForScan = source["waitForScan"];
+	        this.params = this.convertValues(source["params"], ConnectionParams);
+	        this.operationTimeoutSeconds = source["operationTimeoutSeconds"];
+	        this.discoveryTimeoutSeconds = source["discoveryTimeoutSeconds"];
+	        this.adapter = source["adapter"];
+	        this.autoReconnect = source["autoReconnect"];
+	        this.reconnectAttempts = source["reconnectAttempts"];
+	        this.disconnectAfterCommand = source["disconnectAfterCommand"];
+	        this.pruneAfterMissedScans = source["pruneAfterMissedScans"];
+	        this.retry = this.convertValues(source["retry"], RetrySettings);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FilterConfig {
+	    prefixes: string[];
+	    addresses?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FilterConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.prefixes = source["prefixes"];
+	        this.addresses = source["addresses"];
+	    }
+	}
+	export class NotificationSettings {
+	    enabled: boolean;
+	    actionButtons: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotificationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.actionButtons = source["actionButtons"];
+	    }
+	}
+	export class PollingSettings {
+	    whenHidden: string;
+	    slowFactor: number;
+	    statusCacheMs: number;
+	    intervalSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PollingSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.whenHidden = source["whenHidden"];
+	        this.slowFactor = source["slowFactor"];
+	        this.statusCacheMs = source["statusCacheMs"];
+	        this.intervalSeconds = source["intervalSeconds"];
+	    }
+	}
+	export class PowerSnapshot {
+	    name: string;
+	    createdAt: any;
+	    stations: SnapshotStation[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PowerSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.createdAt = source["createdAt"];
+	        this.stations = this.convertValues(source["stations"], SnapshotStation);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PowerSourceSettings {
+	    enabled: boolean;
+	    onUnplugged: string;
+	    onPluggedIn: string;
+	    debounceSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PowerSourceSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.onUnplugged = source["onUnplugged"];
+	        this.onPluggedIn = source["onPluggedIn"];
+	        this.debounceSeconds = source["debounceSeconds"];
+	    }
+	}
+	export class PrivacySettings {
+	    redactAddresses: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PrivacySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.redactAddresses = source["redactAddresses"];
+	    }
+	}
+	export class ProcessRule {
+	    name: string;
+	    enabled: boolean;
+	    processes: string[];
+	    onStart: string;
+	    onExit: string;
+	    gracePeriodSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProcessRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.processes = source["processes"];
+	        this.onStart = source["onStart"];
+	        this.onExit = source["onExit"];
+	        this.gracePeriodSeconds = source["gracePeriodSeconds"];
+	    }
+	}
+	export class RetrySettings {
+	    attempts: number;
+	    initialDelayMs: number;
+	    multiplier: number;
+	    attemptTimeoutMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetrySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.attempts = source["attempts"];
+	        this.initialDelayMs = source["initialDelayMs"];
+	        this.multiplier = source["multiplier"];
+	        this.attemptTimeoutMs = source["attemptTimeoutMs"];
+	    }
+	}
+	export class SafetySettings {
+	    enabled: boolean;
+	    maxOnHours: number;
+	    warningMinutes: number;
+	    snoozeMinutes: number;
+	    action: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SafetySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.maxOnHours = source["maxOnHours"];
+	        this.warningMinutes = source["warningMinutes"];
+	        this.snoozeMinutes = source["snoozeMinutes"];
+	        this.action = source["action"];
+	    }
+	}
+	export class SnapshotStation {
+	    id: string;
+	    name: string;
+	    powerState: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotStation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.powerState = source["powerState"];
+	    }
+	}
+	export class ShutdownSettings {
+	    action: string;
+	    timeoutSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ShutdownSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.timeoutSeconds = source["timeoutSeconds"];
+	    }
+	}
+	export class StartupSettings {
+	    action: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartupSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	    }
+	}
+	export class TimerSettings {
+	    missedPolicy: string;
+	    missedGraceMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimerSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.missedPolicy = source["missedPolicy"];
+	        this.missedGraceMinutes = source["missedGraceMinutes"];
+	    }
+	}
+	export class UISettings {
+	    taskbarBadge: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UISettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskbarBadge = source["taskbarBadge"];
+	    }
+	}
+	export class VerificationSettings {
+	    enabled: boolean;
+	    windowSeconds: number;
+	    checks: number;
+	    reapply: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new VerificationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.windowSeconds = source["windowSeconds"];
+	        this.checks = source["checks"];
+	        this.reapply = source["reapply"];
+	    }
+	}
+
+}
+
+export namespace events {
+	
+	export class Record {
+	    seq: number;
+	    name: string;
+	    time: any;
+	    data?: json.RawMessage;
+	    truncated?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Record(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
+	        this.name = source["name"];
+	        this.time = source["time"];
+	        this.data = source["data"];
+	        this.truncated = source["truncated"];
+	    }
+	}
+
+}
+
+export namespace jobs {
+	
+	export class Job {
+	    id: string;
+	    action: string;
+	    address: string;
+	    status: string;
+	    error?: string;
+	    startedAt: any;
+	    finishedAt: any;
+	    result?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.action = source["action"];
+	        this.address = source["address"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.result = source["result"];
+	    }
+	}
+
+}
+
+export namespace main {
+	
+	export class AboutInfo {
+	    name: string;
+	    version: string;
+	    instance?: string;
+	    paths: paths.Paths;
+	
+	    static createFrom(source: any = {}) {
+	        return new AboutInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	        this.instance = source["instance"];
+	        this.paths = this.convertValues(source["paths"], paths.Paths);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AppStatus {
+	    onBattery: boolean;
+	    powerSourceKnown: boolean;
+	    polling: polling.Status;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.onBattery = source["onBattery"];
+	        this.powerSourceKnown = source["powerSourceKnown"];
+	        this.polling = this.convertValues(source["polling"], polling.Status);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace paths {
+	
+	export class Paths {
+	    config: string;
+	    state: string;
+	    cache: string;
+	    log: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Paths(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.config = source["config"];
+	        this.state = source["state"];
+	        this.cache = source["cache"];
+	        this.log = source["log"];
+	    }
+	}
+
+}
+
+export namespace polling {
+	
+	export class Status {
+	    mode: string;
+	    reason: string;
+	    lastCheck?: any;
+	    windowVisible: boolean;
+	    streamClients: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.reason = source["reason"];
+	        this.lastCheck = source["lastCheck"];
+	        this.windowVisible = source["windowVisible"];
+	        this.streamClients = source["streamClients"];
+	    }
+	}
+
+}
+
+export namespace session {
+	
+	export class ResumeResult {
+	    savedAt: any;
+	    checkpoint: boolean;
+	    results: station.RestoreResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResumeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.savedAt = source["savedAt"];
+	        this.checkpoint = source["checkpoint"];
+	        this.results = this.convertValues(source["results"], station.RestoreResult);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace station {
+	
+	export class AdapterStatus {
+	    state: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AdapterStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.state = source["state"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ImportConflict {
+	    address: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportConflict(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.address = source["address"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class ImportInvalid {
+	    index: number;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportInvalid(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ImportReport {
+	    added: string[];
+	    updated: string[];
+	    unchanged: string[];
+	    skipped: ImportConflict[];
+	    invalid: ImportInvalid[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = source["added"];
+	        this.updated = source["updated"];
+	        this.unchanged = source["unchanged"];
+	        this.skipped = this.convertValues(source["skipped"], ImportConflict);
+	        this.invalid = this.convertValues(source["invalid"], ImportInvalid);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RestoreResult {
+	    id: string;
+	    name: string;
+	    address?: string;
+	    action: string;
+	    status: string;
+	    error?: string;
+	    code?: string;
+	    note?: string;
+	    attempts: number;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.address = source["address"];
+	        this.action = source["action"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.code = source["code"];
+	        this.note = source["note"];
+	        this.attempts = source["attempts"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class SelfTestReport {
+	    startedAt: any;
+	    finishedAt: any;
+	    address: string;
+	    station: string;
+	    passed: boolean;
+	    steps: SelfTestStep[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SelfTestReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.address = source["address"];
+	        this.station = source["station"];
+	        this.passed = source["passed"];
+	        this.steps = this.convertValues(source["steps"], SelfTestStep);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SelfTestStep {
+	    name: string;
+	    status: string;
+	    durationMs: number;
+	    detail?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SelfTestStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.durationMs = source["durationMs"];
+	        this.detail = source["detail"];
+	        this.error = source["error"];
+	    }
+	}
+	export class StationDetails {
+	    station: apiclient.StationInfo;
+	    firmwareRevision: string;
+	    hardwareRevision: string;
+	    rawPower: apiclient.RawPowerReading;
+	    health: bluetooth.HealthCounters;
+	
+	    static createFrom(source: any = {}) {
+	        return new StationDetails(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.station = this.convertValues(source["station"], apiclient.StationInfo);
+	        this.firmwareRevision = source["firmwareRevision"];
+	        this.hardwareRevision = source["hardwareRevision"];
+	        this.rawPower = this.convertValues(source["rawPower"], apiclient.RawPowerReading);
+	        this.health = this.convertValues(source["health"], bluetooth.HealthCounters);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StationOperationResult {
+	    id: string;
+	    address: string;
+	    name: string;
+	    success: boolean;
+	    error?: string;
+	    code?: string;
+	    note?: string;
+	    attempts: number;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StationOperationResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.address = source["address"];
+	        this.name = source["name"];
+	        this.success = source["success"];
+	        this.error = source["error"];
+	        this.code = source["code"];
+	        this.note = source["note"];
+	        this.attempts = source["attempts"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class StationsSnapshot {
+	    seq: number;
+	    stations: apiclient.StationInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new StationsSnapshot(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.seq = source["seq"];
+	        this.stations = this.convertValues(source["stations"], apiclient.StationInfo);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StatusCheck {
+	    stations: apiclient.StationInfo[];
+	    checkedAt: any;
+	    shared: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new StatusCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.stations = this.convertValues(source["stations"], apiclient.StationInfo);
+	        this.checkedAt = source["checkedAt"];
+	        this.shared = source["shared"];
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace timers {
+	
+	export class Timer {
+	    id: string;
+	    action: string;
+	    address: string;
+	    at: any;
+	    createdAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Timer(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.action = source["action"];
+	        this.address = source["address"];
+	        this.at = source["at"];
+	        this.createdAt = source["createdAt"];
 	    }
 	}
 
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/station"
+	"lhcontrol/pkg/apiclient"
+)
+
+// Client talks to the HTTP API of a remote lhcontrol agent. It wraps the
+// public API client and adds the agent's name to errors.
+type Client struct {
+	Name string
+	api  *apiclient.Client
+}
+
+func NewClient(cfg config.AgentConfig) *Client {
+	return &Client{
+		Name: cfg.Name,
+		api:  apiclient.NewClient(cfg.URL, cfg.Token),
+	}
+}
+
+func (c *Client) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("agent %s: %w", c.Name, err)
+}
+
+// Status returns the stations known to the agent.
+func (c *Client) Status(ctx context.Context) ([]station.StationInfo, error) {
+	stations, err := c.api.ListStations(ctx)
+	return stations, c.wrap(err)
+}
+
+// PowerOn turns on one of the agent's stations.
+func (c *Client) PowerOn(ctx context.Context, address string) error {
+	_, err := c.api.PowerStation(ctx, address, true)
+	return c.wrap(err)
+}
+
+// PowerOff turns off one of the agent's stations.
+func (c *Client) PowerOff(ctx context.Context, address string) error {
+	_, err := c.api.PowerStation(ctx, address, false)
+	return c.wrap(err)
+}
+
+// Standby puts one of the agent's stations into standby.
+func (c *Client) Standby(ctx context.Context, address string) error {
+	_, err := c.api.StandbyStation(ctx, address)
+	return c.wrap(err)
+}
+
+// PowerOnAll turns on all of the agent's stations and waits for the result.
+func (c *Client) PowerOnAll(ctx context.Context) error {
+	return c.wrap(c.api.PowerAll(ctx, true, true))
+}
+
+// PowerOffAll turns off all of the agent's stations and waits for the result.
+func (c *Client) PowerOffAll(ctx context.Context) error {
+	return c.wrap(c.api.PowerAll(ctx, false, true))
+}
+
+// StandbyAll puts all of the agent's stations into standby and waits for the result.
+func (c *Client) StandbyAll(ctx context.Context) error {
+	return c.wrap(c.api.StandbyAll(ctx, true))
+}
+
+// Scan starts a scan on the agent. Results arrive with the next Status call.
+func (c *Client) Scan(ctx context.Context) error {
+	_, err := c.api.Scan(ctx, false)
+	return c.wrap(err)
+}
@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/station"
+)
+
+// Registry keeps the station lists of all configured agents.
+type Registry struct {
+	cfg      *config.Config
+	interval time.Duration
+
+	mu       sync.RWMutex
+	clients  map[string]*Client
+	stations map[string][]station.StationInfo // Agent name -> last reported stations
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewRegistry(cfg *config.Config, interval time.Duration) *Registry {
+	return &Registry{
+		cfg:      cfg,
+		interval: interval,
+		clients:  make(map[string]*Client),
+		stations: make(map[string][]station.StationInfo),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins polling the agents in the background.
+func (r *Registry) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+			r.Refresh(ctx)
+			cancel()
+			select {
+			case <-ticker.C:
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the poll loop to exit.
+func (r *Registry) Stop() {
+	r.stopOnce.Do(func() { close(r.stopChan) })
+	r.wg.Wait()
+}
+
+// syncClients rebuilds the client set from the config.
+func (r *Registry) syncClients() []*Client {
+	agents := r.cfg.GetAgents()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clients := make(map[string]*Client, len(agents))
+	list := make([]*Client, 0, len(agents))
+	for _, agentCfg := range agents {
+		client := NewClient(agentCfg)
+		clients[agentCfg.Name] = client
+		list = append(list, client)
+	}
+	r.clients = clients
+	for name := range r.stations {
+		if _, ok := clients[name]; !ok {
+			delete(r.stations, name)
+		}
+	}
+	return list
+}
+
+// Refresh fetches the station lists of all agents concurrently.
+// Agents that can't be reached keep reporting their stations with an unknown state.
+func (r *Registry) Refresh(ctx context.Context) {
+	clients := r.syncClients()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			stations, err := c.Status(ctx)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if err != nil {
+				log.Printf("Agent: Failed to refresh %s: %v", c.Name, err)
+				for i := range r.stations[c.Name] {
+					r.stations[c.Name][i].PowerState = -1
+					r.stations[c.Name][i].Ready = false
+				}
+				return
+			}
+			for i := range stations {
+				stations[i].Origin = c.Name
+			}
+			r.stations[c.Name] = stations
+		}(client)
+	}
+	wg.Wait()
+}
+
+// Stations returns the merged station lists of all agents, tagged with their origin.
+func (r *Registry) Stations() []station.StationInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	merged := make([]station.StationInfo, 0)
+	for _, stations := range r.stations {
+		merged = append(merged, stations...)
+	}
+	return merged
+}
+
+// ClientFor returns the agent that reported the given station address, or nil.
+func (r *Registry) ClientFor(address string) *Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, stations := range r.stations {
+		for _, s := range stations {
+			if bluetooth.SameAddress(s.Address, address) {
+				return r.clients[name]
+			}
+		}
+	}
+	return nil
+}
+
+// Clients returns all configured agents.
+func (r *Registry) Clients() []*Client {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]*Client, 0, len(r.clients))
+	for _, c := range r.clients {
+		list = append(list, c)
+	}
+	return list
+}
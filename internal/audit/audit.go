@@ -0,0 +1,251 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/paths"
+)
+
+const (
+	auditFileName = "audit.jsonl"
+
+	// maxEntries bounds the journal, older entries are dropped
+	maxEntries = 5000
+	// appendBuffer is how many entries may wait for the writer before new
+	// ones are only kept in memory
+	appendBuffer = 256
+)
+
+// Command outcomes
+const (
+	OutcomeOK         = "ok"
+	OutcomeFailed     = "failed"
+	OutcomeSuperseded = "superseded" // Replaced by a newer command before it ran
+)
+
+// ActionConfig marks entries for config changes made through the API.
+const ActionConfig = "config"
+
+// Entry is one power command, or config change, in the journal.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	StationID string    `json:"stationId"`
+	Station   string    `json:"station"` // Name at the time of the command
+	Address   string    `json:"address"`
+	Action    string    `json:"action"`
+	Origin    string    `json:"origin"` // ui, api, schedule, automation, ...
+	ClientIP  string    `json:"clientIp,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+	Changes   []string  `json:"changes,omitempty"` // Config sections changed, for ActionConfig
+}
+
+// Filter selects journal entries. Zero fields match everything.
+type Filter struct {
+	Station string // Station ID or address
+	From    time.Time
+	To      time.Time
+	Limit   int // Most recent entries to return
+}
+
+// Journal is a bounded log of power commands, persisted as JSON lines in the
+// state directory. Appends are written by a background goroutine so they
+// never wait for the disk.
+type Journal struct {
+	mu      sync.RWMutex
+	entries []Entry // Oldest first
+	path    string
+	lines   int // Lines in the file, compacted once it holds twice maxEntries
+
+	appends  chan Entry
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewJournal creates an empty Journal. Call Load to restore persisted entries
+// and Start to begin writing.
+func NewJournal() *Journal {
+	return &Journal{
+		appends:  make(chan Entry, appendBuffer),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Load reads the persisted journal, keeping the newest maxEntries entries.
+func (j *Journal) Load() error {
+	auditFilePath, err := paths.StateFile(auditFileName)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.path = auditFilePath
+
+	f, err := os.Open(auditFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading audit file '%s': %w", auditFilePath, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		j.lines++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn last line after a crash, skip it
+			continue
+		}
+		j.entries = append(j.entries, entry)
+	}
+	if len(j.entries) > maxEntries {
+		j.entries = append([]Entry(nil), j.entries[len(j.entries)-maxEntries:]...)
+	}
+	return scanner.Err()
+}
+
+// Path returns the journal file, empty before Load.
+func (j *Journal) Path() string {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.path
+}
+
+// Start begins writing appended entries in the background.
+func (j *Journal) Start() {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		for {
+			select {
+			case entry := <-j.appends:
+				j.write(entry)
+			case <-j.stopChan:
+				for {
+					select {
+					case entry := <-j.appends:
+						j.write(entry)
+					default:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop writes the entries still waiting and ends the writer.
+func (j *Journal) Stop() {
+	j.stopOnce.Do(func() { close(j.stopChan) })
+	j.wg.Wait()
+}
+
+// Append adds an entry. It never blocks: if the writer falls behind, the entry
+// is kept in memory and written with the next compaction.
+func (j *Journal) Append(entry Entry) {
+	j.mu.Lock()
+	j.entries = append(j.entries, entry)
+	if len(j.entries) > maxEntries {
+		j.entries = append([]Entry(nil), j.entries[len(j.entries)-maxEntries:]...)
+	}
+	j.mu.Unlock()
+
+	select {
+	case j.appends <- entry:
+	default:
+		log.Printf("Audit: Writer is behind, entry for %s kept in memory only", entry.Address)
+	}
+}
+
+// Query returns the entries matching f, oldest first.
+func (j *Journal) Query(f Filter) []Entry {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	result := []Entry{}
+	for _, entry := range j.entries {
+		if f.Station != "" && entry.StationID != f.Station && entry.Address != f.Station {
+			continue
+		}
+		if !f.From.IsZero() && entry.Time.Before(f.From) {
+			continue
+		}
+		if !f.To.IsZero() && entry.Time.After(f.To) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[len(result)-f.Limit:]
+	}
+	return result
+}
+
+// write appends an entry to the file, or rewrites the file from memory once
+// it has grown to twice the bound.
+func (j *Journal) write(entry Entry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.path == "" {
+		return
+	}
+	if j.lines >= 2*maxEntries {
+		if err := j.compactLocked(); err != nil {
+			log.Printf("Audit: Error compacting journal: %v", err)
+		}
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Audit: Error marshalling entry: %v", err)
+		return
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Audit: Error opening journal: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Printf("Audit: Error writing journal: %v", err)
+		return
+	}
+	j.lines++
+}
+
+// compactLocked rewrites the file with the entries in memory, which include
+// the one being written. Assumes the caller holds mu.
+func (j *Journal) compactLocked() error {
+	tmp := j.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, entry := range j.entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		w.Write(append(data, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+	j.lines = len(j.entries)
+	return nil
+}
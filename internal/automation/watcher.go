@@ -0,0 +1,194 @@
+package automation
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/process"
+)
+
+// Trigger events
+const (
+	EventStart = "start"
+	EventExit  = "exit"
+)
+
+// Trigger describes a rule that fired.
+type Trigger struct {
+	Rule      string   `json:"rule"`
+	Event     string   `json:"event"`
+	Action    string   `json:"action"`
+	Processes []string `json:"processes"` // Matching processes at the time the rule fired
+}
+
+// ruleState tracks the reference count of matching processes for one rule.
+type ruleState struct {
+	count  int
+	active bool
+	exitAt time.Time // Non-zero while the exit grace period is running
+}
+
+// Watcher polls the process list and fires triggers for the configured rules.
+type Watcher struct {
+	cfg       *config.Config
+	interval  time.Duration
+	onTrigger func(Trigger)
+
+	states   map[string]*ruleState
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewWatcher creates a Watcher that reads its rules from cfg on every poll,
+// so rule changes take effect without a restart.
+func NewWatcher(cfg *config.Config, interval time.Duration, onTrigger func(Trigger)) *Watcher {
+	return &Watcher{
+		cfg:       cfg,
+		interval:  interval,
+		onTrigger: onTrigger,
+		states:    make(map[string]*ruleState),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background.
+func (w *Watcher) Start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			w.poll()
+			select {
+			case <-ticker.C:
+			case <-w.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the poll loop to exit.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stopChan) })
+	w.wg.Wait()
+}
+
+// matches reports whether a process name matches a rule pattern.
+// The ".exe" suffix is optional so rules work for Windows and Wine/Proton processes alike.
+func matches(pattern, name string) bool {
+	pattern = strings.ToLower(pattern)
+	name = strings.ToLower(name)
+	if ok, _ := filepath.Match(pattern, name); ok {
+		return true
+	}
+	ok, _ := filepath.Match(strings.TrimSuffix(pattern, ".exe"), strings.TrimSuffix(name, ".exe"))
+	return ok
+}
+
+func (w *Watcher) poll() {
+	rules := w.cfg.GetProcessRules()
+	enabled := make([]config.ProcessRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+
+	// Drop state for rules that were removed or disabled
+	seen := make(map[string]bool, len(enabled))
+	for _, rule := range enabled {
+		seen[rule.Name] = true
+	}
+	for name := range w.states {
+		if !seen[name] {
+			delete(w.states, name)
+		}
+	}
+	if len(enabled) == 0 {
+		return
+	}
+
+	names, err := process.List()
+	if err != nil {
+		log.Printf("Automation: Failed to list processes: %v", err)
+		return
+	}
+
+	now := time.Now()
+	matched := make(map[string][]string, len(enabled))
+	for _, rule := range enabled {
+		state, ok := w.states[rule.Name]
+		if !ok {
+			state = &ruleState{}
+			w.states[rule.Name] = state
+		}
+		for _, name := range names {
+			for _, pattern := range rule.Processes {
+				if matches(pattern, name) {
+					matched[rule.Name] = append(matched[rule.Name], name)
+					break
+				}
+			}
+		}
+		state.count = len(matched[rule.Name])
+	}
+
+	for _, rule := range enabled {
+		state := w.states[rule.Name]
+		switch {
+		case state.count > 0 && !state.active:
+			state.active = true
+			state.exitAt = time.Time{}
+			log.Printf("Automation: Rule '%s' started (%d matching process(es))", rule.Name, state.count)
+			w.fire(rule, EventStart, rule.OnStart, matched[rule.Name])
+		case state.count > 0 && !state.exitAt.IsZero():
+			// Came back within the grace period
+			log.Printf("Automation: Rule '%s' processes restarted within grace period, exit action cancelled", rule.Name)
+			state.exitAt = time.Time{}
+		case state.count == 0 && state.active && state.exitAt.IsZero():
+			state.exitAt = now.Add(time.Duration(rule.GracePeriodSeconds) * time.Second)
+		}
+
+		if state.count == 0 && state.active && !state.exitAt.After(now) {
+			state.active = false
+			state.exitAt = time.Time{}
+			if other := w.otherActiveRule(rule.Name); other != "" {
+				log.Printf("Automation: Rule '%s' exited, but rule '%s' is still active; skipping exit action", rule.Name, other)
+				continue
+			}
+			log.Printf("Automation: Rule '%s' exited", rule.Name)
+			w.fire(rule, EventExit, rule.OnExit, nil)
+		}
+	}
+}
+
+// otherActiveRule returns the name of another rule that still has running processes.
+func (w *Watcher) otherActiveRule(name string) string {
+	for other, state := range w.states {
+		if other != name && state.count > 0 {
+			return other
+		}
+	}
+	return ""
+}
+
+func (w *Watcher) fire(rule config.ProcessRule, event, action string, processes []string) {
+	if action == config.ActionNone || action == "" {
+		return
+	}
+	if w.onTrigger != nil {
+		w.onTrigger(Trigger{
+			Rule:      rule.Name,
+			Event:     event,
+			Action:    action,
+			Processes: processes,
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package badge picks the taskbar overlay icon reflecting how many stations are on.
+package badge
+
+//go:generate go run gen.go
+
+import (
+	"embed"
+	"fmt"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/station"
+)
+
+//go:embed icons/*.png
+var icons embed.FS
+
+// State summarizes the station list for the badge.
+type State struct {
+	Total   int
+	On      int
+	Unknown bool // At least one station is in an unknown or unreachable state
+}
+
+// Compute summarizes a station list.
+func Compute(stations []station.StationInfo) State {
+	state := State{Total: len(stations)}
+	for _, s := range stations {
+		switch {
+		case bluetooth.IsPoweredOn(s.PowerState):
+			state.On++
+		case s.PowerState == bluetooth.PowerStateUnknown:
+			state.Unknown = true
+		}
+	}
+	return state
+}
+
+// Icon returns the PNG overlay icon and its accessibility description.
+// It returns nil when there are no stations and the overlay should be cleared.
+func (s State) Icon() ([]byte, string) {
+	var name, description string
+	switch {
+	case s.Total == 0:
+		return nil, ""
+	case s.Unknown:
+		name, description = "unknown.png", "Some stations are unreachable"
+	case s.On == 0:
+		name, description = "off.png", "All stations off"
+	case s.On > 9:
+		name, description = "on-many.png", fmt.Sprintf("%d stations on", s.On)
+	default:
+		name, description = fmt.Sprintf("on-%d.png", s.On), fmt.Sprintf("%d of %d stations on", s.On, s.Total)
+	}
+	data, err := icons.ReadFile("icons/" + name)
+	if err != nil {
+		return nil, ""
+	}
+	return data, description
+}
+
+// Updater applies badge changes once the station states have settled,
+// so rapid transitions don't make the icon flicker.
+type Updater struct {
+	delay time.Duration
+	apply func(icon []byte, description string)
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending State
+	current *State
+}
+
+func NewUpdater(delay time.Duration, apply func(icon []byte, description string)) *Updater {
+	return &Updater{delay: delay, apply: apply}
+}
+
+// Update schedules the badge for a new station list.
+func (u *Updater) Update(stations []station.StationInfo) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.pending = Compute(stations)
+	if u.timer != nil {
+		u.timer.Stop()
+	}
+	u.timer = time.AfterFunc(u.delay, u.flush)
+}
+
+func (u *Updater) flush() {
+	u.mu.Lock()
+	state := u.pending
+	if u.current != nil && *u.current == state {
+		u.mu.Unlock()
+		return
+	}
+	u.current = &state
+	u.mu.Unlock()
+
+	icon, description := state.Icon()
+	u.apply(icon, description)
+}
+
+// Reset forgets the applied state, so the next update is applied even if unchanged.
+func (u *Updater) Reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.current = nil
+}
+
+// Stop cancels a pending update.
+func (u *Updater) Stop() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.timer != nil {
+		u.timer.Stop()
+	}
+}
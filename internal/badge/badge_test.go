@@ -0,0 +1,32 @@
+package badge
+
+import (
+	"testing"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/station"
+)
+
+func TestCompute(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		states []int
+		want   State
+	}{
+		{name: "no stations", want: State{}},
+		{name: "all off", states: []int{bluetooth.PowerStateOff, bluetooth.PowerStateStandby}, want: State{Total: 2}},
+		{name: "on", states: []int{bluetooth.PowerStateOn, bluetooth.PowerStateOff}, want: State{Total: 2, On: 1}},
+		{name: "spinning up counts as on", states: []int{bluetooth.PowerStateBooting, bluetooth.PowerStateOn}, want: State{Total: 2, On: 2}},
+		{name: "unknown", states: []int{bluetooth.PowerStateUnknown, bluetooth.PowerStateOn}, want: State{Total: 2, On: 1, Unknown: true}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			stations := make([]station.StationInfo, len(test.states))
+			for i, state := range test.states {
+				stations[i].PowerState = state
+			}
+			if got := Compute(stations); got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+//go:build ignore
+
+// gen renders the taskbar overlay icons into icons/. Run with: go generate ./internal/badge
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const size = 16
+
+// 3x5 pixel glyphs, drawn at 2x scale
+var glyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	'!': {".#.", ".#.", ".#.", "...", ".#."},
+}
+
+var (
+	grey   = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+	green  = color.RGBA{R: 0x2e, G: 0x9d, B: 0x3a, A: 0xff}
+	yellow = color.RGBA{R: 0xe0, G: 0xa8, B: 0x00, A: 0xff}
+	white  = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+func render(fill color.RGBA, glyph rune) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	center := float64(size-1) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if math.Hypot(float64(x)-center, float64(y)-center) <= center+0.25 {
+				img.Set(x, y, fill)
+			}
+		}
+	}
+	rows, ok := glyphs[glyph]
+	if !ok {
+		return img
+	}
+	const scale = 2
+	left, top := (size-3*scale)/2, (size-5*scale)/2
+	for gy, row := range rows {
+		for gx, px := range row {
+			if px != '#' {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.Set(left+gx*scale+dx, top+gy*scale+dy, white)
+				}
+			}
+		}
+	}
+	return img
+}
+
+func write(name string, img image.Image) {
+	f, err := os.Create(filepath.Join("icons", name))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	if err := os.MkdirAll("icons", 0755); err != nil {
+		log.Fatal(err)
+	}
+	write("off.png", render(grey, 0))
+	write("unknown.png", render(yellow, '!'))
+	for n := 1; n <= 9; n++ {
+		write(fmt.Sprintf("on-%d.png", n), render(green, rune('0'+n)))
+	}
+	write("on-many.png", render(green, '+'))
+}
@@ -0,0 +1,38 @@
+package bluetooth
+
+// AdapterInfo describes a Bluetooth adapter that can be selected with
+// InitializeAdapter.
+type AdapterInfo struct {
+	ID      string `json:"id"` // E.g. hci1 with BlueZ, empty for the system default
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+	Active  bool   `json:"active"` // The adapter in use
+}
+
+// ListAdapters returns the adapters of this machine. Only BlueZ can list
+// them; on other platforms the system default is the only one.
+func ListAdapters() ([]AdapterInfo, error) {
+	adapters, err := listPlatformAdapters()
+	if err != nil {
+		return nil, err
+	}
+	_, activeID := systemBackend.current()
+	if activeID == "" {
+		activeID = defaultAdapterID
+	}
+	for i := range adapters {
+		adapters[i].Active = adapters[i].ID == activeID
+	}
+	return adapters, nil
+}
+
+// InitializeAdapter is Initialize for the adapter with the given ID from
+// ListAdapters, empty for the system default. Stations must be disconnected
+// first, their handles belong to the previous adapter. Backends other than
+// the system one, like a replay, ignore the ID.
+func InitializeAdapter(id string) error {
+	if err := systemBackend.useAdapter(id); err != nil {
+		return stationError("", ErrNoAdapter, err)
+	}
+	return Initialize()
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package bluetooth
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"tinygo.org/x/bluetooth"
+)
+
+// defaultAdapterID is the adapter tinygo uses by default.
+const defaultAdapterID = "hci0"
+
+// listPlatformAdapters returns the adapters BlueZ knows about.
+func listPlatformAdapters() ([]AdapterInfo, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, stationError("", ErrAdapter, fmt.Errorf("failed to connect to system bus: %w", err))
+	}
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	err = conn.Object("org.bluez", dbus.ObjectPath("/")).Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0).Store(&objects)
+	if err != nil {
+		return nil, stationError("", adapterErrorKind(err, ErrAdapter), fmt.Errorf("failed to list BlueZ adapters: %w", err))
+	}
+
+	adapters := make([]AdapterInfo, 0)
+	for objectPath, interfaces := range objects {
+		props, ok := interfaces["org.bluez.Adapter1"]
+		if !ok {
+			continue
+		}
+		info := AdapterInfo{ID: path.Base(string(objectPath))}
+		info.Name, _ = props["Alias"].Value().(string)
+		info.Address, _ = props["Address"].Value().(string)
+		if info.Name == "" {
+			info.Name = info.ID
+		}
+		adapters = append(adapters, info)
+	}
+	sort.Slice(adapters, func(i, j int) bool { return adapters[i].ID < adapters[j].ID })
+	return adapters, nil
+}
+
+// newPlatformAdapter returns the BlueZ adapter with the given ID.
+func newPlatformAdapter(id string) (*bluetooth.Adapter, error) {
+	if id == "" || id == defaultAdapterID {
+		return bluetooth.DefaultAdapter, nil
+	}
+	return bluetooth.NewAdapter(id), nil
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package bluetooth
+
+import (
+	"fmt"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// defaultAdapterID is the ID of the only adapter WinRT and CoreBluetooth offer.
+const defaultAdapterID = ""
+
+// listPlatformAdapters returns the system default, the only adapter the
+// tinygo backends for Windows and macOS can use.
+func listPlatformAdapters() ([]AdapterInfo, error) {
+	return []AdapterInfo{{ID: defaultAdapterID, Name: "System default"}}, nil
+}
+
+// newPlatformAdapter returns the system default adapter.
+func newPlatformAdapter(id string) (*bluetooth.Adapter, error) {
+	if id != defaultAdapterID {
+		return nil, fmt.Errorf("adapter %q: only the system default adapter can be used on this platform", id)
+	}
+	return bluetooth.DefaultAdapter, nil
+}
@@ -0,0 +1,68 @@
+package bluetooth
+
+import (
+	"strings"
+)
+
+// NormalizeAddress parses an address typed by a user or read from a file and
+// returns it in the form this platform's scan reports, so it can be used as
+// a station key. Case, surrounding whitespace and separators don't matter:
+// "aa-bb-cc-dd-ee-ff" and "AABBCCDDEEFF" both become "AA:BB:CC:DD:EE:FF".
+func NormalizeAddress(address string) (Address, error) {
+	return normalizePlatformAddress(address)
+}
+
+// canonicalAddress returns address in the form NormalizeAddress returns, or
+// unchanged if it can't be parsed. Every address that becomes a station key
+// passes through it, so the same station never shows up twice with addresses
+// that only differ in case or separators.
+func canonicalAddress(address Address) Address {
+	if normalized, err := NormalizeAddress(address.String()); err == nil {
+		return normalized
+	}
+	return address
+}
+
+// ValidateAddress checks that an address has the format this platform's
+// adapter reports, so a station can be connected to without scanning first.
+func ValidateAddress(address string) error {
+	_, err := parsePlatformAddress(Address(address))
+	return err
+}
+
+// SameAddress reports whether two addresses name the same station, ignoring
+// case and separators. Unlike NormalizeAddress it accepts the address format
+// of any platform, for addresses reported by agents.
+func SameAddress(a, b string) bool {
+	digits := addressDigits(a)
+	return digits != "" && digits == addressDigits(b)
+}
+
+// addressDigits returns the hex digits of an address in lower case, without
+// whitespace, braces and the ':', '-' and '.' separators. It returns "" if
+// the address contains anything else.
+func addressDigits(address string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(address) {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'f':
+			b.WriteRune(r)
+		case r >= 'A' && r <= 'F':
+			b.WriteRune(r + 'a' - 'A')
+		case r == ':', r == '-', r == '.', r == '{', r == '}':
+		default:
+			return ""
+		}
+	}
+	return b.String()
+}
+
+// groupDigits joins the digits in groups of the given sizes with sep.
+func groupDigits(digits string, sep string, sizes ...int) string {
+	parts := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		parts = append(parts, digits[:size])
+		digits = digits[size:]
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,34 @@
+//go:build darwin
+
+package bluetooth
+
+import (
+	"fmt"
+	"strings"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// normalizePlatformAddress returns a peripheral UUID in the lower case,
+// dashed form of tinygo's UUID.String. Braces and missing dashes are fine.
+func normalizePlatformAddress(address string) (Address, error) {
+	digits := addressDigits(address)
+	if len(digits) != 32 {
+		return "", fmt.Errorf("'%s' is not a peripheral UUID, which macOS uses instead of MAC addresses", strings.TrimSpace(address))
+	}
+	return Address(groupDigits(digits, "-", 8, 4, 4, 4, 12)), nil
+}
+
+// parsePlatformAddress converts an address that wasn't seen in a scan. macOS
+// identifies peripherals by a per-host UUID instead of the MAC address.
+func parsePlatformAddress(address Address) (bluetooth.Address, error) {
+	normalized, err := normalizePlatformAddress(address.String())
+	if err != nil {
+		return bluetooth.Address{}, err
+	}
+	uuid, err := bluetooth.ParseUUID(normalized.String())
+	if err != nil {
+		return bluetooth.Address{}, fmt.Errorf("'%s' is not a peripheral UUID, which macOS uses instead of MAC addresses", address)
+	}
+	return bluetooth.Address{UUID: uuid}, nil
+}
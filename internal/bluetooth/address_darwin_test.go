@@ -0,0 +1,33 @@
+//go:build darwin
+
+package bluetooth
+
+import "testing"
+
+func TestNormalizeAddress(t *testing.T) {
+	const want = "6f1c2b0e-4d8a-4b4e-9f3a-2c7d5e8a1b90"
+	for _, test := range []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: "6f1c2b0e-4d8a-4b4e-9f3a-2c7d5e8a1b90"},
+		{in: "6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B90"},
+		{in: "6F1C2B0E4D8A4B4E9F3A2C7D5E8A1B90"},
+		{in: "{6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B90}"},
+		{in: " 6f1c2b0e-4d8a-4b4e-9f3a-2c7d5e8a1b90 "},
+		{in: "AA:BB:CC:DD:EE:FF", wantErr: true}, // MAC addresses aren't reported on macOS
+		{in: "6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B9", wantErr: true},
+		{in: "", wantErr: true},
+	} {
+		got, err := NormalizeAddress(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeAddress(%q) = %q, want an error", test.in, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != want {
+			t.Errorf("NormalizeAddress(%q) = %q, %v, want %q", test.in, got, err, want)
+		}
+	}
+}
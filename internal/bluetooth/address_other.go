@@ -0,0 +1,33 @@
+//go:build !darwin
+
+package bluetooth
+
+import (
+	"fmt"
+	"strings"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// normalizePlatformAddress returns a MAC address in the upper case, colon
+// separated form of tinygo's MAC.String.
+func normalizePlatformAddress(address string) (Address, error) {
+	digits := addressDigits(address)
+	if len(digits) != 12 {
+		return "", fmt.Errorf("'%s' is not a MAC address like 00:11:22:33:44:55", strings.TrimSpace(address))
+	}
+	return Address(strings.ToUpper(groupDigits(digits, ":", 2, 2, 2, 2, 2, 2))), nil
+}
+
+// parsePlatformAddress converts an address that wasn't seen in a scan.
+func parsePlatformAddress(address Address) (bluetooth.Address, error) {
+	normalized, err := normalizePlatformAddress(address.String())
+	if err != nil {
+		return bluetooth.Address{}, err
+	}
+	mac, err := bluetooth.ParseMAC(normalized.String())
+	if err != nil {
+		return bluetooth.Address{}, fmt.Errorf("'%s' is not a MAC address like 00:11:22:33:44:55", address)
+	}
+	return bluetooth.Address{MACAddress: bluetooth.MACAddress{MAC: mac}}, nil
+}
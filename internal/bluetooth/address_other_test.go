@@ -0,0 +1,38 @@
+//go:build !darwin
+
+package bluetooth
+
+import "testing"
+
+func TestNormalizeAddress(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "AA:BB:CC:DD:EE:FF", want: "AA:BB:CC:DD:EE:FF"},
+		{in: "aa:bb:cc:dd:ee:ff", want: "AA:BB:CC:DD:EE:FF"},
+		{in: "Aa:bB:cC:Dd:eE:Ff", want: "AA:BB:CC:DD:EE:FF"},
+		{in: "aabbccddeeff", want: "AA:BB:CC:DD:EE:FF"},
+		{in: "aa-bb-cc-dd-ee-ff", want: "AA:BB:CC:DD:EE:FF"},
+		{in: "  02:00:00:00:00:0a\n", want: "02:00:00:00:00:0A"},
+		{in: "AA:BB:CC:DD:EE", wantErr: true},
+		{in: "AA:BB:CC:DD:EE:FF:00", wantErr: true},
+		{in: "AA:BB:CC:DD:EE:GG", wantErr: true},
+		{in: "6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B90", wantErr: true}, // A macOS peripheral UUID
+		{in: "", wantErr: true},
+	} {
+		got, err := NormalizeAddress(test.in)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeAddress(%q) = %q, want an error", test.in, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != test.want {
+			t.Errorf("NormalizeAddress(%q) = %q, %v, want %q", test.in, got, err, test.want)
+		}
+		if err := ValidateAddress(test.in); err != nil {
+			t.Errorf("ValidateAddress(%q): %v", test.in, err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package bluetooth
+
+import "testing"
+
+func TestSameAddress(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		want bool
+	}{
+		{"AA:BB:CC:DD:EE:FF", "AA:BB:CC:DD:EE:FF", true},
+		{"AA:BB:CC:DD:EE:FF", "aa:bb:cc:dd:ee:ff", true},
+		{"AA:BB:CC:DD:EE:FF", "aabbccddeeff", true},
+		{"AA:BB:CC:DD:EE:FF", " aa-bb-cc-dd-ee-ff ", true},
+		{"AA:BB:CC:DD:EE:FF", "AABB.CCDD.EEFF", true},
+		{"AA:BB:CC:DD:EE:FF", "AA:BB:CC:DD:EE:00", false},
+		{"6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B90", "6f1c2b0e4d8a4b4e9f3a2c7d5e8a1b90", true},
+		{"6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B90", "{6f1c2b0e-4d8a-4b4e-9f3a-2c7d5e8a1b90}", true},
+		{"AA:BB:CC:DD:EE:FF", "AA:BB:CC:DD:EE:FG", false},
+		{"", "", false},
+	} {
+		if got := SameAddress(test.a, test.b); got != test.want {
+			t.Errorf("SameAddress(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
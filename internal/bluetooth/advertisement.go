@@ -0,0 +1,29 @@
+package bluetooth
+
+// valveCompanyID is the Bluetooth SIG company identifier of Valve Corporation,
+// under which V2 stations advertise their manufacturer data.
+const valveCompanyID = 0x055D
+
+// Layout of the Valve manufacturer data. It isn't documented; captures show a
+// payload of at least 2 bytes whose second byte follows the power
+// characteristic while the station runs.
+const (
+	valvePayloadMinLength = 2
+	valvePowerOffset      = 1
+)
+
+// advertisedPowerState returns the power state a station advertises in its
+// manufacturer data, or PowerStateUnknown without a Valve payload. Only the
+// settled sleep, standby and on values are trusted, anything else could be
+// another payload revision.
+func advertisedPowerState(manufacturerData map[uint16][]byte) int {
+	payload, ok := manufacturerData[valveCompanyID]
+	if !ok || len(payload) < valvePayloadMinLength {
+		return PowerStateUnknown
+	}
+	switch raw := payload[valvePowerOffset]; raw {
+	case RawPowerStateSleep, RawPowerStateStandby, RawPowerStateOn:
+		return powerStateForRaw(raw)
+	}
+	return PowerStateUnknown
+}
@@ -0,0 +1,27 @@
+package bluetooth
+
+import "testing"
+
+func TestAdvertisedPowerState(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data map[uint16][]byte
+		want int
+	}{
+		{"no manufacturer data", nil, PowerStateUnknown},
+		{"other vendor", map[uint16][]byte{0x004C: {0x00, RawPowerStateOn}}, PowerStateUnknown},
+		{"empty payload", map[uint16][]byte{valveCompanyID: {}}, PowerStateUnknown},
+		{"1-byte payload", map[uint16][]byte{valveCompanyID: {RawPowerStateOn}}, PowerStateUnknown},
+		{"sleep", map[uint16][]byte{valveCompanyID: {0x00, RawPowerStateSleep}}, PowerStateOff},
+		{"standby", map[uint16][]byte{valveCompanyID: {0x00, RawPowerStateStandby}}, PowerStateStandby},
+		{"on", map[uint16][]byte{valveCompanyID: {0x00, RawPowerStateOn}}, PowerStateOn},
+		{"longer payload", map[uint16][]byte{valveCompanyID: {0x02, RawPowerStateOn, 0x11, 0x22, 0x33}}, PowerStateOn},
+		{"spinning up", map[uint16][]byte{valveCompanyID: {0x00, 0x09}}, PowerStateUnknown},
+		{"unknown value", map[uint16][]byte{valveCompanyID: {0x00, 0xFF}}, PowerStateUnknown},
+		{"Valve next to another vendor", map[uint16][]byte{0x004C: {0x00, 0x00}, valveCompanyID: {0x00, RawPowerStateStandby}}, PowerStateStandby},
+	} {
+		if got := advertisedPowerState(test.data); got != test.want {
+			t.Errorf("%s: got state %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package bluetooth
+
+import (
+	"log"
+	"sync"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// Address identifies a station, as reported by the backend's scan.
+type Address string
+
+func (a Address) String() string {
+	return string(a)
+}
+
+// Advertisement is a scan result.
+type Advertisement struct {
+	Address   Address `json:"address"`
+	LocalName string  `json:"localName"`
+	RSSI      int16   `json:"rssi"`
+	// PowerService is set if the advertisement lists the power service of
+	// either station generation. Many stations only list it in the scan
+	// response, which not every stack requests.
+	PowerService bool `json:"powerService,omitempty"`
+	// ManufacturerData holds the manufacturer specific data, keyed by
+	// company ID. V2 stations hint their power state in it.
+	ManufacturerData map[uint16][]byte `json:"manufacturerData,omitempty"`
+}
+
+// Backend is the BLE stack the package talks to. The default is the system
+// adapter via tinygo; the recording and replay backends wrap or replace it.
+type Backend interface {
+	Enable() error
+	// Scan blocks, calling onResult for every advertisement, until StopScan is called.
+	Scan(onResult func(Advertisement)) error
+	StopScan() error
+	Connect(address Address, params ConnectionParams) (Device, error)
+}
+
+// Device is a connected station.
+type Device interface {
+	DiscoverServices(uuids []bluetooth.UUID) ([]Service, error)
+	Disconnect() error
+}
+
+// Service is a GATT service of a connected station.
+type Service interface {
+	DiscoverCharacteristics(uuids []bluetooth.UUID) ([]Characteristic, error)
+}
+
+// Characteristic is a GATT characteristic of a connected station.
+type Characteristic interface {
+	UUID() bluetooth.UUID
+	Read(data []byte) (int, error)
+	Write(data []byte) (int, error)
+	WriteWithoutResponse(data []byte) (int, error)
+	// EnableNotifications subscribes to value changes. callback runs on the
+	// stack's own goroutine and must not block.
+	EnableNotifications(callback func(data []byte)) error
+}
+
+var (
+	systemBackend          = newAdapterBackend(bluetooth.DefaultAdapter)
+	defaultBackend Backend = systemBackend
+	backend                = defaultBackend
+)
+
+// DefaultBackend returns the backend for the system adapter.
+func DefaultBackend() Backend {
+	return defaultBackend
+}
+
+// ActiveBackend returns the backend in use, so it can be wrapped.
+func ActiveBackend() Backend {
+	return backend
+}
+
+// SetBackend replaces the BLE backend. It must be called before Initialize.
+func SetBackend(b Backend) {
+	backend = b
+}
+
+// adapterBackend talks to a real adapter through tinygo.
+type adapterBackend struct {
+	mu        sync.Mutex
+	adapter   *bluetooth.Adapter
+	adapterID string                        // As passed to useAdapter, empty for the system default
+	addresses map[Address]bluetooth.Address // Platform addresses of the stations seen while scanning
+	stopWatch func()                        // Ends the disconnect watch of the enabled adapter
+}
+
+func newAdapterBackend(adapter *bluetooth.Adapter) *adapterBackend {
+	return &adapterBackend{adapter: adapter, addresses: make(map[Address]bluetooth.Address)}
+}
+
+// useAdapter switches to the adapter with the given ID. It must be enabled
+// again before use.
+func (b *adapterBackend) useAdapter(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if id == b.adapterID {
+		return nil
+	}
+	adapter, err := newPlatformAdapter(id)
+	if err != nil {
+		return err
+	}
+	b.adapter = adapter
+	b.adapterID = id
+	// Platform addresses are only valid for the adapter that saw them
+	b.addresses = make(map[Address]bluetooth.Address)
+	return nil
+}
+
+func (b *adapterBackend) current() (*bluetooth.Adapter, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.adapter, b.adapterID
+}
+
+// Enable enables the adapter and starts watching for stations that end the
+// connection themselves, see linkDown.
+func (b *adapterBackend) Enable() error {
+	adapter, id := b.current()
+	adapter.SetConnectHandler(func(device bluetooth.Device, connected bool) {
+		if !connected {
+			linkDown(Address(device.Address.String()))
+		}
+	})
+	if err := adapter.Enable(); err != nil {
+		return err
+	}
+
+	stop, err := watchPlatformDisconnects(id)
+	if err != nil {
+		log.Printf("Bluetooth: Can't watch for disconnects, noticing them on the next operation instead: %v", err)
+		stop = func() {}
+	}
+	b.mu.Lock()
+	previous := b.stopWatch
+	b.stopWatch = stop
+	b.mu.Unlock()
+	if previous != nil {
+		previous()
+	}
+	return nil
+}
+
+func (b *adapterBackend) Scan(onResult func(Advertisement)) error {
+	adapter, _ := b.current()
+	return adapter.Scan(func(_ *bluetooth.Adapter, result bluetooth.ScanResult) {
+		address := Address(result.Address.String())
+		b.mu.Lock()
+		b.addresses[address] = result.Address
+		b.mu.Unlock()
+		var manufacturerData map[uint16][]byte
+		for _, element := range result.ManufacturerData() {
+			if manufacturerData == nil {
+				manufacturerData = make(map[uint16][]byte)
+			}
+			manufacturerData[element.CompanyID] = append([]byte(nil), element.Data...)
+		}
+		onResult(Advertisement{
+			Address:          address,
+			LocalName:        result.LocalName(),
+			RSSI:             result.RSSI,
+			PowerService:     result.HasServiceUUID(powerControlServiceUUID) || result.HasServiceUUID(v1ServiceUUID),
+			ManufacturerData: manufacturerData,
+		})
+	})
+}
+
+func (b *adapterBackend) StopScan() error {
+	adapter, _ := b.current()
+	return adapter.StopScan()
+}
+
+func (b *adapterBackend) Connect(address Address, params ConnectionParams) (Device, error) {
+	if normalized, err := NormalizeAddress(address.String()); err == nil {
+		address = normalized
+	}
+	b.mu.Lock()
+	platformAddress, ok := b.addresses[address]
+	b.mu.Unlock()
+	if !ok {
+		// Imported stations can be reached before a scan saw them
+		var err error
+		if platformAddress, err = parsePlatformAddress(address); err != nil {
+			return nil, err
+		}
+	}
+	adapter, _ := b.current()
+	device, err := adapter.Connect(platformAddress, params.tinygoParams())
+	if err != nil {
+		return nil, err
+	}
+	return adapterDevice{device: &device}, nil
+}
+
+type adapterDevice struct {
+	device *bluetooth.Device
+}
+
+func (d adapterDevice) DiscoverServices(uuids []bluetooth.UUID) ([]Service, error) {
+	services, err := d.device.DiscoverServices(uuids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Service, len(services))
+	for i := range services {
+		result[i] = adapterService{service: services[i]}
+	}
+	return result, nil
+}
+
+func (d adapterDevice) Disconnect() error {
+	return d.device.Disconnect()
+}
+
+type adapterService struct {
+	service bluetooth.DeviceService
+}
+
+func (s adapterService) DiscoverCharacteristics(uuids []bluetooth.UUID) ([]Characteristic, error) {
+	chars, err := s.service.DiscoverCharacteristics(uuids)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Characteristic, len(chars))
+	for i := range chars {
+		result[i] = &adapterCharacteristic{char: chars[i]}
+	}
+	return result, nil
+}
+
+type adapterCharacteristic struct {
+	char bluetooth.DeviceCharacteristic
+}
+
+func (c *adapterCharacteristic) UUID() bluetooth.UUID {
+	return c.char.UUID()
+}
+
+func (c *adapterCharacteristic) Read(data []byte) (int, error) {
+	return c.char.Read(data)
+}
+
+func (c *adapterCharacteristic) Write(data []byte) (int, error) {
+	return c.char.Write(data)
+}
+
+func (c *adapterCharacteristic) WriteWithoutResponse(data []byte) (int, error) {
+	return c.char.WriteWithoutResponse(data)
+}
+
+func (c *adapterCharacteristic) EnableNotifications(callback func(data []byte)) error {
+	return c.char.EnableNotifications(callback)
+}
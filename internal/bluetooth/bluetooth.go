@@ -1,48 +1,255 @@
 package bluetooth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"lhcontrol/internal/clock"
+
 	"tinygo.org/x/bluetooth"
 )
 
 var (
-	adapter = bluetooth.DefaultAdapter
+	clk = clock.Real
 
 	// UUIDs
 	powerControlServiceUUIDString        = "00001523-1212-efde-1523-785feabcd124"
 	powerControlCharacteristicUUIDString = "00001525-1212-efde-1523-785feabcd124"
 	powerControlServiceUUID              bluetooth.UUID
 	powerControlCharacteristicUUID       bluetooth.UUID
+	// Makes the status LED blink, in the power control service
+	identifyCharacteristicUUIDString = "00008421-1212-efde-1523-785feabcd124"
+	identifyCharacteristicUUID       bluetooth.UUID
+	// Holds the configured channel (mode), in the power control service
+	channelCharacteristicUUIDString = "00001524-1212-efde-1523-785feabcd124"
+	channelCharacteristicUUID       bluetooth.UUID
 
 	// Track connected stations for cleanup
 	connectedStations      []*BaseStation
 	connectedStationsMutex sync.Mutex
 )
 
+// adapterActivity tracks scans and connection attempts in flight.
+type adapterActivity struct {
+	mu    sync.Mutex
+	count int
+	idle  chan struct{} // Closed while count is zero
+}
+
+var activity = newAdapterActivity()
+
+func newAdapterActivity() *adapterActivity {
+	idle := make(chan struct{})
+	close(idle)
+	return &adapterActivity{idle: idle}
+}
+
+func (a *adapterActivity) begin() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.count == 0 {
+		a.idle = make(chan struct{})
+	}
+	a.count++
+}
+
+func (a *adapterActivity) end() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.count--
+	if a.count == 0 {
+		close(a.idle)
+	}
+}
+
+// AdapterIdle returns a channel that is closed once no scan or connection
+// attempt is in progress.
+func AdapterIdle() <-chan struct{} {
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	return activity.idle
+}
+
+// disconnectWait bounds how long a disconnect waits for a running operation on the station.
+const disconnectWait = 5 * time.Second
+
+// ChannelUnknown is the channel of a station that wasn't read yet, or that
+// has no channel characteristic.
+const ChannelUnknown = -1
+
 // PowerState constants
 const (
 	PowerStateUnknown = -1
 	PowerStateOff     = 0
 	PowerStateOn      = 1
+	PowerStateStandby = 2 // Rotor spun down, radio awake; wakes faster than from off
+	PowerStateBooting = 3 // Rotor spinning up after power on, not tracking-ready yet
+)
+
+// IsPoweredOn reports whether a power state means the station is running or
+// spinning up to it.
+func IsPoweredOn(state int) bool {
+	return state == PowerStateOn || state == PowerStateBooting
+}
+
+// Raw values of the V2 power characteristic
+const (
+	RawPowerStateSleep   = 0x00
+	RawPowerStateStandby = 0x02
+	RawPowerStateOn      = 0x0B // Rotor spun up and tracking-ready
+	rawPowerStateUnknown = -1
 )
 
+// powerStateForRaw maps a raw V2 power byte to a power state. The other
+// values, like 0x01 and 0x09, are reported while the rotor spins up.
+func powerStateForRaw(raw byte) int {
+	switch raw {
+	case RawPowerStateSleep:
+		return PowerStateOff
+	case RawPowerStateStandby:
+		return PowerStateStandby
+	case RawPowerStateOn:
+		return PowerStateOn
+	}
+	return PowerStateBooting
+}
+
+// powerReadBufferSize is the largest value a read returns with the default ATT MTU.
+const powerReadBufferSize = 20
+
 // BaseStation represents a discovered SteamVR Base Station.
 type BaseStation struct {
 	Name       string
-	Address    bluetooth.Address
+	Address    Address
+	RSSI       int16     // Strongest signal of the advertisements seen during the last scan that saw the station
+	FirstSeen  time.Time // When a scan first saw the station, kept across scans by the manager
+	LastSeen   time.Time // When the last advertisement was received
 	PowerState int
+	Protocol   Protocol // Derived from the name, see ProtocolForName
+	// Last raw power byte read from the station, -1 if unknown
+	rawPowerState int
+	// Last power read or notification as received, failed ones included
+	lastRawPower RawPowerReading
+	// State of the last power command, reported for V1 stations, which
+	// can't be read
+	commandedState int
+	// Configured channel, ChannelUnknown until read. Kept across reconnects.
+	channel int
+	// Revision strings, see ReadDeviceInfo
+	deviceInfo     DeviceInfo
+	deviceInfoRead bool
+	// Whether a power read with a length other than 1 byte has been logged
+	oddReadLogged bool
+	// Set once a power command sent without response didn't go out but an
+	// acknowledged write did, so later commands use Write right away
+	writeWithResponse bool
 	// Fields for storing handles and state
-	device         *bluetooth.Device // Correct type
-	characteristic *bluetooth.DeviceCharacteristic
-	isConnected    bool
-	// Add Mutex for thread-safe access
+	device                Device
+	characteristic        Characteristic
+	channelCharacteristic Characteristic // nil if the station has none
+	isConnected           bool
+	// Whether the station pushes power state changes, see enableNotificationsInternal
+	notifying bool
+	// mutex guards the fields and is never held across BLE calls, so readers
+	// like GetPowerState stay responsive while a command is in flight
 	mutex           sync.RWMutex
 	LastStateUpdate time.Time // Track when state was last read
+	// Connection attempts, failures and the parameters of the last attempt
+	connectAttempts int
+	connectFailures int
+	connectParams   ConnectionParams
+	// Failures of connects, power writes and power reads, see HealthCounters
+	health health
+	// op serializes BLE operations on the station, see lockOp
+	op chan struct{}
+}
+
+// ID returns the station's stable identity, see StationID.
+func (bs *BaseStation) ID() string {
+	return StationID(bs.Name, bs.Address)
+}
+
+// StationID returns an identity for a station that survives address changes.
+// Base station names embed the serial (LHB-1A2B3C4D, or HTC BS 1A2B3C for
+// V1 stations), while the address on macOS is a per-host UUID that can
+// change after a Bluetooth reset. Names without a serial fall back to the
+// address.
+func StationID(name string, address Address) string {
+	prefix, idPrefix := v2NamePrefix, v2NamePrefix
+	if ProtocolForName(name) == ProtocolV1 {
+		prefix, idPrefix = v1NamePrefix, "HTC-BS-"
+	}
+	serial, ok := strings.CutPrefix(name, prefix)
+	if !ok || serial == "" {
+		return "addr:" + canonicalAddress(address).String()
+	}
+	for _, r := range serial {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return "addr:" + canonicalAddress(address).String()
+		}
+	}
+	return idPrefix + strings.ToUpper(serial)
+}
+
+// NewStation returns a station with an unknown power state that no scan has
+// reported yet, e.g. one seeded from an import.
+func NewStation(name string, address Address) *BaseStation {
+	return &BaseStation{
+		Name:           name,
+		Address:        address,
+		PowerState:     PowerStateUnknown,
+		Protocol:       ProtocolForName(name),
+		rawPowerState:  rawPowerStateUnknown,
+		commandedState: PowerStateUnknown,
+		channel:        ChannelUnknown,
+	}
+}
+
+// ConnectionDiagnostics summarizes a station's connection attempts since start.
+type ConnectionDiagnostics struct {
+	Connected bool             `json:"connected"`
+	Attempts  int              `json:"attempts"`
+	Failures  int              `json:"failures"`
+	Params    ConnectionParams `json:"params"` // Effective parameters of the last attempt
+}
+
+// ConnectionDiagnostics returns the station's connection counters.
+func (bs *BaseStation) ConnectionDiagnostics() ConnectionDiagnostics {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return ConnectionDiagnostics{
+		Connected: bs.isConnected,
+		Attempts:  bs.connectAttempts,
+		Failures:  bs.connectFailures,
+		Params:    bs.connectParams,
+	}
+}
+
+// lockOp waits until no other BLE operation runs on the station, or until ctx is done.
+func (bs *BaseStation) lockOp(ctx context.Context) error {
+	bs.mutex.Lock()
+	if bs.op == nil {
+		bs.op = make(chan struct{}, 1)
+	}
+	op := bs.op
+	bs.mutex.Unlock()
+
+	select {
+	case op <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return stationError(bs.Name, ErrBusy, fmt.Errorf("station %s is busy: %w", bs.Name, context.Cause(ctx)))
+	}
+}
+
+func (bs *BaseStation) unlockOp() {
+	<-bs.op
 }
 
 // IsConnected returns the current connection status safely.
@@ -56,7 +263,74 @@ func (bs *BaseStation) IsConnected() bool {
 // Assumes caller holds the write lock (bs.mutex.Lock()).
 func (bs *BaseStation) setPowerStateInternal(state int) {
 	bs.PowerState = state
-	bs.LastStateUpdate = time.Now()
+	bs.LastStateUpdate = clk.Now()
+}
+
+// IsReady reports whether the station's last raw power state means it is
+// fully running. V1 stations have no standby, so on means ready.
+func (bs *BaseStation) IsReady() bool {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	if bs.Protocol == ProtocolV1 {
+		return bs.PowerState == PowerStateOn
+	}
+	return bs.rawPowerState == RawPowerStateOn
+}
+
+// Notifying reports whether the station pushes its power state, so a status
+// check only needs to read it once the last update is older than
+// NotificationStaleAfter.
+func (bs *BaseStation) Notifying() bool {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return bs.notifying && bs.isConnected
+}
+
+// LastUpdate returns when the power state was last read, zero if never.
+func (bs *BaseStation) LastUpdate() time.Time {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return bs.LastStateUpdate
+}
+
+// Channel returns the station's configured channel, ChannelUnknown if it
+// wasn't read.
+func (bs *BaseStation) Channel() int {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return bs.channel
+}
+
+// RawPowerReading is the last value a station reported on its power
+// characteristic, for diagnosing stations whose state looks wrong.
+type RawPowerReading struct {
+	Value  int       `json:"value"`           // First byte, -1 if the read failed or was empty
+	Bytes  string    `json:"bytes,omitempty"` // Everything received, in hex
+	At     time.Time `json:"at"`              // Zero if nothing was read yet
+	Source string    `json:"source"`          // "read" or "notification"
+	Error  string    `json:"error,omitempty"` // Why the read failed
+}
+
+// LastRawPower returns the last power read or notification of the station.
+// V1 stations can't be read and never have one.
+func (bs *BaseStation) LastRawPower() RawPowerReading {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return bs.lastRawPower
+}
+
+// recordRawPowerInternal stores a power read or notification. An error marks
+// a failed read.
+// Assumes caller holds the write lock (bs.mutex.Lock()).
+func (bs *BaseStation) recordRawPowerInternal(data []byte, source string, err error) {
+	reading := RawPowerReading{Value: rawPowerStateUnknown, Bytes: fmt.Sprintf("%X", data), At: clk.Now(), Source: source}
+	if len(data) > 0 {
+		reading.Value = int(data[0])
+	}
+	if err != nil {
+		reading.Error = err.Error()
+	}
+	bs.lastRawPower = reading
 }
 
 // GetPowerState reads the power state safely.
@@ -66,14 +340,19 @@ func (bs *BaseStation) GetPowerState() int {
 	return bs.PowerState
 }
 
+// SetClock replaces the clock used for sleeps, timeouts and timestamps. Intended for tests.
+func SetClock(c clock.Clock) {
+	clk = c
+}
+
 // Initialize sets up the Bluetooth adapter and parses UUIDs.
 func Initialize() error {
 	// Re-initialize the tracking slice
 	connectedStations = make([]*BaseStation, 0)
 
-	err := adapter.Enable()
+	err := backend.Enable()
 	if err != nil {
-		return fmt.Errorf("could not enable Bluetooth adapter: %w", err)
+		return stationError("", adapterErrorKind(err, ErrAdapter), fmt.Errorf("could not enable Bluetooth adapter: %w", err))
 	}
 
 	var parseErr error
@@ -85,51 +364,136 @@ func Initialize() error {
 	if parseErr != nil {
 		return fmt.Errorf("could not parse power control characteristic UUID: %w", parseErr)
 	}
+	identifyCharacteristicUUID, parseErr = bluetooth.ParseUUID(identifyCharacteristicUUIDString)
+	if parseErr != nil {
+		return fmt.Errorf("could not parse identify characteristic UUID: %w", parseErr)
+	}
+	channelCharacteristicUUID, parseErr = bluetooth.ParseUUID(channelCharacteristicUUIDString)
+	if parseErr != nil {
+		return fmt.Errorf("could not parse channel characteristic UUID: %w", parseErr)
+	}
 	return nil
 }
 
+// scanThrottleInterval is the minimum time between two processed advertisements
+// of the same station during a scan. The first sighting is always processed.
+const scanThrottleInterval = 300 * time.Millisecond
+
+// scanEntry is a station seen during a scan.
+type scanEntry struct {
+	station     *BaseStation
+	processedAt time.Time
+}
+
+// scanFilterInUse is the filter of the running scan, for the tracer.
+var scanFilterInUse atomic.Pointer[ScanFilter]
+
+// activeScanFilter returns the filter of the running scan, or the default.
+func activeScanFilter() ScanFilter {
+	if filter := scanFilterInUse.Load(); filter != nil {
+		return *filter
+	}
+	return DefaultScanFilter()
+}
+
+// strongerRSSI returns the stronger of two signal strengths. Zero means the
+// stack didn't report one.
+func strongerRSSI(a, b int16) int16 {
+	if a == 0 || (b != 0 && b > a) {
+		return b
+	}
+	return a
+}
+
 // ScanForDuration performs a blocking BLE scan for the specified duration
-// and returns a list of discovered base stations.
-// Uses time.AfterFunc to stop the scan.
-func ScanForDuration(duration time.Duration) ([]BaseStation, error) {
+// and returns a list of the discovered base stations filter accepts.
+// Uses an AfterFunc timer to stop the scan. If ctx is done first the scan
+// is stopped early and ctx's error returned without results.
+func ScanForDuration(ctx context.Context, duration time.Duration, filter ScanFilter) ([]*BaseStation, error) {
 	// log.Printf("[BT] ScanForDuration: Starting scan for %v...", duration)
-	localStations := make(map[string]BaseStation)
+	localStations := make(map[string]*scanEntry)
 	var localMutex sync.Mutex
 	var scanErr error
+	var callbacks atomic.Int64
+	var processed, throttled int // Guarded by localMutex
+
+	scanCallback := func(result Advertisement) {
+		callbacks.Add(1)
 
-	scanCallback := func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
-		if result.LocalName() == "" || !strings.HasPrefix(result.LocalName(), "LHB-") {
+		if ok, _ := filter.accept(result); !ok {
 			return
 		}
+		result.Address = canonicalAddress(result.Address)
 		addressString := result.Address.String()
-		if addressString == "" || addressString == "00:00:00:00:00:00" {
+		now := clk.Now()
+		localMutex.Lock()
+		defer localMutex.Unlock()
+		entry, found := localStations[addressString]
+		if found && now.Sub(entry.processedAt) < scanThrottleInterval {
+			// Busy environments repeat advertisements hundreds of times a
+			// second, only keep the strongest signal and the time
+			entry.station.RSSI = strongerRSSI(entry.station.RSSI, result.RSSI)
+			entry.station.LastSeen = now
+			throttled++
 			return
 		}
-		localMutex.Lock()
-		if _, found := localStations[addressString]; !found {
-			// log.Printf("[BT] Scan: Discovered %s (%s)", result.LocalName(), result.Address.String())
+		rssi, firstSeen := result.RSSI, now
+		if found {
+			rssi, firstSeen = strongerRSSI(entry.station.RSSI, result.RSSI), entry.station.FirstSeen
+		} else {
+			entry = &scanEntry{}
+			localStations[addressString] = entry
 		}
-		localStations[addressString] = BaseStation{
-			Name:       result.LocalName(),
-			Address:    result.Address,
-			PowerState: PowerStateUnknown,
+		processed++
+		entry.processedAt = now
+		// An approximate state until a connection reads the real one
+		powerState := advertisedPowerState(result.ManufacturerData)
+		if powerState == PowerStateUnknown && found {
+			powerState = entry.station.PowerState
+		}
+		entry.station = &BaseStation{
+			Name:           result.LocalName,
+			Address:        result.Address,
+			RSSI:           rssi,
+			FirstSeen:      firstSeen,
+			LastSeen:       now,
+			PowerState:     powerState,
+			Protocol:       ProtocolForName(result.LocalName),
+			rawPowerState:  rawPowerStateUnknown,
+			commandedState: PowerStateUnknown,
+			channel:        ChannelUnknown,
 		}
-		localMutex.Unlock()
 	}
 
-	// Schedule StopScan using time.AfterFunc
-	stopTimer := time.AfterFunc(duration, func() {
+	// Schedule StopScan using an AfterFunc timer
+	stopTimer := clk.AfterFunc(duration, func() {
 		log.Printf("[BT] ScanForDuration (AfterFunc): Duration %v elapsed. Calling StopScan...", duration)
-		err := adapter.StopScan()
+		err := backend.StopScan()
 		if err != nil {
 			log.Printf("[BT] ScanForDuration (AfterFunc): adapter.StopScan() error: %v", err)
 		}
 	})
 
+	stopOnCancel := context.AfterFunc(ctx, func() {
+		log.Println("[BT] ScanForDuration: Canceled. Calling StopScan...")
+		if err := backend.StopScan(); err != nil {
+			log.Printf("[BT] ScanForDuration: adapter.StopScan() error: %v", err)
+		}
+	})
+
 	// Start the blocking scan directly
 	log.Println("[BT] ScanForDuration (AfterFunc): Calling adapter.Scan()...")
-	scanErr = adapter.Scan(scanCallback) // This blocks until StopScan is called (by timer) or an error occurs
+	activity.begin()
+	scanFilterInUse.Store(&filter)
+	scanErr = backend.Scan(scanCallback) // This blocks until StopScan is called (by timer) or an error occurs
 	stopTimer.Stop()                     // Prevent StopScan if Scan returned early (e.g., error)
+	stopOnCancel()
+	activity.end()
+
+	if err := ctx.Err(); err != nil {
+		log.Printf("[BT] ScanForDuration: Scan canceled: %v", err)
+		return nil, err
+	}
 
 	if scanErr != nil {
 		log.Printf("[BT] ScanForDuration (AfterFunc): adapter.Scan() finished with error: %v", scanErr)
@@ -139,99 +503,209 @@ func ScanForDuration(duration time.Duration) ([]BaseStation, error) {
 
 	// Collect results
 	localMutex.Lock()
-	results := make([]BaseStation, 0, len(localStations))
-	for _, station := range localStations {
-		results = append(results, station)
+	results := make([]*BaseStation, 0, len(localStations))
+	for _, entry := range localStations {
+		results = append(results, entry.station)
 	}
+	log.Printf("[BT] ScanForDuration: %d advertisement callback(s), %d station update(s) processed, %d throttled.", callbacks.Load(), processed, throttled)
 	localMutex.Unlock()
 
 	log.Printf("[BT] ScanForDuration (AfterFunc): Finished. Found %d stations.", len(results))
 
 	if len(results) == 0 && scanErr != nil {
-		return nil, fmt.Errorf("scan failed with no results: %w", scanErr)
+		return nil, stationError("", adapterErrorKind(scanErr, ErrScan), fmt.Errorf("scan failed with no results: %w", scanErr))
 	}
 	return results, nil
 }
 
 // readPowerStateInternal performs the actual read and update.
-// Assumes caller holds the write lock (station.mutex.Lock()).
-func readPowerStateInternal(station *BaseStation) error {
-	if station.characteristic == nil {
-		return fmt.Errorf("power characteristic is nil for %s", station.Name)
+// Assumes caller holds the operation lock (station.lockOp).
+func readPowerStateInternal(ctx context.Context, station *BaseStation) error {
+	station.mutex.RLock()
+	characteristic := station.characteristic
+	name := station.Name
+	station.mutex.RUnlock()
+
+	if characteristic == nil {
+		return stationError(name, ErrCharacteristicMissing, fmt.Errorf("power characteristic is nil for %s", name))
 	}
 
-	log.Printf("Bluetooth: Reading power state for %s (%s)", station.Name, station.Address)
-	buf := make([]byte, 1)
-	n, err := station.characteristic.Read(buf)
+	if station.Protocol == ProtocolV1 {
+		return readCommandedStateInternal(station)
+	}
+
+	log.Printf("Bluetooth: Reading power state for %s (%s)", name, station.Address)
+	// Some firmware pads the value and some adapters return the whole buffer,
+	// so read up to a full default-MTU payload and use the first byte
+	buf := make([]byte, powerReadBufferSize)
+	var n int
+	err := callWithTimeout(ctx, name, "power read", func() error {
+		var readErr error
+		n, readErr = characteristic.Read(buf)
+		return readErr
+	}, nil)
 	if err != nil {
-		station.setPowerStateInternal(PowerStateUnknown) // Use helper
-		return fmt.Errorf("failed to read power characteristic for %s: %w", station.Name, err)
+		station.mutex.Lock()
+		station.recordRawPowerInternal(nil, "read", err)
+		station.recordOutcomeInternal(opRead, err)
+		station.mutex.Unlock()
+	}
+	if errors.Is(err, ErrTimeout) {
+		dropLinkInternal(station, err)
+		return err
 	}
-	if n != 1 {
+	if err != nil {
+		// A read on a cached characteristic fails when the link is gone
+		dropLinkInternal(station, err)
+		return stationError(name, ErrRead, fmt.Errorf("failed to read power characteristic for %s: %w", name, err))
+	}
+
+	station.mutex.Lock()
+	defer station.mutex.Unlock()
+	if n < 1 {
+		err := fmt.Errorf("empty power read for %s", name)
+		station.recordRawPowerInternal(nil, "read", err)
+		station.recordOutcomeInternal(opRead, err)
 		station.setPowerStateInternal(PowerStateUnknown) // Use helper
-		return fmt.Errorf("unexpected bytes read (%d) for power on %s", n, station.Name)
+		station.rawPowerState = rawPowerStateUnknown
+		return stationError(name, ErrRead, err)
+	}
+	station.recordRawPowerInternal(buf[:n], "read", nil)
+	station.recordOutcomeInternal(opRead, nil)
+	if n != 1 && !station.oddReadLogged {
+		station.oddReadLogged = true
+		log.Printf("Bluetooth: Power read for %s returned %d bytes (% X), using the first byte. Not logged again for this station.", name, n, buf[:n])
 	}
 
-	newState := int(buf[0])
-	// Treat 0 as Off, anything else as On
-	if newState != PowerStateOff {
-		log.Printf("Bluetooth: Read non-zero state 0x%X for %s. Treating as ON.", buf[0], station.Name)
-		newState = PowerStateOn
+	station.rawPowerState = int(buf[0])
+	newState := powerStateForRaw(buf[0])
+	if newState == PowerStateBooting {
+		log.Printf("Bluetooth: Read transitional state 0x%X for %s, still spinning up.", buf[0], name)
 	}
-	// No need to explicitly check for 1 anymore, and remove warning for other values
 
 	if station.PowerState != newState { // Check before logging
-		log.Printf("Bluetooth: Power state for %s changed from %d to %d", station.Name, station.PowerState, newState)
+		log.Printf("Bluetooth: Power state for %s changed from %d to %d", name, station.PowerState, newState)
 	}
 	station.setPowerStateInternal(newState) // Use helper
 
 	return nil
 }
 
+// readCommandedStateInternal reports the state of the last power command for
+// a V1 station, whose command characteristic doesn't tell the power state.
+// It is unknown until lhcontrol sent a command.
+// Assumes caller holds the operation lock (station.lockOp).
+func readCommandedStateInternal(station *BaseStation) error {
+	station.mutex.Lock()
+	defer station.mutex.Unlock()
+	if station.PowerState != station.commandedState {
+		log.Printf("Bluetooth: Power state for %s (V1) is %d, from the last command", station.Name, station.commandedState)
+	}
+	station.setPowerStateInternal(station.commandedState)
+	return nil
+}
+
 // ReadPowerState attempts to read the current power state for an already connected station.
-func ReadPowerState(station *BaseStation) error {
+// With DisconnectAfterCommand it connects for the read and disconnects again.
+// It gives up if ctx is done before the station is free.
+func ReadPowerState(ctx context.Context, station *BaseStation) error {
 	if station == nil {
 		return fmt.Errorf("station is nil")
 	}
 
-	station.mutex.Lock() // Lock for the duration
-	defer station.mutex.Unlock()
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
 
-	if !station.isConnected || station.device == nil {
-		return fmt.Errorf("station %s is not connected", station.Name)
+	if DisconnectAfterCommand() {
+		defer releaseInternal(station)
+		if err := connectAndDiscoverInternal(ctx, station); err != nil {
+			return err
+		}
 	}
-	if station.characteristic == nil {
+
+	station.mutex.RLock()
+	connected := station.isConnected && station.device != nil
+	hasCharacteristic := station.characteristic != nil
+	station.mutex.RUnlock()
+
+	if !connected {
+		return stationError(station.Name, ErrNotConnected, fmt.Errorf("station %s is not connected", station.Name))
+	}
+	if !hasCharacteristic {
 		log.Printf("Bluetooth: Error - Power characteristic not found for connected station %s.", station.Name)
-		return fmt.Errorf("power characteristic not cached for %s", station.Name)
+		return stationError(station.Name, ErrCharacteristicMissing, fmt.Errorf("power characteristic not cached for %s", station.Name))
 	}
 
-	return readPowerStateInternal(station)
+	return readPowerStateInternal(ctx, station)
 }
 
 // connectAndDiscoverInternal handles connection and discovery.
-// Assumes caller holds the write lock (station.mutex.Lock()).
-func connectAndDiscoverInternal(station *BaseStation) error {
-	if station.isConnected && station.device != nil && station.characteristic != nil {
+// Connecting is abandoned after OperationTimeout or once ctx is done; discovery
+// only checks ctx between the BLE calls. At most MaxConnects stations connect
+// and discover at once, the others wait for a slot.
+// Assumes caller holds the operation lock (station.lockOp).
+func connectAndDiscoverInternal(ctx context.Context, station *BaseStation) error {
+	station.mutex.RLock()
+	connected := station.isConnected && station.device != nil
+	device := station.device
+	hasCharacteristic := station.characteristic != nil
+	station.mutex.RUnlock()
+
+	if connected && hasCharacteristic {
 		return nil // Already good
 	}
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
 
-	if !station.isConnected || station.device == nil {
+	// Only a few stations connect at once, see SetMaxConnects
+	if err := connectSlots.acquire(ctx, station.Name); err != nil {
+		return err
+	}
+	defer connectSlots.release()
+
+	if !connected {
 		log.Printf("Bluetooth: Internal connect attempt for %s...", station.Name)
-		device, err := adapter.Connect(station.Address, bluetooth.ConnectionParams{})
+		params := CurrentConnectionParams()
+		activity.begin()
+		var connectedDevice Device
+		err := callWithTimeout(ctx, station.Name, "connect", func() error {
+			var connectErr error
+			connectedDevice, connectErr = backend.Connect(station.Address, params)
+			return connectErr
+		}, func() {
+			_ = connectedDevice.Disconnect()
+		})
+		activity.end()
+
+		station.mutex.Lock()
+		station.connectAttempts++
+		station.connectParams = params
+		station.recordOutcomeInternal(opConnect, err)
 		if err != nil {
+			station.connectFailures++
 			station.isConnected = false
 			station.device = nil
 			station.characteristic = nil
+			station.channelCharacteristic = nil
+			station.notifying = false
 			station.setPowerStateInternal(PowerStateUnknown)
-			return fmt.Errorf("connection failed internal: %w", err)
+			station.rawPowerState = rawPowerStateUnknown
+			station.mutex.Unlock()
+			return stationError(station.Name, ErrConnect, fmt.Errorf("connection failed internal: %w", err))
 		}
-		station.device = &device // Assign pointer correctly
+		device = connectedDevice
+		station.device = device
 		station.isConnected = true
+		station.mutex.Unlock()
+
 		log.Printf("Bluetooth: Internal connect successful for %s.", station.Name)
 		connectedStationsMutex.Lock()
 		found := false
 		for _, cs := range connectedStations {
-			if cs.Address == station.Address {
+			if SameAddress(cs.Address.String(), station.Address.String()) {
 				found = true
 				break
 			}
@@ -242,23 +716,29 @@ func connectAndDiscoverInternal(station *BaseStation) error {
 		connectedStationsMutex.Unlock()
 	}
 
-	if station.characteristic == nil {
+	if !hasCharacteristic {
 		log.Printf("Bluetooth: Internal discovery attempt for %s...", station.Name)
 
-		var services []bluetooth.DeviceService
-		var chars []bluetooth.DeviceCharacteristic
+		var services []Service
+		var chars []Characteristic
 		var err error
+		serviceUUID, characteristicUUID := station.powerUUIDs()
 
 		const maxRetries = 3
 		for i := 0; i < maxRetries; i++ {
 			if i > 0 {
 				log.Printf("Bluetooth: Retrying discovery for %s (attempt %d/%d)...", station.Name, i+1, maxRetries)
-				time.Sleep(500 * time.Millisecond)
+				if err = clock.Sleep(ctx, clk, 500*time.Millisecond); err != nil {
+					break
+				}
 			}
 
-			services, err = station.device.DiscoverServices([]bluetooth.UUID{powerControlServiceUUID})
+			services, err = discoverServices(ctx, station.Name, device, serviceUUID)
 			if err != nil {
-				// Retry if discovery returns error
+				if ctx.Err() != nil {
+					break
+				}
+				// Retry if discovery returns error or times out
 				continue
 			}
 			if len(services) == 0 {
@@ -266,9 +746,12 @@ func connectAndDiscoverInternal(station *BaseStation) error {
 				continue
 			}
 
-			chars, err = services[0].DiscoverCharacteristics([]bluetooth.UUID{powerControlCharacteristicUUID})
+			chars, err = discoverCharacteristics(ctx, station.Name, services[0], characteristicUUID)
 			if err != nil {
-				// Retry if char discovery returns error
+				if ctx.Err() != nil {
+					break
+				}
+				// Retry if char discovery returns error or times out
 				continue
 			}
 			if len(chars) == 0 {
@@ -283,176 +766,259 @@ func connectAndDiscoverInternal(station *BaseStation) error {
 
 		if err != nil {
 			disconnectInternal(station)
-			return fmt.Errorf("discovery failed internal for %s after %d retries: %w", station.Name, maxRetries, err)
+			kind := ErrDiscovery
+			if errors.Is(err, ErrTimeout) && ctx.Err() == nil {
+				kind = ErrDiscoveryTimeout
+			}
+			return stationError(station.Name, kind, fmt.Errorf("discovery failed internal for %s after %d retries: %w", station.Name, maxRetries, err))
 		}
 
-		station.characteristic = &chars[0]
+		// Older firmware has no channel characteristic, which is fine. It is
+		// discovered on its own because some stacks fail a discovery when
+		// any of the requested characteristics is missing.
+		var channelCharacteristic Characteristic
+		if station.Protocol == ProtocolV2 {
+			channelChars, channelErr := discoverCharacteristics(ctx, station.Name, services[0], channelCharacteristicUUID)
+			if channelErr == nil && len(channelChars) > 0 {
+				channelCharacteristic = channelChars[0]
+			} else {
+				log.Printf("Bluetooth: No channel characteristic on %s: %v", station.Name, channelErr)
+			}
+		}
+
+		station.mutex.Lock()
+		station.characteristic = chars[0]
+		station.channelCharacteristic = channelCharacteristic
+		station.mutex.Unlock()
 		log.Printf("Bluetooth: Internal discovery successful for %s.", station.Name)
+
+		// A connection that is released after the operation misses the changes anyway
+		if station.Protocol == ProtocolV2 && !DisconnectAfterCommand() {
+			enableNotificationsInternal(station, chars[0])
+		}
 	}
 	return nil
 }
 
+// Connect connects to a station and discovers its power characteristic, if
+// that isn't done yet, without reading the state.
+func Connect(ctx context.Context, station *BaseStation) error {
+	if station == nil {
+		return fmt.Errorf("station is nil")
+	}
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
+	return connectAndDiscoverInternal(ctx, station)
+}
+
 // FetchInitialPowerState attempts to connect (if necessary) and read the initial power state.
-func FetchInitialPowerState(station *BaseStation) error {
+// It stops at the next step boundary once ctx is done.
+func FetchInitialPowerState(ctx context.Context, station *BaseStation) error {
 	if station == nil {
 		return fmt.Errorf("station is nil")
 	}
 
-	station.mutex.Lock() // Lock for the whole operation
-	defer station.mutex.Unlock()
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
+	defer releaseInternal(station)
 
-	err := connectAndDiscoverInternal(station)
+	err := connectAndDiscoverInternal(ctx, station)
 	if err != nil {
 		log.Printf("Bluetooth: Failed to connect/discover in FetchInitialPowerState for %s: %v", station.Name, err)
 		return err
 	}
 
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
 	log.Printf("Bluetooth: FetchInitialPowerState proceeding to read state for %s.", station.Name)
-	err = readPowerStateInternal(station)
+	err = readPowerStateInternal(ctx, station)
 	if err != nil {
 		log.Printf("Bluetooth: Failed to read state in FetchInitialPowerState for %s: %v", station.Name, err)
 		return err
 	}
 
-	log.Printf("Bluetooth: FetchInitialPowerState successful for %s. State: %d", station.Name, station.PowerState)
-	return nil
-}
-
-// PowerOn attempts to turn the base station on.
-func PowerOn(station *BaseStation) error {
-	if station == nil {
-		return fmt.Errorf("station is nil")
+	if err := readChannelInternal(station); err != nil {
+		log.Printf("Bluetooth: Failed to read channel in FetchInitialPowerState for %s: %v", station.Name, err)
+	}
+	// Serial and model number don't change, read them once on the first connection
+	if _, read := station.DeviceInfo(); !read && ctx.Err() == nil {
+		readDeviceInfoInternal(station)
 	}
-	station.mutex.Lock()
-	defer station.mutex.Unlock()
 
-	const maxRetries = 2
-	var err error
+	log.Printf("Bluetooth: FetchInitialPowerState successful for %s. State: %d", station.Name, station.GetPowerState())
+	return nil
+}
 
-	for i := 0; i < maxRetries; i++ {
-		if err = connectAndDiscoverInternal(station); err != nil {
-			// If connection fails, we can't proceed with this attempt.
-			// If it was a retry after a write failure, this will be the final error.
-			log.Printf("Bluetooth: connect/discover failed during PowerOn attempt %d/%d for %s: %v", i+1, maxRetries, station.Name, err)
-			if i == maxRetries-1 {
-				return fmt.Errorf("failed to connect/discover before PowerOn: %w", err)
-			}
-			// If we failed to connect, wait a bit and try again (force disconnect just in case state is weird)
-			disconnectInternal(station)
-			time.Sleep(500 * time.Millisecond)
-			continue
-		}
+// PowerOn attempts to turn the base station on. The command is abandoned once
+// ctx is done, and a connect, write or read that blocks for longer than
+// OperationTimeout fails with ErrTimeout.
+func PowerOn(ctx context.Context, station *BaseStation) error {
+	return setPower(ctx, station, PowerStateOn, "ON")
+}
 
-		log.Printf("Bluetooth: Sending Power ON command to %s using WriteWithoutResponse", station.Name)
-		var n int
-		n, err = station.characteristic.WriteWithoutResponse([]byte{0x01})
-		if err != nil && strings.Contains(err.Error(), "not supported") {
-			log.Printf("Bluetooth: WriteWithoutResponse not supported for %s (%v), attempting standard Write...", station.Name, err)
-			n, err = station.characteristic.Write([]byte{0x01})
-		}
+// PowerOff attempts to turn the base station off, like PowerOn.
+func PowerOff(ctx context.Context, station *BaseStation) error {
+	return setPower(ctx, station, PowerStateOff, "OFF")
+}
 
-		if err == nil {
-			if n != 1 {
-				// A successful write should return n=1 for one byte
-				log.Printf("Bluetooth: Warning - wrote %d bytes instead of 1 for Power ON on %s", n, station.Name)
-			}
-			// Success
-			break
-		}
+// Standby attempts to put the base station into standby, like PowerOn. Only
+// V2 stations have a standby mode.
+func Standby(ctx context.Context, station *BaseStation) error {
+	return setPower(ctx, station, PowerStateStandby, "STANDBY")
+}
 
-		log.Printf("Bluetooth: Write Power ON failed for %s: %v. Retrying...", station.Name, err)
-		disconnectInternal(station)
-		// The next iteration will try to reconnect
-		if i < maxRetries-1 {
-			time.Sleep(500 * time.Millisecond)
-		}
+// setPower writes a power command with retries, see SetRetryPolicy, and reads
+// the state back until the station reports it, see confirmPowerInternal. A
+// station that keeps reporting another state gets the command once more
+// before setPower fails with ErrStateMismatch. One whose state can't be read
+// is assumed to have followed the command.
+func setPower(ctx context.Context, station *BaseStation, state int, label string) error {
+	if station == nil {
+		return fmt.Errorf("station is nil")
 	}
-
+	payload, err := station.powerCommand(state)
 	if err != nil {
-		return fmt.Errorf("failed to write Power ON command after %d retries: %w", maxRetries, err)
+		return stationError(station.Name, ErrUnsupported, err)
 	}
-
-	time.Sleep(100 * time.Millisecond)
-	err = readPowerStateInternal(station)
-	if err != nil {
-		log.Printf("Bluetooth: Failed to read back state after PowerOn for %s: %v (state may be stale)", station.Name, err)
+	if err := station.lockOp(ctx); err != nil {
+		return err
 	}
-	return nil
-}
+	defer station.unlockOp()
+	defer releaseInternal(station)
 
-// PowerOff attempts to turn the base station off.
-func PowerOff(station *BaseStation) error {
-	if station == nil {
-		return fmt.Errorf("station is nil")
+	if err := writePowerInternal(ctx, station, payload, label); err != nil {
+		return err
 	}
 	station.mutex.Lock()
-	defer station.mutex.Unlock()
+	station.commandedState = state
+	station.mutex.Unlock()
+
+	confirmed, read := confirmPowerInternal(ctx, station, state)
+	if confirmed || !read || ctx.Err() != nil {
+		// The command went out, an unreadable or cancelled read-back only leaves the state stale
+		return nil
+	}
+	log.Printf("Bluetooth: %s still reports state %d after Power%s, sending the command again", station.Name, station.GetPowerState(), label)
+	if err := writePowerInternal(ctx, station, payload, label); err != nil {
+		return err
+	}
+	if confirmed, read := confirmPowerInternal(ctx, station, state); confirmed || !read || ctx.Err() != nil {
+		return nil
+	}
+	return stationError(station.Name, ErrStateMismatch, fmt.Errorf("%s reports power state %d after Power%s was sent twice", station.Name, station.GetPowerState(), label))
+}
 
-	const maxRetries = 2
+// writePowerInternal connects if needed and writes a power command payload,
+// retrying as the retry policy says.
+// Assumes caller holds the operation lock (station.lockOp).
+func writePowerInternal(ctx context.Context, station *BaseStation, payload []byte, label string) error {
 	var err error
+	policy := CurrentRetryPolicy()
+	maxRetries := policy.Attempts
 
 	for i := 0; i < maxRetries; i++ {
-		if err = connectAndDiscoverInternal(station); err != nil {
-			log.Printf("Bluetooth: connect/discover failed during PowerOff attempt %d/%d for %s: %v", i+1, maxRetries, station.Name, err)
-			if i == maxRetries-1 {
-				return fmt.Errorf("failed to connect/discover before PowerOff: %w", err)
+		attemptCtx, cancelAttempt := ctx, context.CancelFunc(func() {})
+		if policy.AttemptTimeout > 0 {
+			attemptCtx, cancelAttempt = clock.WithTimeout(ctx, clk, policy.AttemptTimeout)
+		}
+		// Only the attempt's own deadline passing is worth another try
+		attemptExpired := func() bool { return ctx.Err() == nil && attemptCtx.Err() != nil }
+
+		if err = connectAndDiscoverInternal(attemptCtx, station); err != nil {
+			expired := attemptExpired()
+			cancelAttempt()
+			// If connection fails, we can't proceed with this attempt.
+			// If it was a retry after a write failure, this will be the final error.
+			log.Printf("Bluetooth: connect/discover failed during Power%s attempt %d/%d for %s: %v", label, i+1, maxRetries, station.Name, err)
+			if i == maxRetries-1 || ctx.Err() != nil || (errors.Is(err, ErrTimeout) && !expired) {
+				return fmt.Errorf("failed to connect/discover before Power%s: %w", label, err)
 			}
+			// If we failed to connect, wait a bit and try again (force disconnect just in case state is weird)
 			disconnectInternal(station)
-			time.Sleep(500 * time.Millisecond)
+			if err = clock.Sleep(ctx, clk, policy.delay(i+1)); err != nil {
+				return fmt.Errorf("power %s command cancelled: %w", label, err)
+			}
 			continue
 		}
 
-		log.Printf("Bluetooth: Sending Power OFF command to %s using WriteWithoutResponse", station.Name)
-		var n int
-		n, err = station.characteristic.WriteWithoutResponse([]byte{0x00})
-		if err != nil && strings.Contains(err.Error(), "not supported") {
-			log.Printf("Bluetooth: WriteWithoutResponse not supported for %s (%v), attempting standard Write...", station.Name, err)
-			n, err = station.characteristic.Write([]byte{0x00})
-		}
+		station.mutex.RLock()
+		characteristic := station.characteristic
+		withResponse := station.writeWithResponse
+		station.mutex.RUnlock()
+
+		err = callWithTimeout(attemptCtx, station.Name, "power write", func() error {
+			_, writeErr := writePayload(station, characteristic, payload, label, withResponse)
+			return writeErr
+		}, nil)
+		station.recordOutcome(opWrite, err)
+		expired := attemptExpired()
+		cancelAttempt()
 
 		if err == nil {
-			if n != 1 {
-				log.Printf("Bluetooth: Warning - wrote %d bytes instead of 1 for Power OFF on %s", n, station.Name)
-			}
 			// Success
 			break
 		}
 
-		log.Printf("Bluetooth: Write Power OFF failed for %s: %v. Retrying...", station.Name, err)
 		disconnectInternal(station)
+		if errors.Is(err, ErrTimeout) && !expired {
+			// A station that stopped answering won't answer a retry either
+			dropLinkInternal(station, err)
+			return writeError(station.Name, i+1, fmt.Errorf("failed to write Power %s command: %w", label, err))
+		}
+		log.Printf("Bluetooth: Write Power %s failed for %s: %v. Retrying...", label, station.Name, err)
+		// The next iteration will try to reconnect
 		if i < maxRetries-1 {
-			time.Sleep(500 * time.Millisecond)
+			if sleepErr := clock.Sleep(ctx, clk, policy.delay(i+1)); sleepErr != nil {
+				return fmt.Errorf("power %s command cancelled: %w", label, sleepErr)
+			}
 		}
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to write Power OFF command after %d retries: %w", maxRetries, err)
-	}
-
-	time.Sleep(100 * time.Millisecond)
-	err = readPowerStateInternal(station)
-	if err != nil {
-		log.Printf("Bluetooth: Failed to read back state after PowerOff for %s: %v (state may be stale)", station.Name, err)
+		dropLinkInternal(station, err)
+		return writeError(station.Name, maxRetries, fmt.Errorf("failed to write Power %s command after %d retries: %w", label, maxRetries, err))
 	}
 	return nil
 }
 
-// disconnectInternal performs disconnection without locking (must be called within locked context).
+// disconnectInternal clears the connection state and then disconnects.
 // Also removes station from the global tracking list.
+// Assumes caller holds the operation lock (station.lockOp).
 func disconnectInternal(s *BaseStation) {
-	if s.device != nil {
-		log.Printf("Bluetooth: Disconnecting internal for %s", s.Name)
-		_ = s.device.Disconnect()
-	}
+	closeConnectionInternal(s, true)
+}
+
+// closeConnectionInternal disconnects like disconnectInternal, and resets the
+// power state to unknown if forgetState is set.
+// Assumes caller holds the operation lock (station.lockOp).
+func closeConnectionInternal(s *BaseStation, forgetState bool) {
+	s.mutex.Lock()
+	device := s.device
 	s.isConnected = false
 	s.device = nil
 	s.characteristic = nil
-	s.setPowerStateInternal(PowerStateUnknown)
+	s.channelCharacteristic = nil
+	s.notifying = false
+	if forgetState {
+		s.setPowerStateInternal(PowerStateUnknown)
+		s.rawPowerState = rawPowerStateUnknown
+	}
+	s.mutex.Unlock()
+
+	if device != nil {
+		log.Printf("Bluetooth: Disconnecting internal for %s", s.Name)
+		_ = device.Disconnect()
+	}
 
 	connectedStationsMutex.Lock()
 	newConnectedStations := make([]*BaseStation, 0, len(connectedStations))
 	for _, cs := range connectedStations {
-		if cs.Address != s.Address {
+		if !SameAddress(cs.Address.String(), s.Address.String()) {
 			newConnectedStations = append(newConnectedStations, cs)
 		}
 	}
@@ -460,16 +1026,44 @@ func disconnectInternal(s *BaseStation) {
 	connectedStationsMutex.Unlock()
 }
 
-// DisconnectStation disconnects from a specific base station.
+// DisconnectStation disconnects from a specific base station, waiting at
+// most disconnectWait for an operation in progress to finish.
 func DisconnectStation(station *BaseStation) {
 	if station == nil {
 		return
 	}
-	station.mutex.Lock() // Lock before calling internal disconnect
-	defer station.mutex.Unlock()
+	ctx, cancel := clock.WithTimeout(context.Background(), clk, disconnectWait)
+	defer cancel()
+	if err := station.lockOp(ctx); err != nil {
+		log.Printf("Bluetooth: Not disconnecting %s: %v", station.Name, err)
+		return
+	}
+	defer station.unlockOp()
 	disconnectInternal(station) // Use internal helper
 }
 
+// DisconnectStationWait disconnects a station like DisconnectStation, but
+// waits for the operation running on it for as long as ctx allows, and fails
+// with ErrBusy if ctx is done first.
+func DisconnectStationWait(ctx context.Context, station *BaseStation) error {
+	if station == nil {
+		return nil
+	}
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
+	disconnectInternal(station)
+	return nil
+}
+
+// ConnectedCount returns the number of stations with an open connection.
+func ConnectedCount() int {
+	connectedStationsMutex.Lock()
+	defer connectedStationsMutex.Unlock()
+	return len(connectedStations)
+}
+
 // DisconnectAllStations disconnects all tracked stations.
 func DisconnectAllStations() {
 	connectedStationsMutex.Lock()
@@ -0,0 +1,106 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Channels a V2 station can be set to
+const (
+	MinChannel = 1
+	MaxChannel = 16
+)
+
+// ErrInvalidChannel is returned by SetChannel for a channel out of range.
+var ErrInvalidChannel = errors.New("invalid channel")
+
+// readChannelInternal reads the configured channel of a connected station. It
+// does nothing for stations without a channel characteristic.
+// Assumes caller holds the operation lock (station.lockOp).
+func readChannelInternal(station *BaseStation) error {
+	station.mutex.RLock()
+	characteristic := station.channelCharacteristic
+	name := station.Name
+	station.mutex.RUnlock()
+
+	if characteristic == nil {
+		return nil
+	}
+
+	buf := make([]byte, powerReadBufferSize)
+	n, err := characteristic.Read(buf)
+	if err != nil {
+		return stationError(name, ErrRead, fmt.Errorf("failed to read channel characteristic for %s: %w", name, err))
+	}
+	if n < 1 {
+		return stationError(name, ErrRead, fmt.Errorf("empty channel read for %s", name))
+	}
+
+	station.mutex.Lock()
+	defer station.mutex.Unlock()
+	if station.channel != int(buf[0]) {
+		log.Printf("Bluetooth: Channel of %s is %d", name, buf[0])
+	}
+	station.channel = int(buf[0])
+	return nil
+}
+
+// ReadChannel reads the configured channel of an already connected station.
+// Stations without a channel characteristic keep ChannelUnknown.
+func ReadChannel(ctx context.Context, station *BaseStation) error {
+	if station == nil {
+		return fmt.Errorf("station is nil")
+	}
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
+
+	if !station.IsConnected() {
+		return stationError(station.Name, ErrNotConnected, fmt.Errorf("station %s is not connected", station.Name))
+	}
+	return readChannelInternal(station)
+}
+
+// SetChannel changes the configured channel of a V2 station, reads it back
+// to confirm and updates the cached value. It connects if necessary.
+func SetChannel(ctx context.Context, station *BaseStation, channel int) error {
+	if station == nil {
+		return fmt.Errorf("station is nil")
+	}
+	if station.Protocol == ProtocolV1 {
+		return stationError(station.Name, ErrUnsupported, fmt.Errorf("%s is a SteamVR 1.0 station, whose channel can't be set over Bluetooth", station.Name))
+	}
+	if channel < MinChannel || channel > MaxChannel {
+		return fmt.Errorf("%w: %d, must be %d to %d", ErrInvalidChannel, channel, MinChannel, MaxChannel)
+	}
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
+	defer releaseInternal(station)
+
+	if err := connectAndDiscoverInternal(ctx, station); err != nil {
+		return err
+	}
+	station.mutex.RLock()
+	characteristic := station.channelCharacteristic
+	station.mutex.RUnlock()
+	if characteristic == nil {
+		return stationError(station.Name, ErrUnsupported, fmt.Errorf("%s has no channel characteristic, its firmware may be too old", station.Name))
+	}
+
+	log.Printf("Bluetooth: Setting channel of %s to %d", station.Name, channel)
+	if _, err := characteristic.Write([]byte{byte(channel)}); err != nil {
+		return writeError(station.Name, 1, fmt.Errorf("failed to write channel for %s: %w", station.Name, err))
+	}
+	if err := readChannelInternal(station); err != nil {
+		return err
+	}
+	if actual := station.Channel(); actual != channel {
+		return writeError(station.Name, 1, fmt.Errorf("%s reports channel %d after setting %d", station.Name, actual, channel))
+	}
+	return nil
+}
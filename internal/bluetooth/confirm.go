@@ -0,0 +1,46 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"lhcontrol/internal/clock"
+)
+
+// A station may take a moment to report a power command it received, and
+// may ignore one sent without response, so the state is read a few times
+// before it counts as not followed.
+const (
+	confirmReads        = 5
+	confirmFirstDelay   = 100 * time.Millisecond
+	confirmReadInterval = 500 * time.Millisecond
+)
+
+// confirmPowerInternal reads the power state until the station reports
+// state, up to confirmReads times. Spinning up counts as on. read is false
+// if no read succeeded, so whether the command was followed is unknown.
+// Assumes caller holds the operation lock (station.lockOp).
+func confirmPowerInternal(ctx context.Context, station *BaseStation, state int) (confirmed, read bool) {
+	delay := confirmFirstDelay
+	for i := 0; i < confirmReads; i++ {
+		if clock.Sleep(ctx, clk, delay) != nil {
+			return false, read
+		}
+		delay = confirmReadInterval
+		if err := readPowerStateInternal(ctx, station); err != nil {
+			log.Printf("Bluetooth: Read-back %d/%d failed for %s: %v (state may be stale)", i+1, confirmReads, station.Name, err)
+			if errors.Is(err, ErrCharacteristicMissing) {
+				return false, read // The link was dropped, later reads fail too
+			}
+			continue
+		}
+		read = true
+		actual := station.GetPowerState()
+		if actual == state || (state == PowerStateOn && IsPoweredOn(actual)) {
+			return true, true
+		}
+	}
+	return false, read
+}
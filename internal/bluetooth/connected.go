@@ -0,0 +1,70 @@
+package bluetooth
+
+import (
+	"log"
+)
+
+// ConnectedLister is implemented by backends that can list the devices the
+// system is already connected to.
+type ConnectedLister interface {
+	// ConnectedDevices returns the connected devices as advertisements
+	// without an RSSI.
+	ConnectedDevices() ([]Advertisement, error)
+}
+
+// ListConnectedStations returns the stations the system is connected to that
+// pass filter, for example left connected by SteamVR or a previous run.
+// Connected stations stop advertising, so scans miss them. It returns nothing
+// if the backend can't list connected devices or the listing fails.
+func ListConnectedStations(filter ScanFilter) []*BaseStation {
+	devices, err := connectedDevices(backend)
+	if err != nil {
+		log.Printf("Bluetooth: Can't list connected devices: %v", err)
+		return nil
+	}
+	now := clk.Now()
+	stations := make([]*BaseStation, 0, len(devices))
+	for _, adv := range devices {
+		if ok, _ := filter.accept(adv); !ok {
+			continue
+		}
+		stations = append(stations, &BaseStation{
+			Name:           adv.LocalName,
+			Address:        canonicalAddress(adv.Address),
+			FirstSeen:      now,
+			PowerState:     PowerStateUnknown,
+			Protocol:       ProtocolForName(adv.LocalName),
+			rawPowerState:  rawPowerStateUnknown,
+			commandedState: PowerStateUnknown,
+			channel:        ChannelUnknown,
+		})
+	}
+	return stations
+}
+
+// ConnectedDevices lists the devices connected to the adapter, see
+// listPlatformConnected.
+func (b *adapterBackend) ConnectedDevices() ([]Advertisement, error) {
+	_, id := b.current()
+	return listPlatformConnected(id)
+}
+
+// connectedDevices lists the devices connected to b, nothing if it can't.
+func connectedDevices(b Backend) ([]Advertisement, error) {
+	if lister, ok := b.(ConnectedLister); ok {
+		return lister.ConnectedDevices()
+	}
+	return nil, nil
+}
+
+func (t *Tracer) ConnectedDevices() ([]Advertisement, error) {
+	started := clk.Now()
+	devices, err := connectedDevices(t.inner)
+	t.logf("connected devices: %d %s", len(devices), traceResult(started, err))
+	return devices, err
+}
+
+// ConnectedDevices isn't recorded, replays only see stations that advertised.
+func (r *Recorder) ConnectedDevices() ([]Advertisement, error) {
+	return connectedDevices(r.inner)
+}
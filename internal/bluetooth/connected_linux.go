@@ -0,0 +1,53 @@
+//go:build linux
+
+package bluetooth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// listPlatformConnected returns the devices of a BlueZ adapter that are
+// connected, by whichever process connected them.
+func listPlatformConnected(adapterID string) ([]Advertisement, error) {
+	if adapterID == "" {
+		adapterID = defaultAdapterID
+	}
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	err = conn.Object("org.bluez", dbus.ObjectPath("/")).Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0).Store(&objects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list BlueZ devices: %w", err)
+	}
+
+	prefix := "/org/bluez/" + adapterID + "/"
+	devices := make([]Advertisement, 0)
+	for objectPath, interfaces := range objects {
+		props, ok := interfaces["org.bluez.Device1"]
+		if !ok || !strings.HasPrefix(string(objectPath), prefix) {
+			continue
+		}
+		if connected, _ := props["Connected"].Value().(bool); !connected {
+			continue
+		}
+		address, _ := props["Address"].Value().(string)
+		name, _ := props["Name"].Value().(string)
+		if address == "" {
+			continue
+		}
+		uuids, _ := props["UUIDs"].Value().([]string)
+		powerService := false
+		for _, uuid := range uuids {
+			if strings.EqualFold(uuid, powerControlServiceUUIDString) || strings.EqualFold(uuid, v1ServiceUUID.String()) {
+				powerService = true
+			}
+		}
+		devices = append(devices, Advertisement{Address: Address(address), LocalName: name, PowerService: powerService})
+	}
+	return devices, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package bluetooth
+
+// listPlatformConnected returns nothing, the tinygo backends for Windows and
+// macOS can't enumerate connected devices.
+func listPlatformConnected(adapterID string) ([]Advertisement, error) {
+	return nil, nil
+}
@@ -0,0 +1,82 @@
+package bluetooth
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// DefaultMaxConnects is how many connects, with their service discovery, run
+// at once when no other limit is set. Several Windows adapters fail most
+// connects when four or more are started together.
+const DefaultMaxConnects = 2
+
+// connectLimiter bounds the connects in flight. Unlike a buffered channel its
+// limit can change while connects wait.
+type connectLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	active   int
+	released chan struct{} // Closed and replaced whenever a slot frees up
+}
+
+var connectSlots = &connectLimiter{limit: DefaultMaxConnects, released: make(chan struct{})}
+
+// SetMaxConnects sets how many stations may be connecting at once. Less than
+// one restores DefaultMaxConnects. Connects already running keep their slot.
+func SetMaxConnects(limit int) {
+	if limit < 1 {
+		limit = DefaultMaxConnects
+	}
+	connectSlots.mu.Lock()
+	defer connectSlots.mu.Unlock()
+	connectSlots.limit = limit
+	connectSlots.wakeInternal()
+}
+
+// MaxConnects returns the limit set with SetMaxConnects.
+func MaxConnects() int {
+	connectSlots.mu.Lock()
+	defer connectSlots.mu.Unlock()
+	return connectSlots.limit
+}
+
+// acquire waits for a free slot or until ctx is done.
+func (l *connectLimiter) acquire(ctx context.Context, name string) error {
+	waited := false
+	for {
+		l.mu.Lock()
+		if l.active < l.limit {
+			l.active++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		if !waited {
+			log.Printf("Bluetooth: %s waits for another connect to finish", name)
+			waited = true
+		}
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+// release frees a slot taken with acquire.
+func (l *connectLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.wakeInternal()
+}
+
+// wakeInternal lets the waiting connects check for a slot again.
+// Assumes caller holds l.mu.
+func (l *connectLimiter) wakeInternal() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
@@ -0,0 +1,71 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingBackend tracks how many connects its inner backend runs at once.
+type countingBackend struct {
+	Backend
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (b *countingBackend) Connect(address Address, params ConnectionParams) (Device, error) {
+	b.mu.Lock()
+	b.inFlight++
+	b.max = max(b.max, b.inFlight)
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.inFlight--
+		b.mu.Unlock()
+	}()
+	return b.Backend.Connect(address, params)
+}
+
+func TestConnectLimit(t *testing.T) {
+	for _, limit := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			previous := MaxConnects()
+			SetMaxConnects(limit)
+			t.Cleanup(func() { SetMaxConnects(previous) })
+
+			stations := make([]FakeStation, 6)
+			for i := range stations {
+				stations[i] = FakeStation{Address: Address(fmt.Sprintf("02:00:00:00:00:%02X", i+1)), Latency: 20 * time.Millisecond}
+			}
+			counting := &countingBackend{Backend: NewFakeBackend(stations...)}
+			previousBackend := backend
+			SetBackend(counting)
+			t.Cleanup(func() {
+				DisconnectAllStations()
+				SetBackend(previousBackend)
+			})
+
+			var wg sync.WaitGroup
+			for _, s := range stations {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					station := NewStation(fakeName(s.Address), s.Address)
+					if err := FetchInitialPowerState(context.Background(), station); err != nil {
+						t.Errorf("fetch %s: %v", station.Name, err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			counting.mu.Lock()
+			defer counting.mu.Unlock()
+			if counting.max != limit {
+				t.Errorf("up to %d connects were in flight, want %d", counting.max, limit)
+			}
+		})
+	}
+}
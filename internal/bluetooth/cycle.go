@@ -0,0 +1,55 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"lhcontrol/internal/clock"
+)
+
+// DefaultCycleOffDuration is how long PowerCycle keeps a station off when no
+// duration is given.
+const DefaultCycleOffDuration = 5 * time.Second
+
+// Steps of a power cycle, passed to its progress callback
+const (
+	CycleOffSent = "off-sent"
+	CycleWaiting = "waiting"
+	CycleOnSent  = "on-sent"
+)
+
+// PowerCycle turns a station off, waits offDuration and turns it back on,
+// which clears a station stuck in a bad tracking state. progress, if not nil,
+// is called with each step as the cycle reaches it. Like every power command,
+// the final state is read back, so a station that doesn't come back on fails
+// with ErrStateMismatch.
+//
+// Once ctx is done the cycle stops where it is, possibly leaving the station off.
+func PowerCycle(ctx context.Context, station *BaseStation, offDuration time.Duration, progress func(step string)) error {
+	if station == nil {
+		return fmt.Errorf("station is nil")
+	}
+	if offDuration <= 0 {
+		offDuration = DefaultCycleOffDuration
+	}
+	if progress == nil {
+		progress = func(string) {}
+	}
+
+	log.Printf("Bluetooth: Power cycling %s, off for %v", station.Name, offDuration)
+	if err := PowerOff(ctx, station); err != nil {
+		return fmt.Errorf("power cycle of %s: %w", station.Name, err)
+	}
+	progress(CycleOffSent)
+	progress(CycleWaiting)
+	if err := clock.Sleep(ctx, clk, offDuration); err != nil {
+		return fmt.Errorf("power cycle of %s cancelled while off: %w", station.Name, err)
+	}
+	if err := PowerOn(ctx, station); err != nil {
+		return fmt.Errorf("power cycle of %s: %w", station.Name, err)
+	}
+	progress(CycleOnSent)
+	return nil
+}
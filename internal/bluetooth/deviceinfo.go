@@ -0,0 +1,107 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// Standard Device Information Service and the revision strings read from it
+var (
+	deviceInfoServiceUUID    = bluetooth.ServiceUUIDDeviceInformation
+	firmwareRevisionCharUUID = bluetooth.CharacteristicUUIDFirmwareRevisionString
+	hardwareRevisionCharUUID = bluetooth.CharacteristicUUIDHardwareRevisionString
+	serialNumberCharUUID     = bluetooth.CharacteristicUUIDSerialNumberString
+	modelNumberCharUUID      = bluetooth.CharacteristicUUIDModelNumberString
+)
+
+// deviceInfoReadSize is enough for the revision strings stations report.
+const deviceInfoReadSize = 64
+
+// DeviceInfo holds the strings of a station's Device Information Service.
+// Values the station doesn't report are empty.
+type DeviceInfo struct {
+	FirmwareRevision string `json:"firmwareRevision"`
+	HardwareRevision string `json:"hardwareRevision"`
+	SerialNumber     string `json:"serialNumber"` // As printed on the unit, unlike the serial in the name
+	ModelNumber      string `json:"modelNumber"`
+}
+
+// DeviceInfo returns the cached device information and whether it was read.
+func (bs *BaseStation) DeviceInfo() (DeviceInfo, bool) {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return bs.deviceInfo, bs.deviceInfoRead
+}
+
+// ReadDeviceInfo reads the Device Information Service and caches the
+// result. It connects if necessary. A station without the service or one of
+// the characteristics, like older units, gets empty values instead of an
+// error. FetchInitialPowerState already reads it on the first connection.
+func ReadDeviceInfo(ctx context.Context, station *BaseStation) (DeviceInfo, error) {
+	if station == nil {
+		return DeviceInfo{}, fmt.Errorf("station is nil")
+	}
+	if err := station.lockOp(ctx); err != nil {
+		return DeviceInfo{}, err
+	}
+	defer station.unlockOp()
+
+	if err := connectAndDiscoverInternal(ctx, station); err != nil {
+		return DeviceInfo{}, err
+	}
+	return readDeviceInfoInternal(station), nil
+}
+
+// readDeviceInfoInternal reads and caches the device information of a
+// connected station. Missing values are left empty.
+// Assumes caller holds the operation lock (station.lockOp).
+func readDeviceInfoInternal(station *BaseStation) DeviceInfo {
+	station.mutex.RLock()
+	device := station.device
+	station.mutex.RUnlock()
+
+	var info DeviceInfo
+	if device == nil {
+		return info
+	}
+	services, err := device.DiscoverServices([]bluetooth.UUID{deviceInfoServiceUUID})
+	if err != nil || len(services) == 0 {
+		log.Printf("Bluetooth: No device information service on %s: %v", station.Name, err)
+	} else {
+		// Read one by one, some stacks fail a discovery when any of the
+		// requested characteristics is missing
+		info.FirmwareRevision = readString(services[0], firmwareRevisionCharUUID, station.Name)
+		info.HardwareRevision = readString(services[0], hardwareRevisionCharUUID, station.Name)
+		info.SerialNumber = readString(services[0], serialNumberCharUUID, station.Name)
+		info.ModelNumber = readString(services[0], modelNumberCharUUID, station.Name)
+	}
+
+	station.mutex.Lock()
+	station.deviceInfo = info
+	station.deviceInfoRead = true
+	station.mutex.Unlock()
+	log.Printf("Bluetooth: Device information of %s: firmware %q, hardware %q, serial %q, model %q",
+		station.Name, info.FirmwareRevision, info.HardwareRevision, info.SerialNumber, info.ModelNumber)
+	return info
+}
+
+// readString reads a string characteristic, or returns "" if it is missing
+// or can't be read.
+func readString(service Service, uuid bluetooth.UUID, name string) string {
+	chars, err := service.DiscoverCharacteristics([]bluetooth.UUID{uuid})
+	if err != nil || len(chars) == 0 {
+		log.Printf("Bluetooth: Characteristic %s not found on %s: %v", uuid.String(), name, err)
+		return ""
+	}
+	buf := make([]byte, deviceInfoReadSize)
+	n, err := chars[0].Read(buf)
+	if err != nil {
+		log.Printf("Bluetooth: Failed to read characteristic %s on %s: %v", uuid.String(), name, err)
+		return ""
+	}
+	return string(bytes.TrimRight(buf[:n], "\x00"))
+}
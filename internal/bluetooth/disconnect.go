@@ -0,0 +1,60 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"lhcontrol/internal/clock"
+)
+
+// errLinkDown is the cause reported for a station that ended the connection
+// itself, e.g. because it was switched off or went out of range.
+var errLinkDown = errors.New("the station ended the connection")
+
+// linkDown handles a disconnect the platform reported for address. A
+// disconnect we made ourselves finds the station already disconnected and is
+// ignored. Otherwise the cached handles are dropped, as with a failed read,
+// once the operation running on the station is over.
+func linkDown(address Address) {
+	var station *BaseStation
+	connectedStationsMutex.Lock()
+	for _, cs := range connectedStations {
+		if SameAddress(cs.Address.String(), address.String()) {
+			station = cs
+			break
+		}
+	}
+	connectedStationsMutex.Unlock()
+	if station == nil {
+		return
+	}
+
+	station.mutex.RLock()
+	connected := station.isConnected
+	attempt := station.connectAttempts
+	station.mutex.RUnlock()
+	if !connected {
+		return
+	}
+
+	// Runs on the stack's goroutine, which must not wait for the operation lock
+	go func() {
+		ctx, cancel := clock.WithTimeout(context.Background(), clk, OperationTimeout())
+		defer cancel()
+		if err := station.lockOp(ctx); err != nil {
+			// The running operation fails on the dead link and drops it itself
+			log.Printf("Bluetooth: %s disconnected while busy: %v", station.Name, err)
+			return
+		}
+		defer station.unlockOp()
+
+		station.mutex.RLock()
+		stale := !station.isConnected || station.connectAttempts != attempt
+		station.mutex.RUnlock()
+		if stale {
+			return // Already dropped, or connected again since
+		}
+		dropLinkInternal(station, errLinkDown)
+	}()
+}
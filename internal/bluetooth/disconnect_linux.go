@@ -0,0 +1,58 @@
+//go:build linux
+
+package bluetooth
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// watchPlatformDisconnects reports the devices of a BlueZ adapter that lost
+// their connection to linkDown. tinygo only reports the disconnects it made
+// itself on Linux. The returned function stops watching.
+func watchPlatformDisconnects(adapterID string) (func(), error) {
+	if adapterID == "" {
+		adapterID = defaultAdapterID
+	}
+	// A private connection, closing it ends the watch without affecting tinygo
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	err = conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchPathNamespace(dbus.ObjectPath("/org/bluez/"+adapterID)),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to watch BlueZ devices: %w", err)
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go func() {
+		for signal := range signals {
+			if len(signal.Body) < 2 {
+				continue
+			}
+			if iface, _ := signal.Body[0].(string); iface != "org.bluez.Device1" {
+				continue
+			}
+			changes, _ := signal.Body[1].(map[string]dbus.Variant)
+			if connected, ok := changes["Connected"].Value().(bool); !ok || connected {
+				continue
+			}
+			// Device paths end in dev_AA_BB_CC_DD_EE_FF
+			name := path.Base(string(signal.Path))
+			if !strings.HasPrefix(name, "dev_") {
+				continue
+			}
+			linkDown(Address(strings.ReplaceAll(strings.TrimPrefix(name, "dev_"), "_", ":")))
+		}
+	}()
+	return func() { conn.Close() }, nil
+}
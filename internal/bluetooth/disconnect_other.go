@@ -0,0 +1,10 @@
+//go:build !linux
+
+package bluetooth
+
+// watchPlatformDisconnects does nothing. CoreBluetooth reports disconnects
+// through tinygo's connect handler, WinRT reports none, so a dead link is
+// only noticed by the next failed operation.
+func watchPlatformDisconnects(adapterID string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,76 @@
+package bluetooth
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/clock"
+)
+
+// connectFake connects to a station of the fake backend.
+func connectFake(t *testing.T, fake *clock.Fake, address Address) *BaseStation {
+	t.Helper()
+	station := NewStation(fakeName(address), address)
+	done := make(chan error, 1)
+	go func() { done <- Connect(context.Background(), station) }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+			return station
+		case <-time.After(time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connect didn't finish")
+		}
+		fake.Advance(100 * time.Millisecond)
+	}
+}
+
+func TestLinkDownDropsConnection(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	fake := useFakeClock(t)
+	b := NewFakeBackend(FakeStation{Address: address})
+	useFakeBackend(t, b)
+	station := connectFake(t, fake, address)
+	t.Cleanup(func() { DisconnectStation(station) })
+
+	b.DropLink(address)
+	deadline := time.Now().Add(2 * time.Second)
+	for station.IsConnected() {
+		if time.Now().After(deadline) {
+			t.Fatal("station is still connected after the link went down")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// A link that goes down while an operation runs is left to the operation,
+// after waiting for it up to the operation timeout of the package clock.
+func TestLinkDownWhileBusy(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	fake := useFakeClock(t)
+	b := NewFakeBackend(FakeStation{Address: address})
+	useFakeBackend(t, b)
+	useOperationTimeout(t, time.Second)
+	station := connectFake(t, fake, address)
+	t.Cleanup(func() { DisconnectStation(station) })
+
+	if err := station.lockOp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	before := runtime.NumGoroutine()
+	b.DropLink(address)
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	waitGoroutines(t, before)
+	station.unlockOp()
+	if n := fake.Pending(); n != 0 {
+		t.Errorf("%d timers still pending", n)
+	}
+}
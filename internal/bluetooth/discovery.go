@@ -0,0 +1,36 @@
+package bluetooth
+
+import (
+	"context"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// discoverServices discovers a service within DiscoveryTimeout. Windows can
+// block for 30 seconds and more on a station that rebooted mid-discovery.
+func discoverServices(ctx context.Context, name string, device Device, uuid bluetooth.UUID) ([]Service, error) {
+	var services []Service
+	err := callWithin(ctx, DiscoveryTimeout(), name, "service discovery", func() error {
+		found, err := device.DiscoverServices([]bluetooth.UUID{uuid})
+		services = found
+		return err
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// discoverCharacteristics discovers a characteristic within DiscoveryTimeout.
+func discoverCharacteristics(ctx context.Context, name string, service Service, uuid bluetooth.UUID) ([]Characteristic, error) {
+	var chars []Characteristic
+	err := callWithin(ctx, DiscoveryTimeout(), name, "characteristic discovery", func() error {
+		found, err := service.DiscoverCharacteristics([]bluetooth.UUID{uuid})
+		chars = found
+		return err
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return chars, nil
+}
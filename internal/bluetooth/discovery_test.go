@@ -0,0 +1,81 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// silentBackend connects to the stations of a fake backend, but their
+// service discovery never answers until release is closed.
+type silentBackend struct {
+	*FakeBackend
+	release   chan struct{}
+	discovers atomic.Int32
+}
+
+func (b *silentBackend) Connect(address Address, params ConnectionParams) (Device, error) {
+	device, err := b.FakeBackend.Connect(address, params)
+	if err != nil {
+		return nil, err
+	}
+	return &silentDevice{Device: device, b: b}, nil
+}
+
+type silentDevice struct {
+	Device
+	b *silentBackend
+}
+
+func (d *silentDevice) DiscoverServices([]bluetooth.UUID) ([]Service, error) {
+	d.b.discovers.Add(1)
+	<-d.b.release
+	return nil, ErrSimulatedFailure
+}
+
+func TestDiscoveryTimeout(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	fake := useFakeClock(t)
+	b := &silentBackend{FakeBackend: NewFakeBackend(FakeStation{Address: address}), release: make(chan struct{})}
+	previousBackend, previousTimeout := backend, DiscoveryTimeout()
+	SetBackend(b)
+	SetDiscoveryTimeout(time.Second)
+	t.Cleanup(func() {
+		SetDiscoveryTimeout(previousTimeout)
+		SetBackend(previousBackend)
+	})
+	// Runs first and lets the abandoned discoveries return
+	t.Cleanup(func() { close(b.release) })
+
+	station := NewStation(fakeName(address), address)
+	start := fake.Now()
+	done := make(chan error, 1)
+	go func() { done <- Connect(context.Background(), station) }()
+	const step = 100 * time.Millisecond
+	err := advanceUntil(t, fake, step, done)
+	if !errors.Is(err, ErrDiscoveryTimeout) {
+		t.Fatalf("got %v, want ErrDiscoveryTimeout", err)
+	}
+
+	// Three attempts of a second each with half a second between them
+	budget := 3*time.Second + 2*500*time.Millisecond
+	if took := fake.Now().Sub(start); took > budget+step {
+		t.Errorf("connect gave up after %v, want at most %v", took, budget)
+	}
+	if n := b.discovers.Load(); n != 3 {
+		t.Errorf("discovery was attempted %d times, want 3", n)
+	}
+	if station.IsConnected() {
+		t.Error("station is still connected after discovery timed out")
+	}
+	b.mu.Lock()
+	connected := b.stations[address].connected
+	b.mu.Unlock()
+	if connected {
+		t.Error("the link wasn't closed after discovery timed out")
+	}
+}
@@ -0,0 +1,101 @@
+package bluetooth
+
+import (
+	"errors"
+	"strings"
+)
+
+// Kinds of BLE failures, wrapped in an *Error so callers can tell them apart
+// with errors.Is.
+var (
+	ErrAdapter               = errors.New("bluetooth adapter error")
+	ErrNoAdapter             = errors.New("no bluetooth adapter found") // No radio at all, as opposed to one that is off or failing
+	ErrAdapterDisabled       = errors.New("bluetooth is turned off")
+	ErrScan                  = errors.New("bluetooth scan failed")
+	ErrBusy                  = errors.New("station is busy")
+	ErrConnect               = errors.New("connection failed")
+	ErrNotConnected          = errors.New("station is not connected") // An operation that needs an open connection found none
+	ErrDiscovery             = errors.New("service discovery failed")
+	ErrDiscoveryTimeout      = errors.New("service discovery timed out") // Every discovery attempt was abandoned, see SetDiscoveryTimeout
+	ErrCharacteristicMissing = errors.New("characteristic not found")    // Discovery succeeded, but the characteristic isn't cached
+	ErrRead                  = errors.New("read failed")
+	ErrWrite                 = errors.New("write failed")
+	ErrTimeout               = errors.New("operation timed out")           // A connect, read or write was abandoned, see SetOperationTimeout
+	ErrUnsupported           = errors.New("not supported by this station") // E.g. standby on a SteamVR 1.0 station
+	ErrStateMismatch         = errors.New("station reports another state") // A command went out, but the station didn't end up in its state
+)
+
+// Error is a failed BLE operation on a station. Its message is the technical
+// detail; Kind tells what went wrong.
+type Error struct {
+	Station  string // Station name, empty for adapter-wide failures
+	Kind     error  // One of the Err* kinds above
+	Err      error
+	Attempts int // Writes tried before giving up, set for ErrWrite
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// StationName returns the name of the station an error refers to, if any.
+func StationName(err error) string {
+	var bleErr *Error
+	if errors.As(err, &bleErr) {
+		return bleErr.Station
+	}
+	return ""
+}
+
+func stationError(name string, kind, err error) error {
+	return &Error{Station: name, Kind: kind, Err: err}
+}
+
+func writeError(name string, attempts int, err error) error {
+	return &Error{Station: name, Kind: ErrWrite, Err: err, Attempts: attempts}
+}
+
+// noAdapterHints are fragments of the errors the platform stacks return when
+// there is no Bluetooth radio: BlueZ has no hci0 object (or none for the
+// selected adapter), and WinRT reports ERROR_NOT_FOUND. macOS only reports a
+// timeout, which can't be told apart from Bluetooth being off.
+var noAdapterHints = []string{
+	"no such adapter",
+	"unknownobject",
+	"unknown object",
+	"does not exist",
+	"element not found",
+	"0x80070490",
+}
+
+// disabledHints are fragments of the errors for a radio that is present but
+// turned off: BlueZ reports NotReady, WinRT and CoreBluetooth a powered off
+// radio.
+var disabledHints = []string{
+	"notready",
+	"not ready",
+	"powered off",
+	"poweredoff",
+	"radio is off",
+}
+
+// adapterErrorKind returns ErrNoAdapter if err says there is no adapter,
+// ErrAdapterDisabled if it says the radio is off, else fallback.
+func adapterErrorKind(err error, fallback error) error {
+	text := strings.ToLower(err.Error())
+	for _, hint := range noAdapterHints {
+		if strings.Contains(text, hint) {
+			return ErrNoAdapter
+		}
+	}
+	for _, hint := range disabledHints {
+		if strings.Contains(text, hint) {
+			return ErrAdapterDisabled
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,92 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("device unreachable")
+	for _, test := range []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"connect", stationError("LHB-A", ErrConnect, cause), ErrConnect},
+		{"not connected", stationError("LHB-A", ErrNotConnected, cause), ErrNotConnected},
+		{"missing characteristic", stationError("LHB-A", ErrCharacteristicMissing, cause), ErrCharacteristicMissing},
+		{"adapter off", stationError("", ErrAdapterDisabled, cause), ErrAdapterDisabled},
+		{"timeout", stationError("LHB-A", ErrTimeout, cause), ErrTimeout},
+		{"write", writeError("LHB-A", 3, cause), ErrWrite},
+		{"wrapped again", fmt.Errorf("power on: %w", writeError("LHB-A", 3, cause)), ErrWrite},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if !errors.Is(test.err, test.kind) {
+				t.Errorf("errors.Is(%v, %v) is false", test.err, test.kind)
+			}
+			if !errors.Is(test.err, cause) {
+				t.Errorf("the cause isn't wrapped: %v", test.err)
+			}
+			for _, other := range []error{ErrConnect, ErrWrite, ErrTimeout, ErrAdapterDisabled} {
+				if other != test.kind && errors.Is(test.err, other) {
+					t.Errorf("error of kind %v is also %v", test.kind, other)
+				}
+			}
+			var bleErr *Error
+			if !errors.As(test.err, &bleErr) || bleErr.Kind != test.kind {
+				t.Fatalf("errors.As didn't find an *Error of kind %v", test.kind)
+			}
+			if test.kind == ErrWrite && bleErr.Attempts != 3 {
+				t.Errorf("write error has %d attempts, want 3", bleErr.Attempts)
+			}
+		})
+	}
+	if name := StationName(fmt.Errorf("command: %w", stationError("LHB-A", ErrRead, cause))); name != "LHB-A" {
+		t.Errorf("StationName = %q, want LHB-A", name)
+	}
+	if name := StationName(cause); name != "" {
+		t.Errorf("StationName of a plain error = %q, want none", name)
+	}
+}
+
+func TestAdapterErrorKind(t *testing.T) {
+	for _, test := range []struct {
+		message string
+		want    error
+	}{
+		{"org.freedesktop.DBus.Error.UnknownObject: Method \"StartDiscovery\" doesn't exist", ErrNoAdapter},
+		{"Element not found. (0x80070490)", ErrNoAdapter},
+		{"org.bluez.Error.NotReady: Resource Not Ready", ErrAdapterDisabled},
+		{"bluetooth radio is off", ErrAdapterDisabled},
+		{"CBManagerStatePoweredOff", ErrAdapterDisabled},
+		{"operation already in progress", ErrScan},
+	} {
+		if got := adapterErrorKind(errors.New(test.message), ErrScan); got != test.want {
+			t.Errorf("adapterErrorKind(%q) = %v, want %v", test.message, got, test.want)
+		}
+	}
+}
+
+// Operations on the fake backend fail with the kind of what went wrong.
+func TestOperationErrorKinds(t *testing.T) {
+	fake := useFakeClock(t)
+	dead := FakeStation{Address: "02:00:00:00:00:01", Unreachable: true}
+	b := NewFakeBackend(dead)
+	useFakeBackend(t, b)
+	useOperationTimeout(t, time.Second)
+
+	station := NewStation("LHB-00000001", dead.Address)
+	done := make(chan error, 1)
+	go func() { done <- Connect(context.Background(), station) }()
+	err := advanceUntil(t, fake, 100*time.Millisecond, done)
+	if !errors.Is(err, ErrConnect) {
+		t.Errorf("connect to an unreachable station: got %v, want ErrConnect", err)
+	}
+	var bleErr *Error
+	if !errors.As(err, &bleErr) || bleErr.Station != station.Name {
+		t.Errorf("connect error doesn't name the station: %v", err)
+	}
+}
@@ -0,0 +1,429 @@
+package bluetooth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// ErrSimulatedFailure is returned by the fake backend for the failures a
+// FakeStation scripts.
+var ErrSimulatedFailure = errors.New("simulated failure")
+
+// rawPowerStateSpinningUp is what a fake V2 station reports between a power on
+// command and RawPowerStateOn, like real stations report 0x09.
+const rawPowerStateSpinningUp = 0x09
+
+// FakeStation scripts a station of the fake backend.
+type FakeStation struct {
+	Address Address
+	Name    string // Defaults to an LHB- name derived from the address
+	RSSI    int16
+	// RawPower is the raw V2 power value the station starts with.
+	RawPower byte
+	Channel  byte
+	// SpinUp is how long a power on command takes to reach RawPowerStateOn.
+	// Reads report a spinning-up value until then.
+	SpinUp time.Duration
+	// Latency is added to every connect, discovery, read and write.
+	Latency time.Duration
+	// ConnectFailures is the number of connection attempts that fail before
+	// one succeeds. Unreachable makes every attempt fail.
+	ConnectFailures int
+	Unreachable     bool
+	// DropsUnacknowledged makes writes without response report success
+	// without writing anything, like some BlueZ versions do.
+	DropsUnacknowledged bool
+}
+
+// FakeBackend is a Backend that simulates stations in memory, for running the
+// app and the manager without an adapter. It uses the package clock, so a
+// fake clock set with SetClock also drives latencies and spin-up.
+type FakeBackend struct {
+	mu       sync.Mutex
+	stations map[Address]*fakeStation
+	order    []Address // Scan order
+	stopScan chan struct{}
+}
+
+type fakeStation struct {
+	FakeStation
+	onUntil   time.Time    // Spin-up end while a power on is in progress
+	connected bool         // A Connect succeeded and Disconnect wasn't called
+	notify    func([]byte) // Power notification callback, if subscribed
+}
+
+// NewFakeBackend creates a fake backend with the given stations.
+func NewFakeBackend(stations ...FakeStation) *FakeBackend {
+	b := &FakeBackend{stations: make(map[Address]*fakeStation)}
+	for _, s := range stations {
+		b.AddStation(s)
+	}
+	return b
+}
+
+// SimulatedStations returns n stations with realistic latencies, for the
+// -simulate flag. The last one needs a second connection attempt.
+func SimulatedStations(n int) []FakeStation {
+	stations := make([]FakeStation, n)
+	for i := range stations {
+		stations[i] = FakeStation{
+			Address:  Address(fmt.Sprintf("02:00:00:00:00:%02X", i+1)),
+			RSSI:     int16(-50 - 5*i),
+			RawPower: RawPowerStateSleep,
+			Channel:  byte(i + 1),
+			SpinUp:   3 * time.Second,
+			Latency:  150 * time.Millisecond,
+		}
+	}
+	if n > 1 {
+		stations[n-1].ConnectFailures = 1
+	}
+	return stations
+}
+
+// AddStation adds or replaces a simulated station. It shows up in the next scan.
+func (b *FakeBackend) AddStation(s FakeStation) {
+	if s.Name == "" {
+		s.Name = fakeName(s.Address)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.stations[s.Address]; !ok {
+		b.order = append(b.order, s.Address)
+	}
+	b.stations[s.Address] = &fakeStation{FakeStation: s}
+}
+
+// fakeName derives an LHB- name from the last four address bytes.
+func fakeName(address Address) string {
+	hex := strings.ReplaceAll(address.String(), ":", "")
+	if len(hex) > 8 {
+		hex = hex[len(hex)-8:]
+	}
+	return v2NamePrefix + strings.ToUpper(hex)
+}
+
+// SetRawPower changes the power value of a simulated station, as if it was
+// switched by another app, and notifies a subscriber.
+func (b *FakeBackend) SetRawPower(address Address, raw byte) error {
+	b.mu.Lock()
+	s, ok := b.stations[address]
+	if !ok {
+		b.mu.Unlock()
+		return fmt.Errorf("no simulated station %s", address)
+	}
+	s.RawPower = raw
+	s.onUntil = time.Time{}
+	notify := s.notify
+	b.mu.Unlock()
+	if notify != nil {
+		go notify([]byte{raw})
+	}
+	return nil
+}
+
+// FailConnects makes the next n connection attempts to address fail.
+func (b *FakeBackend) FailConnects(address Address, n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s, ok := b.stations[address]; ok {
+		s.ConnectFailures = n
+	}
+}
+
+// MoveStation makes a simulated station advertise under a new address from the
+// next scan on, like a peripheral whose macOS identifier changed after a
+// Bluetooth reset. An open connection to the old address is dropped.
+func (b *FakeBackend) MoveStation(from, to Address) error {
+	b.mu.Lock()
+	s, ok := b.stations[from]
+	if !ok {
+		b.mu.Unlock()
+		return fmt.Errorf("no simulated station %s", from)
+	}
+	wasConnected := s.connected
+	s.connected = false
+	s.notify = nil
+	s.Address = to
+	delete(b.stations, from)
+	b.stations[to] = s
+	for i, address := range b.order {
+		if address == from {
+			b.order[i] = to
+		}
+	}
+	b.mu.Unlock()
+	if wasConnected {
+		linkDown(from)
+	}
+	return nil
+}
+
+// DropLink ends the connection to address from the station's side, like a
+// station that was switched off at the wall.
+func (b *FakeBackend) DropLink(address Address) {
+	b.mu.Lock()
+	s, ok := b.stations[address]
+	wasConnected := ok && s.connected
+	if ok {
+		s.connected = false
+		s.notify = nil
+	}
+	b.mu.Unlock()
+	if wasConnected {
+		linkDown(address)
+	}
+}
+
+func (b *FakeBackend) station(address Address) (*fakeStation, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.stations[address]
+	if !ok {
+		return nil, fmt.Errorf("%w: no station %s in range", ErrSimulatedFailure, address)
+	}
+	return s, nil
+}
+
+func (b *FakeBackend) Enable() error {
+	return nil
+}
+
+// Scan delivers one advertisement per station and then blocks until StopScan,
+// like a real adapter would.
+func (b *FakeBackend) Scan(onResult func(Advertisement)) error {
+	stop := make(chan struct{})
+	b.mu.Lock()
+	b.stopScan = stop
+	advertisements := make([]Advertisement, 0, len(b.order))
+	for _, address := range b.order {
+		s := b.stations[address]
+		advertisements = append(advertisements, Advertisement{
+			Address:      s.Address,
+			LocalName:    s.Name,
+			RSSI:         s.RSSI,
+			PowerService: true,
+			ManufacturerData: map[uint16][]byte{
+				valveCompanyID: {0x00, b.rawPowerLocked(s)},
+			},
+		})
+	}
+	b.mu.Unlock()
+	for _, adv := range advertisements {
+		onResult(adv)
+	}
+	<-stop
+	return nil
+}
+
+func (b *FakeBackend) StopScan() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopScan != nil {
+		close(b.stopScan)
+		b.stopScan = nil
+	}
+	return nil
+}
+
+func (b *FakeBackend) Connect(address Address, _ ConnectionParams) (Device, error) {
+	s, err := b.station(address)
+	if err != nil {
+		return nil, err
+	}
+	clk.Sleep(s.Latency)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.Unreachable {
+		return nil, fmt.Errorf("%w: %s is unreachable", ErrSimulatedFailure, address)
+	}
+	if s.ConnectFailures > 0 {
+		s.ConnectFailures--
+		return nil, fmt.Errorf("%w: connecting to %s", ErrSimulatedFailure, address)
+	}
+	s.connected = true
+	return &fakeDevice{b: b, s: s}, nil
+}
+
+// rawPowerLocked returns the current power value, finishing a spin-up whose
+// time is up. The caller holds b.mu.
+func (b *FakeBackend) rawPowerLocked(s *fakeStation) byte {
+	if !s.onUntil.IsZero() && !clk.Now().Before(s.onUntil) {
+		s.RawPower = RawPowerStateOn
+		s.onUntil = time.Time{}
+	}
+	return s.RawPower
+}
+
+// writePower applies a V2 power command.
+func (b *FakeBackend) writePower(s *fakeStation, value byte) {
+	b.mu.Lock()
+	s.onUntil = time.Time{}
+	switch {
+	case value != 0x01:
+		s.RawPower = value
+	case s.SpinUp <= 0:
+		s.RawPower = RawPowerStateOn
+	case s.RawPower != RawPowerStateOn:
+		s.RawPower = rawPowerStateSpinningUp
+		s.onUntil = clk.Now().Add(s.SpinUp)
+	}
+	raw := s.RawPower
+	spinUp := s.onUntil
+	notify := s.notify
+	b.mu.Unlock()
+
+	if notify == nil {
+		return
+	}
+	go notify([]byte{raw})
+	if !spinUp.IsZero() {
+		clk.AfterFunc(spinUp.Sub(clk.Now()), func() {
+			b.mu.Lock()
+			// A read may have finished the spin-up already, a later command replaced it
+			current := s.onUntil.Equal(spinUp) || s.onUntil.IsZero()
+			notify := s.notify
+			raw := b.rawPowerLocked(s)
+			b.mu.Unlock()
+			if current && raw == RawPowerStateOn && notify != nil {
+				notify([]byte{raw})
+			}
+		})
+	}
+}
+
+type fakeDevice struct {
+	b *FakeBackend
+	s *fakeStation
+}
+
+// fakeCharacteristicUUIDs are the characteristics of the power control service.
+func fakeCharacteristicUUIDs() []bluetooth.UUID {
+	return []bluetooth.UUID{powerControlCharacteristicUUID, channelCharacteristicUUID, identifyCharacteristicUUID}
+}
+
+func (d *fakeDevice) DiscoverServices(uuids []bluetooth.UUID) ([]Service, error) {
+	if err := d.wait(); err != nil {
+		return nil, err
+	}
+	for _, uuid := range uuids {
+		if uuid != powerControlServiceUUID {
+			return nil, fmt.Errorf("%w: service %s not found", ErrSimulatedFailure, uuid)
+		}
+	}
+	return []Service{&fakeService{d: d}}, nil
+}
+
+func (d *fakeDevice) Disconnect() error {
+	d.b.mu.Lock()
+	defer d.b.mu.Unlock()
+	d.s.connected = false
+	d.s.notify = nil
+	return nil
+}
+
+// wait adds the station's latency and fails once the link was dropped.
+func (d *fakeDevice) wait() error {
+	clk.Sleep(d.s.Latency)
+	d.b.mu.Lock()
+	defer d.b.mu.Unlock()
+	if !d.s.connected {
+		return fmt.Errorf("%w: not connected to %s", ErrSimulatedFailure, d.s.Address)
+	}
+	return nil
+}
+
+type fakeService struct {
+	d *fakeDevice
+}
+
+func (s *fakeService) DiscoverCharacteristics(uuids []bluetooth.UUID) ([]Characteristic, error) {
+	if err := s.d.wait(); err != nil {
+		return nil, err
+	}
+	if len(uuids) == 0 {
+		uuids = fakeCharacteristicUUIDs()
+	}
+	chars := make([]Characteristic, 0, len(uuids))
+	for _, uuid := range uuids {
+		known := false
+		for _, c := range fakeCharacteristicUUIDs() {
+			known = known || c == uuid
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: characteristic %s not found", ErrSimulatedFailure, uuid)
+		}
+		chars = append(chars, &fakeCharacteristic{d: s.d, uuid: uuid})
+	}
+	return chars, nil
+}
+
+type fakeCharacteristic struct {
+	d    *fakeDevice
+	uuid bluetooth.UUID
+}
+
+func (c *fakeCharacteristic) UUID() bluetooth.UUID {
+	return c.uuid
+}
+
+func (c *fakeCharacteristic) Read(data []byte) (int, error) {
+	if err := c.d.wait(); err != nil {
+		return 0, err
+	}
+	b, s := c.d.b, c.d.s
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch c.uuid {
+	case powerControlCharacteristicUUID:
+		return copy(data, []byte{b.rawPowerLocked(s)}), nil
+	case channelCharacteristicUUID:
+		return copy(data, []byte{s.Channel}), nil
+	}
+	return 0, fmt.Errorf("%w: characteristic %s can't be read", ErrSimulatedFailure, c.uuid)
+}
+
+func (c *fakeCharacteristic) Write(data []byte) (int, error) {
+	if err := c.d.wait(); err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("%w: empty write", ErrSimulatedFailure)
+	}
+	b, s := c.d.b, c.d.s
+	switch c.uuid {
+	case powerControlCharacteristicUUID:
+		b.writePower(s, data[0])
+	case channelCharacteristicUUID:
+		b.mu.Lock()
+		s.Channel = data[0]
+		b.mu.Unlock()
+	}
+	return len(data), nil
+}
+
+func (c *fakeCharacteristic) WriteWithoutResponse(data []byte) (int, error) {
+	if c.d.s.DropsUnacknowledged {
+		if err := c.d.wait(); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	return c.Write(data)
+}
+
+func (c *fakeCharacteristic) EnableNotifications(callback func(data []byte)) error {
+	if c.uuid != powerControlCharacteristicUUID {
+		return fmt.Errorf("%w: characteristic %s doesn't notify", ErrSimulatedFailure, c.uuid)
+	}
+	b := c.d.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c.d.s.notify = callback
+	return nil
+}
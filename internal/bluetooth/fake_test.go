@@ -0,0 +1,161 @@
+package bluetooth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// fakePowerCharacteristic connects to a station of b and returns its power
+// characteristic.
+func fakePowerCharacteristic(t *testing.T, b *FakeBackend, address Address) (Device, Characteristic) {
+	t.Helper()
+	device, err := b.Connect(address, ConnectionParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	services, err := device.DiscoverServices([]bluetooth.UUID{powerControlServiceUUID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chars, err := services[0].DiscoverCharacteristics([]bluetooth.UUID{powerControlCharacteristicUUID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return device, chars[0]
+}
+
+func readRaw(t *testing.T, c Characteristic) byte {
+	t.Helper()
+	buf := make([]byte, powerReadBufferSize)
+	n, err := c.Read(buf)
+	if err != nil || n != 1 {
+		t.Fatalf("read %d bytes: %v", n, err)
+	}
+	return buf[0]
+}
+
+func TestFakeBackendScan(t *testing.T) {
+	b := NewFakeBackend(
+		FakeStation{Address: "02:00:00:00:1A:2B", RSSI: -40, RawPower: RawPowerStateStandby},
+		FakeStation{Address: "02:00:00:00:00:02", Name: "LHB-CUSTOM"},
+	)
+	results := make(chan []Advertisement, 1)
+	go func() {
+		var advertisements []Advertisement
+		_ = b.Scan(func(adv Advertisement) { advertisements = append(advertisements, adv) })
+		results <- advertisements
+	}()
+	select {
+	case <-results:
+		t.Fatal("scan returned before StopScan")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := b.StopScan(); err != nil {
+		t.Fatal(err)
+	}
+
+	advertisements := <-results
+	if len(advertisements) != 2 {
+		t.Fatalf("got %d advertisements, want 2", len(advertisements))
+	}
+	first := advertisements[0]
+	if first.LocalName != "LHB-00001A2B" || first.RSSI != -40 || !first.PowerService {
+		t.Errorf("got advertisement %+v", first)
+	}
+	if data := first.ManufacturerData[valveCompanyID]; len(data) != 2 || data[1] != RawPowerStateStandby {
+		t.Errorf("manufacturer data %X doesn't hint standby", data)
+	}
+	if name := advertisements[1].LocalName; name != "LHB-CUSTOM" {
+		t.Errorf("station advertised as %q, want its own name", name)
+	}
+}
+
+func TestFakeBackendConnectFailures(t *testing.T) {
+	b := NewFakeBackend(
+		FakeStation{Address: "02:00:00:00:00:01", ConnectFailures: 2},
+		FakeStation{Address: "02:00:00:00:00:02", Unreachable: true},
+	)
+	for i := 0; i < 2; i++ {
+		if _, err := b.Connect("02:00:00:00:00:01", ConnectionParams{}); !errors.Is(err, ErrSimulatedFailure) {
+			t.Fatalf("connect %d: got %v, want a simulated failure", i, err)
+		}
+	}
+	if _, err := b.Connect("02:00:00:00:00:01", ConnectionParams{}); err != nil {
+		t.Fatalf("connect after the failures: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := b.Connect("02:00:00:00:00:02", ConnectionParams{}); !errors.Is(err, ErrSimulatedFailure) {
+			t.Fatalf("connect %d to an unreachable station: got %v", i, err)
+		}
+	}
+	if _, err := b.Connect("02:00:00:00:00:03", ConnectionParams{}); !errors.Is(err, ErrSimulatedFailure) {
+		t.Errorf("connect to an unknown station: got %v", err)
+	}
+
+	b.FailConnects("02:00:00:00:00:01", 1)
+	if _, err := b.Connect("02:00:00:00:00:01", ConnectionParams{}); !errors.Is(err, ErrSimulatedFailure) {
+		t.Errorf("connect after FailConnects: got %v", err)
+	}
+}
+
+func TestFakeBackendSpinUp(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	fake := useFakeClock(t)
+	b := NewFakeBackend(FakeStation{Address: address, SpinUp: 3 * time.Second})
+	_, c := fakePowerCharacteristic(t, b, address)
+	notifications := make(chan byte, 4)
+	if err := c.EnableNotifications(func(data []byte) { notifications <- data[0] }); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw := readRaw(t, c); raw != RawPowerStateSleep {
+		t.Fatalf("station starts at %X, want asleep", raw)
+	}
+	if _, err := c.Write([]byte{0x01}); err != nil {
+		t.Fatal(err)
+	}
+	if raw := <-notifications; raw != rawPowerStateSpinningUp {
+		t.Errorf("notified %X after power on, want spinning up", raw)
+	}
+	fake.Advance(2 * time.Second)
+	if raw := readRaw(t, c); raw != rawPowerStateSpinningUp {
+		t.Errorf("read %X during the spin-up, want spinning up", raw)
+	}
+	fake.Advance(time.Second)
+	if raw := <-notifications; raw != RawPowerStateOn {
+		t.Errorf("notified %X after the spin-up, want on", raw)
+	}
+	if raw := readRaw(t, c); raw != RawPowerStateOn {
+		t.Errorf("read %X after the spin-up, want on", raw)
+	}
+
+	// A change made by another app is notified too
+	if err := b.SetRawPower(address, RawPowerStateStandby); err != nil {
+		t.Fatal(err)
+	}
+	if raw := <-notifications; raw != RawPowerStateStandby {
+		t.Errorf("notified %X after SetRawPower, want standby", raw)
+	}
+}
+
+func TestFakeBackendDropLink(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	b := NewFakeBackend(FakeStation{Address: address})
+	_, c := fakePowerCharacteristic(t, b, address)
+	b.DropLink(address)
+	if _, err := c.Read(make([]byte, 1)); !errors.Is(err, ErrSimulatedFailure) {
+		t.Errorf("read after the link dropped: got %v, want a simulated failure", err)
+	}
+
+	device, c := fakePowerCharacteristic(t, b, address)
+	if err := device.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write([]byte{0x01}); !errors.Is(err, ErrSimulatedFailure) {
+		t.Errorf("write after disconnecting: got %v, want a simulated failure", err)
+	}
+}
@@ -0,0 +1,53 @@
+package bluetooth
+
+import "strings"
+
+// ScanFilter selects the advertisements a scan reports as stations.
+type ScanFilter struct {
+	// Prefixes are the accepted name prefixes. Empty accepts any named
+	// device that advertises a power service, see Advertisement.PowerService.
+	Prefixes []string
+	// Addresses, if not empty, are the only stations accepted.
+	Addresses []Address
+}
+
+// DefaultScanFilter accepts the names SteamVR 2.0 and 1.0 stations advertise.
+func DefaultScanFilter() ScanFilter {
+	return ScanFilter{Prefixes: []string{v2NamePrefix, v1NamePrefix}}
+}
+
+// accept tells whether an advertisement is from a base station, and if not,
+// why it was rejected.
+func (f ScanFilter) accept(adv Advertisement) (bool, string) {
+	if adv.LocalName == "" {
+		return false, "no local name"
+	}
+	address := adv.Address.String()
+	if address == "" || address == "00:00:00:00:00:00" {
+		return false, "empty address"
+	}
+	if len(f.Addresses) > 0 && !f.allowsAddress(address) {
+		return false, "address not in the allowlist"
+	}
+	if len(f.Prefixes) == 0 {
+		if !adv.PowerService {
+			return false, "no power service advertised"
+		}
+		return true, ""
+	}
+	for _, prefix := range f.Prefixes {
+		if strings.HasPrefix(adv.LocalName, prefix) {
+			return true, ""
+		}
+	}
+	return false, "name doesn't start with " + strings.Join(f.Prefixes, " or ")
+}
+
+func (f ScanFilter) allowsAddress(address string) bool {
+	for _, allowed := range f.Addresses {
+		if SameAddress(allowed.String(), address) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package bluetooth
+
+import "time"
+
+// Health of a station, judged by the outcome of its recent connects, power
+// writes and power reads
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // Some recent operations failed
+	HealthFailing  = "failing"  // At least half of them failed
+)
+
+const (
+	// healthWindow is how many recent operations the health is judged by
+	healthWindow = 20
+	// degradedRatio is the failure ratio above which a station is degraded
+	degradedRatio = 0.1
+	// failingRatio is the failure ratio from which a station is failing
+	failingRatio = 0.5
+)
+
+// HealthCounters are a station's failures since start or the last
+// ResetHealth. They aren't persisted.
+type HealthCounters struct {
+	Health          string    `json:"health"` // HealthOK, HealthDegraded or HealthFailing
+	Operations      int       `json:"operations"`
+	ConnectFailures int       `json:"connectFailures"`
+	WriteFailures   int       `json:"writeFailures"`
+	ReadFailures    int       `json:"readFailures"`
+	LastError       string    `json:"lastError,omitempty"`
+	LastErrorAt     time.Time `json:"lastErrorAt,omitempty"`
+	Since           time.Time `json:"since"` // Start of counting
+}
+
+// health holds a station's counters and its recent outcomes.
+type health struct {
+	counters HealthCounters
+	recent   []bool // Outcomes of the last healthWindow operations, true for failures
+}
+
+// recordOutcomeInternal counts an operation and, if err is set, its failure.
+// op is opConnect, opWrite or opRead.
+// Assumes caller holds station.mutex.
+func (bs *BaseStation) recordOutcomeInternal(op string, err error) {
+	h := &bs.health
+	if h.counters.Since.IsZero() {
+		h.counters.Since = clk.Now()
+	}
+	h.counters.Operations++
+	h.recent = append(h.recent, err != nil)
+	if len(h.recent) > healthWindow {
+		h.recent = h.recent[len(h.recent)-healthWindow:]
+	}
+	if err == nil {
+		return
+	}
+	switch op {
+	case opConnect:
+		h.counters.ConnectFailures++
+	case opWrite:
+		h.counters.WriteFailures++
+	case opRead:
+		h.counters.ReadFailures++
+	}
+	h.counters.LastError = err.Error()
+	h.counters.LastErrorAt = clk.Now()
+}
+
+// recordOutcome is recordOutcomeInternal for callers that don't hold the mutex.
+func (bs *BaseStation) recordOutcome(op string, err error) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	bs.recordOutcomeInternal(op, err)
+}
+
+// Health returns the station's health, HealthOK if nothing was tried yet.
+func (bs *BaseStation) Health() string {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	return bs.health.status()
+}
+
+// HealthCounters returns the station's failure counters with its health.
+func (bs *BaseStation) HealthCounters() HealthCounters {
+	bs.mutex.RLock()
+	defer bs.mutex.RUnlock()
+	counters := bs.health.counters
+	counters.Health = bs.health.status()
+	return counters
+}
+
+// ResetHealth clears the station's failure counters.
+func (bs *BaseStation) ResetHealth() {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	bs.health = health{counters: HealthCounters{Since: clk.Now()}}
+}
+
+func (h *health) status() string {
+	if len(h.recent) == 0 {
+		return HealthOK
+	}
+	failures := 0
+	for _, failed := range h.recent {
+		if failed {
+			failures++
+		}
+	}
+	ratio := float64(failures) / float64(len(h.recent))
+	switch {
+	case ratio >= failingRatio:
+		return HealthFailing
+	case ratio > degradedRatio:
+		return HealthDegraded
+	}
+	return HealthOK
+}
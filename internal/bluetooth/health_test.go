@@ -0,0 +1,109 @@
+package bluetooth
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHealthStatus(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		failures  int
+		successes int // After the failures
+		want      string
+	}{
+		{name: "nothing tried", want: HealthOK},
+		{name: "all succeeded", successes: 5, want: HealthOK},
+		{name: "one in ten failed", failures: 1, successes: 9, want: HealthOK},
+		{name: "two in ten failed", failures: 2, successes: 8, want: HealthDegraded},
+		{name: "half failed", failures: 5, successes: 5, want: HealthFailing},
+		{name: "all failed", failures: 3, want: HealthFailing},
+		{name: "failures left the window", failures: 20, successes: healthWindow, want: HealthOK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			station := NewStation("LHB-TEST", "02:00:00:00:00:01")
+			for i := 0; i < test.failures; i++ {
+				station.recordOutcome(opConnect, ErrSimulatedFailure)
+			}
+			for i := 0; i < test.successes; i++ {
+				station.recordOutcome(opConnect, nil)
+			}
+			if got := station.Health(); got != test.want {
+				t.Errorf("health is %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHealthCounters(t *testing.T) {
+	fake := useFakeClock(t)
+	station := NewStation("LHB-TEST", "02:00:00:00:00:01")
+	start := fake.Now()
+	station.recordOutcome(opConnect, ErrSimulatedFailure)
+	station.recordOutcome(opConnect, nil)
+	station.recordOutcome(opWrite, ErrSimulatedFailure)
+	fake.Advance(time.Minute)
+	station.recordOutcome(opRead, ErrSimulatedFailure)
+	station.recordOutcome(opRead, nil)
+
+	counters := station.HealthCounters()
+	if counters.Operations != 5 || counters.ConnectFailures != 1 || counters.WriteFailures != 1 || counters.ReadFailures != 1 {
+		t.Errorf("got %d operations with %d connect, %d write and %d read failures, want 5 with 1 each",
+			counters.Operations, counters.ConnectFailures, counters.WriteFailures, counters.ReadFailures)
+	}
+	if counters.Health != HealthFailing {
+		t.Errorf("health is %q with 3 of 5 failed, want %q", counters.Health, HealthFailing)
+	}
+	if counters.LastError != ErrSimulatedFailure.Error() || !counters.LastErrorAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("last error is %q at %v, want the read's", counters.LastError, counters.LastErrorAt)
+	}
+	if !counters.Since.Equal(start) {
+		t.Errorf("counting since %v, want %v", counters.Since, start)
+	}
+
+	fake.Advance(time.Minute)
+	station.ResetHealth()
+	counters = station.HealthCounters()
+	if counters.Operations != 0 || counters.ConnectFailures != 0 || counters.WriteFailures != 0 || counters.ReadFailures != 0 || counters.LastError != "" {
+		t.Errorf("counters weren't reset: %+v", counters)
+	}
+	if counters.Health != HealthOK || !counters.Since.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("after the reset health is %q counting since %v", counters.Health, counters.Since)
+	}
+}
+
+// The connects, writes and reads of a command are counted.
+func TestHealthCountsCommand(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	fake := useFakeClock(t)
+	b := NewFakeBackend(FakeStation{Address: address, RawPower: RawPowerStateSleep, ConnectFailures: 1})
+	useFakeBackend(t, b)
+
+	station := NewStation(fakeName(address), address)
+	t.Cleanup(func() { DisconnectStation(station) })
+	done := make(chan error, 1)
+	go func() { done <- PowerOn(context.Background(), station) }()
+	if err := advanceUntil(t, fake, 100*time.Millisecond, done); err != nil {
+		t.Fatalf("power on: %v", err)
+	}
+	counters := station.HealthCounters()
+	if counters.ConnectFailures != 1 || counters.WriteFailures != 0 || counters.ReadFailures != 0 {
+		t.Errorf("got %d connect, %d write and %d read failures, want only the failed connect",
+			counters.ConnectFailures, counters.WriteFailures, counters.ReadFailures)
+	}
+	// Both connects, the write and at least one read-back
+	if counters.Operations < 4 {
+		t.Errorf("counted %d operations, want at least 4", counters.Operations)
+	}
+
+	station.mutex.Lock()
+	station.characteristic = &readStub{}
+	station.mutex.Unlock()
+	if err := readPowerStateInternal(context.Background(), station); err == nil {
+		t.Fatal("empty read succeeded")
+	}
+	if counters := station.HealthCounters(); counters.ReadFailures != 1 {
+		t.Errorf("got %d read failures after an empty read, want 1", counters.ReadFailures)
+	}
+}
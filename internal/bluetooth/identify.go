@@ -0,0 +1,46 @@
+package bluetooth
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Identify makes the station's status LED blink so it can be found among
+// stations with similar names. Stations without the identify characteristic,
+// V1 stations and V2 stations with older firmware, fail with ErrUnsupported.
+func Identify(ctx context.Context, station *BaseStation) error {
+	if station == nil {
+		return fmt.Errorf("station is nil")
+	}
+	if station.Protocol == ProtocolV1 {
+		return stationError(station.Name, ErrUnsupported, fmt.Errorf("%s is a SteamVR 1.0 station, which can't be identified", station.Name))
+	}
+	if err := station.lockOp(ctx); err != nil {
+		return err
+	}
+	defer station.unlockOp()
+	defer releaseInternal(station)
+
+	if err := connectAndDiscoverInternal(ctx, station); err != nil {
+		return err
+	}
+	station.mutex.RLock()
+	device := station.device
+	station.mutex.RUnlock()
+
+	services, err := discoverServices(ctx, station.Name, device, powerControlServiceUUID)
+	if err != nil || len(services) == 0 {
+		return stationError(station.Name, ErrDiscovery, fmt.Errorf("power control service not found on %s: %v", station.Name, err))
+	}
+	chars, err := discoverCharacteristics(ctx, station.Name, services[0], identifyCharacteristicUUID)
+	if err != nil || len(chars) == 0 {
+		return stationError(station.Name, ErrUnsupported, fmt.Errorf("%s has no identify characteristic, its firmware may be too old", station.Name))
+	}
+
+	log.Printf("Bluetooth: Sending identify to %s", station.Name)
+	if _, err := chars[0].Write([]byte{0x01}); err != nil {
+		return writeError(station.Name, 1, fmt.Errorf("failed to write identify for %s: %w", station.Name, err))
+	}
+	return nil
+}
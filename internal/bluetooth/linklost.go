@@ -0,0 +1,31 @@
+package bluetooth
+
+import "log"
+
+var linkLostHandler func(station *BaseStation) // Guarded by handlerMutex
+
+// SetLinkLostHandler sets the function called when a connected station
+// stopped answering or ended the connection itself and was disconnected,
+// e.g. because it went out of range. Deliberate disconnects don't call it. It runs on the goroutine of the
+// failed operation, with the station's operation lock held, and must not
+// block.
+func SetLinkLostHandler(handler func(station *BaseStation)) {
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+	linkLostHandler = handler
+}
+
+// dropLinkInternal disconnects a station whose link failed and reports it
+// through the link-lost handler.
+// Assumes caller holds the operation lock (station.lockOp).
+func dropLinkInternal(station *BaseStation, cause error) {
+	log.Printf("Bluetooth: Lost the connection to %s: %v", station.Name, cause)
+	disconnectInternal(station)
+
+	handlerMutex.RLock()
+	handler := linkLostHandler
+	handlerMutex.RUnlock()
+	if handler != nil {
+		handler(station)
+	}
+}
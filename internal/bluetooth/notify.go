@@ -0,0 +1,78 @@
+package bluetooth
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// NotificationStaleAfter is how long a notifying station's power state is
+// trusted without a read. Stations only notify on changes, so a missed
+// notification is corrected by the next read after this.
+const NotificationStaleAfter = 60 * time.Second
+
+var (
+	handlerMutex      sync.RWMutex
+	powerStateHandler func(station *BaseStation)
+)
+
+// SetPowerStateHandler sets the function called when a notification changed a
+// station's power state or readiness. It runs on the BLE stack's goroutine
+// and must not block.
+func SetPowerStateHandler(handler func(station *BaseStation)) {
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+	powerStateHandler = handler
+}
+
+// enableNotificationsInternal subscribes to the power characteristic of a V2
+// station. Stacks or firmware that refuse it leave the station on reads.
+// Assumes caller holds the operation lock (station.lockOp).
+func enableNotificationsInternal(station *BaseStation, characteristic Characteristic) {
+	err := characteristic.EnableNotifications(func(data []byte) {
+		handlePowerNotification(station, characteristic, data)
+	})
+	if err != nil {
+		log.Printf("Bluetooth: Notifications unavailable for %s, polling the power state instead: %v", station.Name, err)
+		return
+	}
+	station.mutex.Lock()
+	station.notifying = true
+	station.mutex.Unlock()
+	log.Printf("Bluetooth: Power state notifications enabled for %s.", station.Name)
+}
+
+// handlePowerNotification applies a pushed power value like a read would.
+// Notifications from a characteristic that was dropped since, e.g. by a
+// reconnect, are ignored.
+func handlePowerNotification(station *BaseStation, characteristic Characteristic, data []byte) {
+	if len(data) < 1 {
+		return
+	}
+
+	station.mutex.Lock()
+	if station.characteristic != characteristic || !station.notifying {
+		station.mutex.Unlock()
+		return
+	}
+	station.recordRawPowerInternal(data, "notification", nil)
+	newState := powerStateForRaw(data[0])
+	// A raw change alone, like spinning up, still changes readiness
+	changed := station.PowerState != newState || station.rawPowerState != int(data[0])
+	station.rawPowerState = int(data[0])
+	if station.PowerState != newState {
+		log.Printf("Bluetooth: Power state for %s changed from %d to %d (notified)", station.Name, station.PowerState, newState)
+	}
+	station.setPowerStateInternal(newState)
+	station.mutex.Unlock()
+
+	if !changed {
+		return
+	}
+	handlerMutex.RLock()
+	handler := powerStateHandler
+	handlerMutex.RUnlock()
+	if handler != nil {
+		handler(station)
+	}
+}
@@ -0,0 +1,87 @@
+package bluetooth
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// ConnectionParams tunes new BLE connections. Zero fields keep the platform
+// default. Times are in milliseconds.
+type ConnectionParams struct {
+	ConnectTimeoutMs     int `json:"connectTimeoutMs"`     // How long a connection attempt may take
+	MinIntervalMs        int `json:"minIntervalMs"`        // Shortest connection interval
+	MaxIntervalMs        int `json:"maxIntervalMs"`        // Longest connection interval
+	SupervisionTimeoutMs int `json:"supervisionTimeoutMs"` // Silence after which the link counts as lost
+}
+
+// ParamSupport tells which connection parameters this platform's BLE stack honors.
+type ParamSupport struct {
+	ConnectTimeout     bool `json:"connectTimeout"`
+	Intervals          bool `json:"intervals"`
+	SupervisionTimeout bool `json:"supervisionTimeout"`
+}
+
+var (
+	paramsMutex     sync.RWMutex
+	effectiveParams ConnectionParams
+)
+
+// SupportedParams returns the connection parameters the platform honors.
+func SupportedParams() ParamSupport {
+	return platformParamSupport
+}
+
+// SetConnectionParams sets the parameters for new connections. Fields the
+// platform ignores are dropped with a logged note instead of failing. It
+// returns the parameters that take effect.
+func SetConnectionParams(params ConnectionParams) ConnectionParams {
+	support := SupportedParams()
+	var ignored []string
+	if params.ConnectTimeoutMs != 0 && !support.ConnectTimeout {
+		ignored = append(ignored, "connectTimeoutMs")
+		params.ConnectTimeoutMs = 0
+	}
+	if (params.MinIntervalMs != 0 || params.MaxIntervalMs != 0) && !support.Intervals {
+		ignored = append(ignored, "minIntervalMs", "maxIntervalMs")
+		params.MinIntervalMs, params.MaxIntervalMs = 0, 0
+	}
+	if params.SupervisionTimeoutMs != 0 && !support.SupervisionTimeout {
+		ignored = append(ignored, "supervisionTimeoutMs")
+		params.SupervisionTimeoutMs = 0
+	}
+	if len(ignored) > 0 {
+		log.Printf("Bluetooth: The BLE stack on this platform ignores %s, using its defaults", strings.Join(ignored, ", "))
+	}
+
+	paramsMutex.Lock()
+	defer paramsMutex.Unlock()
+	if params != effectiveParams {
+		log.Printf("Bluetooth: Connection parameters now %+v", params)
+	}
+	effectiveParams = params
+	return params
+}
+
+// CurrentConnectionParams returns the parameters used for new connections.
+func CurrentConnectionParams() ConnectionParams {
+	paramsMutex.RLock()
+	defer paramsMutex.RUnlock()
+	return effectiveParams
+}
+
+// tinygoParams converts the parameters to tinygo's 0.625 ms units.
+func (p ConnectionParams) tinygoParams() bluetooth.ConnectionParams {
+	units := func(ms int) bluetooth.Duration {
+		return bluetooth.NewDuration(time.Duration(ms) * time.Millisecond)
+	}
+	return bluetooth.ConnectionParams{
+		ConnectionTimeout: units(p.ConnectTimeoutMs),
+		MinInterval:       units(p.MinIntervalMs),
+		MaxInterval:       units(p.MaxIntervalMs),
+		Timeout:           units(p.SupervisionTimeoutMs),
+	}
+}
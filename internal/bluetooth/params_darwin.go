@@ -0,0 +1,6 @@
+//go:build darwin
+
+package bluetooth
+
+// CoreBluetooth only takes the connect timeout from tinygo's parameters.
+var platformParamSupport = ParamSupport{ConnectTimeout: true}
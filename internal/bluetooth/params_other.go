@@ -0,0 +1,6 @@
+//go:build !darwin
+
+package bluetooth
+
+// The tinygo backends for BlueZ and WinRT ignore all connection parameters.
+var platformParamSupport = ParamSupport{}
@@ -0,0 +1,62 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// readStub is a power characteristic whose reads return data.
+type readStub struct {
+	data []byte
+}
+
+func (c *readStub) UUID() bluetooth.UUID { return powerControlCharacteristicUUID }
+
+func (c *readStub) Read(data []byte) (int, error) { return copy(data, c.data), nil }
+
+func (c *readStub) Write(data []byte) (int, error) { return len(data), nil }
+
+func (c *readStub) WriteWithoutResponse(data []byte) (int, error) { return len(data), nil }
+
+func (c *readStub) EnableNotifications(func(data []byte)) error { return nil }
+
+func TestReadPowerStateLengths(t *testing.T) {
+	padded := make([]byte, powerReadBufferSize)
+	padded[0] = RawPowerStateOn
+	for _, test := range []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", data: nil, want: PowerStateUnknown, wantErr: true},
+		{name: "sleep", data: []byte{RawPowerStateSleep}, want: PowerStateOff},
+		{name: "standby", data: []byte{RawPowerStateStandby}, want: PowerStateStandby},
+		{name: "on", data: []byte{RawPowerStateOn}, want: PowerStateOn},
+		{name: "spinning up", data: []byte{0x09}, want: PowerStateBooting},
+		{name: "padded to 2 bytes", data: []byte{RawPowerStateStandby, 0x00}, want: PowerStateStandby},
+		{name: "2 bytes of sleep", data: []byte{RawPowerStateSleep, 0xFF}, want: PowerStateOff},
+		{name: "whole 20-byte buffer", data: padded, want: PowerStateOn},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			station := &BaseStation{Name: "LHB-TEST", Address: "02:00:00:00:00:01", Protocol: ProtocolV2, characteristic: &readStub{data: test.data}}
+			err := readPowerStateInternal(context.Background(), station)
+			if test.wantErr {
+				if !errors.Is(err, ErrRead) {
+					t.Errorf("got %v, want ErrRead", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if state := station.GetPowerState(); state != test.want {
+				t.Errorf("state is %d, want %d", state, test.want)
+			}
+			if logged := len(test.data) > 1; station.oddReadLogged != logged {
+				t.Errorf("odd read logged is %v, want %v", station.oddReadLogged, logged)
+			}
+		})
+	}
+}
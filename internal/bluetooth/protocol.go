@@ -0,0 +1,106 @@
+package bluetooth
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// Protocol is the BLE protocol generation a station speaks.
+type Protocol int
+
+const (
+	// ProtocolV1 is spoken by the HTC SteamVR 1.0 base stations, which
+	// advertise as "HTC BS XXXXXX".
+	ProtocolV1 Protocol = 1
+	// ProtocolV2 is spoken by the Valve SteamVR 2.0 base stations, which
+	// advertise as "LHB-XXXXXXXX".
+	ProtocolV2 Protocol = 2
+)
+
+// Name prefixes the generations advertise with
+const (
+	v1NamePrefix = "HTC BS "
+	v2NamePrefix = "LHB-"
+)
+
+var (
+	// V1 power service and its command characteristic
+	v1ServiceUUID        = bluetooth.New16BitUUID(0xcb00)
+	v1CharacteristicUUID = bluetooth.New16BitUUID(0xcb01)
+)
+
+// V1 command layout: a 20-byte write starting with the 0x12 opcode
+const (
+	v1CommandLength = 20
+	v1Opcode        = 0x12
+	v1ModeOn        = 0x00
+	v1ModeSleep     = 0x02
+)
+
+// ProtocolForName returns the protocol of a station from its advertised name.
+func ProtocolForName(name string) Protocol {
+	if strings.HasPrefix(name, v1NamePrefix) {
+		return ProtocolV1
+	}
+	return ProtocolV2
+}
+
+// v1UniqueID returns the unique ID a V1 station needs in every command. It
+// is the hex number at the end of the advertised name.
+func v1UniqueID(name string) (uint32, error) {
+	suffix := strings.TrimSpace(strings.TrimPrefix(name, v1NamePrefix))
+	id, err := strconv.ParseUint(suffix, 16, 32)
+	if err != nil || suffix == "" {
+		return 0, fmt.Errorf("can't read the unique ID from the name '%s'", name)
+	}
+	return uint32(id), nil
+}
+
+// v1PowerCommand builds the command that wakes a V1 station up or puts it
+// to sleep. Byte 1 is the mode, bytes 2-3 a timeout in seconds (big endian,
+// 0 for none) and bytes 4-7 the station's unique ID (little endian).
+func v1PowerCommand(on bool, id uint32) []byte {
+	cmd := make([]byte, v1CommandLength)
+	cmd[0] = v1Opcode
+	if on {
+		cmd[1] = v1ModeOn
+	} else {
+		cmd[1] = v1ModeSleep
+		binary.BigEndian.PutUint16(cmd[2:4], 1)
+	}
+	binary.LittleEndian.PutUint32(cmd[4:8], id)
+	return cmd
+}
+
+// powerUUIDs returns the service and characteristic power commands go to.
+func (bs *BaseStation) powerUUIDs() (bluetooth.UUID, bluetooth.UUID) {
+	if bs.Protocol == ProtocolV1 {
+		return v1ServiceUUID, v1CharacteristicUUID
+	}
+	return powerControlServiceUUID, powerControlCharacteristicUUID
+}
+
+// powerCommand returns the bytes that set the station to a power state.
+func (bs *BaseStation) powerCommand(state int) ([]byte, error) {
+	if bs.Protocol == ProtocolV1 {
+		if state == PowerStateStandby {
+			return nil, fmt.Errorf("%s is a SteamVR 1.0 station, which has no standby mode", bs.Name)
+		}
+		id, err := v1UniqueID(bs.Name)
+		if err != nil {
+			return nil, err
+		}
+		return v1PowerCommand(state == PowerStateOn, id), nil
+	}
+	switch state {
+	case PowerStateOn:
+		return []byte{0x01}, nil
+	case PowerStateStandby:
+		return []byte{RawPowerStateStandby}, nil
+	}
+	return []byte{0x00}, nil
+}
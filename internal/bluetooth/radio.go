@@ -0,0 +1,31 @@
+package bluetooth
+
+// RadioAvailable reports whether the selected adapter is present and turned
+// on. supported is false where the platform can't tell, and when the stations
+// are replayed from a transcript instead of reached through the adapter.
+func RadioAvailable() (available, supported bool) {
+	if !usesSystemAdapter() {
+		return false, false
+	}
+	_, id := systemBackend.current()
+	if id == "" {
+		id = defaultAdapterID
+	}
+	return platformRadioAvailable(id)
+}
+
+// usesSystemAdapter reports whether the active backend, under any recorder or
+// tracer, is the system adapter.
+func usesSystemAdapter() bool {
+	b := backend
+	for {
+		switch w := b.(type) {
+		case *Recorder:
+			b = w.inner
+		case *Tracer:
+			b = w.inner
+		default:
+			return b == defaultBackend
+		}
+	}
+}
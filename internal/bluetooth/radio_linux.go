@@ -0,0 +1,22 @@
+//go:build linux
+
+package bluetooth
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// platformRadioAvailable asks BlueZ whether the adapter exists and is
+// powered. A stopped bluetoothd counts as unavailable too.
+func platformRadioAvailable(id string) (available, supported bool) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return false, false
+	}
+	variant, err := conn.Object("org.bluez", dbus.ObjectPath("/org/bluez/"+id)).GetProperty("org.bluez.Adapter1.Powered")
+	if err != nil {
+		return false, true
+	}
+	powered, _ := variant.Value().(bool)
+	return powered, true
+}
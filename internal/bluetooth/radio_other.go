@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package bluetooth
+
+// platformRadioAvailable can't tell on macOS: tinygo doesn't expose the
+// CoreBluetooth state after Enable. A failing scan still reports the adapter
+// as unavailable there.
+func platformRadioAvailable(string) (available, supported bool) {
+	return false, false
+}
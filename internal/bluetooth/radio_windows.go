@@ -0,0 +1,36 @@
+//go:build windows
+
+package bluetooth
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	bthprops                    = syscall.NewLazyDLL("bthprops.cpl")
+	procBluetoothFindFirstRadio = bthprops.NewProc("BluetoothFindFirstRadio")
+	procBluetoothFindRadioClose = bthprops.NewProc("BluetoothFindRadioClose")
+)
+
+// BLUETOOTH_FIND_RADIO_PARAMS
+type bluetoothFindRadioParams struct {
+	size uint32
+}
+
+// platformRadioAvailable looks for a Bluetooth radio with the classic
+// Bluetooth API, which WinRT has no synchronous equivalent for.
+func platformRadioAvailable(string) (available, supported bool) {
+	if procBluetoothFindFirstRadio.Find() != nil || procBluetoothFindRadioClose.Find() != nil {
+		return false, false
+	}
+	params := bluetoothFindRadioParams{size: uint32(unsafe.Sizeof(bluetoothFindRadioParams{}))}
+	var radio syscall.Handle
+	find, _, _ := procBluetoothFindFirstRadio.Call(uintptr(unsafe.Pointer(&params)), uintptr(unsafe.Pointer(&radio)))
+	if find == 0 {
+		return false, true
+	}
+	_ = syscall.CloseHandle(radio)
+	_, _, _ = procBluetoothFindRadioClose.Call(find)
+	return true, true
+}
@@ -0,0 +1,84 @@
+package bluetooth
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// MaxRawPayload caps the size of a raw characteristic write.
+const MaxRawPayload = 64
+
+// RawWriteResult is the outcome of a raw characteristic write.
+type RawWriteResult struct {
+	Written   int    `json:"written"`
+	ReadBack  string `json:"readBack,omitempty"`  // Hex encoded value read after the write
+	ReadError string `json:"readError,omitempty"` // Set when the characteristic couldn't be read back
+}
+
+// WriteRawCharacteristic writes payload to any characteristic of a station and
+// reads the value back. It is a debugging aid for protocol exploration; it
+// shares the connection and operation lock used by power commands.
+func WriteRawCharacteristic(ctx context.Context, station *BaseStation, serviceUUID, charUUID string, payload []byte, withResponse bool) (RawWriteResult, error) {
+	if station == nil {
+		return RawWriteResult{}, fmt.Errorf("station is nil")
+	}
+	if len(payload) == 0 || len(payload) > MaxRawPayload {
+		return RawWriteResult{}, fmt.Errorf("payload must be 1 to %d bytes", MaxRawPayload)
+	}
+	service, err := bluetooth.ParseUUID(serviceUUID)
+	if err != nil {
+		return RawWriteResult{}, fmt.Errorf("invalid service UUID '%s': %w", serviceUUID, err)
+	}
+	characteristic, err := bluetooth.ParseUUID(charUUID)
+	if err != nil {
+		return RawWriteResult{}, fmt.Errorf("invalid characteristic UUID '%s': %w", charUUID, err)
+	}
+
+	if err := station.lockOp(ctx); err != nil {
+		return RawWriteResult{}, err
+	}
+	defer station.unlockOp()
+
+	if err := connectAndDiscoverInternal(ctx, station); err != nil {
+		return RawWriteResult{}, fmt.Errorf("failed to connect to %s: %w", station.Name, err)
+	}
+	station.mutex.RLock()
+	device := station.device
+	station.mutex.RUnlock()
+
+	services, err := device.DiscoverServices([]bluetooth.UUID{service})
+	if err != nil || len(services) == 0 {
+		return RawWriteResult{}, fmt.Errorf("service %s not found on %s: %v", serviceUUID, station.Name, err)
+	}
+	chars, err := services[0].DiscoverCharacteristics([]bluetooth.UUID{characteristic})
+	if err != nil || len(chars) == 0 {
+		return RawWriteResult{}, fmt.Errorf("characteristic %s not found on %s: %v", charUUID, station.Name, err)
+	}
+
+	log.Printf("Bluetooth: DEBUG RAW WRITE to %s (%s) service %s characteristic %s: %s (withResponse=%v)",
+		station.Name, station.Address.String(), serviceUUID, charUUID, hex.EncodeToString(payload), withResponse)
+	var result RawWriteResult
+	if withResponse {
+		result.Written, err = chars[0].Write(payload)
+	} else {
+		result.Written, err = chars[0].WriteWithoutResponse(payload)
+	}
+	if err != nil {
+		log.Printf("Bluetooth: DEBUG RAW WRITE to %s failed: %v", station.Name, err)
+		return result, fmt.Errorf("write failed: %w", err)
+	}
+
+	buf := make([]byte, 512)
+	n, err := chars[0].Read(buf)
+	if err != nil {
+		result.ReadError = err.Error()
+	} else {
+		result.ReadBack = hex.EncodeToString(buf[:n])
+	}
+	log.Printf("Bluetooth: DEBUG RAW WRITE to %s wrote %d byte(s), read back '%s'", station.Name, result.Written, result.ReadBack)
+	return result, nil
+}
@@ -0,0 +1,213 @@
+package bluetooth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// Transcript operations
+const (
+	opEnable          = "enable"
+	opScan            = "scan"
+	opStopScan        = "stop-scan"
+	opConnect         = "connect"
+	opDiscoverService = "discover-services"
+	opDiscoverChars   = "discover-characteristics"
+	opRead            = "read"
+	opWrite           = "write"
+	opWriteNoResponse = "write-without-response"
+	opEnableNotify    = "enable-notifications"
+	opNotification    = "notification" // Value pushed by the station, recorded for inspection only
+	opDisconnect      = "disconnect"
+)
+
+// TranscriptEntry is one BLE operation and its result, stored as a JSON line.
+type TranscriptEntry struct {
+	Op             string          `json:"op"`
+	Address        string          `json:"address,omitempty"`
+	Characteristic string          `json:"characteristic,omitempty"`
+	UUIDs          []string        `json:"uuids,omitempty"`          // Requested service or characteristic UUIDs
+	Data           string          `json:"data,omitempty"`           // Hex encoded bytes read or written
+	N              int             `json:"n,omitempty"`              // Bytes read or written
+	Found          []string        `json:"found,omitempty"`          // UUIDs of discovered characteristics, or one entry per service
+	Advertisements []Advertisement `json:"advertisements,omitempty"` // Results seen during a scan
+	Error          string          `json:"error,omitempty"`
+	StartMs        int64           `json:"startMs"`    // Since the recording started
+	DurationMs     int64           `json:"durationMs"` // How long the operation took
+}
+
+// Recorder is a Backend that passes every call to another backend and appends
+// the call and its result to a transcript.
+type Recorder struct {
+	inner Backend
+	start time.Time
+
+	mu  sync.Mutex
+	enc *json.Encoder
+
+	scanMu  sync.Mutex
+	scanned []Advertisement
+}
+
+// NewRecorder wraps inner and writes the transcript to w.
+func NewRecorder(inner Backend, w io.Writer) *Recorder {
+	return &Recorder{inner: inner, start: clk.Now(), enc: json.NewEncoder(w)}
+}
+
+func (r *Recorder) record(entry TranscriptEntry, started time.Time, err error) {
+	entry.StartMs = started.Sub(r.start).Milliseconds()
+	entry.DurationMs = clk.Now().Sub(started).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if encErr := r.enc.Encode(entry); encErr != nil {
+		log.Printf("Bluetooth: Failed to write transcript entry: %v", encErr)
+	}
+}
+
+func (r *Recorder) Enable() error {
+	started := clk.Now()
+	err := r.inner.Enable()
+	r.record(TranscriptEntry{Op: opEnable}, started, err)
+	return err
+}
+
+func (r *Recorder) Scan(onResult func(Advertisement)) error {
+	started := clk.Now()
+	r.scanMu.Lock()
+	r.scanned = nil
+	r.scanMu.Unlock()
+	err := r.inner.Scan(func(adv Advertisement) {
+		r.scanMu.Lock()
+		r.scanned = append(r.scanned, adv)
+		r.scanMu.Unlock()
+		onResult(adv)
+	})
+	r.scanMu.Lock()
+	advertisements := r.scanned
+	r.scanMu.Unlock()
+	r.record(TranscriptEntry{Op: opScan, Advertisements: advertisements}, started, err)
+	return err
+}
+
+func (r *Recorder) StopScan() error {
+	started := clk.Now()
+	err := r.inner.StopScan()
+	r.record(TranscriptEntry{Op: opStopScan}, started, err)
+	return err
+}
+
+func (r *Recorder) Connect(address Address, params ConnectionParams) (Device, error) {
+	started := clk.Now()
+	device, err := r.inner.Connect(address, params)
+	r.record(TranscriptEntry{Op: opConnect, Address: address.String()}, started, err)
+	if err != nil {
+		return nil, err
+	}
+	return &recordedDevice{r: r, address: address.String(), inner: device}, nil
+}
+
+type recordedDevice struct {
+	r       *Recorder
+	address string
+	inner   Device
+}
+
+func (d *recordedDevice) DiscoverServices(uuids []bluetooth.UUID) ([]Service, error) {
+	started := clk.Now()
+	services, err := d.inner.DiscoverServices(uuids)
+	entry := TranscriptEntry{Op: opDiscoverService, Address: d.address, UUIDs: uuidStrings(uuids), N: len(services)}
+	d.r.record(entry, started, err)
+	wrapped := make([]Service, len(services))
+	for i, service := range services {
+		wrapped[i] = &recordedService{r: d.r, address: d.address, inner: service}
+	}
+	return wrapped, err
+}
+
+func (d *recordedDevice) Disconnect() error {
+	started := clk.Now()
+	err := d.inner.Disconnect()
+	d.r.record(TranscriptEntry{Op: opDisconnect, Address: d.address}, started, err)
+	return err
+}
+
+type recordedService struct {
+	r       *Recorder
+	address string
+	inner   Service
+}
+
+func (s *recordedService) DiscoverCharacteristics(uuids []bluetooth.UUID) ([]Characteristic, error) {
+	started := clk.Now()
+	chars, err := s.inner.DiscoverCharacteristics(uuids)
+	entry := TranscriptEntry{Op: opDiscoverChars, Address: s.address, UUIDs: uuidStrings(uuids)}
+	wrapped := make([]Characteristic, len(chars))
+	for i, char := range chars {
+		entry.Found = append(entry.Found, char.UUID().String())
+		wrapped[i] = &recordedCharacteristic{r: s.r, address: s.address, inner: char}
+	}
+	s.r.record(entry, started, err)
+	return wrapped, err
+}
+
+type recordedCharacteristic struct {
+	r       *Recorder
+	address string
+	inner   Characteristic
+}
+
+func (c *recordedCharacteristic) UUID() bluetooth.UUID {
+	return c.inner.UUID()
+}
+
+func (c *recordedCharacteristic) Read(data []byte) (int, error) {
+	started := clk.Now()
+	n, err := c.inner.Read(data)
+	entry := TranscriptEntry{Op: opRead, Address: c.address, Characteristic: c.UUID().String(), N: n}
+	if n > 0 {
+		entry.Data = hex.EncodeToString(data[:n])
+	}
+	c.r.record(entry, started, err)
+	return n, err
+}
+
+func (c *recordedCharacteristic) Write(data []byte) (int, error) {
+	started := clk.Now()
+	n, err := c.inner.Write(data)
+	c.r.record(TranscriptEntry{Op: opWrite, Address: c.address, Characteristic: c.UUID().String(), Data: hex.EncodeToString(data), N: n}, started, err)
+	return n, err
+}
+
+func (c *recordedCharacteristic) WriteWithoutResponse(data []byte) (int, error) {
+	started := clk.Now()
+	n, err := c.inner.WriteWithoutResponse(data)
+	c.r.record(TranscriptEntry{Op: opWriteNoResponse, Address: c.address, Characteristic: c.UUID().String(), Data: hex.EncodeToString(data), N: n}, started, err)
+	return n, err
+}
+
+func (c *recordedCharacteristic) EnableNotifications(callback func(data []byte)) error {
+	started := clk.Now()
+	err := c.inner.EnableNotifications(func(data []byte) {
+		c.r.record(TranscriptEntry{Op: opNotification, Address: c.address, Characteristic: c.UUID().String(), Data: hex.EncodeToString(data), N: len(data)}, clk.Now(), nil)
+		callback(data)
+	})
+	c.r.record(TranscriptEntry{Op: opEnableNotify, Address: c.address, Characteristic: c.UUID().String()}, started, err)
+	return err
+}
+
+func uuidStrings(uuids []bluetooth.UUID) []string {
+	result := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		result[i] = uuid.String()
+	}
+	return result
+}
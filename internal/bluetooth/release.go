@@ -0,0 +1,33 @@
+package bluetooth
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+var disconnectAfterCommand atomic.Bool
+
+// SetDisconnectAfterCommand makes power commands, state reads and the other
+// station operations disconnect once they are done instead of keeping the
+// connection for the next one. Some adapters can't hold several connections
+// for long, and an open connection keeps SteamVR from talking to the station.
+func SetDisconnectAfterCommand(enabled bool) {
+	disconnectAfterCommand.Store(enabled)
+}
+
+// DisconnectAfterCommand returns the mode set with SetDisconnectAfterCommand.
+func DisconnectAfterCommand() bool {
+	return disconnectAfterCommand.Load()
+}
+
+// releaseInternal disconnects a station after an operation when
+// DisconnectAfterCommand is set. Unlike disconnectInternal it keeps the power
+// state and channel, they are still current.
+// Assumes caller holds the operation lock (station.lockOp).
+func releaseInternal(station *BaseStation) {
+	if !DisconnectAfterCommand() || !station.IsConnected() {
+		return
+	}
+	log.Printf("Bluetooth: Releasing the connection to %s", station.Name)
+	closeConnectionInternal(station, false)
+}
@@ -0,0 +1,203 @@
+package bluetooth
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// ErrTranscriptMismatch is returned by the replay backend when the app makes a
+// call the transcript has no matching entry for.
+var ErrTranscriptMismatch = errors.New("transcript has no matching entry")
+
+// Replayer is a Backend that answers calls from a recorded transcript instead
+// of an adapter. Entries are consumed in order per operation and address, so
+// interleaved calls to different stations replay independently.
+type Replayer struct {
+	mu      sync.Mutex
+	entries map[string][]TranscriptEntry
+
+	stopScan chan struct{}
+}
+
+// NewReplayer reads a transcript written by a Recorder.
+func NewReplayer(r io.Reader) (*Replayer, error) {
+	replayer := &Replayer{entries: make(map[string][]TranscriptEntry)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("transcript line %d: %w", line, err)
+		}
+		key := replayKey(entry.Op, entry.Address)
+		replayer.entries[key] = append(replayer.entries[key], entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return replayer, nil
+}
+
+func replayKey(op, address string) string {
+	return op + "|" + address
+}
+
+// next pops the next entry for op and address and returns its recorded error.
+func (r *Replayer) next(op, address string) (TranscriptEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := replayKey(op, address)
+	queue := r.entries[key]
+	if len(queue) == 0 {
+		if address == "" {
+			return TranscriptEntry{}, fmt.Errorf("%w: %s", ErrTranscriptMismatch, op)
+		}
+		return TranscriptEntry{}, fmt.Errorf("%w: %s %s", ErrTranscriptMismatch, op, address)
+	}
+	entry := queue[0]
+	r.entries[key] = queue[1:]
+	if entry.Error != "" {
+		return entry, errors.New(entry.Error)
+	}
+	return entry, nil
+}
+
+func (r *Replayer) Enable() error {
+	_, err := r.next(opEnable, "")
+	return err
+}
+
+// Scan delivers the recorded advertisements and then blocks until StopScan,
+// like a real adapter would.
+func (r *Replayer) Scan(onResult func(Advertisement)) error {
+	entry, err := r.next(opScan, "")
+	if err != nil && len(entry.Advertisements) == 0 {
+		return err
+	}
+	stop := make(chan struct{})
+	r.mu.Lock()
+	r.stopScan = stop
+	r.mu.Unlock()
+	for _, adv := range entry.Advertisements {
+		onResult(adv)
+	}
+	<-stop
+	return err
+}
+
+func (r *Replayer) StopScan() error {
+	r.mu.Lock()
+	if r.stopScan != nil {
+		close(r.stopScan)
+		r.stopScan = nil
+	}
+	r.mu.Unlock()
+	_, err := r.next(opStopScan, "")
+	return err
+}
+
+func (r *Replayer) Connect(address Address, _ ConnectionParams) (Device, error) {
+	if _, err := r.next(opConnect, address.String()); err != nil {
+		return nil, err
+	}
+	return &replayDevice{r: r, address: address.String()}, nil
+}
+
+type replayDevice struct {
+	r       *Replayer
+	address string
+}
+
+func (d *replayDevice) DiscoverServices(_ []bluetooth.UUID) ([]Service, error) {
+	entry, err := d.r.next(opDiscoverService, d.address)
+	services := make([]Service, entry.N)
+	for i := range services {
+		services[i] = &replayService{r: d.r, address: d.address}
+	}
+	return services, err
+}
+
+func (d *replayDevice) Disconnect() error {
+	_, err := d.r.next(opDisconnect, d.address)
+	return err
+}
+
+type replayService struct {
+	r       *Replayer
+	address string
+}
+
+func (s *replayService) DiscoverCharacteristics(_ []bluetooth.UUID) ([]Characteristic, error) {
+	entry, err := s.r.next(opDiscoverChars, s.address)
+	chars := make([]Characteristic, 0, len(entry.Found))
+	for _, found := range entry.Found {
+		uuid, parseErr := bluetooth.ParseUUID(found)
+		if parseErr != nil {
+			return nil, fmt.Errorf("transcript characteristic %q: %w", found, parseErr)
+		}
+		chars = append(chars, &replayCharacteristic{r: s.r, address: s.address, uuid: uuid})
+	}
+	return chars, err
+}
+
+type replayCharacteristic struct {
+	r       *Replayer
+	address string
+	uuid    bluetooth.UUID
+}
+
+func (c *replayCharacteristic) UUID() bluetooth.UUID {
+	return c.uuid
+}
+
+func (c *replayCharacteristic) Read(data []byte) (int, error) {
+	entry, err := c.r.next(opRead, c.address)
+	if err != nil {
+		return entry.N, err
+	}
+	recorded, decodeErr := hex.DecodeString(entry.Data)
+	if decodeErr != nil {
+		return 0, fmt.Errorf("transcript read data: %w", decodeErr)
+	}
+	return copy(data, recorded), nil
+}
+
+func (c *replayCharacteristic) Write(data []byte) (int, error) {
+	return c.replayWrite(opWrite, data)
+}
+
+func (c *replayCharacteristic) WriteWithoutResponse(data []byte) (int, error) {
+	return c.replayWrite(opWriteNoResponse, data)
+}
+
+// EnableNotifications replays the recorded result but never delivers
+// notifications, since their timing can't be reproduced. Transcripts recorded
+// before notifications were used have no entry, which fails the call and
+// makes the station fall back to reads like it did when recorded.
+func (c *replayCharacteristic) EnableNotifications(callback func(data []byte)) error {
+	_, err := c.r.next(opEnableNotify, c.address)
+	return err
+}
+
+func (c *replayCharacteristic) replayWrite(op string, data []byte) (int, error) {
+	entry, err := c.r.next(op, c.address)
+	if err != nil {
+		return entry.N, err
+	}
+	if written := hex.EncodeToString(data); written != entry.Data {
+		return 0, fmt.Errorf("%w: %s %s wrote %s, transcript has %s", ErrTranscriptMismatch, op, c.address, written, entry.Data)
+	}
+	return entry.N, nil
+}
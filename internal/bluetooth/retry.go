@@ -0,0 +1,56 @@
+package bluetooth
+
+import (
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how often a power command is tried and how long it
+// waits between the tries.
+type RetryPolicy struct {
+	Attempts       int           // Tries in total, at least 1
+	InitialDelay   time.Duration // Wait before the second try
+	Multiplier     float64       // Each later wait is this many times longer, at least 1
+	AttemptTimeout time.Duration // Bounds one try, connect and write included; 0 for no bound
+}
+
+// DefaultRetryPolicy tries twice, half a second apart.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Attempts: 2, InitialDelay: 500 * time.Millisecond, Multiplier: 1}
+}
+
+var (
+	retryMutex  sync.RWMutex
+	retryPolicy = DefaultRetryPolicy()
+)
+
+// SetRetryPolicy sets the retry policy of power commands started from now
+// on. Less than one attempt restores DefaultRetryPolicy, a multiplier below
+// one counts as one.
+func SetRetryPolicy(policy RetryPolicy) {
+	if policy.Attempts < 1 {
+		policy = DefaultRetryPolicy()
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+	retryMutex.Lock()
+	defer retryMutex.Unlock()
+	retryPolicy = policy
+}
+
+// CurrentRetryPolicy returns the policy set with SetRetryPolicy.
+func CurrentRetryPolicy() RetryPolicy {
+	retryMutex.RLock()
+	defer retryMutex.RUnlock()
+	return retryPolicy
+}
+
+// delay returns the wait before the given retry, 1 for the second try.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	delay := float64(p.InitialDelay)
+	for i := 1; i < retry; i++ {
+		delay *= p.Multiplier
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,90 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// useRetryPolicy sets the retry policy for the test.
+func useRetryPolicy(t *testing.T, policy RetryPolicy) {
+	t.Helper()
+	previous := CurrentRetryPolicy()
+	SetRetryPolicy(policy)
+	t.Cleanup(func() { SetRetryPolicy(previous) })
+}
+
+// initializeFake makes a fake backend with the given stations the backend
+// for the test and initializes the package with it.
+func initializeFake(t *testing.T, stations ...FakeStation) *FakeBackend {
+	t.Helper()
+	b := NewFakeBackend(stations...)
+	useFakeBackend(t, b)
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(DisconnectAllStations)
+	return b
+}
+
+func TestPowerOnRetriesAfterConnectFailure(t *testing.T) {
+	fake := useFakeClock(t)
+	useRetryPolicy(t, DefaultRetryPolicy())
+	const address = Address("02:00:00:00:00:01")
+	initializeFake(t, FakeStation{Address: address, RawPower: RawPowerStateSleep, ConnectFailures: 1})
+	station := NewStation(fakeName(address), address)
+
+	start := fake.Now()
+	done := make(chan error, 1)
+	go func() { done <- PowerOn(context.Background(), station) }()
+	if err := advanceUntil(t, fake, 100*time.Millisecond, done); err != nil {
+		t.Fatalf("PowerOn failed: %v", err)
+	}
+
+	if took := fake.Now().Sub(start); took < DefaultRetryPolicy().InitialDelay {
+		t.Errorf("PowerOn took %v, the retry should have waited %v", took, DefaultRetryPolicy().InitialDelay)
+	}
+	if attempts := station.ConnectionDiagnostics().Attempts; attempts != 2 {
+		t.Errorf("%d connect attempts, want 2", attempts)
+	}
+	if state := station.GetPowerState(); state != PowerStateOn {
+		t.Errorf("power state is %d, want on", state)
+	}
+}
+
+func TestPowerOnGivesUpAfterBackoff(t *testing.T) {
+	fake := useFakeClock(t)
+	useRetryPolicy(t, RetryPolicy{Attempts: 3, InitialDelay: time.Second, Multiplier: 2})
+	const address = Address("02:00:00:00:00:01")
+	initializeFake(t, FakeStation{Address: address, Unreachable: true})
+	station := NewStation(fakeName(address), address)
+
+	start := fake.Now()
+	done := make(chan error, 1)
+	go func() { done <- PowerOn(context.Background(), station) }()
+	err := advanceUntil(t, fake, 100*time.Millisecond, done)
+	if !errors.Is(err, ErrConnect) {
+		t.Fatalf("got %v, want ErrConnect", err)
+	}
+
+	// Waits of 1s and 2s between the three attempts
+	if took := fake.Now().Sub(start); took < 3*time.Second || took > 4*time.Second {
+		t.Errorf("PowerOn gave up after %v, want about 3s", took)
+	}
+	if attempts := station.ConnectionDiagnostics().Attempts; attempts != 3 {
+		t.Errorf("%d connect attempts, want 3", attempts)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Attempts: 4, InitialDelay: 100 * time.Millisecond, Multiplier: 3}
+	for retry, want := range []time.Duration{0, 100 * time.Millisecond, 300 * time.Millisecond, 900 * time.Millisecond} {
+		if retry == 0 {
+			continue
+		}
+		if got := policy.delay(retry); got != want {
+			t.Errorf("delay(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package bluetooth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScanForDuration(t *testing.T) {
+	fake := useFakeClock(t)
+	useFakeBackend(t, NewFakeBackend(
+		FakeStation{Address: "02:00:00:00:00:01", RSSI: -40, RawPower: RawPowerStateOn},
+		FakeStation{Address: "02:00:00:00:00:02", RSSI: -60, RawPower: RawPowerStateSleep},
+		FakeStation{Address: "02:00:00:00:00:03", Name: "Headphones"},
+	))
+
+	type result struct {
+		stations []*BaseStation
+		err      error
+	}
+	results := make(chan result, 1)
+	go func() {
+		stations, err := ScanForDuration(context.Background(), 5*time.Second, DefaultScanFilter())
+		results <- result{stations, err}
+	}()
+
+	// The scan runs until its stop timer fires
+	fake.BlockUntil(1)
+	fake.Advance(5*time.Second - time.Millisecond)
+	select {
+	case <-results:
+		t.Fatal("scan ended before its duration")
+	case <-time.After(10 * time.Millisecond):
+	}
+	fake.Advance(time.Millisecond)
+
+	var r result
+	select {
+	case r = <-results:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scan didn't end after its duration")
+	}
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	sort.Slice(r.stations, func(i, j int) bool { return r.stations[i].Address < r.stations[j].Address })
+	if len(r.stations) != 2 {
+		t.Fatalf("got %d stations, want the 2 lighthouses", len(r.stations))
+	}
+	want := []struct {
+		address Address
+		rssi    int16
+		state   int
+	}{
+		{"02:00:00:00:00:01", -40, PowerStateOn},
+		{"02:00:00:00:00:02", -60, PowerStateOff},
+	}
+	for i, w := range want {
+		s := r.stations[i]
+		if s.Address != w.address || s.RSSI != w.rssi || s.PowerState != w.state {
+			t.Errorf("station %d is %s with RSSI %d and state %d, want %s with %d and %d",
+				i, s.Address, s.RSSI, s.PowerState, w.address, w.rssi, w.state)
+		}
+		if s.Protocol != ProtocolV2 {
+			t.Errorf("%s has protocol %d, want V2", s.Address, s.Protocol)
+		}
+	}
+}
+
+// lockedBuffer is a transcript destination that can be read while a recorder
+// writes to it.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// ops returns the recorded operations in the order they finished.
+func (b *lockedBuffer) ops(t *testing.T) []string {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var ops []string
+	dec := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for dec.More() {
+		var entry TranscriptEntry
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		ops = append(ops, entry.Op)
+	}
+	return ops
+}
+
+// Canceling a scan stops the adapter scan right away, once, and before
+// ScanForDuration returns.
+func TestScanForDurationCancel(t *testing.T) {
+	fake := useFakeClock(t)
+	var transcript lockedBuffer
+	useFakeBackend(t, NewFakeBackend(FakeStation{Address: "02:00:00:00:00:01"}))
+	SetBackend(NewRecorder(backend, &transcript))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := ScanForDuration(ctx, 5*time.Second, DefaultScanFilter())
+		errs <- err
+	}()
+	fake.BlockUntil(1)
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("scan didn't stop when it was canceled")
+	}
+	// Both are recorded when they return, which may happen in either order
+	ops := transcript.ops(t)
+	sort.Strings(ops)
+	if got := strings.Join(ops, " "); got != "scan stop-scan" {
+		t.Errorf("adapter calls were %q, want a scan and a stop", got)
+	}
+
+	// The duration timer was stopped and doesn't stop the next scan
+	if n := fake.Pending(); n != 0 {
+		t.Errorf("%d timers still pending", n)
+	}
+	fake.Advance(5 * time.Second)
+	if ops := transcript.ops(t); len(ops) != 2 {
+		t.Errorf("adapter calls after the duration: %v", ops)
+	}
+}
@@ -0,0 +1,122 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/clock"
+)
+
+// DefaultOperationTimeout bounds a single connect, read or write when no
+// other timeout is set.
+const DefaultOperationTimeout = 10 * time.Second
+
+// DefaultDiscoveryTimeout bounds a single service or characteristic discovery
+// when no other timeout is set.
+const DefaultDiscoveryTimeout = 5 * time.Second
+
+var (
+	timeoutMutex     sync.RWMutex
+	operationTimeout = DefaultOperationTimeout
+	discoveryTimeout = DefaultDiscoveryTimeout
+)
+
+// SetOperationTimeout sets how long a single connect, read or write may block
+// before it is abandoned. Zero or less restores DefaultOperationTimeout.
+func SetOperationTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultOperationTimeout
+	}
+	timeoutMutex.Lock()
+	defer timeoutMutex.Unlock()
+	operationTimeout = timeout
+}
+
+// OperationTimeout returns the timeout set with SetOperationTimeout.
+func OperationTimeout() time.Duration {
+	timeoutMutex.RLock()
+	defer timeoutMutex.RUnlock()
+	return operationTimeout
+}
+
+// SetDiscoveryTimeout sets how long a single service or characteristic
+// discovery may block before it is abandoned and tried again. Zero or less
+// restores DefaultDiscoveryTimeout.
+func SetDiscoveryTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultDiscoveryTimeout
+	}
+	timeoutMutex.Lock()
+	defer timeoutMutex.Unlock()
+	discoveryTimeout = timeout
+}
+
+// DiscoveryTimeout returns the timeout set with SetDiscoveryTimeout.
+func DiscoveryTimeout() time.Duration {
+	timeoutMutex.RLock()
+	defer timeoutMutex.RUnlock()
+	return discoveryTimeout
+}
+
+// callWithTimeout runs a blocking BLE call on its own goroutine and waits for
+// it for at most OperationTimeout, or until ctx is done. The platform stacks
+// can block for minutes when a station went out of range, and the call
+// itself can't be interrupted, so it is abandoned instead. If an abandoned
+// call succeeds later, cleanup (if not nil) runs on its goroutine, e.g. to
+// drop a connection nobody waits for any more.
+//
+// The error of an abandoned call is an ErrTimeout when a deadline passed.
+// Callers should disconnect the station then, its link is in an unknown state.
+func callWithTimeout(ctx context.Context, name, op string, call func() error, cleanup func()) error {
+	return callWithin(ctx, OperationTimeout(), name, op, call, cleanup)
+}
+
+// callWithin is callWithTimeout with an explicit timeout.
+func callWithin(ctx context.Context, timeout time.Duration, name, op string, call func() error, cleanup func()) error {
+	ctx, cancel := clock.WithTimeout(ctx, clk, timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	abandoned := false
+	done := make(chan error, 1)
+	go func() {
+		err := call()
+		mu.Lock()
+		defer mu.Unlock()
+		if !abandoned {
+			done <- err
+			return
+		}
+		log.Printf("Bluetooth: Abandoned %s for %s finished after all: %v", op, name, err)
+		if err == nil && cleanup != nil {
+			cleanup()
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	abandoned = true
+	select {
+	case err := <-done: // Finished while ctx ended
+		mu.Unlock()
+		return err
+	default:
+	}
+	mu.Unlock()
+
+	cause := context.Cause(ctx)
+	if errors.Is(cause, context.DeadlineExceeded) {
+		log.Printf("Bluetooth: %s for %s didn't finish in time, abandoning it", op, name)
+		return stationError(name, ErrTimeout, fmt.Errorf("%s for %s timed out: %w", op, name, cause))
+	}
+	return fmt.Errorf("%s for %s abandoned: %w", op, name, cause)
+}
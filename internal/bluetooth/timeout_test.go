@@ -0,0 +1,153 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/clock"
+)
+
+// useFakeBackend makes b the backend for the test and restores the previous
+// one afterwards.
+func useFakeBackend(t *testing.T, b *FakeBackend) {
+	t.Helper()
+	previous := backend
+	SetBackend(b)
+	t.Cleanup(func() { SetBackend(previous) })
+}
+
+// useFakeClock makes a fake clock the package clock for the test.
+func useFakeClock(t *testing.T) *clock.Fake {
+	t.Helper()
+	previous := clk
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(fake)
+	t.Cleanup(func() { SetClock(previous) })
+	return fake
+}
+
+// advanceUntil advances the fake clock by step until done delivers the
+// result of the operation under test, giving it a moment to react to each
+// step.
+func advanceUntil(t *testing.T, fake *clock.Fake, step time.Duration, done <-chan error) error {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("operation didn't finish")
+		}
+		fake.Advance(step)
+	}
+}
+
+// useOperationTimeout sets the operation timeout for the test.
+func useOperationTimeout(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	previous := OperationTimeout()
+	SetOperationTimeout(timeout)
+	t.Cleanup(func() { SetOperationTimeout(previous) })
+}
+
+// waitGoroutines waits until at most n goroutines run, and fails the test if
+// there are still more after a while.
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running, want at most %d", runtime.NumGoroutine(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCallWithTimeoutAbandonedCallsDontLeak(t *testing.T) {
+	const address = Address("02:00:00:00:00:01")
+	fake := useFakeClock(t)
+	b := NewFakeBackend(FakeStation{Address: address, Latency: time.Minute})
+	useFakeBackend(t, b)
+	useOperationTimeout(t, time.Second)
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		errs := make(chan error, 1)
+		go func() {
+			var device Device
+			errs <- callWithTimeout(context.Background(), "LHB-TEST", "connect", func() error {
+				var connectErr error
+				device, connectErr = backend.Connect(address, ConnectionParams{})
+				return connectErr
+			}, func() {
+				_ = device.Disconnect()
+			})
+		}()
+		// The timeout and the connect's latency, plus those of the calls before
+		fake.BlockUntil(i + 2)
+		fake.Advance(time.Second)
+		if err := <-errs; !errors.Is(err, ErrTimeout) {
+			t.Fatalf("call %d: got %v, want ErrTimeout", i, err)
+		}
+	}
+
+	// The abandoned connects finish after the latency and their goroutines end
+	fake.Advance(time.Minute)
+	waitGoroutines(t, before)
+
+	b.mu.Lock()
+	connected := b.stations[address].connected
+	b.mu.Unlock()
+	if connected {
+		t.Error("an abandoned connect that succeeded later wasn't cleaned up")
+	}
+}
+
+func TestCallWithTimeoutInTime(t *testing.T) {
+	fake := useFakeClock(t)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- callWithin(context.Background(), time.Second, "LHB-TEST", "read", func() error {
+			clk.Sleep(999 * time.Millisecond)
+			return ErrSimulatedFailure
+		}, nil)
+	}()
+	fake.BlockUntil(2)
+	fake.Advance(999 * time.Millisecond)
+	if err := <-errs; err != ErrSimulatedFailure {
+		t.Errorf("got %v, want the call's own error", err)
+	}
+	if n := fake.Pending(); n != 0 {
+		t.Errorf("%d timers still pending", n)
+	}
+}
+
+func TestCallWithTimeoutCancel(t *testing.T) {
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	before := runtime.NumGoroutine()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	err := callWithin(ctx, time.Minute, "LHB-TEST", "read", func() error {
+		<-release
+		return nil
+	}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if errors.Is(err, ErrTimeout) {
+		t.Errorf("a cancelled call reported ErrTimeout: %v", err)
+	}
+
+	close(release)
+	waitGoroutines(t, before)
+}
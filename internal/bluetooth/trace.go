@@ -0,0 +1,244 @@
+package bluetooth
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// maxTraceSize is the size at which the trace file is rotated. One previous
+// file is kept, so a trace never takes more than twice this on disk.
+const maxTraceSize = 8 << 20
+
+// Tracer is a Backend that passes every call to another backend and writes a
+// human readable line for each operation to a trace file: scans, every
+// advertisement with whether it was accepted and why not, connection
+// attempts, discovery results, and reads and writes with their payloads and
+// durations. It is only installed when tracing is requested, so normal runs
+// pay nothing for it.
+type Tracer struct {
+	inner Backend
+
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+}
+
+// NewTracer wraps inner and writes the trace to path, rotating it to
+// <path>.1 once it reaches maxTraceSize.
+func NewTracer(inner Backend, path string) (*Tracer, error) {
+	t := &Tracer{inner: inner, path: path}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	t.logf("trace started, platform supports %+v", SupportedParams())
+	return t, nil
+}
+
+// Path returns the current trace file.
+func (t *Tracer) Path() string {
+	return t.path
+}
+
+// Close flushes and closes the trace file.
+func (t *Tracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.f == nil {
+		return nil
+	}
+	err := t.f.Close()
+	t.f = nil
+	return err
+}
+
+func (t *Tracer) open() error {
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open BLE trace '%s': %w", t.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	t.f = f
+	t.size = info.Size()
+	return nil
+}
+
+// rotateLocked moves the current file aside and starts a new one. Assumes
+// the caller holds mu.
+func (t *Tracer) rotateLocked() error {
+	t.f.Close()
+	t.f = nil
+	if err := os.Rename(t.path, t.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return t.open()
+}
+
+func (t *Tracer) logf(format string, args ...interface{}) {
+	line := clk.Now().Format("2006-01-02 15:04:05.000") + " " + fmt.Sprintf(format, args...) + "\n"
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.f == nil {
+		return
+	}
+	if t.size+int64(len(line)) > maxTraceSize {
+		if err := t.rotateLocked(); err != nil {
+			log.Printf("Bluetooth: Failed to rotate BLE trace: %v", err)
+			return
+		}
+	}
+	n, err := t.f.WriteString(line)
+	t.size += int64(n)
+	if err != nil {
+		log.Printf("Bluetooth: Failed to write BLE trace: %v", err)
+	}
+}
+
+// traceResult formats the outcome of an operation for a trace line.
+func traceResult(started time.Time, err error) string {
+	took := clk.Now().Sub(started).Round(time.Millisecond)
+	if err != nil {
+		return fmt.Sprintf("failed after %v: %v", took, err)
+	}
+	return fmt.Sprintf("ok in %v", took)
+}
+
+func (t *Tracer) Enable() error {
+	started := clk.Now()
+	err := t.inner.Enable()
+	t.logf("enable: %s", traceResult(started, err))
+	return err
+}
+
+func (t *Tracer) Scan(onResult func(Advertisement)) error {
+	started := clk.Now()
+	t.logf("scan: started")
+	var accepted, rejected atomic.Int64
+	filter := activeScanFilter()
+	err := t.inner.Scan(func(adv Advertisement) {
+		if ok, reason := filter.accept(adv); ok {
+			accepted.Add(1)
+			t.logf("scan: accepted %s %q rssi %d", adv.Address, adv.LocalName, adv.RSSI)
+		} else {
+			rejected.Add(1)
+			t.logf("scan: rejected %s %q rssi %d: %s", adv.Address, adv.LocalName, adv.RSSI, reason)
+		}
+		onResult(adv)
+	})
+	t.logf("scan: ended, %d accepted, %d rejected advertisement(s), %s", accepted.Load(), rejected.Load(), traceResult(started, err))
+	return err
+}
+
+func (t *Tracer) StopScan() error {
+	started := clk.Now()
+	err := t.inner.StopScan()
+	t.logf("stop-scan: %s", traceResult(started, err))
+	return err
+}
+
+func (t *Tracer) Connect(address Address, params ConnectionParams) (Device, error) {
+	started := clk.Now()
+	t.logf("connect %s: attempt with %+v", address, params)
+	device, err := t.inner.Connect(address, params)
+	t.logf("connect %s: %s", address, traceResult(started, err))
+	if err != nil {
+		return nil, err
+	}
+	return &tracedDevice{t: t, address: address, inner: device}, nil
+}
+
+type tracedDevice struct {
+	t       *Tracer
+	address Address
+	inner   Device
+}
+
+func (d *tracedDevice) DiscoverServices(uuids []bluetooth.UUID) ([]Service, error) {
+	started := clk.Now()
+	services, err := d.inner.DiscoverServices(uuids)
+	d.t.logf("discover-services %s [%s]: %d found, %s", d.address, strings.Join(uuidStrings(uuids), " "), len(services), traceResult(started, err))
+	wrapped := make([]Service, len(services))
+	for i, service := range services {
+		wrapped[i] = &tracedService{t: d.t, address: d.address, inner: service}
+	}
+	return wrapped, err
+}
+
+func (d *tracedDevice) Disconnect() error {
+	started := clk.Now()
+	err := d.inner.Disconnect()
+	d.t.logf("disconnect %s: %s", d.address, traceResult(started, err))
+	return err
+}
+
+type tracedService struct {
+	t       *Tracer
+	address Address
+	inner   Service
+}
+
+func (s *tracedService) DiscoverCharacteristics(uuids []bluetooth.UUID) ([]Characteristic, error) {
+	started := clk.Now()
+	chars, err := s.inner.DiscoverCharacteristics(uuids)
+	found := make([]string, len(chars))
+	wrapped := make([]Characteristic, len(chars))
+	for i, char := range chars {
+		found[i] = char.UUID().String()
+		wrapped[i] = &tracedCharacteristic{t: s.t, address: s.address, inner: char}
+	}
+	s.t.logf("discover-characteristics %s [%s]: found [%s], %s", s.address, strings.Join(uuidStrings(uuids), " "), strings.Join(found, " "), traceResult(started, err))
+	return wrapped, err
+}
+
+type tracedCharacteristic struct {
+	t       *Tracer
+	address Address
+	inner   Characteristic
+}
+
+func (c *tracedCharacteristic) UUID() bluetooth.UUID {
+	return c.inner.UUID()
+}
+
+func (c *tracedCharacteristic) Read(data []byte) (int, error) {
+	started := clk.Now()
+	n, err := c.inner.Read(data)
+	c.t.logf("read %s %s: %d byte(s) [%s], %s", c.address, c.UUID(), n, hex.EncodeToString(data[:max(n, 0)]), traceResult(started, err))
+	return n, err
+}
+
+func (c *tracedCharacteristic) Write(data []byte) (int, error) {
+	started := clk.Now()
+	n, err := c.inner.Write(data)
+	c.t.logf("write %s %s: [%s], %d byte(s) written, %s", c.address, c.UUID(), hex.EncodeToString(data), n, traceResult(started, err))
+	return n, err
+}
+
+func (c *tracedCharacteristic) WriteWithoutResponse(data []byte) (int, error) {
+	started := clk.Now()
+	n, err := c.inner.WriteWithoutResponse(data)
+	c.t.logf("write-without-response %s %s: [%s], %d byte(s) written, %s", c.address, c.UUID(), hex.EncodeToString(data), n, traceResult(started, err))
+	return n, err
+}
+
+func (c *tracedCharacteristic) EnableNotifications(callback func(data []byte)) error {
+	started := clk.Now()
+	err := c.inner.EnableNotifications(func(data []byte) {
+		c.t.logf("notification %s %s: [%s]", c.address, c.UUID(), hex.EncodeToString(data))
+		callback(data)
+	})
+	c.t.logf("enable-notifications %s %s: %s", c.address, c.UUID(), traceResult(started, err))
+	return err
+}
@@ -0,0 +1,46 @@
+package bluetooth
+
+import (
+	"log"
+	"strings"
+)
+
+// writePayload writes a power command payload to the power characteristic.
+// Unless withResponse is set it is sent without response first. Some BlueZ
+// versions reject that, or accept it and send nothing, which shows as a short
+// write; the acknowledged Write is tried then, and if it goes out the station
+// uses it for its later commands.
+// Assumes caller holds the operation lock (station.lockOp).
+func writePayload(station *BaseStation, characteristic Characteristic, payload []byte, label string, withResponse bool) (int, error) {
+	if withResponse {
+		log.Printf("Bluetooth: Sending Power %s command to %s using Write", label, station.Name)
+		return characteristic.Write(payload)
+	}
+
+	log.Printf("Bluetooth: Sending Power %s command to %s using WriteWithoutResponse", label, station.Name)
+	n, err := characteristic.WriteWithoutResponse(payload)
+	switch {
+	case err == nil && n == len(payload):
+		return n, nil
+	case err == nil:
+		log.Printf("Bluetooth: WriteWithoutResponse wrote %d of %d bytes for Power %s on %s, attempting standard Write...", n, len(payload), label, station.Name)
+	case strings.Contains(err.Error(), "not supported"):
+		log.Printf("Bluetooth: WriteWithoutResponse not supported for %s (%v), attempting standard Write...", station.Name, err)
+	default:
+		return n, err
+	}
+
+	n, err = characteristic.Write(payload)
+	if err != nil {
+		return n, err
+	}
+	if n != len(payload) {
+		log.Printf("Bluetooth: Warning - wrote %d bytes instead of %d for Power %s on %s", n, len(payload), label, station.Name)
+		return n, nil
+	}
+	station.mutex.Lock()
+	station.writeWithResponse = true
+	station.mutex.Unlock()
+	log.Printf("Bluetooth: Standard Write worked for %s, using it for later power commands", station.Name)
+	return n, nil
+}
@@ -0,0 +1,103 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+// writeStub is a power characteristic that counts its writes. Writes
+// without response return unacknowledgedN and unacknowledgedErr.
+type writeStub struct {
+	unacknowledgedN   int
+	unacknowledgedErr error
+	writes            int
+	unacknowledged    int
+}
+
+func (c *writeStub) UUID() bluetooth.UUID { return powerControlCharacteristicUUID }
+
+func (c *writeStub) Read(data []byte) (int, error) { return 0, nil }
+
+func (c *writeStub) Write(data []byte) (int, error) {
+	c.writes++
+	return len(data), nil
+}
+
+func (c *writeStub) WriteWithoutResponse(data []byte) (int, error) {
+	c.unacknowledged++
+	return c.unacknowledgedN, c.unacknowledgedErr
+}
+
+func (c *writeStub) EnableNotifications(func(data []byte)) error { return nil }
+
+func TestWritePayload(t *testing.T) {
+	for _, test := range []struct {
+		name                 string
+		withResponse         bool
+		stub                 writeStub
+		wantErr              bool
+		wantUnacknowledged   int
+		wantWrites           int
+		wantWithResponseNext bool
+	}{
+		{name: "without response", stub: writeStub{unacknowledgedN: 1}, wantUnacknowledged: 1},
+		{name: "short write falls back", stub: writeStub{unacknowledgedN: 0}, wantUnacknowledged: 1, wantWrites: 1, wantWithResponseNext: true},
+		{name: "unsupported falls back", stub: writeStub{unacknowledgedErr: errors.New("operation not supported")}, wantUnacknowledged: 1, wantWrites: 1, wantWithResponseNext: true},
+		{name: "other error fails", stub: writeStub{unacknowledgedErr: ErrSimulatedFailure}, wantErr: true, wantUnacknowledged: 1},
+		{name: "with response", withResponse: true, wantWrites: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			station := &BaseStation{Name: "LHB-TEST", Address: "02:00:00:00:00:01", Protocol: ProtocolV2}
+			stub := test.stub
+			_, err := writePayload(station, &stub, []byte{RawPowerStateOn}, "ON", test.withResponse)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if stub.unacknowledged != test.wantUnacknowledged || stub.writes != test.wantWrites {
+				t.Errorf("wrote %d times without and %d times with response, want %d and %d",
+					stub.unacknowledged, stub.writes, test.wantUnacknowledged, test.wantWrites)
+			}
+			if station.writeWithResponse != test.wantWithResponseNext {
+				t.Errorf("later commands use Write: %v, want %v", station.writeWithResponse, test.wantWithResponseNext)
+			}
+		})
+	}
+}
+
+// A station whose writes without response go nowhere is still powered on,
+// and remembers to use Write.
+func TestPowerOnDroppedUnacknowledgedWrite(t *testing.T) {
+	const (
+		dropping = Address("02:00:00:00:00:01")
+		working  = Address("02:00:00:00:00:02")
+	)
+	fake := useFakeClock(t)
+	b := NewFakeBackend(
+		FakeStation{Address: dropping, RawPower: RawPowerStateSleep, DropsUnacknowledged: true},
+		FakeStation{Address: working, RawPower: RawPowerStateSleep},
+	)
+	useFakeBackend(t, b)
+
+	for _, address := range []Address{dropping, working} {
+		station := NewStation(fakeName(address), address)
+		t.Cleanup(func() { DisconnectStation(station) })
+		done := make(chan error, 1)
+		go func() { done <- PowerOn(context.Background(), station) }()
+		if err := advanceUntil(t, fake, 100*time.Millisecond, done); err != nil {
+			t.Fatalf("power on %s: %v", address, err)
+		}
+		if state := station.GetPowerState(); !IsPoweredOn(state) {
+			t.Errorf("%s is in state %d after power on", address, state)
+		}
+		station.mutex.RLock()
+		withResponse := station.writeWithResponse
+		station.mutex.RUnlock()
+		if want := address == dropping; withResponse != want {
+			t.Errorf("%s uses Write for later commands: %v, want %v", address, withResponse, want)
+		}
+	}
+}
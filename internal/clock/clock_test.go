@@ -0,0 +1,159 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// received reports whether c has a value ready.
+func received(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(start)
+	timer := f.NewTimer(time.Second)
+
+	f.Advance(999 * time.Millisecond)
+	if received(timer.C()) {
+		t.Fatal("timer fired early")
+	}
+	f.Advance(time.Millisecond)
+	if !received(timer.C()) {
+		t.Fatal("timer didn't fire when due")
+	}
+	if got := f.Now(); !got.Equal(start.Add(time.Second)) {
+		t.Errorf("Now() = %v, want %v", got, start.Add(time.Second))
+	}
+	if timer.Stop() {
+		t.Error("Stop reported a fired timer as pending")
+	}
+}
+
+func TestFakeTimerStopAndReset(t *testing.T) {
+	f := NewFake(start)
+	timer := f.NewTimer(time.Second)
+	if !timer.Stop() {
+		t.Fatal("Stop didn't report the pending timer")
+	}
+	f.Advance(time.Hour)
+	if received(timer.C()) {
+		t.Fatal("stopped timer fired")
+	}
+
+	if timer.Reset(time.Minute) {
+		t.Error("Reset reported a stopped timer as pending")
+	}
+	f.Advance(time.Minute)
+	if !received(timer.C()) {
+		t.Fatal("reset timer didn't fire")
+	}
+}
+
+func TestFakeAdvanceFiresInOrder(t *testing.T) {
+	f := NewFake(start)
+	fired := make(chan time.Duration, 3)
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second} {
+		f.AfterFunc(d, func() { fired <- d })
+	}
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		f.Advance(time.Second)
+		select {
+		case got := <-fired:
+			if got != want {
+				t.Fatalf("timer of %v fired, want %v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timer of %v didn't fire", want)
+		}
+	}
+	if n := f.Pending(); n != 0 {
+		t.Errorf("%d timers still pending", n)
+	}
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFake(start)
+	done := make(chan struct{})
+	go func() {
+		f.Sleep(time.Minute)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(30 * time.Second)
+	select {
+	case <-done:
+		t.Fatal("Sleep returned early")
+	case <-time.After(10 * time.Millisecond):
+	}
+	f.Advance(30 * time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep didn't return")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	f := NewFake(start)
+	ctx, cancel := WithTimeout(context.Background(), f, time.Second)
+	defer cancel()
+
+	f.Advance(time.Second - time.Nanosecond)
+	if ctx.Err() != nil {
+		t.Fatal("context ended before the timeout")
+	}
+	f.Advance(time.Nanosecond)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context didn't end at the timeout")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, context.DeadlineExceeded) {
+		t.Errorf("cause is %v, want context.DeadlineExceeded", cause)
+	}
+}
+
+func TestWithTimeoutCancel(t *testing.T) {
+	f := NewFake(start)
+	ctx, cancel := WithTimeout(context.Background(), f, time.Second)
+	cancel()
+	if cause := context.Cause(ctx); !errors.Is(cause, context.Canceled) {
+		t.Errorf("cause is %v, want context.Canceled", cause)
+	}
+	if n := f.Pending(); n != 0 {
+		t.Errorf("cancel left %d timers pending", n)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	f := NewFake(start)
+	errs := make(chan error, 1)
+	go func() { errs <- Sleep(context.Background(), f, time.Second) }()
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	if err := <-errs; err != nil {
+		t.Errorf("Sleep returned %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { errs <- Sleep(ctx, f, time.Second) }()
+	f.BlockUntil(1)
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled Sleep returned %v, want context.Canceled", err)
+	}
+	if n := f.Pending(); n != 0 {
+		t.Errorf("canceled Sleep left %d timers pending", n)
+	}
+}
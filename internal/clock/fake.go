@@ -0,0 +1,163 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock for tests. Its time only moves when Advance is called,
+// which fires the timers that came due on the way, in order.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond // Broadcast when a timer is added or removed
+	now     time.Time
+	timers  []*fakeTimer // Pending timers, unordered
+}
+
+// NewFake returns a fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.changed = sync.NewCond(&f.mu)
+	return f
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep blocks until the clock was advanced by d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// AfterFunc calls fn on its own goroutine once the clock was advanced by d.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.newTimer(d, fn)
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.newTimer(d, nil)
+}
+
+func (f *Fake) newTimer(d time.Duration, fn func()) *fakeTimer {
+	t := &fakeTimer{clock: f, fn: fn}
+	if fn == nil {
+		t.c = make(chan time.Time, 1)
+	}
+	t.Reset(d)
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that come due in
+// the order of their due time. Timer callbacks run on their own goroutines,
+// so a timer one of them sets is only seen by a later Advance.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	end := f.now.Add(d)
+	for {
+		next := -1
+		for i, t := range f.timers {
+			if !t.when.After(end) && (next < 0 || t.when.Before(f.timers[next].when)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		t := f.timers[next]
+		f.removeLocked(next)
+		if t.when.After(f.now) {
+			f.now = t.when
+		}
+		t.fireLocked()
+	}
+	f.now = end
+	f.mu.Unlock()
+}
+
+// BlockUntil waits until at least n timers are pending, e.g. until the code
+// under test reached the sleeps it is expected to make.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.timers) < n {
+		f.changed.Wait()
+	}
+}
+
+// Pending returns the number of timers that haven't fired or been stopped.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+func (f *Fake) removeLocked(i int) {
+	f.timers = append(f.timers[:i], f.timers[i+1:]...)
+	f.changed.Broadcast()
+}
+
+type fakeTimer struct {
+	clock *Fake
+	c     chan time.Time // Nil for AfterFunc timers
+	fn    func()
+	when  time.Time // Guarded by clock.mu
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return t.stopLocked()
+}
+
+// Reset schedules the timer d from now, like time.Timer.Reset. A duration of
+// zero or less fires it right away.
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wasPending := t.stopLocked()
+	t.when = f.now.Add(d)
+	if d <= 0 {
+		t.fireLocked()
+		return wasPending
+	}
+	f.timers = append(f.timers, t)
+	f.changed.Broadcast()
+	return wasPending
+}
+
+func (t *fakeTimer) stopLocked() bool {
+	f := t.clock
+	for i, pending := range f.timers {
+		if pending == t {
+			f.removeLocked(i)
+			return true
+		}
+	}
+	return false
+}
+
+// fireLocked delivers the tick. Like a time.Timer's channel, C holds at
+// most one undelivered tick.
+func (t *fakeTimer) fireLocked() {
+	if t.fn != nil {
+		go t.fn()
+		return
+	}
+	select {
+	case t.c <- t.clock.now:
+	default:
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"log"
+	"slices"
+	"strings"
+)
+
+// addressIDPrefix marks station IDs derived from the address, for stations
+// whose name has no serial.
+const addressIDPrefix = "addr:"
+
+// NormalizeStationAddresses rewrites the addresses of known stations and of
+// address based station IDs with normalize, which returns the form scans
+// report and false for addresses it can't parse; those are kept as they are.
+// Older versions could store the same station twice with addresses that only
+// differ in case, e.g. after switching adapters. Such entries are collapsed:
+// the first known station is kept, and a per-station setting stays on if
+// either entry had it on; a group keeps one copy of a member. It reports
+// whether anything changed, so the caller can save the config.
+func (c *Config) NormalizeStationAddresses(normalize func(address string) (string, bool)) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := false
+
+	known := make([]KnownStation, 0, len(c.KnownStations))
+	seen := make(map[string]bool, len(c.KnownStations))
+	for _, s := range c.KnownStations {
+		if address, ok := normalize(s.Address); ok && address != s.Address {
+			s.Address = address
+			changed = true
+		}
+		if seen[s.Address] {
+			log.Printf("Config: Dropping duplicate known station %s", s.Address)
+			changed = true
+			continue
+		}
+		seen[s.Address] = true
+		known = append(known, s)
+	}
+	c.KnownStations = known
+
+	for id, settings := range c.Stations {
+		address, ok := strings.CutPrefix(id, addressIDPrefix)
+		if !ok {
+			continue
+		}
+		normalized, ok := normalize(address)
+		if !ok || normalized == address {
+			continue
+		}
+		delete(c.Stations, id)
+		key := addressIDPrefix + normalized
+		if existing, ok := c.Stations[key]; ok {
+			log.Printf("Config: Merging the settings of %s into %s", id, key)
+			settings.Essential = settings.Essential || existing.Essential
+			settings.NoReconnect = settings.NoReconnect || existing.NoReconnect
+			settings.Unmanaged = settings.Unmanaged || existing.Unmanaged
+		}
+		c.Stations[key] = settings
+		changed = true
+	}
+
+	for name, ids := range c.Groups {
+		members := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if address, ok := strings.CutPrefix(id, addressIDPrefix); ok {
+				if normalized, ok := normalize(address); ok && normalized != address {
+					id = addressIDPrefix + normalized
+					changed = true
+				}
+			}
+			if slices.Contains(members, id) {
+				changed = true
+				continue
+			}
+			members = append(members, id)
+		}
+		c.Groups[name] = members
+	}
+	return changed
+}
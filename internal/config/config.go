@@ -6,40 +6,751 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/instance"
+	"lhcontrol/internal/paths"
+)
+
+// defaultAPIPort is the API port of the default instance; named instances
+// derive theirs from it, see instance.APIPort
+const defaultAPIPort = 7575
+
+// Automation actions
+const (
+	ActionNone    = "none"
+	ActionOn      = "on"
+	ActionOff     = "off"
+	ActionStandby = "standby"
+)
+
+// ProcessRule runs power actions when any of the listed processes start or exit.
+type ProcessRule struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Processes are executable names or glob patterns (e.g. "vrserver.exe", "*.vr.exe"), matched case-insensitively
+	Processes          []string `json:"processes"`
+	OnStart            string   `json:"onStart"`
+	OnExit             string   `json:"onExit"`
+	GracePeriodSeconds int      `json:"gracePeriodSeconds"` // Delay before OnExit runs, a restart within it cancels the action
+}
+
+// PowerSourceSettings runs power actions when the machine switches between AC and battery.
+type PowerSourceSettings struct {
+	Enabled         bool   `json:"enabled"`
+	OnUnplugged     string `json:"onUnplugged"`
+	OnPluggedIn     string `json:"onPluggedIn"`
+	DebounceSeconds int    `json:"debounceSeconds"` // How long the new source must persist before acting
+}
+
+// SafetySettings powers stations off after a maximum continuous on-time.
+type SafetySettings struct {
+	Enabled        bool    `json:"enabled"`
+	MaxOnHours     float64 `json:"maxOnHours"`
+	WarningMinutes int     `json:"warningMinutes"` // Time between the warning and the action
+	SnoozeMinutes  int     `json:"snoozeMinutes"`
+	Action         string  `json:"action"`
+}
+
+// NotificationSettings configures desktop notifications.
+type NotificationSettings struct {
+	Enabled       bool `json:"enabled"`
+	ActionButtons bool `json:"actionButtons"` // Add "Turn off now"/"Snooze" buttons where supported
+}
+
+// Missed timer policies
+const (
+	MissedPolicyFire = "fire"
+	MissedPolicySkip = "skip"
 )
 
+// TimerSettings configures how timers that came due while the app was not running are handled.
+type TimerSettings struct {
+	MissedPolicy       string `json:"missedPolicy"`       // MissedPolicyFire or MissedPolicySkip
+	MissedGraceMinutes int    `json:"missedGraceMinutes"` // Missed "on" timers later than this are skipped even with MissedPolicyFire
+}
+
+// ConnectionSettings configures how BLE connections to the stations are managed.
+type ConnectionSettings struct {
+	Prewarm       bool `json:"prewarm"`       // Connect to known stations in the background after a scan
+	MaxConcurrent int  `json:"maxConcurrent"` // Stations connecting at once, for scans, commands and pre-warming alike
+
+	// DisconnectBeforeScan drops all connections before scanning and reconnects
+	// afterwards. Many Windows adapters return few or no advertisements while
+	// GATT connections are open.
+	DisconnectBeforeScan bool `json:"disconnectBeforeScan"`
+
+	// RetryFailed retries the stations that failed in a bulk power command
+	// once more, one at a time, before reporting the failure. A saturated
+	// adapter often fails one station while its siblings succeed.
+	RetryFailed bool `json:"retryFailed"`
+
+	// WaitForScan holds power commands that arrive during a scan until the
+	// scan is over. Connecting while the adapter scans usually fails.
+	WaitForScan bool `json:"waitForScan"`
+
+	// Params tunes new BLE connections, for adapters that only connect
+	// reliably with relaxed timing. Zero values keep the platform defaults.
+	Params ConnectionParams `json:"params"`
+
+	// OperationTimeoutSeconds bounds a single connect, read or write. Stacks
+	// can block for minutes on a station that went out of range. 0 means 10.
+	OperationTimeoutSeconds int `json:"operationTimeoutSeconds"`
+
+	// DiscoveryTimeoutSeconds bounds a single service or characteristic
+	// discovery, which is then tried again. Windows can block for 30
+	// seconds and more on a station that rebooted mid-discovery. 0 means 5.
+	DiscoveryTimeoutSeconds int `json:"discoveryTimeoutSeconds"`
+
+	// Adapter is the ID of the Bluetooth adapter to use, e.g. hci1 with
+	// BlueZ. Empty uses the system default.
+	Adapter string `json:"adapter,omitempty"`
+
+	// AutoReconnect reconnects to a station that dropped its connection,
+	// waiting 5, 15 and then 60 seconds between tries, and gives up after
+	// ReconnectAttempts tries.
+	AutoReconnect     bool `json:"autoReconnect"`
+	ReconnectAttempts int  `json:"reconnectAttempts"`
+
+	// DisconnectAfterCommand connects for each command or status read and
+	// disconnects afterwards instead of keeping the connections open. Some
+	// dongles can't hold several connections for long, and an open
+	// connection keeps SteamVR from managing the station itself.
+	DisconnectAfterCommand bool `json:"disconnectAfterCommand"`
+
+	// PruneAfterMissedScans removes a station from the list once this many
+	// scans in a row didn't see it. Connected stations don't advertise and
+	// don't count as missed. 0 keeps stations forever.
+	PruneAfterMissedScans int `json:"pruneAfterMissedScans"`
+
+	// Retry controls how often a power command is tried before it fails.
+	Retry RetrySettings `json:"retry"`
+}
+
+// RetrySettings is the retry policy of power commands. The wait before each
+// further try grows by Multiplier.
+type RetrySettings struct {
+	Attempts         int     `json:"attempts"`         // Tries in total
+	InitialDelayMs   int     `json:"initialDelayMs"`   // Wait before the second try
+	Multiplier       float64 `json:"multiplier"`       // 1 keeps the wait constant
+	AttemptTimeoutMs int     `json:"attemptTimeoutMs"` // Bounds one try, 0 for no bound
+}
+
+// ConnectionParams are advanced BLE connection parameters in milliseconds.
+// Platforms that don't support a parameter ignore it.
+type ConnectionParams struct {
+	ConnectTimeoutMs     int `json:"connectTimeoutMs"`
+	MinIntervalMs        int `json:"minIntervalMs"`
+	MaxIntervalMs        int `json:"maxIntervalMs"`
+	SupervisionTimeoutMs int `json:"supervisionTimeoutMs"`
+}
+
+// VerificationSettings configures re-checking a station's state after a power
+// command, to catch another tool switching it back.
+type VerificationSettings struct {
+	Enabled       bool `json:"enabled"`
+	WindowSeconds int  `json:"windowSeconds"` // How long after a command the state is watched
+	Checks        int  `json:"checks"`        // Reads spread evenly over the window
+	Reapply       bool `json:"reapply"`       // Re-send the command once instead of only notifying
+}
+
+// FilterConfig selects which advertisements a scan lists as stations.
+type FilterConfig struct {
+	// Prefixes are the accepted advertised name prefixes. Empty accepts any
+	// named device that advertises a base station power service.
+	Prefixes []string `json:"prefixes"`
+	// Addresses, when set, restrict scans to these stations.
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// Polling behaviors while the window is hidden
+const (
+	PollingNormal = "normal"
+	PollingSlow   = "slow"
+	PollingPause  = "pause"
+)
+
+// PollingSettings configures status polling while nobody looks at the data.
+type PollingSettings struct {
+	WhenHidden string `json:"whenHidden"` // PollingNormal, PollingSlow or PollingPause
+	SlowFactor int    `json:"slowFactor"` // How many times longer the interval gets with PollingSlow
+
+	// StatusCacheMs is how long a completed status check is reused by later
+	// callers instead of reading the stations again. 0 disables the cache.
+	StatusCacheMs int `json:"statusCacheMs"`
+
+	// IntervalSeconds is how often the backend refreshes the station states
+	// on its own, so the API serves current data while the window is idle.
+	// 0 disables background polling.
+	IntervalSeconds int `json:"intervalSeconds"`
+}
+
+// Startup actions
+const (
+	StartupNone   = "none"
+	StartupOn     = "on"     // Power on all stations
+	StartupResume = "resume" // Power on the stations that were on at the last exit
+)
+
+// StartupSettings configures what lhcontrol does with the stations when it starts.
+type StartupSettings struct {
+	Action string `json:"action"` // StartupNone, StartupOn or StartupResume
+}
+
+// Shutdown actions
+const (
+	ShutdownNone = "none"
+	ShutdownOff  = "off" // Power off the local stations
+)
+
+// ShutdownSettings configures what lhcontrol does with the stations when it exits.
+type ShutdownSettings struct {
+	Action         string `json:"action"`         // ShutdownNone or ShutdownOff
+	TimeoutSeconds int    `json:"timeoutSeconds"` // How long exiting waits for the stations to turn off
+}
+
+// PrivacySettings configures what lhcontrol reveals in logs.
+type PrivacySettings struct {
+	RedactAddresses bool `json:"redactAddresses"` // Replace Bluetooth addresses in log output with short tokens
+}
+
+// UISettings configures desktop integration of the main window.
+type UISettings struct {
+	TaskbarBadge bool `json:"taskbarBadge"` // Overlay the taskbar icon with the number of stations that are on
+}
+
+// APISettings configures the HTTP API server.
+type APISettings struct {
+	Address       string `json:"address"`       // Listen address, e.g. "127.0.0.1:7575"
+	Token         string `json:"token"`         // When set, every request must carry it as a Bearer token
+	FallbackPorts int    `json:"fallbackPorts"` // How many following ports to try when the port is taken
+}
+
+// AgentConfig describes a remote lhcontrol agent whose stations are merged into this instance.
+type AgentConfig struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// StationSettings holds per-station options, keyed by station ID.
+type StationSettings struct {
+	Essential   bool `json:"essential,omitempty"`   // Included in the essentials quick actions
+	NoReconnect bool `json:"noReconnect,omitempty"` // Not reconnected automatically, e.g. for a station left off on purpose
+	Unmanaged   bool `json:"unmanaged,omitempty"`   // Left out of commands to all stations, e.g. for a station shared with another PC
+}
+
+// KnownStation is a station found by a scan or added by import. Known
+// stations are listed at startup, so they can be controlled before a scan
+// sees them. The generation follows from the name.
+type KnownStation struct {
+	Address      string `json:"address"`
+	OriginalName string `json:"originalName"` // Advertised name, e.g. LHB-1A2B3C4D
+}
+
+// SnapshotStation is the recorded state of one station in a power snapshot.
+type SnapshotStation struct {
+	ID         string `json:"id"`   // Stable station ID, see station.StationInfo
+	Name       string `json:"name"` // Display name when the snapshot was taken
+	PowerState int    `json:"powerState"`
+}
+
+// PowerSnapshot is a named record of which stations were on, to be restored later.
+type PowerSnapshot struct {
+	Name      string            `json:"name"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Stations  []SnapshotStation `json:"stations"`
+}
+
 type Config struct {
-	RenamedStations map[string]string `json:"renamedStations"`
+	RenamedStations map[string]string          `json:"renamedStations"`
+	KnownStations   []KnownStation             `json:"knownStations"`
+	Stations        map[string]StationSettings `json:"stations"`
+	ProcessRules    []ProcessRule              `json:"processRules"`
+	PowerSource     PowerSourceSettings        `json:"powerSource"`
+	Safety          SafetySettings             `json:"safety"`
+	Notifications   NotificationSettings       `json:"notifications"`
+	UI              UISettings                 `json:"ui"`
+	Timers          TimerSettings              `json:"timers"`
+	Connection      ConnectionSettings         `json:"connection"`
+	Filter          FilterConfig               `json:"filter"`
+	Verification    VerificationSettings       `json:"verification"`
+	Startup         StartupSettings            `json:"startup"`
+	Shutdown        ShutdownSettings           `json:"shutdown"`
+	Polling         PollingSettings            `json:"polling"`
+	Privacy         PrivacySettings            `json:"privacy"`
+	API             APISettings                `json:"api"`
+	Agents          []AgentConfig              `json:"agents"`
+	PowerSnapshots  []PowerSnapshot            `json:"powerSnapshots"`
+	Groups          map[string][]string        `json:"groups"` // Group name to the IDs of its stations
+
+	mu            sync.RWMutex
+	tokenRequired bool // See RequireAPIToken, guarded by mu
+
+	saveMu sync.Mutex // Serializes writes so concurrent saves can't interleave them
+	disk   diskState  // What the file held when this process last read or wrote it, guarded by saveMu
+
+	pendingMu sync.Mutex
+	pending   *pendingSave // Save waiting for more saves to join it
 }
 
 // NewConfig creates a new Config with defaults
 func NewConfig() *Config {
 	return &Config{
 		RenamedStations: make(map[string]string),
+		KnownStations:   []KnownStation{},
+		Stations:        make(map[string]StationSettings),
+		ProcessRules: []ProcessRule{
+			{
+				Name:               "SteamVR",
+				Enabled:            false,
+				Processes:          []string{"vrserver.exe", "vrserver"},
+				OnStart:            ActionOn,
+				OnExit:             ActionOff,
+				GracePeriodSeconds: 30,
+			},
+		},
+		PowerSource: PowerSourceSettings{
+			Enabled:         false,
+			OnUnplugged:     ActionOff,
+			OnPluggedIn:     ActionOn,
+			DebounceSeconds: 10,
+		},
+		Safety: SafetySettings{
+			Enabled:        false,
+			MaxOnHours:     8,
+			WarningMinutes: 5,
+			SnoozeMinutes:  30,
+			Action:         ActionOff,
+		},
+		Notifications: NotificationSettings{
+			Enabled:       true,
+			ActionButtons: true,
+		},
+		UI: UISettings{
+			TaskbarBadge: true,
+		},
+		Timers: TimerSettings{
+			MissedPolicy:       MissedPolicyFire,
+			MissedGraceMinutes: 10,
+		},
+		Connection: ConnectionSettings{
+			Prewarm:                 false,
+			MaxConcurrent:           2,
+			DisconnectBeforeScan:    runtime.GOOS == "windows",
+			RetryFailed:             true,
+			WaitForScan:             true,
+			OperationTimeoutSeconds: 10,
+			DiscoveryTimeoutSeconds: 5,
+			AutoReconnect:           true,
+			ReconnectAttempts:       5,
+			Retry: RetrySettings{
+				Attempts:       2,
+				InitialDelayMs: 500,
+				Multiplier:     1,
+			},
+		},
+		Filter: FilterConfig{
+			Prefixes: []string{"LHB-", "HTC BS "},
+		},
+		Verification: VerificationSettings{
+			Enabled:       false,
+			WindowSeconds: 60,
+			Checks:        3,
+			Reapply:       false,
+		},
+		Startup: StartupSettings{
+			Action: StartupNone,
+		},
+		Shutdown: ShutdownSettings{
+			Action:         ShutdownNone,
+			TimeoutSeconds: 10,
+		},
+		Polling: PollingSettings{
+			WhenHidden:      PollingSlow,
+			SlowFactor:      4,
+			StatusCacheMs:   2000,
+			IntervalSeconds: 15,
+		},
+		API: APISettings{
+			Address:       fmt.Sprintf("127.0.0.1:%d", instance.APIPort(defaultAPIPort)),
+			FallbackPorts: 10,
+		},
+		Agents:         []AgentConfig{},
+		PowerSnapshots: []PowerSnapshot{},
+		Groups:         make(map[string][]string),
 	}
 }
 
-// Helper function to get the full path to the config file
-func getConfigPath() (string, error) {
-	configDir, err := os.UserConfigDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get user config dir: %w", err)
+func validAction(action string) bool {
+	return action == ActionNone || action == ActionOn || action == ActionOff || action == ActionStandby
+}
+
+// ValidateProcessRules checks that rule names are unique and actions are known.
+func ValidateProcessRules(rules []ProcessRule) error {
+	names := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate rule name '%s'", name)
+		}
+		names[name] = true
+		if len(rule.Processes) == 0 {
+			return fmt.Errorf("rule '%s' has no processes", name)
+		}
+		for _, pattern := range rule.Processes {
+			if _, err := filepath.Match(strings.ToLower(pattern), ""); err != nil {
+				return fmt.Errorf("rule '%s' has invalid process pattern '%s': %w", name, pattern, err)
+			}
+		}
+		if !validAction(rule.OnStart) || !validAction(rule.OnExit) {
+			return fmt.Errorf("rule '%s' has an unknown action", name)
+		}
+		if rule.GracePeriodSeconds < 0 {
+			return fmt.Errorf("rule '%s' has a negative grace period", name)
+		}
 	}
-	appConfigDir := filepath.Join(configDir, "lhcontrol")
-	err = os.MkdirAll(appConfigDir, 0755) // Ensure the directory exists
-	if err != nil {
-		return "", fmt.Errorf("failed to create app config dir '%s': %w", appConfigDir, err)
+	return nil
+}
+
+// ValidatePowerSourceSettings checks the power source actions and debounce.
+func ValidatePowerSourceSettings(settings PowerSourceSettings) error {
+	if !validAction(settings.OnUnplugged) || !validAction(settings.OnPluggedIn) {
+		return fmt.Errorf("power source settings have an unknown action")
+	}
+	if settings.DebounceSeconds < 0 {
+		return fmt.Errorf("power source debounce must not be negative")
+	}
+	return nil
+}
+
+// ValidateSafetySettings checks the on-time cap and timings.
+func ValidateSafetySettings(settings SafetySettings) error {
+	if settings.MaxOnHours <= 0 {
+		return fmt.Errorf("maximum on-time must be positive")
+	}
+	if settings.WarningMinutes < 0 || settings.SnoozeMinutes < 0 {
+		return fmt.Errorf("safety warning and snooze durations must not be negative")
+	}
+	if settings.Action != ActionOff && settings.Action != ActionStandby {
+		return fmt.Errorf("unsupported safety action '%s'", settings.Action)
+	}
+	return nil
+}
+
+// ValidateTimerSettings checks the missed timer policy.
+func ValidateTimerSettings(settings TimerSettings) error {
+	if settings.MissedPolicy != MissedPolicyFire && settings.MissedPolicy != MissedPolicySkip {
+		return fmt.Errorf("invalid missed timer policy '%s'", settings.MissedPolicy)
+	}
+	if settings.MissedGraceMinutes < 0 {
+		return fmt.Errorf("missed timer grace period must not be negative")
+	}
+	return nil
+}
+
+// ValidateConnectionSettings checks the connection concurrency limit, timeout,
+// reconnect attempts, prune threshold, retry policy and parameters.
+func ValidateConnectionSettings(settings ConnectionSettings) error {
+	if settings.MaxConcurrent < 1 || settings.MaxConcurrent > 8 {
+		return fmt.Errorf("maxConcurrent must be between 1 and 8")
+	}
+	if settings.OperationTimeoutSeconds != 0 && (settings.OperationTimeoutSeconds < 2 || settings.OperationTimeoutSeconds > 60) {
+		return fmt.Errorf("operationTimeoutSeconds must be 0 or between 2 and 60")
+	}
+	if settings.DiscoveryTimeoutSeconds != 0 && (settings.DiscoveryTimeoutSeconds < 1 || settings.DiscoveryTimeoutSeconds > 60) {
+		return fmt.Errorf("discoveryTimeoutSeconds must be 0 or between 1 and 60")
+	}
+	if settings.ReconnectAttempts < 1 || settings.ReconnectAttempts > 20 {
+		return fmt.Errorf("reconnectAttempts must be between 1 and 20")
+	}
+	if settings.PruneAfterMissedScans < 0 || settings.PruneAfterMissedScans > 100 {
+		return fmt.Errorf("pruneAfterMissedScans must be between 0 and 100")
+	}
+	r := settings.Retry
+	if r.Attempts < 1 || r.Attempts > 10 {
+		return fmt.Errorf("retry attempts must be between 1 and 10")
+	}
+	if r.InitialDelayMs < 0 || r.InitialDelayMs > 10000 {
+		return fmt.Errorf("retry initialDelayMs must be between 0 and 10000")
+	}
+	if r.Multiplier < 1 || r.Multiplier > 5 {
+		return fmt.Errorf("retry multiplier must be between 1 and 5")
+	}
+	if r.AttemptTimeoutMs != 0 && (r.AttemptTimeoutMs < 1000 || r.AttemptTimeoutMs > 60000) {
+		return fmt.Errorf("retry attemptTimeoutMs must be 0 or between 1000 and 60000")
+	}
+	p := settings.Params
+	// BLE limits, and the 0.625 ms units tinygo uses for the connect timeout
+	if p.ConnectTimeoutMs != 0 && (p.ConnectTimeoutMs < 1000 || p.ConnectTimeoutMs > 40000) {
+		return fmt.Errorf("connectTimeoutMs must be 0 or between 1000 and 40000")
+	}
+	for _, interval := range []int{p.MinIntervalMs, p.MaxIntervalMs} {
+		if interval != 0 && (interval < 8 || interval > 4000) {
+			return fmt.Errorf("connection intervals must be 0 or between 8 and 4000 ms")
+		}
+	}
+	if p.MinIntervalMs != 0 && p.MaxIntervalMs != 0 && p.MinIntervalMs > p.MaxIntervalMs {
+		return fmt.Errorf("minIntervalMs must not exceed maxIntervalMs")
+	}
+	if p.SupervisionTimeoutMs != 0 && (p.SupervisionTimeoutMs < 100 || p.SupervisionTimeoutMs > 32000) {
+		return fmt.Errorf("supervisionTimeoutMs must be 0 or between 100 and 32000")
+	}
+	return nil
+}
+
+// ValidateFilterConfig checks that the prefixes and addresses aren't blank.
+func ValidateFilterConfig(filter FilterConfig) error {
+	if len(filter.Prefixes) > 20 {
+		return fmt.Errorf("at most 20 prefixes are allowed")
+	}
+	for _, prefix := range filter.Prefixes {
+		if strings.TrimSpace(prefix) == "" {
+			return fmt.Errorf("prefixes must not be blank")
+		}
+	}
+	for _, address := range filter.Addresses {
+		if strings.TrimSpace(address) == "" {
+			return fmt.Errorf("addresses must not be blank")
+		}
+	}
+	return nil
+}
+
+// ValidateVerificationSettings checks the verification window and check count.
+func ValidateVerificationSettings(settings VerificationSettings) error {
+	if settings.WindowSeconds < 10 || settings.WindowSeconds > 600 {
+		return fmt.Errorf("windowSeconds must be between 10 and 600")
+	}
+	if settings.Checks < 1 || settings.Checks > 10 {
+		return fmt.Errorf("checks must be between 1 and 10")
+	}
+	return nil
+}
+
+// ValidateStartupSettings checks the startup action.
+func ValidateStartupSettings(settings StartupSettings) error {
+	switch settings.Action {
+	case StartupNone, StartupOn, StartupResume:
+		return nil
+	}
+	return fmt.Errorf("invalid startup action '%s'", settings.Action)
+}
+
+// ValidateShutdownSettings checks the shutdown action and its timeout, which
+// has to end before a signal-triggered shutdown is cut off.
+func ValidateShutdownSettings(settings ShutdownSettings) error {
+	switch settings.Action {
+	case ShutdownNone, ShutdownOff:
+	default:
+		return fmt.Errorf("invalid shutdown action '%s'", settings.Action)
+	}
+	if settings.TimeoutSeconds < 1 || settings.TimeoutSeconds > 12 {
+		return fmt.Errorf("timeoutSeconds must be between 1 and 12")
+	}
+	return nil
+}
+
+// ValidatePollingSettings checks the hidden-window behavior, slow-down factor,
+// cache window and background interval.
+func ValidatePollingSettings(settings PollingSettings) error {
+	switch settings.WhenHidden {
+	case PollingNormal, PollingSlow, PollingPause:
+	default:
+		return fmt.Errorf("invalid hidden polling behavior '%s'", settings.WhenHidden)
+	}
+	if settings.SlowFactor < 2 || settings.SlowFactor > 20 {
+		return fmt.Errorf("slowFactor must be between 2 and 20")
+	}
+	if settings.StatusCacheMs < 0 || settings.StatusCacheMs > 30000 {
+		return fmt.Errorf("statusCacheMs must be between 0 and 30000")
+	}
+	if settings.IntervalSeconds < 0 || settings.IntervalSeconds > 3600 {
+		return fmt.Errorf("intervalSeconds must be between 0 and 3600")
+	}
+	return nil
+}
+
+// ValidateAgents checks that agents have unique names and a URL.
+func ValidateAgents(agents []AgentConfig) error {
+	names := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		if strings.TrimSpace(agent.Name) == "" {
+			return fmt.Errorf("agent name must not be empty")
+		}
+		if names[agent.Name] {
+			return fmt.Errorf("duplicate agent name '%s'", agent.Name)
+		}
+		names[agent.Name] = true
+		if !strings.HasPrefix(agent.URL, "http://") && !strings.HasPrefix(agent.URL, "https://") {
+			return fmt.Errorf("agent '%s' URL must start with http:// or https://", agent.Name)
+		}
+	}
+	return nil
+}
+
+// ValidateKnownStations checks that known stations have a name and a unique address.
+func ValidateKnownStations(stations []KnownStation) error {
+	addresses := make(map[string]bool, len(stations))
+	for _, s := range stations {
+		if s.Address == "" || s.OriginalName == "" {
+			return fmt.Errorf("known stations need an address and an original name")
+		}
+		if addresses[s.Address] {
+			return fmt.Errorf("duplicate known station '%s'", s.Address)
+		}
+		addresses[s.Address] = true
+	}
+	return nil
+}
+
+// ValidatePowerSnapshots checks that snapshots have unique names and known states.
+func ValidatePowerSnapshots(snapshots []PowerSnapshot) error {
+	names := make(map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		if strings.TrimSpace(snapshot.Name) == "" {
+			return fmt.Errorf("snapshot name must not be empty")
+		}
+		if names[snapshot.Name] {
+			return fmt.Errorf("duplicate snapshot name '%s'", snapshot.Name)
+		}
+		names[snapshot.Name] = true
+		for _, s := range snapshot.Stations {
+			if s.ID == "" {
+				return fmt.Errorf("snapshot '%s' has a station without an ID", snapshot.Name)
+			}
+			if s.PowerState < 0 || s.PowerState > 2 { // Off, on or standby
+				return fmt.Errorf("snapshot '%s' has an invalid state for %s", snapshot.Name, s.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateGroups checks that groups have names and that no station is in
+// more than one group.
+func ValidateGroups(groups map[string][]string) error {
+	member := make(map[string]string)
+	for name, ids := range groups {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("group name must not be empty")
+		}
+		for _, id := range ids {
+			if id == "" {
+				return fmt.Errorf("group '%s' has a station without an ID", name)
+			}
+			if other, ok := member[id]; ok {
+				return fmt.Errorf("station %s is in both '%s' and '%s'", id, other, name)
+			}
+			member[id] = name
+		}
+	}
+	return nil
+}
+
+// StationName returns the custom name for a station, if it was renamed.
+func (c *Config) StationName(originalName string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.RenamedStations[originalName]
+	return name, ok
+}
+
+// SetStationName sets the custom name for a station. An empty name removes it.
+// Call Save to persist it.
+func (c *Config) SetStationName(originalName, newName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if newName == "" {
+		delete(c.RenamedStations, originalName)
+	} else {
+		c.RenamedStations[originalName] = newName
+	}
+}
+
+// GetStationSettings returns the options of a station, zero if none are set.
+func (c *Config) GetStationSettings(id string) StationSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Stations[id]
+}
+
+// SetStationSettings replaces the options of a station. Zero settings remove
+// the entry. Call Save to persist them.
+func (c *Config) SetStationSettings(id string, settings StationSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if settings == (StationSettings{}) {
+		delete(c.Stations, id)
+	} else {
+		c.Stations[id] = settings
+	}
+}
+
+// GetKnownStations returns a copy of the known stations.
+func (c *Config) GetKnownStations() []KnownStation {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]KnownStation(nil), c.KnownStations...)
+}
+
+// SetKnownStations validates and replaces the known stations. Call Save to persist them.
+func (c *Config) SetKnownStations(stations []KnownStation) error {
+	if err := ValidateKnownStations(stations); err != nil {
+		return err
 	}
-	return filepath.Join(appConfigDir, "config.json"), nil
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.KnownStations = stations
+	return nil
+}
+
+// GetProcessRules returns a copy of the configured process rules.
+func (c *Config) GetProcessRules() []ProcessRule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rules := make([]ProcessRule, len(c.ProcessRules))
+	for i, rule := range c.ProcessRules {
+		rule.Processes = append([]string(nil), rule.Processes...)
+		rules[i] = rule
+	}
+	return rules
+}
+
+// SetProcessRules validates and replaces the process rules. Call Save to persist them.
+func (c *Config) SetProcessRules(rules []ProcessRule) error {
+	if err := ValidateProcessRules(rules); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ProcessRules = rules
+	return nil
 }
 
 // Load reads the configuration from disk
 func (c *Config) Load() error {
-	configFilePath, err := getConfigPath()
+	configFilePath, err := paths.ConfigFile()
 	if err != nil {
 		return err
 	}
+	// Stat first, so a change made while reading shows up as a newer file
+	info, statErr := os.Stat(configFilePath)
+	if err := c.load(configFilePath); err != nil {
+		return err
+	}
+	c.rememberDisk(info, statErr)
+	return nil
+}
 
+func (c *Config) load(configFilePath string) error {
 	log.Printf("Loading config from: %s", configFilePath)
 	configFile, err := os.ReadFile(configFilePath)
 	if err != nil {
@@ -49,6 +760,9 @@ func (c *Config) Load() error {
 		return fmt.Errorf("error reading config file '%s': %w", configFilePath, err)
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	err = json.Unmarshal(configFile, c)
 	if err != nil {
 		return fmt.Errorf("error unmarshalling config: %w", err)
@@ -57,25 +771,443 @@ func (c *Config) Load() error {
 	if c.RenamedStations == nil {
 		c.RenamedStations = make(map[string]string)
 	}
+	if c.Stations == nil {
+		c.Stations = make(map[string]StationSettings)
+	}
+	if err := ValidateKnownStations(c.KnownStations); err != nil {
+		log.Printf("Invalid known stations in config, ignoring them: %v", err)
+		c.KnownStations = []KnownStation{}
+	}
+	if err := ValidateProcessRules(c.ProcessRules); err != nil {
+		log.Printf("Invalid process rules in config, disabling automation: %v", err)
+		c.ProcessRules = nil
+	}
+	if err := ValidatePowerSourceSettings(c.PowerSource); err != nil {
+		log.Printf("Invalid power source settings in config, disabling them: %v", err)
+		c.PowerSource = NewConfig().PowerSource
+	}
+	if err := ValidateSafetySettings(c.Safety); err != nil {
+		log.Printf("Invalid safety settings in config, resetting them: %v", err)
+		c.Safety = NewConfig().Safety
+	}
+	if err := ValidateTimerSettings(c.Timers); err != nil {
+		log.Printf("Invalid timer settings in config, resetting them: %v", err)
+		c.Timers = NewConfig().Timers
+	}
+	if err := ValidateConnectionSettings(c.Connection); err != nil {
+		log.Printf("Invalid connection settings in config, resetting them: %v", err)
+		c.Connection = NewConfig().Connection
+	}
+	if err := ValidateFilterConfig(c.Filter); err != nil {
+		log.Printf("Invalid scan filter in config, resetting it: %v", err)
+		c.Filter = NewConfig().Filter
+	}
+	if err := ValidateVerificationSettings(c.Verification); err != nil {
+		log.Printf("Invalid verification settings in config, resetting them: %v", err)
+		c.Verification = NewConfig().Verification
+	}
+	if err := ValidateStartupSettings(c.Startup); err != nil {
+		log.Printf("Invalid startup settings in config, resetting them: %v", err)
+		c.Startup = NewConfig().Startup
+	}
+	if err := ValidatePollingSettings(c.Polling); err != nil {
+		log.Printf("Invalid polling settings in config, resetting them: %v", err)
+		c.Polling = NewConfig().Polling
+	}
+	if c.API.Address == "" {
+		c.API.Address = NewConfig().API.Address
+	}
+	if c.API.FallbackPorts < 0 {
+		c.API.FallbackPorts = 0
+	}
+	if err := ValidateAgents(c.Agents); err != nil {
+		log.Printf("Invalid agents in config, ignoring them: %v", err)
+		c.Agents = []AgentConfig{}
+	}
+	if err := ValidatePowerSnapshots(c.PowerSnapshots); err != nil {
+		log.Printf("Invalid power snapshots in config, ignoring them: %v", err)
+		c.PowerSnapshots = []PowerSnapshot{}
+	}
+	if err := ValidateGroups(c.Groups); err != nil {
+		log.Printf("Invalid groups in config, ignoring them: %v", err)
+		c.Groups = nil
+	}
+	if c.Groups == nil {
+		c.Groups = make(map[string][]string)
+	}
 	return nil
 }
 
-// Save writes the configuration to disk
-func (c *Config) Save() error {
-	configFilePath, err := getConfigPath()
-	if err != nil {
+// GetPowerSourceSettings returns the power source automation settings.
+func (c *Config) GetPowerSourceSettings() PowerSourceSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.PowerSource
+}
+
+// SetPowerSourceSettings validates and replaces the power source settings. Call Save to persist them.
+func (c *Config) SetPowerSourceSettings(settings PowerSourceSettings) error {
+	if err := ValidatePowerSourceSettings(settings); err != nil {
 		return err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.PowerSource = settings
+	return nil
+}
 
-	configFile, err := json.MarshalIndent(c, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshalling config: %w", err)
+// GetSafetySettings returns the safety auto-off settings.
+func (c *Config) GetSafetySettings() SafetySettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Safety
+}
+
+// SetSafetySettings validates and replaces the safety settings. Call Save to persist them.
+func (c *Config) SetSafetySettings(settings SafetySettings) error {
+	if err := ValidateSafetySettings(settings); err != nil {
+		return err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Safety = settings
+	return nil
+}
 
-	log.Printf("Saving config to: %s", configFilePath)
-	err = os.WriteFile(configFilePath, configFile, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write config file '%s': %w", configFilePath, err)
+// GetNotificationSettings returns the notification settings.
+func (c *Config) GetNotificationSettings() NotificationSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Notifications
+}
+
+// SetNotificationSettings replaces the notification settings. Call Save to persist them.
+func (c *Config) SetNotificationSettings(settings NotificationSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Notifications = settings
+}
+
+// GetUISettings returns the desktop integration settings.
+func (c *Config) GetUISettings() UISettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.UI
+}
+
+// SetUISettings replaces the desktop integration settings. Call Save to persist them.
+func (c *Config) SetUISettings(settings UISettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.UI = settings
+}
+
+// GetTimerSettings returns the timer settings.
+func (c *Config) GetTimerSettings() TimerSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Timers
+}
+
+// SetTimerSettings validates and replaces the timer settings. Call Save to persist them.
+func (c *Config) SetTimerSettings(settings TimerSettings) error {
+	if err := ValidateTimerSettings(settings); err != nil {
+		return err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Timers = settings
 	return nil
 }
+
+// GetConnectionSettings returns the connection settings.
+func (c *Config) GetConnectionSettings() ConnectionSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Connection
+}
+
+// SetConnectionSettings validates and replaces the connection settings. Call Save to persist them.
+func (c *Config) SetConnectionSettings(settings ConnectionSettings) error {
+	if err := ValidateConnectionSettings(settings); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Connection = settings
+	return nil
+}
+
+// GetFilterConfig returns the scan filter.
+func (c *Config) GetFilterConfig() FilterConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Filter
+}
+
+// SetFilterConfig validates and replaces the scan filter. Call Save to persist it.
+func (c *Config) SetFilterConfig(filter FilterConfig) error {
+	if err := ValidateFilterConfig(filter); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Filter = filter
+	return nil
+}
+
+// GetVerificationSettings returns the state verification settings.
+func (c *Config) GetVerificationSettings() VerificationSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Verification
+}
+
+// SetVerificationSettings validates and replaces the state verification settings. Call Save to persist them.
+func (c *Config) SetVerificationSettings(settings VerificationSettings) error {
+	if err := ValidateVerificationSettings(settings); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Verification = settings
+	return nil
+}
+
+// GetStartupSettings returns the startup settings.
+func (c *Config) GetStartupSettings() StartupSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Startup
+}
+
+// SetStartupSettings validates and replaces the startup settings. Call Save to persist them.
+func (c *Config) SetStartupSettings(settings StartupSettings) error {
+	if err := ValidateStartupSettings(settings); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Startup = settings
+	return nil
+}
+
+// GetShutdownSettings returns the shutdown settings.
+func (c *Config) GetShutdownSettings() ShutdownSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Shutdown
+}
+
+// SetShutdownSettings validates and replaces the shutdown settings. Call Save to persist them.
+func (c *Config) SetShutdownSettings(settings ShutdownSettings) error {
+	if err := ValidateShutdownSettings(settings); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Shutdown = settings
+	return nil
+}
+
+// GetPollingSettings returns the status polling settings.
+func (c *Config) GetPollingSettings() PollingSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Polling
+}
+
+// SetPollingSettings validates and replaces the status polling settings. Call Save to persist them.
+func (c *Config) SetPollingSettings(settings PollingSettings) error {
+	if err := ValidatePollingSettings(settings); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Polling = settings
+	return nil
+}
+
+// GetPrivacySettings returns the privacy settings.
+func (c *Config) GetPrivacySettings() PrivacySettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Privacy
+}
+
+// SetPrivacySettings replaces the privacy settings. Call Save to persist them.
+func (c *Config) SetPrivacySettings(settings PrivacySettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Privacy = settings
+}
+
+// GetAPISettings returns the HTTP API settings.
+func (c *Config) GetAPISettings() APISettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.API
+}
+
+// SetAPISettings replaces the HTTP API settings. Call Save to persist them.
+func (c *Config) SetAPISettings(settings APISettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.API = settings
+}
+
+// RequireAPIToken makes Update refuse documents that remove the API token, for
+// agent mode, where the API is reachable from the network.
+func (c *Config) RequireAPIToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokenRequired = true
+}
+
+// GetAgents returns a copy of the configured remote agents.
+func (c *Config) GetAgents() []AgentConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]AgentConfig(nil), c.Agents...)
+}
+
+// SetAgents validates and replaces the remote agents. Call Save to persist them.
+func (c *Config) SetAgents(agents []AgentConfig) error {
+	if err := ValidateAgents(agents); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Agents = agents
+	return nil
+}
+
+// GetPowerSnapshots returns a copy of the saved power snapshots.
+func (c *Config) GetPowerSnapshots() []PowerSnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	snapshots := make([]PowerSnapshot, len(c.PowerSnapshots))
+	for i, snapshot := range c.PowerSnapshots {
+		snapshot.Stations = append([]SnapshotStation(nil), snapshot.Stations...)
+		snapshots[i] = snapshot
+	}
+	return snapshots
+}
+
+// GetPowerSnapshot returns the power snapshot with the given name.
+func (c *Config) GetPowerSnapshot(name string) (PowerSnapshot, bool) {
+	for _, snapshot := range c.GetPowerSnapshots() {
+		if snapshot.Name == name {
+			return snapshot, true
+		}
+	}
+	return PowerSnapshot{}, false
+}
+
+// SetPowerSnapshot adds a power snapshot, replacing one with the same name.
+// Call Save to persist it.
+func (c *Config) SetPowerSnapshot(snapshot PowerSnapshot) error {
+	if err := ValidatePowerSnapshots([]PowerSnapshot{snapshot}); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, existing := range c.PowerSnapshots {
+		if existing.Name == snapshot.Name {
+			c.PowerSnapshots[i] = snapshot
+			return nil
+		}
+	}
+	c.PowerSnapshots = append(c.PowerSnapshots, snapshot)
+	return nil
+}
+
+// DeletePowerSnapshot removes the power snapshot with the given name and
+// reports whether it existed. Call Save to persist it.
+func (c *Config) DeletePowerSnapshot(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, snapshot := range c.PowerSnapshots {
+		if snapshot.Name == name {
+			c.PowerSnapshots = append(c.PowerSnapshots[:i], c.PowerSnapshots[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetGroups returns a copy of the groups.
+func (c *Config) GetGroups() map[string][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	groups := make(map[string][]string, len(c.Groups))
+	for name, ids := range c.Groups {
+		groups[name] = append([]string{}, ids...)
+	}
+	return groups
+}
+
+// StationGroup returns the name of the group a station is in, empty if none.
+func (c *Config) StationGroup(id string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, ids := range c.Groups {
+		if slices.Contains(ids, id) {
+			return name
+		}
+	}
+	return ""
+}
+
+// CreateGroup adds an empty group and reports whether it was added, false if
+// one with that name exists. Call Save to persist it.
+func (c *Config) CreateGroup(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.Groups[name]; ok {
+		return false
+	}
+	c.Groups[name] = []string{}
+	return true
+}
+
+// DeleteGroup removes a group and reports whether it existed. Its stations
+// are left without a group. Call Save to persist it.
+func (c *Config) DeleteGroup(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.Groups[name]; !ok {
+		return false
+	}
+	delete(c.Groups, name)
+	return true
+}
+
+// SetStationGroup moves a station into a group, out of the one it was in. An
+// empty group only removes it from its group. It reports false, changing
+// nothing, if the group doesn't exist. Call Save to persist it.
+func (c *Config) SetStationGroup(id, group string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.Groups[group]; group != "" && !ok {
+		return false
+	}
+	for name, ids := range c.Groups {
+		c.Groups[name] = slices.DeleteFunc(ids, func(member string) bool { return member == id })
+	}
+	if group != "" {
+		c.Groups[group] = append(c.Groups[group], id)
+	}
+	return true
+}
+
+// Snapshot returns the config as it would be saved to disk.
+func (c *Config) Snapshot() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	configFile, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling config: %w", err)
+	}
+	return configFile, nil
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// RedactedSecret replaces tokens in a redacted config. Sending it back in an
+// update keeps the current token.
+const RedactedSecret = "<redacted>"
+
+// UpdateResult lists what an update changed.
+type UpdateResult struct {
+	Changed         []string `json:"changed"`         // Top-level sections that changed
+	RestartRequired []string `json:"restartRequired"` // Settings that only take effect after a restart
+}
+
+// Redacted returns the config as JSON with the API and agent tokens replaced
+// by RedactedSecret.
+func (c *Config) Redacted() ([]byte, error) {
+	data, err := c.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	redactToken := func(v interface{}) {
+		if m, ok := v.(map[string]interface{}); ok {
+			if token, _ := m["token"].(string); token != "" {
+				m["token"] = RedactedSecret
+			}
+		}
+	}
+	redactToken(doc["api"])
+	if agents, ok := doc["agents"].([]interface{}); ok {
+		for _, agent := range agents {
+			redactToken(agent)
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Update applies a full or partial config document as a JSON merge patch
+// (RFC 7386): objects are merged, other values replace the current ones and
+// null removes a key, e.g. a station rename. The result must pass the same
+// validation as the individual setters, otherwise nothing changes; after
+// RequireAPIToken it must keep the API token. Call Save to persist it.
+func (c *Config) Update(patch []byte) (UpdateResult, error) {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return UpdateResult{}, fmt.Errorf("invalid config document: %w", err)
+	}
+	if _, ok := patchDoc.(map[string]interface{}); !ok {
+		return UpdateResult{}, fmt.Errorf("config document must be a JSON object")
+	}
+
+	// Hold the lock throughout so concurrent setters can't be lost
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	currentJSON, err := json.Marshal(c)
+	if err != nil {
+		return UpdateResult{}, fmt.Errorf("error marshalling config: %w", err)
+	}
+	var currentDoc interface{}
+	if err := json.Unmarshal(currentJSON, &currentDoc); err != nil {
+		return UpdateResult{}, err
+	}
+	mergedJSON, err := json.Marshal(mergePatch(currentDoc, patchDoc))
+	if err != nil {
+		return UpdateResult{}, err
+	}
+
+	next := NewConfig()
+	next.RenamedStations = nil
+	next.Stations = nil
+	decoder := json.NewDecoder(bytes.NewReader(mergedJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(next); err != nil {
+		return UpdateResult{}, fmt.Errorf("invalid config document: %w", err)
+	}
+	next.keepRedactedSecrets(c)
+	if err := next.validate(); err != nil {
+		return UpdateResult{}, err
+	}
+	if c.tokenRequired && next.API.Token == "" {
+		return UpdateResult{}, fmt.Errorf("invalid api: the token can't be removed in agent mode")
+	}
+
+	nextJSON, err := json.Marshal(next)
+	if err != nil {
+		return UpdateResult{}, fmt.Errorf("error marshalling config: %w", err)
+	}
+	result := UpdateResult{Changed: changedSections(currentJSON, nextJSON), RestartRequired: []string{}}
+	if next.API.Address != c.API.Address {
+		result.RestartRequired = append(result.RestartRequired, "api.address")
+	}
+	if next.API.FallbackPorts != c.API.FallbackPorts {
+		result.RestartRequired = append(result.RestartRequired, "api.fallbackPorts")
+	}
+
+	// Every field is in nextJSON, so decoding replaces them all; only maps
+	// would be merged into
+	c.RenamedStations = nil
+	c.Stations = nil
+	c.Groups = nil
+	if err := json.Unmarshal(nextJSON, c); err != nil {
+		return UpdateResult{}, err
+	}
+	if c.RenamedStations == nil {
+		c.RenamedStations = make(map[string]string)
+	}
+	if c.Stations == nil {
+		c.Stations = make(map[string]StationSettings)
+	}
+	if c.Groups == nil {
+		c.Groups = make(map[string][]string)
+	}
+	return result, nil
+}
+
+// keepRedactedSecrets restores tokens that were sent back as RedactedSecret.
+// Agent tokens are matched by agent name.
+func (c *Config) keepRedactedSecrets(current *Config) {
+	if c.API.Token == RedactedSecret {
+		c.API.Token = current.API.Token
+	}
+	for i := range c.Agents {
+		if c.Agents[i].Token != RedactedSecret {
+			continue
+		}
+		c.Agents[i].Token = ""
+		for _, agent := range current.Agents {
+			if agent.Name == c.Agents[i].Name {
+				c.Agents[i].Token = agent.Token
+				break
+			}
+		}
+	}
+}
+
+// validate checks every section, strictly, unlike Load which resets invalid ones.
+func (c *Config) validate() error {
+	checks := []struct {
+		section string
+		err     error
+	}{
+		{"knownStations", ValidateKnownStations(c.KnownStations)},
+		{"processRules", ValidateProcessRules(c.ProcessRules)},
+		{"powerSource", ValidatePowerSourceSettings(c.PowerSource)},
+		{"safety", ValidateSafetySettings(c.Safety)},
+		{"timers", ValidateTimerSettings(c.Timers)},
+		{"connection", ValidateConnectionSettings(c.Connection)},
+		{"filter", ValidateFilterConfig(c.Filter)},
+		{"verification", ValidateVerificationSettings(c.Verification)},
+		{"startup", ValidateStartupSettings(c.Startup)},
+		{"shutdown", ValidateShutdownSettings(c.Shutdown)},
+		{"polling", ValidatePollingSettings(c.Polling)},
+		{"agents", ValidateAgents(c.Agents)},
+		{"powerSnapshots", ValidatePowerSnapshots(c.PowerSnapshots)},
+		{"groups", ValidateGroups(c.Groups)},
+	}
+	for _, check := range checks {
+		if check.err != nil {
+			return fmt.Errorf("invalid %s: %w", check.section, check.err)
+		}
+	}
+	if c.API.Address == "" {
+		return fmt.Errorf("invalid api: address must not be empty")
+	}
+	if c.API.FallbackPorts < 0 {
+		return fmt.Errorf("invalid api: fallbackPorts must not be negative")
+	}
+	return nil
+}
+
+// mergePatch applies a JSON merge patch to a decoded document.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// changedSections returns the top-level keys whose values differ between two
+// config documents, sorted.
+func changedSections(before, after []byte) []string {
+	var beforeDoc, afterDoc map[string]json.RawMessage
+	json.Unmarshal(before, &beforeDoc)
+	json.Unmarshal(after, &afterDoc)
+	changed := []string{}
+	for key, value := range afterDoc {
+		if !bytes.Equal(beforeDoc[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
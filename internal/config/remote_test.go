@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestUpdateKeepsRequiredToken(t *testing.T) {
+	for _, patch := range []string{`{"api":{"token":null}}`, `{"api":{"token":""}}`} {
+		c := NewConfig()
+		settings := c.GetAPISettings()
+		settings.Token = "secret"
+		c.SetAPISettings(settings)
+		c.RequireAPIToken()
+		if _, err := c.Update([]byte(patch)); err == nil {
+			t.Errorf("%s was accepted in agent mode", patch)
+		}
+		if token := c.GetAPISettings().Token; token != "secret" {
+			t.Errorf("%s changed the token to %q", patch, token)
+		}
+
+		open := NewConfig()
+		open.SetAPISettings(settings)
+		if _, err := open.Update([]byte(patch)); err != nil {
+			t.Errorf("%s outside agent mode: %v", patch, err)
+		}
+		if token := open.GetAPISettings().Token; token != "" {
+			t.Errorf("%s outside agent mode left the token %q", patch, token)
+		}
+	}
+}
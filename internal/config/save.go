@@ -0,0 +1,253 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"time"
+
+	"lhcontrol/internal/paths"
+)
+
+const (
+	// saveDebounce is how long a save waits for more saves to join its write
+	saveDebounce = 200 * time.Millisecond
+	// lockTimeout bounds the wait for another process to finish writing
+	lockTimeout = 5 * time.Second
+	// lockRetry is how often a held lock is tried again
+	lockRetry = 50 * time.Millisecond
+)
+
+// diskState is what the config file held when this process last read or
+// wrote it. A file that changed since was written by another process.
+type diskState struct {
+	modTime time.Time
+	size    int64
+	base    []byte // The config as of then, the common ancestor for merging
+}
+
+// pendingSave is a write that saves join until it starts.
+type pendingSave struct {
+	done chan struct{}
+	err  error
+}
+
+// Save writes the configuration to disk, replacing the file atomically. Saves
+// made in quick succession are written once, after saveDebounce; each call
+// returns once the write that includes its changes finished.
+//
+// Other lhcontrol processes, like a second instance or a command line tool,
+// may save the same file. Writes take an advisory lock on "<config>.lock",
+// and if the file changed since this process last read or wrote it, the
+// changes on disk are merged in first: settings changed only there are
+// taken over, settings changed here win.
+func (c *Config) Save() error {
+	c.pendingMu.Lock()
+	p := c.pending
+	if p == nil {
+		p = &pendingSave{done: make(chan struct{})}
+		c.pending = p
+		time.AfterFunc(saveDebounce, func() {
+			// Saves from now on need a new write, this one may have
+			// snapshotted the config before their changes
+			c.pendingMu.Lock()
+			c.pending = nil
+			c.pendingMu.Unlock()
+			p.err = c.write()
+			close(p.done)
+		})
+	}
+	c.pendingMu.Unlock()
+	<-p.done
+	return p.err
+}
+
+func (c *Config) write() error {
+	configFilePath, err := paths.ConfigFile()
+	if err != nil {
+		return err
+	}
+
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	lock, err := lockConfigFile(configFilePath + ".lock")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlock(lock)
+		lock.Close()
+	}()
+
+	if err := c.mergeFromDisk(configFilePath); err != nil {
+		log.Printf("Config: Couldn't merge changes from '%s', overwriting them: %v", configFilePath, err)
+	}
+
+	configFile, err := c.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Saving config to: %s", configFilePath)
+	// Write a temporary file and rename it, so a crash or a concurrent
+	// reader never sees a half-written config
+	tmpPath := configFilePath + ".tmp"
+	if err := os.WriteFile(tmpPath, configFile, 0644); err != nil {
+		return fmt.Errorf("failed to write config file '%s': %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, configFilePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace config file '%s': %w", configFilePath, err)
+	}
+	info, err := os.Stat(configFilePath)
+	c.disk = diskState{base: configFile}
+	if err == nil {
+		c.disk.modTime, c.disk.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// lockConfigFile opens and locks path, waiting up to lockTimeout for another
+// process to release it.
+func lockConfigFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config lock '%s': %w", path, err)
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock '%s': %w", path, err)
+		}
+		if locked {
+			return f, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("config is locked by another process, '%s' was held for more than %v", path, lockTimeout)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// rememberDisk records the file Load just read, or the defaults if there
+// was none.
+func (c *Config) rememberDisk(info os.FileInfo, statErr error) {
+	base, err := c.Snapshot()
+	if err != nil {
+		return
+	}
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	c.disk = diskState{base: base}
+	if statErr == nil {
+		c.disk.modTime, c.disk.size = info.ModTime(), info.Size()
+	}
+}
+
+// mergeFromDisk takes over the changes another process saved since this one
+// last read or wrote the file. Assumes the caller holds saveMu and the file lock.
+func (c *Config) mergeFromDisk(configFilePath string) error {
+	info, err := os.Stat(configFilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if c.disk.base == nil || (info.ModTime().Equal(c.disk.modTime) && info.Size() == c.disk.size) {
+		return nil
+	}
+	theirsJSON, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return err
+	}
+	var base, theirs interface{}
+	if err := json.Unmarshal(c.disk.base, &base); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(theirsJSON, &theirs); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	oursJSON, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var ours interface{}
+	if err := json.Unmarshal(oursJSON, &ours); err != nil {
+		return err
+	}
+	mergedJSON, err := json.Marshal(merge3(base, ours, theirs))
+	if err != nil {
+		return err
+	}
+
+	// As in Update, decoding replaces every field but merges into maps
+	c.RenamedStations = nil
+	c.Stations = nil
+	c.Groups = nil
+	err = json.Unmarshal(mergedJSON, c)
+	if c.RenamedStations == nil {
+		c.RenamedStations = make(map[string]string)
+	}
+	if c.Stations == nil {
+		c.Stations = make(map[string]StationSettings)
+	}
+	if c.Groups == nil {
+		c.Groups = make(map[string][]string)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Config: Merged changes another process saved to '%s'", configFilePath)
+	return nil
+}
+
+// merge3 combines the changes ours and theirs made to base. Objects are
+// merged key by key; for anything else that both changed, ours wins.
+func merge3(base, ours, theirs interface{}) interface{} {
+	if reflect.DeepEqual(ours, base) {
+		return theirs
+	}
+	if reflect.DeepEqual(theirs, base) {
+		return ours
+	}
+	oursObject, oursOK := ours.(map[string]interface{})
+	theirsObject, theirsOK := theirs.(map[string]interface{})
+	if !oursOK || !theirsOK {
+		return ours
+	}
+	baseObject, _ := base.(map[string]interface{})
+
+	merged := make(map[string]interface{})
+	for key, value := range oursObject {
+		theirValue, inTheirs := theirsObject[key]
+		baseValue, inBase := baseObject[key]
+		if !inTheirs {
+			// Removed there, keep it only if it changed here
+			if !inBase || !reflect.DeepEqual(value, baseValue) {
+				merged[key] = value
+			}
+			continue
+		}
+		merged[key] = merge3(baseValue, value, theirValue)
+	}
+	for key, value := range theirsObject {
+		if _, inOurs := oursObject[key]; inOurs {
+			continue
+		}
+		// Removed here, keep it only if it changed there
+		if baseValue, inBase := baseObject[key]; !inBase || !reflect.DeepEqual(value, baseValue) {
+			merged[key] = value
+		}
+	}
+	return merged
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// useTempConfig points the config file to a temporary directory for the test.
+func useTempConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "APPDATA", "LOCALAPPDATA"} {
+		t.Setenv(env, dir)
+	}
+}
+
+// Two writers stand in for two processes: each has its own Config loaded from
+// the same file, and neither sees the other's changes in memory. Every rename
+// either of them saved must end up in the file.
+func TestConcurrentWritersLoseNoUpdates(t *testing.T) {
+	useTempConfig(t)
+	const writers, saves = 2, 5
+
+	configs := make([]*Config, writers)
+	for i := range configs {
+		configs[i] = NewConfig()
+		if err := configs[i].Load(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w, c := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < saves; i++ {
+				c.SetStationName(fmt.Sprintf("LHB-%d%07d", w, i), fmt.Sprintf("Writer %d station %d", w, i))
+				if err := c.Save(); err != nil {
+					t.Errorf("writer %d save %d: %v", w, i, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved := NewConfig()
+	if err := saved.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for w := 0; w < writers; w++ {
+		for i := 0; i < saves; i++ {
+			name := fmt.Sprintf("LHB-%d%07d", w, i)
+			want := fmt.Sprintf("Writer %d station %d", w, i)
+			if got, ok := saved.StationName(name); !ok || got != want {
+				t.Errorf("rename of %s was lost: got %q", name, got)
+			}
+		}
+	}
+}
+
+// Saves made at the same time in one process join a write, and each returns
+// once its own change is on disk.
+func TestSavesAreDebounced(t *testing.T) {
+	useTempConfig(t)
+	c := NewConfig()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.SetStationName(fmt.Sprintf("LHB-%08d", i), "Renamed")
+			if err := c.Save(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved := NewConfig()
+	if err := saved.Load(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := saved.StationName(fmt.Sprintf("LHB-%08d", i)); !ok {
+			t.Errorf("rename %d wasn't saved", i)
+		}
+	}
+}
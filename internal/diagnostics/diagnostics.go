@@ -0,0 +1,140 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"lhcontrol/internal/redact"
+)
+
+// Contents is the data collected into a diagnostics bundle.
+type Contents struct {
+	About    interface{}
+	Config   []byte // Config file JSON, secrets are removed on export
+	Stations interface{}
+	// Connection parameters and per-station connection counters
+	Connections interface{}
+	SelfTest    interface{} // Last self-test report, nil if none ran
+	Events      interface{} // Events published since start
+	LogFile     string      // Path of the log file, skipped if it doesn't exist
+	AuditFile   string      // Path of the power command journal, skipped if it doesn't exist
+	TraceFile   string      // Path of the BLE trace, skipped if it doesn't exist
+}
+
+// system describes the machine the bundle was created on.
+type system struct {
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	GoVersion string    `json:"goVersion"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Export writes a zip bundle to path. With redactAddresses, Bluetooth addresses
+// in every file are replaced with the same tokens the log redaction uses.
+func Export(path string, contents Contents, redactAddresses bool) error {
+	config, err := stripSecrets(contents.Config)
+	if err != nil {
+		return fmt.Errorf("failed to prepare config: %w", err)
+	}
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"system.json", system{OS: runtime.GOOS, Arch: runtime.GOARCH, GoVersion: runtime.Version(), CreatedAt: time.Now()}},
+		{"about.json", contents.About},
+		{"config.json", config},
+		{"stations.json", contents.Stations},
+		{"connections.json", contents.Connections},
+		{"selftest.json", contents.SelfTest},
+		{"events.json", contents.Events},
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", path, err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	write := func(name string, data []byte) error {
+		if redactAddresses {
+			data = redact.Bytes(data)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", file.name, err)
+		}
+		if err := write(file.name, data); err != nil {
+			return err
+		}
+	}
+	logs := []struct {
+		name string
+		path string
+	}{
+		{"lhcontrol.log", contents.LogFile},
+		{"audit.jsonl", contents.AuditFile},
+		{"ble-trace.log", contents.TraceFile},
+	}
+	for _, file := range logs {
+		if file.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(file.path)
+		if err == nil {
+			if err := write(file.name, data); err != nil {
+				return err
+			}
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", file.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish '%s': %w", path, err)
+	}
+	return f.Close()
+}
+
+// stripSecrets decodes the config JSON and blanks every "token" field, so API
+// and agent tokens never end up in a bundle.
+func stripSecrets(configJSON []byte) (interface{}, error) {
+	var config interface{}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, err
+	}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, value := range v {
+				if key == "token" {
+					if s, ok := value.(string); ok && s != "" {
+						v[key] = "<removed>"
+					}
+					continue
+				}
+				walk(value)
+			}
+		case []interface{}:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+	walk(config)
+	return config, nil
+}
@@ -0,0 +1,66 @@
+// Package discovery announces the address the running instance's API is bound
+// to, so clients don't have to assume the default port.
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"lhcontrol/internal/paths"
+)
+
+// Info is the content of the discovery file.
+type Info struct {
+	Address string `json:"address"` // host:port the API is listening on
+	PID     int    `json:"pid"`
+}
+
+// Write records the API address of this process.
+func Write(address string) error {
+	filePath, err := paths.DiscoveryFile()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(Info{Address: address, PID: os.Getpid()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write discovery file '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+// Read returns the announced API address. It fails if no instance announced one.
+func Read() (Info, error) {
+	filePath, err := paths.DiscoveryFile()
+	if err != nil {
+		return Info{}, err
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to read discovery file '%s': %w", filePath, err)
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, fmt.Errorf("failed to parse discovery file '%s': %w", filePath, err)
+	}
+	return info, nil
+}
+
+// Remove deletes the discovery file if it was written by this process.
+func Remove() error {
+	info, err := Read()
+	if err != nil || info.PID != os.Getpid() {
+		return nil
+	}
+	filePath, err := paths.DiscoveryFile()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove discovery file '%s': %w", filePath, err)
+	}
+	return nil
+}
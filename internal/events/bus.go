@@ -0,0 +1,82 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a named notification published by the application.
+type Event struct {
+	Name string      `json:"name"`
+	Data interface{} `json:"data"`
+	Time time.Time   `json:"time"`
+}
+
+// Bus fans events out to in-process subscribers such as the API event stream,
+// and keeps them in a Journal for later lookup.
+type Bus struct {
+	mu      sync.RWMutex
+	subs    map[int]chan Event
+	nextID  int
+	journal *Journal
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subs:    make(map[int]chan Event),
+		journal: NewJournal(),
+	}
+}
+
+// Journal returns the journal of recently published events.
+func (b *Bus) Journal() *Journal {
+	return b.journal
+}
+
+// Publish delivers an event to all subscribers. Slow subscribers whose
+// buffer is full miss the event rather than blocking the publisher.
+func (b *Bus) Publish(name string, data interface{}) {
+	event := Event{Name: name, Data: data, Time: time.Now()}
+	b.journal.Record(event)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving future events and a function that
+// ends the subscription and closes the channel.
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		// Already gone if the bus was closed or the subscription ended before
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// Close ends all subscriptions. Subscribers see their channel closed.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, ch := range b.subs {
+		delete(b.subs, id)
+		close(ch)
+	}
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	// maxJournalEvents and maxJournalBytes cap the journal; the oldest events
+	// are dropped once either is reached
+	maxJournalEvents = 2000
+	maxJournalBytes  = 2 << 20
+	// maxJournalEventBytes is the largest payload kept; bigger ones are
+	// recorded without their data
+	maxJournalEventBytes = 64 << 10
+)
+
+// Record is a published event as kept in the journal.
+type Record struct {
+	Seq       uint64          `json:"seq"` // Increases by one per event, starting at 1 on every start
+	Name      string          `json:"name"`
+	Time      time.Time       `json:"time"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"` // Data was too large to keep
+}
+
+// Query selects journal records. Zero fields match everything.
+type Query struct {
+	AfterSeq  uint64    // Only records with a higher sequence number
+	AfterTime time.Time // Only records published later
+	Names     []string  // Only records with one of these names
+	Limit     int       // Most recent records to return
+}
+
+// Journal keeps the most recent events in memory, so what happened can be
+// looked at later without a client having been connected. Readers never
+// remove records; the journal only empties on restart.
+type Journal struct {
+	mu      sync.RWMutex
+	records []Record // Oldest first
+	bytes   int
+	lastSeq uint64
+}
+
+// NewJournal creates an empty Journal.
+func NewJournal() *Journal {
+	return &Journal{}
+}
+
+// Record adds an event. The payload is encoded right away, so later changes
+// to it don't show up in the journal.
+func (j *Journal) Record(event Event) {
+	data, err := json.Marshal(event.Data)
+	truncated := false
+	if err != nil || len(data) > maxJournalEventBytes {
+		data, truncated = nil, true
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.lastSeq++
+	record := Record{Seq: j.lastSeq, Name: event.Name, Time: event.Time, Data: data, Truncated: truncated}
+	j.records = append(j.records, record)
+	j.bytes += recordSize(record)
+	drop := 0
+	for len(j.records)-drop > maxJournalEvents || j.bytes > maxJournalBytes {
+		j.bytes -= recordSize(j.records[drop])
+		drop++
+	}
+	if drop > 0 {
+		j.records = append([]Record(nil), j.records[drop:]...)
+	}
+}
+
+// Recent returns the records matching q, oldest first.
+func (j *Journal) Recent(q Query) []Record {
+	names := make(map[string]bool, len(q.Names))
+	for _, name := range q.Names {
+		names[name] = true
+	}
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	result := []Record{}
+	for _, record := range j.records {
+		if record.Seq <= q.AfterSeq {
+			continue
+		}
+		if !q.AfterTime.IsZero() && !record.Time.After(q.AfterTime) {
+			continue
+		}
+		if len(names) > 0 && !names[record.Name] {
+			continue
+		}
+		result = append(result, record)
+	}
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[len(result)-q.Limit:]
+	}
+	return result
+}
+
+// LastSeq returns the sequence number of the newest record, 0 if there is none.
+func (j *Journal) LastSeq() uint64 {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.lastSeq
+}
+
+// recordSize estimates the memory a record takes.
+func recordSize(r Record) int {
+	return len(r.Name) + len(r.Data) + 64
+}
@@ -0,0 +1,39 @@
+package instance
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+)
+
+// IsAddrInUse reports whether a listen error means the port is taken, i.e.
+// another instance is most likely running.
+func IsAddrInUse(err error) bool {
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		for _, code := range addrInUseErrnos {
+			if errno == code {
+				return true
+			}
+		}
+		return false
+	}
+	// Conservative fallback for errors that don't carry an errno
+	msg := err.Error()
+	return strings.Contains(msg, "address already in use") || strings.Contains(msg, "Only one usage of each socket address")
+}
+
+// IsAccessDenied reports whether a listen error means binding the port is
+// not permitted, e.g. because it is reserved or blocked by policy.
+func IsAccessDenied(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	for _, code := range accessDeniedErrnos {
+		if errno == code {
+			return true
+		}
+	}
+	return false
+}
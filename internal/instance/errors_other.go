@@ -0,0 +1,10 @@
+//go:build !windows
+
+package instance
+
+import "syscall"
+
+var (
+	addrInUseErrnos    = []syscall.Errno{syscall.EADDRINUSE}
+	accessDeniedErrnos = []syscall.Errno{syscall.EACCES, syscall.EPERM}
+)
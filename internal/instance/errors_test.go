@@ -0,0 +1,82 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// listenError wraps errno the way net.Listen does.
+func listenError(errno syscall.Errno) error {
+	return &net.OpError{
+		Op:   "listen",
+		Net:  "tcp",
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 47821},
+		Err:  os.NewSyscallError("bind", errno),
+	}
+}
+
+func TestIsAddrInUse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"net.OpError", listenError(addrInUseErrnos[0]), true},
+		{"os.SyscallError", os.NewSyscallError("bind", addrInUseErrnos[0]), true},
+		{"wrapped", fmt.Errorf("instance lock: %w", listenError(addrInUseErrnos[0])), true},
+		{"access denied", listenError(accessDeniedErrnos[0]), false},
+		{"other errno", listenError(syscall.EINVAL), false},
+		// Errors without an errno fall back to the message
+		{"message", errors.New("listen tcp 127.0.0.1:47821: bind: address already in use"), true},
+		{"windows message", errors.New("bind: Only one usage of each socket address is normally permitted."), true},
+		{"unrelated message", errors.New("listen tcp: lookup localhost: no such host"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAddrInUse(tt.err); got != tt.want {
+				t.Errorf("IsAddrInUse(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAccessDenied(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"net.OpError", listenError(accessDeniedErrnos[0]), true},
+		{"os.SyscallError", os.NewSyscallError("bind", accessDeniedErrnos[0]), true},
+		{"address in use", listenError(addrInUseErrnos[0]), false},
+		// No message fallback, a denied port is only reported when certain
+		{"message", errors.New("bind: permission denied"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAccessDenied(tt.err); got != tt.want {
+				t.Errorf("IsAccessDenied(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsAddrInUseTakenPort(t *testing.T) {
+	first, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("can't listen: %v", err)
+	}
+	defer first.Close()
+	second, err := net.Listen("tcp", first.Addr().String())
+	if err == nil {
+		second.Close()
+		t.Skip("the platform allows binding the port twice")
+	}
+	if !IsAddrInUse(err) {
+		t.Errorf("IsAddrInUse(%v) = false for a taken port", err)
+	}
+}
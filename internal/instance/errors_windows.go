@@ -0,0 +1,17 @@
+//go:build windows
+
+package instance
+
+import "syscall"
+
+// Winsock reports its own error codes, which syscall.EADDRINUSE and
+// syscall.EACCES don't match on Windows.
+const (
+	wsaeacces     = syscall.Errno(10013)
+	wsaeaddrinuse = syscall.Errno(10048)
+)
+
+var (
+	addrInUseErrnos    = []syscall.Errno{wsaeaddrinuse, syscall.EADDRINUSE}
+	accessDeniedErrnos = []syscall.Errno{wsaeacces, syscall.EACCES}
+)
@@ -0,0 +1,14 @@
+//go:build windows
+
+package instance
+
+import "testing"
+
+func TestWinsockErrnos(t *testing.T) {
+	if !IsAddrInUse(listenError(wsaeaddrinuse)) {
+		t.Error("WSAEADDRINUSE isn't reported as address in use")
+	}
+	if !IsAccessDenied(listenError(wsaeacces)) {
+		t.Error("WSAEACCES isn't reported as access denied")
+	}
+}
@@ -0,0 +1,55 @@
+package instance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// Forward sends command line arguments to the running instance listening on addr.
+func Forward(addr string, args []string) error {
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to running instance: %w", err)
+	}
+	defer conn.Close()
+
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	conn.SetWriteDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write(append(payload, '\n')); err != nil {
+		return fmt.Errorf("failed to forward arguments: %w", err)
+	}
+	return nil
+}
+
+// Serve accepts forwarded arguments on the instance lock listener and passes
+// them to handle. It returns when the listener is closed.
+func Serve(listener net.Listener, handle func(args []string)) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			c.SetReadDeadline(time.Now().Add(2 * time.Second))
+			line, err := bufio.NewReader(c).ReadBytes('\n')
+			if err != nil {
+				log.Printf("Instance: Failed to read forwarded arguments: %v", err)
+				return
+			}
+			var args []string
+			if err := json.Unmarshal(line, &args); err != nil {
+				log.Printf("Instance: Ignoring malformed forwarded arguments: %v", err)
+				return
+			}
+			handle(args)
+		}(conn)
+	}
+}
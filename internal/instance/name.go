@@ -0,0 +1,80 @@
+package instance
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+)
+
+// defaultLockPort is the instance lock port of the unnamed instance.
+const defaultLockPort = 34115
+
+// lockPortRange and apiPortRange bound the ports derived from an instance name
+const (
+	lockPortRange = 1000
+	apiPortRange  = 100
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// name is the instance name, empty for the default instance
+var name string
+
+// SetName selects a named instance. Everything that assumes a single copy of
+// lhcontrol is namespaced by it: the instance lock, the data directories, the
+// default API port and the window title. It must be called before any of
+// them is used.
+func SetName(instanceName string) error {
+	if instanceName != "" && !validName.MatchString(instanceName) {
+		return fmt.Errorf("invalid instance name '%s': use up to 32 lowercase letters, digits and dashes", instanceName)
+	}
+	name = instanceName
+	return nil
+}
+
+// Name returns the instance name, empty for the default instance.
+func Name() string {
+	return name
+}
+
+// Suffix returns "-<name>" for a named instance, to append to file and
+// directory names, and "" for the default instance.
+func Suffix() string {
+	if name == "" {
+		return ""
+	}
+	return "-" + name
+}
+
+// Title returns the window title for this instance.
+func Title(appTitle string) string {
+	if name == "" {
+		return appTitle
+	}
+	return fmt.Sprintf("%s (%s)", appTitle, name)
+}
+
+// LockPort returns the loopback port whose listener marks this instance as
+// running. Named instances get a port derived from the name, so copies of
+// the same instance still find each other.
+func LockPort() int {
+	if name == "" {
+		return defaultLockPort
+	}
+	return defaultLockPort + 1 + int(nameHash()%lockPortRange)
+}
+
+// APIPort returns the default API port for this instance, derived from the
+// base port so differently named instances don't start on the same one.
+func APIPort(base int) int {
+	if name == "" {
+		return base
+	}
+	return base + 1 + int(nameHash()%apiPortRange)
+}
+
+func nameHash() uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return h.Sum32()
+}
@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Job states
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// maxFinished is how many finished jobs are kept for GetJobResult.
+const maxFinished = 100
+
+// Job describes a power command running in the background.
+type Job struct {
+	ID         string    `json:"id"`
+	Action     string    `json:"action"`
+	Address    string    `json:"address"` // Empty for commands on all stations
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// Result is what the command returned besides its error, e.g. the
+	// outcome of every station for a command on all stations.
+	Result interface{} `json:"result,omitempty"`
+}
+
+type entry struct {
+	job  Job
+	err  error
+	done chan struct{}
+}
+
+// Manager runs jobs and keeps their results. Commands to the same station are
+// still serialized by the bluetooth layer, so jobs only decouple the caller
+// from the BLE operation.
+type Manager struct {
+	mu       sync.Mutex
+	jobs     map[string]*entry
+	finished []string // IDs of finished jobs, oldest first
+	nextID   uint64
+	emit     func(eventName string, data ...interface{})
+}
+
+// NewManager creates a Manager that publishes "job-started" and
+// "job-completed" events through emit.
+func NewManager(emit func(eventName string, data ...interface{})) *Manager {
+	return &Manager{
+		jobs: make(map[string]*entry),
+		emit: emit,
+	}
+}
+
+// Submit starts run in the background and returns the job ID.
+func (m *Manager) Submit(action, address string, run func() error) string {
+	return m.SubmitResult(action, address, func() (interface{}, error) {
+		return nil, run()
+	})
+}
+
+// SubmitResult is Submit for commands with a result, which is kept in the
+// job and sent with its "job-completed" event.
+func (m *Manager) SubmitResult(action, address string, run func() (interface{}, error)) string {
+	m.mu.Lock()
+	m.nextID++
+	e := &entry{
+		job: Job{
+			ID:        fmt.Sprintf("job-%d", m.nextID),
+			Action:    action,
+			Address:   address,
+			Status:    StatusRunning,
+			StartedAt: time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+	m.jobs[e.job.ID] = e
+	started := e.job
+	m.mu.Unlock()
+
+	m.emit("job-started", started)
+	go m.run(e, run)
+	return started.ID
+}
+
+func (m *Manager) run(e *entry, run func() (interface{}, error)) {
+	result, err := run()
+
+	m.mu.Lock()
+	e.err = err
+	e.job.Result = result
+	e.job.FinishedAt = time.Now()
+	if err != nil {
+		e.job.Status = StatusFailed
+		e.job.Error = err.Error()
+	} else {
+		e.job.Status = StatusSucceeded
+	}
+	finished := e.job
+	m.finished = append(m.finished, finished.ID)
+	if len(m.finished) > maxFinished {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+	close(e.done)
+	m.mu.Unlock()
+
+	m.emit("job-completed", finished)
+}
+
+// Get returns the current state of a job.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// Wait blocks until the job finishes or ctx is done and returns the job's own error.
+func (m *Manager) Wait(ctx context.Context, id string) error {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown job '%s'", id)
+	}
+	select {
+	case <-e.done:
+		return e.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// recorder collects the events a Manager emits.
+type recorder struct {
+	events chan Job
+}
+
+func (r *recorder) emit(eventName string, data ...interface{}) {
+	if eventName == "job-completed" {
+		r.events <- data[0].(Job)
+	}
+}
+
+func TestSubmitResult(t *testing.T) {
+	r := &recorder{events: make(chan Job, 1)}
+	m := NewManager(r.emit)
+	failed := errors.New("one station failed")
+	results := []string{"on", "failed"}
+
+	id := m.SubmitResult("on", "", func() (interface{}, error) {
+		return results, failed
+	})
+	if err := m.Wait(context.Background(), id); err != failed {
+		t.Fatalf("Wait returned %v, want the job's error", err)
+	}
+
+	event := <-r.events
+	if event.ID != id || event.Status != StatusFailed || event.Error != failed.Error() {
+		t.Errorf("job-completed event is %+v", event)
+	}
+	if got, ok := event.Result.([]string); !ok || len(got) != 2 {
+		t.Errorf("job-completed event carries result %v, want %v", event.Result, results)
+	}
+	if job, _ := m.Get(id); job.Result == nil {
+		t.Error("the finished job lost its result")
+	}
+}
+
+func TestSubmitWithoutResult(t *testing.T) {
+	r := &recorder{events: make(chan Job, 1)}
+	m := NewManager(r.emit)
+	id := m.Submit("off", "02:00:00:00:00:01", func() error { return nil })
+	if err := m.Wait(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	event := <-r.events
+	if event.Status != StatusSucceeded || event.Result != nil {
+		t.Errorf("job-completed event is %+v", event)
+	}
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Scheme is the URI scheme used by notification buttons to send commands
+// back to the running instance.
+const Scheme = "lhcontrol"
+
+// AppUserModelID identifies lhcontrol to the Windows shell for notifications
+// and the jump list. Unpackaged apps have no AppUserModelID unless they register one.
+const AppUserModelID = "FlameInTheDark.lhcontrol"
+
+// ErrUnsupported is returned when the platform can't show notifications.
+var ErrUnsupported = errors.New("notifications are not supported on this platform")
+
+// Action is a button on a notification.
+type Action struct {
+	Label string
+	URI   string // Activation URI, see CommandURI
+}
+
+// Notification is a desktop notification with optional action buttons.
+type Notification struct {
+	Title   string
+	Message string
+	Actions []Action
+}
+
+// CommandURI builds an activation URI such as "lhcontrol:off?address=...".
+func CommandURI(command string, params url.Values) string {
+	uri := Scheme + ":" + command
+	if len(params) > 0 {
+		uri += "?" + params.Encode()
+	}
+	return uri
+}
+
+// ParseCommandURI splits an activation URI into its command and parameters.
+func ParseCommandURI(uri string) (string, url.Values, error) {
+	if !strings.HasPrefix(uri, Scheme+":") {
+		return "", nil, fmt.Errorf("not a %s URI: %s", Scheme, uri)
+	}
+	rest := strings.TrimLeft(strings.TrimPrefix(uri, Scheme+":"), "/")
+	command, query, _ := strings.Cut(rest, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid %s URI parameters: %w", Scheme, err)
+	}
+	return strings.TrimSuffix(command, "/"), params, nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package notify
+
+// Register is a no-op on non-Windows platforms.
+func Register(appName string) error {
+	return nil
+}
+
+// Show is not implemented on non-Windows platforms yet.
+func Show(n Notification, withActions bool) error {
+	return ErrUnsupported
+}
+
+// Unregister is a no-op on non-Windows platforms.
+func Unregister() error {
+	return nil
+}
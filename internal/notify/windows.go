@@ -0,0 +1,192 @@
+//go:build windows
+
+package notify
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const createNoWindow = 0x08000000
+
+var (
+	shell32                                   = syscall.NewLazyDLL("shell32.dll")
+	procSetCurrentProcessExplicitAppUserModel = shell32.NewProc("SetCurrentProcessExplicitAppUserModelID")
+)
+
+var (
+	registerOnce sync.Once
+	toastsReady  bool // False when registration failed and toasts can't be shown
+)
+
+// Register sets up the AppUserModelID and the lhcontrol: protocol handler that
+// notification buttons use to reach the app. Failures leave toasts disabled,
+// Show then falls back to balloon notifications.
+func Register(appName string) error {
+	var err error
+	registerOnce.Do(func() {
+		err = register(appName)
+		toastsReady = err == nil
+	})
+	return err
+}
+
+func register(appName string) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	idPtr, err := syscall.UTF16PtrFromString(AppUserModelID)
+	if err != nil {
+		return err
+	}
+	if hr, _, _ := procSetCurrentProcessExplicitAppUserModel.Call(uintptr(unsafe.Pointer(idPtr))); hr != 0 {
+		return fmt.Errorf("SetCurrentProcessExplicitAppUserModelID failed: 0x%x", hr)
+	}
+
+	aumidKey, _, err := registry.CreateKey(registry.CURRENT_USER, `Software\Classes\AppUserModelId\`+AppUserModelID, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to register AppUserModelID: %w", err)
+	}
+	defer aumidKey.Close()
+	if err := aumidKey.SetStringValue("DisplayName", appName); err != nil {
+		return fmt.Errorf("failed to register AppUserModelID: %w", err)
+	}
+
+	protocolKey, _, err := registry.CreateKey(registry.CURRENT_USER, `Software\Classes\`+Scheme, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to register %s protocol: %w", Scheme, err)
+	}
+	defer protocolKey.Close()
+	if err := protocolKey.SetStringValue("", "URL:"+appName); err != nil {
+		return fmt.Errorf("failed to register %s protocol: %w", Scheme, err)
+	}
+	if err := protocolKey.SetStringValue("URL Protocol", ""); err != nil {
+		return fmt.Errorf("failed to register %s protocol: %w", Scheme, err)
+	}
+
+	commandKey, _, err := registry.CreateKey(protocolKey, `shell\open\command`, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to register %s protocol: %w", Scheme, err)
+	}
+	defer commandKey.Close()
+	if err := commandKey.SetStringValue("", fmt.Sprintf(`"%s" "%%1"`, exePath)); err != nil {
+		return fmt.Errorf("failed to register %s protocol: %w", Scheme, err)
+	}
+	return nil
+}
+
+// Show displays a toast notification. Action buttons are only added when
+// withActions is set. If the toast can't be shown, a balloon notification
+// without buttons is shown instead.
+func Show(n Notification, withActions bool) error {
+	if toastsReady {
+		err := runPowerShell(toastScript(n, withActions), true)
+		if err == nil {
+			return nil
+		}
+		log.Printf("Notify: Toast failed, falling back to balloon: %v", err)
+	}
+	// The balloon has to stay alive while it's displayed, don't block the caller
+	return runPowerShell(balloonScript(n), false)
+}
+
+func runPowerShell(script string, wait bool) error {
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", "-")
+	cmd.Stdin = strings.NewReader(script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+	if !wait {
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start powershell: %w", err)
+		}
+		go cmd.Wait()
+		return nil
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("powershell failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// psQuote quotes a string as a PowerShell single-quoted literal.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// toastXML renders the toast content. Buttons use protocol activation, so
+// clicking one launches lhcontrol with the URI, which forwards it to the
+// running instance.
+func toastXML(n Notification, withActions bool) string {
+	var b strings.Builder
+	b.WriteString(`<toast><visual><binding template="ToastGeneric">`)
+	fmt.Fprintf(&b, `<text>%s</text><text>%s</text>`, xmlEscape(n.Title), xmlEscape(n.Message))
+	b.WriteString(`</binding></visual>`)
+	if withActions && len(n.Actions) > 0 {
+		b.WriteString(`<actions>`)
+		for _, action := range n.Actions {
+			fmt.Fprintf(&b, `<action content="%s" activationType="protocol" arguments="%s"/>`, xmlEscape(action.Label), xmlEscape(action.URI))
+		}
+		b.WriteString(`</actions>`)
+	}
+	b.WriteString(`</toast>`)
+	return b.String()
+}
+
+func toastScript(n Notification, withActions bool) string {
+	return fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml(%s)
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(%s).Show($toast)
+`, psQuote(toastXML(n, withActions)), psQuote(AppUserModelID))
+}
+
+func balloonScript(n Notification) string {
+	exePath, _ := os.Executable()
+	return fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+$balloon = New-Object System.Windows.Forms.NotifyIcon
+$balloon.Icon = [System.Drawing.Icon]::ExtractAssociatedIcon(%s)
+$balloon.BalloonTipTitle = %s
+$balloon.BalloonTipText = %s
+$balloon.Visible = $true
+$balloon.ShowBalloonTip(10000)
+Start-Sleep -Seconds 10
+$balloon.Dispose()
+`, psQuote(exePath), psQuote(n.Title), psQuote(n.Message))
+}
+
+// Unregister removes the AppUserModelID and protocol handler registration.
+func Unregister() error {
+	keys := []string{
+		`Software\Classes\` + Scheme + `\shell\open\command`,
+		`Software\Classes\` + Scheme + `\shell\open`,
+		`Software\Classes\` + Scheme + `\shell`,
+		`Software\Classes\` + Scheme,
+		`Software\Classes\AppUserModelId\` + AppUserModelID,
+	}
+	for _, key := range keys {
+		if err := registry.DeleteKey(registry.CURRENT_USER, key); err != nil && err != registry.ErrNotExist {
+			return fmt.Errorf("failed to delete registry key '%s': %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+//go:build darwin
+
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// resolve uses the standard macOS Library locations.
+func resolve() (Paths, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Paths{}, fmt.Errorf("failed to get home dir: %w", err)
+	}
+	support := filepath.Join(home, "Library", "Application Support", appDir())
+	return Paths{
+		Config: support,
+		State:  filepath.Join(support, "State"),
+		Cache:  filepath.Join(home, "Library", "Caches", appDir()),
+		Log:    filepath.Join(home, "Library", "Logs", appDir()),
+	}, nil
+}
@@ -0,0 +1,172 @@
+package paths
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"lhcontrol/internal/instance"
+)
+
+const appDirName = "lhcontrol"
+
+// appDir returns the name of the application directories, suffixed with the
+// instance name for named instances.
+func appDir() string {
+	return appDirName + instance.Suffix()
+}
+
+// Paths holds the per-platform directories used by the application.
+type Paths struct {
+	Config string `json:"config"` // config.json
+	State  string `json:"state"`  // Persistent runtime state (stats, timers)
+	Cache  string `json:"cache"`  // Disposable data
+	Log    string `json:"log"`    // Log files and crash dumps
+}
+
+// Resolve returns the application directories for the current platform,
+// creating them if they don't exist yet.
+func Resolve() (Paths, error) {
+	p, err := resolve()
+	if err != nil {
+		return Paths{}, err
+	}
+	for _, dir := range []string{p.Config, p.State, p.Cache, p.Log} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return Paths{}, fmt.Errorf("failed to create directory '%s': %w", dir, err)
+		}
+	}
+	return p, nil
+}
+
+// ConfigFile returns the full path to the config file.
+func ConfigFile() (string, error) {
+	p, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.Config, "config.json"), nil
+}
+
+// DiscoveryFile returns the full path to the file announcing the running instance's API address.
+func DiscoveryFile() (string, error) {
+	p, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.Config, "api.json"), nil
+}
+
+// StateFile returns the full path to a file in the state directory.
+func StateFile(name string) (string, error) {
+	p, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.State, name), nil
+}
+
+// LogFile returns the full path to the log file.
+func LogFile() (string, error) {
+	p, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.Log, "lhcontrol.log"), nil
+}
+
+// TranscriptFile returns a new path for a BLE transcript in the log directory.
+func TranscriptFile(started time.Time) (string, error) {
+	p, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.Log, "ble-transcript-"+started.Format("20060102-150405")+".jsonl"), nil
+}
+
+// TraceFile returns the path of the BLE trace in the log directory.
+func TraceFile() (string, error) {
+	p, err := Resolve()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.Log, "ble-trace.log"), nil
+}
+
+// legacyFiles lists files from older versions and where they live now.
+func legacyFiles() (map[string]string, error) {
+	files := make(map[string]string)
+
+	// Config used to live in os.UserConfigDir()/lhcontrol on every platform
+	if configDir, err := os.UserConfigDir(); err == nil {
+		configFile, err := ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Join(configDir, appDirName, "config.json")] = configFile
+	}
+
+	// Logs used to be written next to the executable
+	if exePath, err := os.Executable(); err == nil {
+		logFile, err := LogFile()
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Join(filepath.Dir(exePath), "lhcontrol.log")] = logFile
+	}
+	return files, nil
+}
+
+// Migrate copies files from legacy locations to the current ones on first run.
+// The legacy file is left in place with a ".bak" suffix.
+func Migrate() error {
+	if instance.Name() != "" {
+		return nil // Only the default instance existed in older versions
+	}
+	files, err := legacyFiles()
+	if err != nil {
+		return err
+	}
+	for legacyPath, currentPath := range files {
+		if filepath.Clean(legacyPath) == filepath.Clean(currentPath) {
+			continue
+		}
+		if _, err := os.Stat(legacyPath); err != nil {
+			continue // Nothing to migrate
+		}
+		if _, err := os.Stat(currentPath); err == nil {
+			continue // Already migrated, don't overwrite newer data
+		}
+
+		log.Printf("Migrating '%s' to '%s'", legacyPath, currentPath)
+		if err := copyFile(legacyPath, currentPath); err != nil {
+			return fmt.Errorf("failed to migrate '%s': %w", legacyPath, err)
+		}
+		if err := os.Rename(legacyPath, legacyPath+".bak"); err != nil {
+			log.Printf("Failed to rename migrated file '%s' to backup: %v", legacyPath, err)
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
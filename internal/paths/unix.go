@@ -0,0 +1,32 @@
+//go:build !windows && !darwin
+
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// xdgDir returns $envVar, or $HOME/fallback when it is unset or not absolute.
+func xdgDir(envVar, home, fallback string) string {
+	if dir := os.Getenv(envVar); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(home, fallback)
+}
+
+// resolve follows the XDG Base Directory specification.
+func resolve() (Paths, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Paths{}, fmt.Errorf("failed to get home dir: %w", err)
+	}
+	state := filepath.Join(xdgDir("XDG_STATE_HOME", home, ".local/state"), appDir())
+	return Paths{
+		Config: filepath.Join(xdgDir("XDG_CONFIG_HOME", home, ".config"), appDir()),
+		State:  state,
+		Cache:  filepath.Join(xdgDir("XDG_CACHE_HOME", home, ".cache"), appDir()),
+		Log:    filepath.Join(state, "logs"),
+	}, nil
+}
@@ -0,0 +1,61 @@
+//go:build windows
+
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	shell32                  = syscall.NewLazyDLL("shell32.dll")
+	ole32                    = syscall.NewLazyDLL("ole32.dll")
+	procSHGetKnownFolderPath = shell32.NewProc("SHGetKnownFolderPath")
+	procCoTaskMemFree        = ole32.NewProc("CoTaskMemFree")
+)
+
+// Known folder IDs (from KnownFolders.h)
+var (
+	folderIDRoamingAppData = syscall.GUID{Data1: 0x3EB685DB, Data2: 0x65F9, Data3: 0x4CF6, Data4: [8]byte{0xA0, 0x3A, 0xE3, 0xEF, 0x65, 0x72, 0x9F, 0x3D}}
+	folderIDLocalAppData   = syscall.GUID{Data1: 0xF1B32785, Data2: 0x6FBA, Data3: 0x4FCF, Data4: [8]byte{0x9D, 0x55, 0x7B, 0x8E, 0x7F, 0x15, 0x70, 0x91}}
+)
+
+// knownFolderPath resolves a known folder, falling back to an environment variable.
+func knownFolderPath(id *syscall.GUID, fallbackEnv string) (string, error) {
+	var pathPtr *uint16
+	hr, _, _ := procSHGetKnownFolderPath.Call(uintptr(unsafe.Pointer(id)), 0, 0, uintptr(unsafe.Pointer(&pathPtr)))
+	if hr == 0 && pathPtr != nil {
+		defer procCoTaskMemFree.Call(uintptr(unsafe.Pointer(pathPtr)))
+		n := 0
+		for ptr := unsafe.Pointer(pathPtr); *(*uint16)(ptr) != 0; ptr = unsafe.Add(ptr, 2) {
+			n++
+		}
+		return syscall.UTF16ToString(unsafe.Slice(pathPtr, n)), nil
+	}
+	if dir := os.Getenv(fallbackEnv); dir != "" {
+		return dir, nil
+	}
+	return "", fmt.Errorf("failed to resolve known folder (HRESULT 0x%X) and %%%s%% is not set", hr, fallbackEnv)
+}
+
+// resolve uses the roaming profile for config and the local profile for everything else.
+func resolve() (Paths, error) {
+	roaming, err := knownFolderPath(&folderIDRoamingAppData, "APPDATA")
+	if err != nil {
+		return Paths{}, err
+	}
+	local, err := knownFolderPath(&folderIDLocalAppData, "LOCALAPPDATA")
+	if err != nil {
+		return Paths{}, err
+	}
+	localApp := filepath.Join(local, appDir())
+	return Paths{
+		Config: filepath.Join(roaming, appDir()),
+		State:  filepath.Join(localApp, "State"),
+		Cache:  filepath.Join(localApp, "Cache"),
+		Log:    filepath.Join(localApp, "Logs"),
+	}, nil
+}
@@ -0,0 +1,84 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	ole32                = syscall.NewLazyDLL("ole32.dll")
+	procCoInitializeEx   = ole32.NewProc("CoInitializeEx")
+	procCoUninitialize   = ole32.NewProc("CoUninitialize")
+	procCoCreateInstance = ole32.NewProc("CoCreateInstance")
+)
+
+const (
+	coinitApartmentThreaded = 0x2
+	clsctxInprocServer      = 0x1
+)
+
+// IUnknown vtable slots
+const (
+	vtblQueryInterface = 0
+	vtblRelease        = 2
+)
+
+// comObject is the layout of a COM interface, which starts with its vtable
+// pointer. Interfaces are always handled as *comObject so they stay
+// unsafe.Pointer from the out-parameter of the call that returned them.
+type comObject struct {
+	vtbl unsafe.Pointer
+}
+
+// method returns the address of the function in the given vtable slot.
+// Callers pass it to syscall.SyscallN directly so pointer arguments stay valid during the call.
+func (o *comObject) method(slot int) uintptr {
+	return *(*uintptr)(unsafe.Add(o.vtbl, uintptr(slot)*unsafe.Sizeof(uintptr(0))))
+}
+
+func (o *comObject) release() {
+	if o != nil {
+		syscall.SyscallN(o.method(vtblRelease), uintptr(unsafe.Pointer(o)))
+	}
+}
+
+// hresult converts a failed HRESULT into an error.
+func hresult(hr uintptr, what string) error {
+	if int32(hr) < 0 {
+		return fmt.Errorf("%s failed: HRESULT 0x%08x", what, uint32(hr))
+	}
+	return nil
+}
+
+func createInstance(clsid, iid *syscall.GUID) (*comObject, error) {
+	var obj *comObject
+	hr, _, _ := procCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(clsid)), 0, clsctxInprocServer,
+		uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&obj)))
+	if err := hresult(hr, "CoCreateInstance"); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// withCOM runs fn on a locked OS thread with COM initialized.
+func withCOM(fn func() error) error {
+	errChan := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		hr, _, _ := procCoInitializeEx.Call(0, coinitApartmentThreaded)
+		if err := hresult(hr, "CoInitializeEx"); err != nil {
+			errChan <- err
+			return
+		}
+		defer procCoUninitialize.Call()
+		errChan <- fn()
+	}()
+	return <-errChan
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package platform
+
+// OnConsoleClose is a no-op on non-Windows platforms, where closing the
+// terminal sends SIGHUP or SIGTERM instead.
+func OnConsoleClose(cleanup func()) error {
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build windows
+
+package platform
+
+import (
+	"syscall"
+)
+
+var (
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleCtrlHandler = kernel32.NewProc("SetConsoleCtrlHandler")
+)
+
+// Console control events (from wincon.h)
+const (
+	CTRL_C_EVENT        = 0
+	CTRL_BREAK_EVENT    = 1
+	CTRL_CLOSE_EVENT    = 2
+	CTRL_LOGOFF_EVENT   = 5
+	CTRL_SHUTDOWN_EVENT = 6
+)
+
+// OnConsoleClose runs cleanup when the console window is closed or the user
+// logs off or shuts down. Windows terminates the process as soon as the handler
+// returns, and after about five seconds even if it doesn't, so cleanup must be
+// quick. Ctrl+C and Ctrl+Break are left to os/signal.
+func OnConsoleClose(cleanup func()) error {
+	callback := syscall.NewCallback(func(event uint32) uintptr {
+		switch event {
+		case CTRL_CLOSE_EVENT, CTRL_LOGOFF_EVENT, CTRL_SHUTDOWN_EVENT:
+			cleanup()
+			return 1
+		}
+		return 0
+	})
+	ret, _, err := procSetConsoleCtrlHandler.Call(callback, 1)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
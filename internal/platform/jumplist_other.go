@@ -0,0 +1,19 @@
+//go:build !windows
+
+package platform
+
+// JumpTask is an entry in the "Tasks" section of the taskbar jump list.
+type JumpTask struct {
+	Title     string
+	Arguments string // Command line arguments passed to the executable
+}
+
+// SetJumpListTasks is a no-op on non-Windows platforms.
+func SetJumpListTasks(appID, exePath string, tasks []JumpTask) error {
+	return nil
+}
+
+// RemoveJumpList is a no-op on non-Windows platforms.
+func RemoveJumpList(appID string) error {
+	return nil
+}
@@ -0,0 +1,208 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// JumpTask is an entry in the "Tasks" section of the taskbar jump list.
+type JumpTask struct {
+	Title     string
+	Arguments string // Command line arguments passed to the executable
+}
+
+const vtLPWStr = 31
+
+var (
+	clsidDestinationList            = syscall.GUID{Data1: 0x77f10cf0, Data2: 0x3db5, Data3: 0x4966, Data4: [8]byte{0xb5, 0x20, 0xb7, 0xc5, 0x4f, 0xd3, 0x5e, 0xd6}}
+	iidICustomDestinationList       = syscall.GUID{Data1: 0x6332debf, Data2: 0x87b5, Data3: 0x4670, Data4: [8]byte{0x90, 0xc0, 0x5e, 0x57, 0xb4, 0x08, 0xa4, 0x9e}}
+	clsidEnumerableObjectCollection = syscall.GUID{Data1: 0x2d3468c1, Data2: 0x36a7, Data3: 0x43b6, Data4: [8]byte{0xac, 0x24, 0xd3, 0xf0, 0x2f, 0xd9, 0x60, 0x7a}}
+	iidIObjectCollection            = syscall.GUID{Data1: 0x5632b1a4, Data2: 0xe38a, Data3: 0x400a, Data4: [8]byte{0x92, 0x8a, 0xd4, 0xcd, 0x63, 0x23, 0x02, 0x95}}
+	iidIObjectArray                 = syscall.GUID{Data1: 0x92ca9dcd, Data2: 0x5622, Data3: 0x4bba, Data4: [8]byte{0xa8, 0x05, 0x5e, 0x9f, 0x54, 0x1b, 0xd8, 0xc9}}
+	clsidShellLink                  = syscall.GUID{Data1: 0x00021401, Data2: 0x0000, Data3: 0x0000, Data4: [8]byte{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	iidIShellLinkW                  = syscall.GUID{Data1: 0x000214f9, Data2: 0x0000, Data3: 0x0000, Data4: [8]byte{0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46}}
+	iidIPropertyStore               = syscall.GUID{Data1: 0x886d8eeb, Data2: 0x8cf2, Data3: 0x4446, Data4: [8]byte{0x8d, 0x02, 0xcd, 0xba, 0x1d, 0xbd, 0xcf, 0x99}}
+	pkeyTitle                       = propertyKey{fmtid: syscall.GUID{Data1: 0xf29f85e0, Data2: 0x4ff9, Data3: 0x1068, Data4: [8]byte{0xab, 0x91, 0x08, 0x00, 0x2b, 0x27, 0xb3, 0xd9}}, pid: 2}
+)
+
+// COM vtable slots used below
+const (
+	destListSetAppID     = 3
+	destListBeginList    = 4
+	destListAddUserTasks = 7
+	destListCommitList   = 8
+	destListDeleteList   = 10
+	destListAbortList    = 11
+
+	collectionAddObject = 5
+
+	shellLinkSetDescription  = 7
+	shellLinkSetArguments    = 11
+	shellLinkSetIconLocation = 17
+	shellLinkSetPath         = 20
+
+	propStoreSetValue = 6
+	propStoreCommit   = 7
+)
+
+type propertyKey struct {
+	fmtid syscall.GUID
+	pid   uint32
+}
+
+// propVariant is a PROPVARIANT holding a VT_LPWSTR.
+type propVariant struct {
+	vt       uint16
+	reserved [3]uint16
+	val      uintptr
+	pad      uintptr
+}
+
+// newTaskLink creates a shell link that launches exePath with the task's arguments.
+func newTaskLink(exePath string, task JumpTask) (link *comObject, err error) {
+	path, err := syscall.UTF16PtrFromString(exePath)
+	if err != nil {
+		return nil, err
+	}
+	args, err := syscall.UTF16PtrFromString(task.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	title, err := syscall.UTF16PtrFromString(task.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err = createInstance(&clsidShellLink, &iidIShellLinkW)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			link.release()
+			link = nil
+		}
+	}()
+
+	hr, _, _ := syscall.SyscallN(link.method(shellLinkSetPath), uintptr(unsafe.Pointer(link)), uintptr(unsafe.Pointer(path)))
+	if err := hresult(hr, "SetPath"); err != nil {
+		return nil, err
+	}
+	hr, _, _ = syscall.SyscallN(link.method(shellLinkSetArguments), uintptr(unsafe.Pointer(link)), uintptr(unsafe.Pointer(args)))
+	if err := hresult(hr, "SetArguments"); err != nil {
+		return nil, err
+	}
+	hr, _, _ = syscall.SyscallN(link.method(shellLinkSetDescription), uintptr(unsafe.Pointer(link)), uintptr(unsafe.Pointer(title)))
+	if err := hresult(hr, "SetDescription"); err != nil {
+		return nil, err
+	}
+	hr, _, _ = syscall.SyscallN(link.method(shellLinkSetIconLocation), uintptr(unsafe.Pointer(link)), uintptr(unsafe.Pointer(path)), 0)
+	if err := hresult(hr, "SetIconLocation"); err != nil {
+		return nil, err
+	}
+
+	// The jump list shows the link's title property, not its description
+	var store *comObject
+	hr, _, _ = syscall.SyscallN(link.method(vtblQueryInterface), uintptr(unsafe.Pointer(link)), uintptr(unsafe.Pointer(&iidIPropertyStore)), uintptr(unsafe.Pointer(&store)))
+	if err := hresult(hr, "QueryInterface(IPropertyStore)"); err != nil {
+		return nil, err
+	}
+	defer store.release()
+
+	value := &propVariant{vt: vtLPWStr, val: uintptr(unsafe.Pointer(title))}
+	hr, _, _ = syscall.SyscallN(store.method(propStoreSetValue), uintptr(unsafe.Pointer(store)), uintptr(unsafe.Pointer(&pkeyTitle)), uintptr(unsafe.Pointer(value)))
+	runtime.KeepAlive(title)
+	if err := hresult(hr, "SetValue(PKEY_Title)"); err != nil {
+		return nil, err
+	}
+	hr, _, _ = syscall.SyscallN(store.method(propStoreCommit), uintptr(unsafe.Pointer(store)))
+	if err := hresult(hr, "Commit"); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// SetJumpListTasks replaces the jump list tasks of the application with the given AppUserModelID.
+func SetJumpListTasks(appID, exePath string, tasks []JumpTask) error {
+	id, err := syscall.UTF16PtrFromString(appID)
+	if err != nil {
+		return err
+	}
+	return withCOM(func() error {
+		list, err := createInstance(&clsidDestinationList, &iidICustomDestinationList)
+		if err != nil {
+			return err
+		}
+		defer list.release()
+
+		hr, _, _ := syscall.SyscallN(list.method(destListSetAppID), uintptr(unsafe.Pointer(list)), uintptr(unsafe.Pointer(id)))
+		if err := hresult(hr, "SetAppID"); err != nil {
+			return err
+		}
+		var minSlots uint32
+		var removed *comObject
+		hr, _, _ = syscall.SyscallN(list.method(destListBeginList), uintptr(unsafe.Pointer(list)), uintptr(unsafe.Pointer(&minSlots)), uintptr(unsafe.Pointer(&iidIObjectArray)), uintptr(unsafe.Pointer(&removed)))
+		if err := hresult(hr, "BeginList"); err != nil {
+			return err
+		}
+		removed.release()
+
+		committed := false
+		defer func() {
+			if !committed {
+				syscall.SyscallN(list.method(destListAbortList), uintptr(unsafe.Pointer(list)))
+			}
+		}()
+
+		collection, err := createInstance(&clsidEnumerableObjectCollection, &iidIObjectCollection)
+		if err != nil {
+			return err
+		}
+		defer collection.release()
+
+		for _, task := range tasks {
+			link, err := newTaskLink(exePath, task)
+			if err != nil {
+				return fmt.Errorf("failed to create task '%s': %w", task.Title, err)
+			}
+			hr, _, _ = syscall.SyscallN(collection.method(collectionAddObject), uintptr(unsafe.Pointer(collection)), uintptr(unsafe.Pointer(link)))
+			link.release()
+			if err := hresult(hr, "AddObject"); err != nil {
+				return err
+			}
+		}
+
+		// IObjectCollection extends IObjectArray, so the pointer can be passed as is
+		hr, _, _ = syscall.SyscallN(list.method(destListAddUserTasks), uintptr(unsafe.Pointer(list)), uintptr(unsafe.Pointer(collection)))
+		if err := hresult(hr, "AddUserTasks"); err != nil {
+			return err
+		}
+		hr, _, _ = syscall.SyscallN(list.method(destListCommitList), uintptr(unsafe.Pointer(list)))
+		if err := hresult(hr, "CommitList"); err != nil {
+			return err
+		}
+		committed = true
+		return nil
+	})
+}
+
+// RemoveJumpList deletes the jump list of the application with the given AppUserModelID.
+func RemoveJumpList(appID string) error {
+	id, err := syscall.UTF16PtrFromString(appID)
+	if err != nil {
+		return err
+	}
+	return withCOM(func() error {
+		list, err := createInstance(&clsidDestinationList, &iidICustomDestinationList)
+		if err != nil {
+			return err
+		}
+		defer list.release()
+		hr, _, _ := syscall.SyscallN(list.method(destListDeleteList), uintptr(unsafe.Pointer(list)), uintptr(unsafe.Pointer(id)))
+		return hresult(hr, "DeleteList")
+	})
+}
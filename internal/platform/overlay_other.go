@@ -0,0 +1,8 @@
+//go:build !windows
+
+package platform
+
+// SetTaskbarOverlay is a no-op on non-Windows platforms.
+func SetTaskbarOverlay(appTitle string, icon []byte, description string) error {
+	return nil
+}
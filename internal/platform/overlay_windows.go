@@ -0,0 +1,73 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procCreateIconFromResourceEx = user32.NewProc("CreateIconFromResourceEx")
+	procDestroyIcon              = user32.NewProc("DestroyIcon")
+)
+
+var (
+	clsidTaskbarList = syscall.GUID{Data1: 0x56fdf344, Data2: 0xfd6d, Data3: 0x11d0, Data4: [8]byte{0x95, 0x8a, 0x00, 0x60, 0x97, 0xc9, 0xa0, 0x90}}
+	iidITaskbarList3 = syscall.GUID{Data1: 0xea1afb91, Data2: 0x9e28, Data3: 0x4b86, Data4: [8]byte{0x90, 0xe9, 0x9e, 0x9f, 0x8a, 0x5e, 0xed, 0xaf}}
+)
+
+// ITaskbarList3 vtable slots
+const (
+	taskbarListHrInit  = 3
+	taskbarListOverlay = 18
+)
+
+const iconResourceVersion = 0x00030000
+
+// SetTaskbarOverlay shows a small PNG icon over the taskbar button of the
+// window with the given title. A nil icon clears the overlay.
+func SetTaskbarOverlay(appTitle string, icon []byte, description string) error {
+	hwnd, err := findWindow(appTitle)
+	if err != nil {
+		return fmt.Errorf("failed to find window: %w", err)
+	}
+	if hwnd == 0 {
+		return fmt.Errorf("window '%s' not found", appTitle)
+	}
+	desc, err := syscall.UTF16PtrFromString(description)
+	if err != nil {
+		return err
+	}
+
+	var hicon uintptr
+	if len(icon) > 0 {
+		// PNG data is accepted as an icon resource since Windows Vista
+		var callErr error
+		hicon, _, callErr = procCreateIconFromResourceEx.Call(uintptr(unsafe.Pointer(&icon[0])), uintptr(len(icon)), 1, iconResourceVersion, 16, 16, 0)
+		if hicon == 0 {
+			if err := lastError(callErr); err != nil {
+				return fmt.Errorf("failed to create overlay icon: %w", err)
+			}
+			return fmt.Errorf("failed to create overlay icon")
+		}
+		// The taskbar keeps its own copy
+		defer procDestroyIcon.Call(hicon)
+	}
+
+	return withCOM(func() error {
+		taskbar, err := createInstance(&clsidTaskbarList, &iidITaskbarList3)
+		if err != nil {
+			return err
+		}
+		defer taskbar.release()
+
+		hr, _, _ := syscall.SyscallN(taskbar.method(taskbarListHrInit), uintptr(unsafe.Pointer(taskbar)))
+		if err := hresult(hr, "HrInit"); err != nil {
+			return err
+		}
+		hr, _, _ = syscall.SyscallN(taskbar.method(taskbarListOverlay), uintptr(unsafe.Pointer(taskbar)), uintptr(hwnd), hicon, uintptr(unsafe.Pointer(desc)))
+		return hresult(hr, "SetOverlayIcon")
+	})
+}
@@ -0,0 +1,75 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+// errorInvalidWindowHandle is ERROR_INVALID_WINDOW_HANDLE, which syscall doesn't define.
+const errorInvalidWindowHandle = syscall.Errno(1400)
+
+// fakeProc returns fixed results, like a user32 function would.
+type fakeProc struct {
+	r1    uintptr
+	errno syscall.Errno
+	calls int
+}
+
+func (p *fakeProc) Call(a ...uintptr) (uintptr, uintptr, error) {
+	p.calls++
+	return p.r1, 0, p.errno
+}
+
+func TestFindWindowWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		hwnd    uintptr
+		errno   syscall.Errno
+		want    syscall.Handle
+		wantErr error
+	}{
+		{"found", 0x1234, 0, 0x1234, nil},
+		// A stale last error doesn't matter once a handle was returned
+		{"found with stale error", 0x1234, syscall.ERROR_ACCESS_DENIED, 0x1234, nil},
+		{"not found", 0, 0, 0, nil},
+		{"not found with file not found", 0, syscall.ERROR_FILE_NOT_FOUND, 0, nil},
+		{"failed", 0, syscall.ERROR_ACCESS_DENIED, 0, syscall.ERROR_ACCESS_DENIED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakeProc{r1: tt.hwnd, errno: tt.errno}
+			hwnd, err := findWindowWith(p, "lhcontrol")
+			if hwnd != tt.want {
+				t.Errorf("got handle %#x, want %#x", hwnd, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if p.calls != 1 {
+				t.Errorf("FindWindowW called %d times, want 1", p.calls)
+			}
+		})
+	}
+}
+
+func TestFindWindowWithInvalidTitle(t *testing.T) {
+	p := &fakeProc{}
+	if _, err := findWindowWith(p, "lh\x00control"); err == nil {
+		t.Error("a title with a NUL byte was accepted")
+	}
+	if p.calls != 0 {
+		t.Error("FindWindowW was called with an invalid title")
+	}
+}
+
+func TestLastError(t *testing.T) {
+	if err := lastError(syscall.Errno(0)); err != nil {
+		t.Errorf("errno 0 is %v, want no error", err)
+	}
+	if err := lastError(errorInvalidWindowHandle); err != errorInvalidWindowHandle {
+		t.Errorf("got %v, want errorInvalidWindowHandle", err)
+	}
+}
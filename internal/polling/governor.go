@@ -0,0 +1,127 @@
+package polling
+
+import (
+	"sync"
+	"time"
+
+	"lhcontrol/internal/config"
+)
+
+// Polling modes
+const (
+	ModeActive = "active"
+	ModeSlowed = "slowed"
+	ModePaused = "paused"
+)
+
+// BaseInterval is the status polling cadence of the window while it is visible.
+const BaseInterval = 15 * time.Second
+
+// clientGrace is how long after the last event stream client disconnected
+// polling stays at full cadence.
+const clientGrace = 5 * time.Minute
+
+// Status tells why station data may be stale.
+type Status struct {
+	Mode          string    `json:"mode"`   // ModeActive, ModeSlowed or ModePaused
+	Reason        string    `json:"reason"` // Why polling isn't active, empty if it is
+	LastCheck     time.Time `json:"lastCheck,omitempty"`
+	WindowVisible bool      `json:"windowVisible"`
+	StreamClients int       `json:"streamClients"`
+}
+
+// Governor decides whether a status poll may talk to the stations, so the
+// radio stays quiet while nobody looks at the data.
+type Governor struct {
+	cfg *config.Config
+
+	mu             sync.Mutex
+	windowVisible  bool
+	streamClients  int
+	lastStreamSeen time.Time
+	lastCheck      time.Time
+}
+
+// NewGovernor creates a Governor. The window counts as visible until told otherwise.
+func NewGovernor(cfg *config.Config) *Governor {
+	return &Governor{cfg: cfg, windowVisible: true}
+}
+
+// SetWindowVisible records whether the window is shown and focused. It
+// reports whether the polling mode changed.
+func (g *Governor) SetWindowVisible(visible bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	before := g.modeLocked(time.Now())
+	g.windowVisible = visible
+	return g.modeLocked(time.Now()) != before
+}
+
+// StreamOpened records an event stream client connecting. It reports whether
+// the polling mode changed.
+func (g *Governor) StreamOpened() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	before := g.modeLocked(time.Now())
+	g.streamClients++
+	g.lastStreamSeen = time.Now()
+	return g.modeLocked(time.Now()) != before
+}
+
+// StreamClosed records an event stream client disconnecting.
+func (g *Governor) StreamClosed() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.streamClients--
+	g.lastStreamSeen = time.Now()
+}
+
+// Allow reports whether a status poll should read the stations now, and
+// records the check if so. Polls that aren't allowed should serve cached data.
+func (g *Governor) Allow() bool {
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch g.modeLocked(now) {
+	case ModePaused:
+		return false
+	case ModeSlowed:
+		interval := BaseInterval * time.Duration(g.cfg.GetPollingSettings().SlowFactor)
+		// Leave some slack for the caller's timer jitter
+		if now.Sub(g.lastCheck) < interval-BaseInterval/2 {
+			return false
+		}
+	}
+	g.lastCheck = now
+	return true
+}
+
+// Status returns the current polling mode and why.
+func (g *Governor) Status() Status {
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	status := Status{
+		Mode:          g.modeLocked(now),
+		LastCheck:     g.lastCheck,
+		WindowVisible: g.windowVisible,
+		StreamClients: g.streamClients,
+	}
+	if status.Mode != ModeActive {
+		status.Reason = "window hidden and no API client connected"
+	}
+	return status
+}
+
+func (g *Governor) modeLocked(now time.Time) string {
+	if g.windowVisible || g.streamClients > 0 || (!g.lastStreamSeen.IsZero() && now.Sub(g.lastStreamSeen) < clientGrace) {
+		return ModeActive
+	}
+	switch g.cfg.GetPollingSettings().WhenHidden {
+	case config.PollingPause:
+		return ModePaused
+	case config.PollingSlow:
+		return ModeSlowed
+	}
+	return ModeActive
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package powersource
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ReadOnBattery reports whether the system is running on battery power using UPower.
+// supported is false when UPower is not available.
+func ReadOnBattery() (onBattery bool, supported bool, err error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return false, false, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	obj := conn.Object("org.freedesktop.UPower", dbus.ObjectPath("/org/freedesktop/UPower"))
+	variant, err := obj.GetProperty("org.freedesktop.UPower.OnBattery")
+	if err != nil {
+		// UPower not installed (e.g. desktop without battery support)
+		return false, false, nil
+	}
+	value, ok := variant.Value().(bool)
+	if !ok {
+		return false, false, fmt.Errorf("unexpected OnBattery value type %T", variant.Value())
+	}
+	return value, true, nil
+}
@@ -0,0 +1,111 @@
+package powersource
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Monitor polls the power source and reports debounced AC/battery transitions.
+type Monitor struct {
+	interval time.Duration
+	debounce func() time.Duration
+	onChange func(onBattery bool)
+
+	mu        sync.RWMutex
+	known     bool
+	onBattery bool
+
+	pending      bool // Candidate state waiting for the debounce to pass
+	pendingSince time.Time
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewMonitor creates a Monitor. debounce is consulted on every poll so
+// configuration changes apply immediately.
+func NewMonitor(interval time.Duration, debounce func() time.Duration, onChange func(onBattery bool)) *Monitor {
+	return &Monitor{
+		interval: interval,
+		debounce: debounce,
+		onChange: onChange,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins polling in the background.
+func (m *Monitor) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.poll()
+			select {
+			case <-ticker.C:
+			case <-m.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends polling and waits for the poll loop to exit.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+	m.wg.Wait()
+}
+
+// OnBattery returns the last debounced state and whether it is known.
+func (m *Monitor) OnBattery() (onBattery bool, known bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.onBattery, m.known
+}
+
+func (m *Monitor) poll() {
+	onBattery, supported, err := ReadOnBattery()
+	if err != nil {
+		log.Printf("PowerSource: Failed to read power status: %v", err)
+		return
+	}
+	if !supported {
+		return
+	}
+
+	m.mu.Lock()
+	if !m.known {
+		// First reading establishes the baseline without firing
+		m.known = true
+		m.onBattery = onBattery
+		m.mu.Unlock()
+		log.Printf("PowerSource: Initial power source: on battery=%v", onBattery)
+		return
+	}
+	if onBattery == m.onBattery {
+		m.pending = false
+		m.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	if !m.pending {
+		m.pending = true
+		m.pendingSince = now
+	}
+	if now.Sub(m.pendingSince) < m.debounce() {
+		m.mu.Unlock()
+		return
+	}
+	m.pending = false
+	m.onBattery = onBattery
+	m.mu.Unlock()
+
+	log.Printf("PowerSource: Power source changed, on battery=%v", onBattery)
+	if m.onChange != nil {
+		m.onChange(onBattery)
+	}
+}
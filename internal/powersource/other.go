@@ -0,0 +1,8 @@
+//go:build !windows && !linux
+
+package powersource
+
+// ReadOnBattery is not implemented for this platform.
+func ReadOnBattery() (onBattery bool, supported bool, err error) {
+	return false, false, nil
+}
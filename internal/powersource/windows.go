@@ -0,0 +1,51 @@
+//go:build windows
+
+package powersource
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	procGetSystemPowerStatus = kernel32.NewProc("GetSystemPowerStatus")
+)
+
+// SYSTEM_POWER_STATUS struct
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+const (
+	acLineOffline   = 0
+	acLineOnline    = 1
+	batteryNoSystem = 128
+)
+
+// ReadOnBattery reports whether the system is running on battery power.
+// supported is false on machines without a battery or when the AC state is unknown.
+func ReadOnBattery() (onBattery bool, supported bool, err error) {
+	var status systemPowerStatus
+	ret, _, callErr := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status)))
+	if ret == 0 {
+		return false, false, fmt.Errorf("GetSystemPowerStatus failed: %w", callErr)
+	}
+	if status.BatteryFlag == batteryNoSystem {
+		return false, false, nil
+	}
+	switch status.ACLineStatus {
+	case acLineOffline:
+		return true, true, nil
+	case acLineOnline:
+		return false, true, nil
+	default:
+		return false, false, nil
+	}
+}
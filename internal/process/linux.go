@@ -0,0 +1,43 @@
+//go:build linux
+
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// List returns the executable names of all running processes.
+func List() ([]string, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name()[0] < '0' || entry.Name()[0] > '9' {
+			continue
+		}
+		// Prefer argv[0] since comm is truncated to 15 characters
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err == nil && len(cmdline) > 0 {
+			argv0 := string(cmdline)
+			if i := bytes.IndexByte(cmdline, 0); i >= 0 {
+				argv0 = string(cmdline[:i])
+			}
+			// Wine/Proton processes report Windows paths
+			argv0 = strings.ReplaceAll(argv0, "\\", "/")
+			names = append(names, filepath.Base(argv0))
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		if err == nil {
+			names = append(names, strings.TrimSpace(string(comm)))
+		}
+	}
+	return names, nil
+}
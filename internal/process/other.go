@@ -0,0 +1,28 @@
+//go:build !windows && !linux
+
+package process
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// List returns the executable names of all running processes.
+func List() ([]string, error) {
+	out, err := exec.Command("ps", "-axo", "comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	lines := strings.Split(string(out), "\n")
+	names := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			names = append(names, filepath.Base(line))
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package process
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// List returns the executable names of all running processes.
+func List() ([]string, error) {
+	snapshot, err := syscall.CreateToolhelp32Snapshot(syscall.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+	defer syscall.CloseHandle(snapshot)
+
+	var entry syscall.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	if err := syscall.Process32First(snapshot, &entry); err != nil {
+		return nil, fmt.Errorf("failed to read first process: %w", err)
+	}
+
+	names := make([]string, 0, 256)
+	for {
+		names = append(names, syscall.UTF16ToString(entry.ExeFile[:]))
+		if err := syscall.Process32Next(snapshot, &entry); err != nil {
+			// ERROR_NO_MORE_FILES marks the end of the snapshot
+			break
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,71 @@
+package redact
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// macPattern matches Bluetooth addresses written with ':' or '-' separators.
+var macPattern = regexp.MustCompile(`(?i)\b[0-9a-f]{2}(?:[:-][0-9a-f]{2}){5}\b`)
+
+// salt makes tokens stable within a session but unlinkable across sessions.
+var salt = func() []byte {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return b
+}()
+
+var enabled atomic.Bool
+
+// SetEnabled turns redaction of log output on or off.
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether log output is redacted.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Token returns the short replacement for an address. The same address maps
+// to the same token for the lifetime of the process, regardless of case or separator.
+func Token(address string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(address, "-", ":"))
+	sum := sha256.Sum256(append(append([]byte{}, salt...), normalized...))
+	return "dev-" + hex.EncodeToString(sum[:3])
+}
+
+// Addresses replaces every Bluetooth address in s with its token.
+func Addresses(s string) string {
+	return macPattern.ReplaceAllStringFunc(s, Token)
+}
+
+// Bytes is Addresses for byte slices.
+func Bytes(b []byte) []byte {
+	return macPattern.ReplaceAllFunc(b, func(m []byte) []byte { return []byte(Token(string(m))) })
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// Writer wraps w so that addresses are replaced while redaction is enabled.
+// It is meant for log.SetOutput, where every Write is one complete message.
+func Writer(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	if !enabled.Load() {
+		return w.w.Write(p)
+	}
+	if _, err := w.w.Write(Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
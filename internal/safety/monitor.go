@@ -0,0 +1,158 @@
+package safety
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/station"
+	"lhcontrol/internal/stats"
+)
+
+// Warning is emitted when a station exceeds the maximum on-time.
+type Warning struct {
+	ID       string    `json:"id"` // Stable station ID, also accepted wherever an address is
+	Address  string    `json:"address"`
+	Name     string    `json:"name"`
+	OnHours  float64   `json:"onHours"`
+	ActionAt time.Time `json:"actionAt"` // When the action runs unless snoozed
+}
+
+// Monitor samples station states into the stats tracker and enforces the on-time cap.
+type Monitor struct {
+	cfg      *config.Config
+	tracker  *stats.Tracker
+	manager  *station.Manager
+	interval time.Duration
+
+	onWarning func(Warning)
+	onAction  func(address string, settings config.SafetySettings)
+
+	mu           sync.Mutex
+	warnedAt     map[string]time.Time
+	snoozedUntil map[string]time.Time
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewMonitor(cfg *config.Config, tracker *stats.Tracker, manager *station.Manager, interval time.Duration,
+	onWarning func(Warning), onAction func(address string, settings config.SafetySettings)) *Monitor {
+	return &Monitor{
+		cfg:          cfg,
+		tracker:      tracker,
+		manager:      manager,
+		interval:     interval,
+		onWarning:    onWarning,
+		onAction:     onAction,
+		warnedAt:     make(map[string]time.Time),
+		snoozedUntil: make(map[string]time.Time),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start begins sampling in the background.
+func (m *Monitor) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			m.check(time.Now())
+			select {
+			case <-ticker.C:
+			case <-m.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends sampling, waits for the loop to exit and saves the stats.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+	m.wg.Wait()
+	if err := m.tracker.Save(); err != nil {
+		log.Printf("Safety: Error saving stats: %v", err)
+	}
+}
+
+// Snooze postpones the action for a station, given by address or ID, by the
+// configured snooze time.
+func (m *Monitor) Snooze(address string) time.Time {
+	id := address
+	if stationID, ok := m.manager.StationID(address); ok {
+		id = stationID
+	}
+	settings := m.cfg.GetSafetySettings()
+	until := time.Now().Add(time.Duration(settings.SnoozeMinutes) * time.Minute)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snoozedUntil[id] = until
+	delete(m.warnedAt, id)
+	log.Printf("Safety: Auto-off for %s snoozed until %s", address, until.Format(time.Kitchen))
+	return until
+}
+
+func (m *Monitor) check(now time.Time) {
+	stations := m.manager.GetStationInfo()
+	for _, info := range stations {
+		// Stats used to be kept by address, which changes on some platforms
+		m.tracker.Rekey(info.Address, info.ID)
+		m.tracker.Observe(info.ID, info.PowerState, now)
+	}
+	if err := m.tracker.Save(); err != nil {
+		log.Printf("Safety: Error saving stats: %v", err)
+	}
+
+	settings := m.cfg.GetSafetySettings()
+	if !settings.Enabled {
+		return
+	}
+	maxOn := time.Duration(settings.MaxOnHours * float64(time.Hour))
+	warningGrace := time.Duration(settings.WarningMinutes) * time.Minute
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, info := range stations {
+		onFor := m.tracker.ContinuousOn(info.ID, now)
+		if !bluetooth.IsPoweredOn(info.PowerState) || onFor < maxOn {
+			// Back under the cap (turned off or reset), forget any warning
+			delete(m.warnedAt, info.ID)
+			delete(m.snoozedUntil, info.ID)
+			continue
+		}
+		if until, ok := m.snoozedUntil[info.ID]; ok && now.Before(until) {
+			continue
+		}
+
+		warnedAt, warned := m.warnedAt[info.ID]
+		if !warned {
+			m.warnedAt[info.ID] = now
+			log.Printf("Safety: %s has been on for %v, auto-off in %v unless snoozed", info.Name, onFor.Round(time.Minute), warningGrace)
+			if m.onWarning != nil {
+				m.onWarning(Warning{
+					ID:       info.ID,
+					Address:  info.Address,
+					Name:     info.Name,
+					OnHours:  onFor.Hours(),
+					ActionAt: now.Add(warningGrace),
+				})
+			}
+			continue
+		}
+		if now.Sub(warnedAt) >= warningGrace {
+			delete(m.warnedAt, info.ID)
+			delete(m.snoozedUntil, info.ID)
+			log.Printf("Safety: %s exceeded the maximum on-time, running action '%s'", info.Name, settings.Action)
+			if m.onAction != nil {
+				go m.onAction(info.Address, settings)
+			}
+		}
+	}
+}
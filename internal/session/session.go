@@ -0,0 +1,153 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/paths"
+	"lhcontrol/internal/station"
+)
+
+const sessionFileName = "session.json"
+
+// ErrNoSession is returned when resuming without a recorded session.
+var ErrNoSession = errors.New("no previous session recorded")
+
+// Record is the set of stations that were on when the app last exited.
+type Record struct {
+	SavedAt time.Time `json:"savedAt"`
+	// Clean is set when the record was written by the shutdown sequence. A
+	// record without it is the last periodic checkpoint of a session that crashed.
+	Clean bool                     `json:"clean"`
+	On    []config.SnapshotStation `json:"on"`
+}
+
+// ResumeResult is the outcome of powering on the stations of the last session.
+type ResumeResult struct {
+	SavedAt    time.Time               `json:"savedAt"`
+	Checkpoint bool                    `json:"checkpoint"` // The last shutdown didn't finish, a periodic checkpoint was used
+	Results    []station.RestoreResult `json:"results"`
+}
+
+// Load reads the record of the previous session. It returns false if there is none.
+func Load() (Record, bool, error) {
+	sessionFilePath, err := paths.StateFile(sessionFileName)
+	if err != nil {
+		return Record{}, false, err
+	}
+	data, err := os.ReadFile(sessionFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Record{}, false, nil
+		}
+		return Record{}, false, fmt.Errorf("error reading session file '%s': %w", sessionFilePath, err)
+	}
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return Record{}, false, fmt.Errorf("error unmarshalling session: %w", err)
+	}
+	return record, true, nil
+}
+
+func save(record Record) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling session: %w", err)
+	}
+	sessionFilePath, err := paths.StateFile(sessionFileName)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sessionFilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session file '%s': %w", sessionFilePath, err)
+	}
+	return nil
+}
+
+// Recorder checkpoints the set of stations that are on at a fixed interval and
+// writes the final record when stopped.
+type Recorder struct {
+	manager  *station.Manager
+	interval time.Duration
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewRecorder(manager *station.Manager, interval time.Duration) *Recorder {
+	return &Recorder{
+		manager:  manager,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins checkpointing in the background.
+func (r *Recorder) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.record(false)
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends checkpointing and records the stations that are on now. Call it
+// before the stations are disconnected, which forgets their states, or
+// turned off on exit. Later calls do nothing.
+func (r *Recorder) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+		r.wg.Wait()
+		r.record(true)
+	})
+}
+
+// record saves the stations that are on. If no station has a known state,
+// e.g. because no scan ran, the previous record is kept.
+func (r *Recorder) record(clean bool) {
+	record := Record{SavedAt: time.Now(), Clean: clean, On: []config.SnapshotStation{}}
+	known := 0
+	for _, info := range r.manager.GetStationInfo() {
+		if info.PowerState == bluetooth.PowerStateUnknown {
+			continue
+		}
+		known++
+		if bluetooth.IsPoweredOn(info.PowerState) {
+			record.On = append(record.On, config.SnapshotStation{ID: info.ID, Name: info.Name, PowerState: info.PowerState})
+		}
+	}
+	if known == 0 {
+		return
+	}
+	if err := save(record); err != nil {
+		log.Printf("Session: Error saving session: %v", err)
+	}
+}
+
+// Resume powers on the stations that were on in record. Stations that are on
+// now but weren't then are left alone.
+func Resume(ctx context.Context, manager *station.Manager, record Record, src station.Source) ResumeResult {
+	return ResumeResult{
+		SavedAt:    record.SavedAt,
+		Checkpoint: !record.Clean,
+		Results:    manager.ApplyStates(ctx, record.On, src),
+	}
+}
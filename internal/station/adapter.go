@@ -0,0 +1,328 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// Adapter states
+const (
+	AdapterInitializing = "initializing"
+	AdapterReady        = "ready"
+	AdapterFailed       = "failed"
+	AdapterMissing      = "no_adapter" // No Bluetooth radio found, probed again every adapterProbeInterval
+	AdapterDisabled     = "disabled"   // Bluetooth turned off or the radio went away, checked again every adapterHealthInterval
+)
+
+// adapterWaitTimeout is how long an early request waits for the adapter before it is rejected.
+const adapterWaitTimeout = 30 * time.Second
+
+// adapterProbeInterval is how often a missing adapter is looked for again, so
+// a dongle plugged in later is picked up without a restart.
+const adapterProbeInterval = 15 * time.Second
+
+// adapterHealthInterval is how often the radio is checked while lhcontrol runs.
+const adapterHealthInterval = 5 * time.Second
+
+// adapterInitRetries is how often enabling the adapter is tried again at
+// startup, waiting adapterInitBackoff and then twice as long each time. A
+// radio that is still coming up after login is usually there within seconds.
+const (
+	adapterInitRetries = 4
+	adapterInitBackoff = time.Second
+)
+
+var (
+	// ErrAdapterInitializing is returned for requests that gave up waiting for the adapter.
+	ErrAdapterInitializing = errors.New("bluetooth adapter is still initializing")
+	// ErrAdapterUnavailable is returned for requests after the adapter failed to initialize.
+	ErrAdapterUnavailable = errors.New("bluetooth adapter is unavailable")
+	// ErrNoAdapter is returned for requests while the machine has no Bluetooth adapter.
+	ErrNoAdapter = errors.New("no bluetooth adapter found")
+)
+
+// AdapterStatus describes the state of the Bluetooth adapter initialization.
+type AdapterStatus struct {
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// InitializeAsync enables the Bluetooth adapter in the background, because
+// adapter.Enable can take several seconds or hang on some machines. Requests
+// made in the meantime wait for it, see waitAdapter. Progress is published as
+// "adapter-state" events.
+func (m *Manager) InitializeAsync() {
+	m.emit("adapter-state", m.AdapterStatus())
+	go func() {
+		start := m.clock.Now()
+		err := m.initializeWithRetry()
+		m.setAdapterResult(err)
+		close(m.adapterDone)
+
+		if err != nil {
+			log.Printf("Error initializing Bluetooth: %v", err)
+		} else {
+			log.Printf("Manager: Bluetooth adapter ready after %v", m.clock.Now().Sub(start).Round(time.Millisecond))
+		}
+		m.emit("adapter-state", m.AdapterStatus())
+		go m.monitorAdapter()
+		if err == nil {
+			m.adoptConnectedStations()
+		} else if errors.Is(err, bluetooth.ErrNoAdapter) {
+			m.probeAdapter()
+		}
+	}()
+}
+
+// initializeWithRetry enables the adapter, trying again with backoff if it
+// fails for another reason than a missing radio, which probeAdapter looks for.
+func (m *Manager) initializeWithRetry() error {
+	err := m.Initialize()
+	backoff := adapterInitBackoff
+	for retry := 1; err != nil && retry <= adapterInitRetries && !errors.Is(err, bluetooth.ErrNoAdapter); retry++ {
+		log.Printf("Manager: Enabling the Bluetooth adapter failed, trying again in %v (%d/%d): %v", backoff, retry, adapterInitRetries, err)
+		select {
+		case <-m.clock.After(backoff):
+		case <-m.stopChan:
+			return err
+		}
+		err = m.Initialize()
+		backoff *= 2
+	}
+	return err
+}
+
+// RetryInitialize enables the adapter again right away after it failed, went
+// missing or was turned off, instead of waiting for the next automatic check.
+// The outcome is published as "adapter-state" and, on success,
+// "bluetooth-available" events.
+func (m *Manager) RetryInitialize() error {
+	switch m.AdapterStatus().State {
+	case AdapterReady:
+		return nil
+	case AdapterInitializing:
+		return ErrAdapterInitializing
+	}
+	err := m.Initialize()
+	m.setAdapterResult(err)
+	status := m.AdapterStatus()
+	m.emit("adapter-state", status)
+	if err != nil {
+		log.Printf("Manager: Enabling the Bluetooth adapter failed again: %v", err)
+		return err
+	}
+	log.Println("Manager: Bluetooth adapter enabled on request")
+	m.emit("bluetooth-available", status)
+	m.publishStations("stations-updated", m.GetStationInfo())
+	go m.adoptConnectedStations()
+	go m.Prewarm()
+	return nil
+}
+
+// setAdapterResult records the outcome of enabling the adapter.
+func (m *Manager) setAdapterResult(err error) {
+	m.adapterMutex.Lock()
+	defer m.adapterMutex.Unlock()
+	m.adapterErr = err
+	switch {
+	case err == nil:
+		m.adapterState = AdapterReady
+	case errors.Is(err, bluetooth.ErrNoAdapter):
+		m.adapterState = AdapterMissing
+	case errors.Is(err, bluetooth.ErrAdapterDisabled):
+		m.adapterState = AdapterDisabled
+	default:
+		m.adapterState = AdapterFailed
+	}
+}
+
+// probeAdapter tries to enable the adapter every adapterProbeInterval until it
+// works or the manager shuts down. There is no portable hot-plug notification
+// for Bluetooth radios, so polling is how a dongle plugged in later is found.
+func (m *Manager) probeAdapter() {
+	if !m.probing.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.probing.Store(false)
+	log.Printf("Manager: No Bluetooth adapter found, checking again every %v", adapterProbeInterval)
+	for {
+		select {
+		case <-m.clock.After(adapterProbeInterval):
+		case <-m.stopChan:
+			return
+		}
+		err := m.Initialize()
+		previous := m.AdapterStatus().State
+		m.setAdapterResult(err)
+		if status := m.AdapterStatus(); status.State != previous {
+			m.emit("adapter-state", status)
+		}
+		if err == nil {
+			log.Println("Manager: Bluetooth adapter found")
+			m.adoptConnectedStations()
+			return
+		}
+	}
+}
+
+// Initialize enables the configured Bluetooth adapter and blocks until it is done.
+func (m *Manager) Initialize() error {
+	bluetooth.SetPowerStateHandler(m.onPowerNotification)
+	bluetooth.SetLinkLostHandler(m.onLinkLost)
+	return bluetooth.InitializeAdapter(m.config.GetConnectionSettings().Adapter)
+}
+
+// ListAdapters returns the Bluetooth adapters that can be selected.
+func (m *Manager) ListAdapters() ([]bluetooth.AdapterInfo, error) {
+	return bluetooth.ListAdapters()
+}
+
+// SelectAdapter switches to the Bluetooth adapter with the given ID, empty
+// for the system default, and stores the choice in the config. Open
+// connections are dropped, since their handles belong to the previous
+// adapter; the stations reconnect on their next command or status check.
+// The caller saves the config.
+func (m *Manager) SelectAdapter(id string) error {
+	adapters, err := bluetooth.ListAdapters()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, adapter := range adapters {
+		found = found || adapter.ID == id
+	}
+	if !found && id != "" {
+		return fmt.Errorf("%w: %s", ErrNoAdapter, id)
+	}
+	if id == m.config.GetConnectionSettings().Adapter && m.AdapterStatus().State == AdapterReady {
+		return nil
+	}
+	if m.IsScanning() {
+		return ErrScanInProgress
+	}
+
+	settings := m.config.GetConnectionSettings()
+	settings.Adapter = id
+	if err := m.config.SetConnectionSettings(settings); err != nil {
+		return err
+	}
+
+	log.Printf("Manager: Switching to Bluetooth adapter %q", id)
+	bluetooth.DisconnectAllStations()
+	err = bluetooth.InitializeAdapter(id)
+	m.setAdapterResult(err)
+	m.emit("adapter-state", m.AdapterStatus())
+	if errors.Is(err, bluetooth.ErrNoAdapter) {
+		go m.probeAdapter()
+	}
+	return err
+}
+
+// AdapterStatus returns the current adapter state.
+func (m *Manager) AdapterStatus() AdapterStatus {
+	m.adapterMutex.Lock()
+	defer m.adapterMutex.Unlock()
+	status := AdapterStatus{State: m.adapterState}
+	if m.adapterErr != nil {
+		status.Error = m.adapterErr.Error()
+	}
+	return status
+}
+
+// waitAdapter waits until the adapter finished initializing, for at most
+// adapterWaitTimeout or until ctx is done, and returns a typed error if it
+// isn't usable.
+func (m *Manager) waitAdapter(ctx context.Context) error {
+	m.adapterMutex.Lock()
+	done := m.adapterDone
+	m.adapterMutex.Unlock()
+
+	select {
+	case <-done:
+	case <-m.clock.After(adapterWaitTimeout):
+		return ErrAdapterInitializing
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	m.adapterMutex.Lock()
+	defer m.adapterMutex.Unlock()
+	if m.adapterErr == nil {
+		return nil
+	}
+	// Keep the bluetooth kind, e.g. ErrAdapterDisabled, for errors.Is
+	if m.adapterState == AdapterMissing {
+		return fmt.Errorf("%w: %w", ErrNoAdapter, m.adapterErr)
+	}
+	return fmt.Errorf("%w: %w", ErrAdapterUnavailable, m.adapterErr)
+}
+
+// monitorAdapter checks every adapterHealthInterval whether the radio is
+// still there and turned on, until the manager shuts down. Handles of a radio
+// that was turned off stay dead after it is back, so losing it drops all
+// connections, and getting it back enables the adapter again and reconnects
+// the stations. Platforms that can't tell notice it from failing scans, and
+// while disabled simply try to enable the adapter again.
+func (m *Manager) monitorAdapter() {
+	for {
+		select {
+		case <-m.clock.After(adapterHealthInterval):
+		case <-m.stopChan:
+			return
+		}
+		available, supported := bluetooth.RadioAvailable()
+		if !supported {
+			available = true
+		}
+		switch state := m.AdapterStatus().State; {
+		case !available && state == AdapterReady:
+			m.adapterDisabled(fmt.Errorf("%w: the radio is off or gone", bluetooth.ErrAdapterDisabled))
+		case available && state == AdapterDisabled:
+			m.adapterRestored()
+		}
+	}
+}
+
+// adapterDisabled switches to the disabled state and drops all connections,
+// whose handles won't work again. A "bluetooth-unavailable" event with the
+// adapter status is sent.
+func (m *Manager) adapterDisabled(err error) {
+	m.setAdapterResult(err)
+	log.Printf("Manager: Bluetooth became unavailable: %v", err)
+	bluetooth.DisconnectAllStations()
+	status := m.AdapterStatus()
+	m.emit("bluetooth-unavailable", status)
+	m.emit("adapter-state", status)
+	m.publishStations("stations-updated", m.GetStationInfo())
+}
+
+// adapterRestored enables the adapter again once the radio is back and
+// reconnects the known stations. It stays disabled if enabling fails, and the
+// next check tries again.
+func (m *Manager) adapterRestored() {
+	if err := m.Initialize(); err != nil {
+		log.Printf("Manager: Bluetooth is back but the adapter failed to start: %v", err)
+		return
+	}
+	m.setAdapterResult(nil)
+	log.Println("Manager: Bluetooth is available again")
+	status := m.AdapterStatus()
+	m.emit("bluetooth-available", status)
+	m.emit("adapter-state", status)
+	m.publishStations("stations-updated", m.GetStationInfo())
+	go m.Prewarm()
+}
+
+// adapterLost switches to the no-adapter state when a scan found the adapter
+// gone, e.g. because the dongle was unplugged, and starts probing for it.
+func (m *Manager) adapterLost(err error) {
+	m.setAdapterResult(err)
+	log.Printf("Manager: Bluetooth adapter disappeared: %v", err)
+	m.emit("adapter-state", m.AdapterStatus())
+	go m.probeAdapter()
+}
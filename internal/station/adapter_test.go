@@ -0,0 +1,76 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// slowEnableBackend is a fake backend whose adapter takes until release is
+// closed to enable, like adapters that hang for seconds at login.
+type slowEnableBackend struct {
+	*bluetooth.FakeBackend
+	release chan struct{}
+}
+
+func (b *slowEnableBackend) Enable() error {
+	<-b.release
+	return b.FakeBackend.Enable()
+}
+
+func TestStartupWithSlowAdapter(t *testing.T) {
+	s := fakeStation(1)
+	backend := &slowEnableBackend{FakeBackend: bluetooth.NewFakeBackend(s), release: make(chan struct{})}
+	m := newUninitializedManager(t, backend, s)
+	defer func() {
+		select {
+		case <-backend.release:
+		default:
+			close(backend.release)
+		}
+	}()
+
+	timeCall(t, "InitializeAsync", 50*time.Millisecond, m.InitializeAsync)
+	if state := m.AdapterStatus().State; state != AdapterInitializing {
+		t.Fatalf("adapter state is %q while enabling, want %q", state, AdapterInitializing)
+	}
+	// The list is there right away
+	timeCall(t, "GetStationInfo", 50*time.Millisecond, func() {
+		if n := len(m.GetStationInfo()); n != 1 {
+			t.Errorf("got %d stations while the adapter initializes, want 1", n)
+		}
+	})
+
+	// Requests that can't wait give up with their context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := m.ScanAndFetchStations(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("scan while initializing returned %v, want the context's error", err)
+	}
+
+	// Those that wait run once the adapter is ready
+	done := make(chan error, 1)
+	go func() {
+		done <- m.PowerOnStation(context.Background(), s.Address.String(), Source{Kind: SourceUI})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("command ran before the adapter was ready: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(backend.release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("command queued during startup failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command queued during startup didn't run")
+	}
+	if state := m.AdapterStatus().State; state != AdapterReady {
+		t.Errorf("adapter state is %q, want %q", state, AdapterReady)
+	}
+}
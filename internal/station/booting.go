@@ -0,0 +1,68 @@
+package station
+
+import (
+	"log"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// followBooting starts polling every booting station in stations that isn't
+// polled yet, see pollBooting.
+func (m *Manager) followBooting(stations []StationInfo) {
+	for _, info := range stations {
+		if info.PowerState != bluetooth.PowerStateBooting || info.Origin != OriginLocal {
+			continue
+		}
+		m.bootingMutex.Lock()
+		if m.booting[info.Address] {
+			m.bootingMutex.Unlock()
+			continue
+		}
+		m.booting[info.Address] = true
+		m.bootingMutex.Unlock()
+		go m.pollBooting(info.Address)
+	}
+}
+
+// pollBooting reads the state of a spinning-up station every
+// readinessPollInterval until it settled, and publishes the settled state
+// like a notification. It gives up after readinessTimeout.
+func (m *Manager) pollBooting(address string) {
+	defer func() {
+		m.bootingMutex.Lock()
+		delete(m.booting, address)
+		m.bootingMutex.Unlock()
+	}()
+	ctx, cancel := clock.WithTimeout(m.ctx, m.clock, readinessTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if m.ctx.Err() == nil {
+				log.Printf("Manager: %s is still spinning up, no longer polling it", address)
+			}
+			return
+		case <-m.clock.After(readinessPollInterval):
+		}
+
+		s := m.lookup(address)
+		if s == nil {
+			return
+		}
+		if s.GetPowerState() != bluetooth.PowerStateBooting {
+			return // Settled and already published, e.g. by a notification
+		}
+		var err error
+		if s.IsConnected() || bluetooth.DisconnectAfterCommand() {
+			err = bluetooth.ReadPowerState(ctx, s)
+		} else {
+			err = bluetooth.FetchInitialPowerState(ctx, s)
+		}
+		if err == nil && s.GetPowerState() != bluetooth.PowerStateBooting {
+			m.onPowerNotification(s)
+			return
+		}
+	}
+}
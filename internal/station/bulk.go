@@ -0,0 +1,237 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/internal/config"
+)
+
+const (
+	// bulkDeadline bounds a bulk power command including its second pass
+	bulkDeadline = 90 * time.Second
+	// retryPassDelay gives the adapter time to settle before the second pass
+	retryPassDelay = 3 * time.Second
+)
+
+// BulkRetryEvent announces the second pass of a bulk power command.
+type BulkRetryEvent struct {
+	Action   string   `json:"action"`
+	Stations []string `json:"stations"` // Names of the stations being retried
+}
+
+// bulkCommand is one station's part of a bulk power command.
+type bulkCommand struct {
+	station *bluetooth.BaseStation
+	value   int
+	result  *RestoreResult
+}
+
+// runBulk runs the commands in parallel. If enabled in the connection
+// settings, the failed ones are then retried once, one at a time, as long as
+// the bulk deadline leaves room for a full command. Results record the
+// number of attempts. Once ctx is done no retries are made.
+func (m *Manager) runBulk(ctx context.Context, commands []bulkCommand, src Source) {
+	deadline := m.clock.Now().Add(bulkDeadline)
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, bulkDeadline)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, cmd := range commands {
+		wg.Add(1)
+		go func(cmd bulkCommand) {
+			defer wg.Done()
+			m.attempt(ctx, cmd, src)
+		}(cmd)
+	}
+	wg.Wait()
+
+	if !m.config.GetConnectionSettings().RetryFailed || ctx.Err() != nil {
+		return
+	}
+	var retry []bulkCommand
+	for _, cmd := range commands {
+		// A superseded command was replaced on purpose, don't undo that, and
+		// without an adapter a retry can't succeed
+		err := cmd.result.err
+		if cmd.result.Status == RestoreFailed && !errors.Is(err, ErrSuperseded) && !errors.Is(err, ErrNoAdapter) && !errors.Is(err, context.Canceled) {
+			retry = append(retry, cmd)
+		}
+	}
+	if len(retry) == 0 {
+		return
+	}
+
+	event := BulkRetryEvent{Action: actionName(retry[0].value)}
+	for _, cmd := range retry {
+		event.Stations = append(event.Stations, cmd.result.Name)
+	}
+	log.Printf("Manager: Retrying %d station(s) that failed: %v", len(retry), event.Stations)
+	m.emit("bulk-retry", event)
+	if err := clock.Sleep(ctx, m.clock, retryPassDelay); err != nil {
+		for _, cmd := range retry {
+			cmd.result.Note = "not retried, the bulk command was canceled"
+		}
+		return
+	}
+
+	for _, cmd := range retry {
+		if ctx.Err() != nil {
+			cmd.result.Note = "not retried, the bulk command was canceled"
+			continue
+		}
+		if m.clock.Now().Add(commandTimeout).After(deadline) {
+			cmd.result.Note = "not retried, the bulk command ran out of time"
+			continue
+		}
+		m.attempt(ctx, cmd, src)
+	}
+}
+
+// attempt runs a bulk command once and records the outcome in its result.
+func (m *Manager) attempt(ctx context.Context, cmd bulkCommand, src Source) {
+	cmd.result.Attempts++
+	start := m.clock.Now()
+	err := m.runCommand(ctx, cmd.station, cmd.value, src)
+	cmd.result.DurationMs += m.clock.Now().Sub(start).Milliseconds()
+	cmd.result.err = err
+	if err != nil {
+		cmd.result.Status = RestoreFailed
+		cmd.result.Error = err.Error()
+		cmd.result.Code = m.errorCode(err)
+		return
+	}
+	cmd.result.Status = RestoreOK
+	cmd.result.Error = ""
+	cmd.result.Code = ""
+}
+
+// powerAll sets every managed local station to value through runBulk.
+func (m *Manager) powerAll(ctx context.Context, value int, src Source) []RestoreResult {
+	stations := m.managedStations()
+
+	if value == bluetooth.PowerStateOn {
+		m.watchReadiness(stations)
+	}
+
+	results := make([]RestoreResult, len(stations))
+	commands := make([]bulkCommand, len(stations))
+	for i, s := range stations {
+		name := s.Name
+		if renamed, ok := m.config.StationName(s.Name); ok {
+			name = renamed
+		}
+		results[i] = RestoreResult{ID: s.ID(), Name: name, Address: s.Address.String(), Action: actionName(value)}
+		commands[i] = bulkCommand{station: s, value: value, result: &results[i]}
+	}
+	m.runBulk(ctx, commands, src)
+	return results
+}
+
+// StationOperationResult is what a command on all stations did to one of
+// them.
+type StationOperationResult struct {
+	ID         string `json:"id"`
+	Address    string `json:"address"`
+	Name       string `json:"name"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	Code       string `json:"code,omitempty"` // Kind of failure, one of the API error codes
+	Note       string `json:"note,omitempty"`
+	Attempts   int    `json:"attempts"`   // Commands sent, 2 if the second pass retried the station
+	DurationMs int64  `json:"durationMs"` // Time the commands took, over all attempts
+}
+
+// operationResults returns the results of a command on all stations.
+func operationResults(results []RestoreResult) []StationOperationResult {
+	operations := make([]StationOperationResult, len(results))
+	for i, result := range results {
+		operations[i] = StationOperationResult{
+			ID:         result.ID,
+			Address:    result.Address,
+			Name:       result.Name,
+			Success:    result.Status == RestoreOK,
+			Error:      result.Error,
+			Code:       result.Code,
+			Note:       result.Note,
+			Attempts:   result.Attempts,
+			DurationMs: result.DurationMs,
+		}
+	}
+	return operations
+}
+
+// BulkError is the failure of a bulk power command where at least one
+// station failed. Its message names what happened to every station; Err
+// joins the errors of the failed ones with errors.Join.
+type BulkError struct {
+	Op      string
+	Results []RestoreResult
+	Err     error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Op, e.Summary(), e.Err)
+}
+
+// Summary tells for every station whether it followed the command, e.g.
+// "LHB-A turned on, LHB-B did not respond".
+func (e *BulkError) Summary() string {
+	parts := make([]string, 0, len(e.Results))
+	for _, result := range e.Results {
+		switch result.Status {
+		case RestoreOK:
+			parts = append(parts, fmt.Sprintf("%s %s", result.Name, followedPhrase(result.Action)))
+		case RestoreFailed:
+			parts = append(parts, fmt.Sprintf("%s %s", result.Name, failurePhrase(result.err)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
+
+func followedPhrase(action string) string {
+	switch action {
+	case config.ActionOn:
+		return "turned on"
+	case config.ActionStandby:
+		return "went into standby"
+	}
+	return "turned off"
+}
+
+func failurePhrase(err error) string {
+	switch {
+	case errors.Is(err, bluetooth.ErrStateMismatch):
+		return "did not respond"
+	case errors.Is(err, ErrSuperseded):
+		return "got a newer command"
+	case errors.Is(err, bluetooth.ErrTimeout), errors.Is(err, bluetooth.ErrConnect):
+		return "could not be reached"
+	}
+	return "failed"
+}
+
+// bulkError returns a *BulkError for the results, nil if none failed.
+func bulkError(op string, results []RestoreResult) error {
+	var errs []error
+	for _, result := range results {
+		if result.Status == RestoreFailed {
+			errs = append(errs, result.err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BulkError{Op: op, Results: results, Err: errors.Join(errs...)}
+}
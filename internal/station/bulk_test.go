@@ -0,0 +1,82 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+func TestBulkErrorUnwrap(t *testing.T) {
+	connectErr := &bluetooth.Error{Station: "LHB-B", Kind: bluetooth.ErrConnect, Err: errors.New("unreachable")}
+	writeErr := &bluetooth.Error{Station: "LHB-C", Kind: bluetooth.ErrWrite, Err: errors.New("rejected"), Attempts: 2}
+	results := []RestoreResult{
+		{Name: "LHB-A", Status: RestoreOK},
+		{Name: "LHB-B", Status: RestoreFailed, err: connectErr},
+		{Name: "LHB-C", Status: RestoreFailed, err: writeErr},
+		{Name: "LHB-D", Status: RestoreSkipped},
+	}
+	err := bulkError("PowerOnAllStations", results)
+
+	var bulk *BulkError
+	if !errors.As(err, &bulk) {
+		t.Fatalf("got %v, want a *BulkError", err)
+	}
+	if joined, ok := bulk.Err.(interface{ Unwrap() []error }); !ok || len(joined.Unwrap()) != 2 {
+		t.Fatalf("got %v, want the 2 failures joined", bulk.Err)
+	}
+	for _, kind := range []error{bluetooth.ErrConnect, bluetooth.ErrWrite} {
+		if !errors.Is(err, kind) {
+			t.Errorf("errors.Is(err, %v) is false", kind)
+		}
+	}
+	if errors.Is(err, bluetooth.ErrTimeout) {
+		t.Error("bulk error is a timeout, but no station timed out")
+	}
+	var bleErr *bluetooth.Error
+	if !errors.As(err, &bleErr) || bleErr.Station != "LHB-B" {
+		t.Errorf("errors.As found %+v, want the first failure", bleErr)
+	}
+
+	if err := bulkError("PowerOnAllStations", results[:1]); err != nil {
+		t.Errorf("no station failed, got %v", err)
+	}
+}
+
+// A bulk command that fails for one station tells why through errors.Is.
+func TestBulkErrorFromCommand(t *testing.T) {
+	dead := fakeStation(2)
+	dead.Unreachable = true
+	m, _, clk := newFakeClockManager(t, fakeStation(1), dead)
+
+	var results []StationOperationResult
+	err := runAdvancing(t, clk, func() error {
+		var powerErr error
+		results, powerErr = m.PowerOffAllStations(context.Background(), Source{Kind: SourceUI})
+		return powerErr
+	})
+	var bulk *BulkError
+	if !errors.As(err, &bulk) {
+		t.Fatalf("got %v, want a *BulkError", err)
+	}
+	if !errors.Is(err, bluetooth.ErrConnect) {
+		t.Errorf("bulk error doesn't wrap the connect failure: %v", err)
+	}
+	if len(results) != 2 || len(bulk.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, result := range results {
+		failed := result.Address == dead.Address.String()
+		if result.Success == failed || (result.Error != "") != failed {
+			t.Errorf("station %s: success %v with error %q", result.Address, result.Success, result.Error)
+		}
+		if result.Name == "" || result.Attempts == 0 {
+			t.Errorf("station %s: result is incomplete: %+v", result.Address, result)
+		}
+		// The connects to the dead station are retried after a delay
+		if failed && result.DurationMs == 0 {
+			t.Errorf("station %s: failing took no time", result.Address)
+		}
+	}
+}
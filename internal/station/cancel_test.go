@@ -0,0 +1,175 @@
+package station
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// Canceling the context of an operation stops it at the next retry or sleep,
+// wherever it is.
+func TestCancelMidOperation(t *testing.T) {
+	slow := func(n int) bluetooth.FakeStation {
+		s := fakeStation(n)
+		s.Latency = 200 * time.Millisecond
+		return s
+	}
+	dead := func(n int) bluetooth.FakeStation {
+		s := slow(n)
+		s.Unreachable = true
+		return s
+	}
+	for _, test := range []struct {
+		name     string
+		stations []bluetooth.FakeStation
+		run      func(ctx context.Context, m *Manager) error
+	}{
+		{
+			name:     "scan",
+			stations: []bluetooth.FakeStation{fakeStation(1)},
+			run: func(ctx context.Context, m *Manager) error {
+				_, err := m.ScanAndFetchStations(ctx)
+				return err
+			},
+		},
+		{
+			name:     "power on with connect retries",
+			stations: []bluetooth.FakeStation{dead(1)},
+			run: func(ctx context.Context, m *Manager) error {
+				return m.PowerOnStation(ctx, dead(1).Address.String(), Source{Kind: SourceUI})
+			},
+		},
+		{
+			name:     "bulk power off",
+			stations: []bluetooth.FakeStation{dead(1), dead(2), slow(3)},
+			run: func(ctx context.Context, m *Manager) error {
+				_, err := m.PowerOffAllStations(ctx, Source{Kind: SourceUI})
+				return err
+			},
+		},
+		{
+			name:     "status check",
+			stations: []bluetooth.FakeStation{slow(1), dead(2)},
+			run: func(ctx context.Context, m *Manager) error {
+				_, err := m.CheckAllStationStatuses(ctx)
+				return err
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			m, _ := newTestManager(t, test.stations...)
+			before := runtime.NumGoroutine()
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- test.run(ctx, m) }()
+
+			time.Sleep(100 * time.Millisecond)
+			select {
+			case err := <-done:
+				t.Fatalf("finished before it was canceled: %v", err)
+			default:
+			}
+			canceled := time.Now()
+			cancel()
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("got %v, want context.Canceled", err)
+				}
+				// An operation already handed to the stack is abandoned at once,
+				// the slowest step left is the 200ms latency of the fake
+				if took := time.Since(canceled); took > 500*time.Millisecond {
+					t.Errorf("took %v to stop after it was canceled", took)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("didn't stop after it was canceled")
+			}
+
+			// The abandoned calls end once the fake answers them
+			deadline := time.Now().Add(2 * time.Second)
+			for runtime.NumGoroutine() > before {
+				if time.Now().After(deadline) {
+					t.Fatalf("%d goroutines still running, want at most %d", runtime.NumGoroutine(), before)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
+
+// transcriptBuffer collects a recorder's transcript while it's written.
+type transcriptBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *transcriptBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// ops returns the recorded operations in the order they finished.
+func (b *transcriptBuffer) ops(t *testing.T) []string {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var ops []string
+	dec := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for dec.More() {
+		var entry bluetooth.TranscriptEntry
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		ops = append(ops, entry.Op)
+	}
+	return ops
+}
+
+// Shutting down during a scan, like the app does, stops the adapter scan
+// before the stations are disconnected.
+func TestShutdownStopsScanBeforeDisconnecting(t *testing.T) {
+	s := fakeStation(1)
+	var transcript transcriptBuffer
+	m := newUninitializedManager(t, bluetooth.NewRecorder(bluetooth.NewFakeBackend(s), &transcript), s)
+	m.InitializeAsync()
+	// Leaves a connection open
+	if err := m.PowerOnStation(context.Background(), s.Address.String(), Source{Kind: SourceUI}); err != nil {
+		t.Fatal(err)
+	}
+
+	scanCtx, cancelScan := context.WithCancel(context.Background())
+	scanned := make(chan error, 1)
+	go func() {
+		_, err := m.ScanAndFetchStations(scanCtx)
+		scanned <- err
+	}()
+	for !m.IsScanning() {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	cancelScan()
+	m.Shutdown()
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("shutdown took %v during a canceled scan", took)
+	}
+	if err := <-scanned; !errors.Is(err, context.Canceled) {
+		t.Errorf("scan: got %v, want context.Canceled", err)
+	}
+
+	ops := transcript.ops(t)
+	stop, scanEnd, disconnect := slices.Index(ops, "stop-scan"), slices.Index(ops, "scan"), slices.Index(ops, "disconnect")
+	if stop < 0 || scanEnd < 0 || disconnect < stop || disconnect < scanEnd {
+		t.Errorf("adapter calls were %v, want the scan stopped before the disconnect", ops)
+	}
+}
@@ -0,0 +1,129 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// ErrChannelConflict is returned when a channel is already used by another station.
+var ErrChannelConflict = errors.New("channel already in use")
+
+// SetStationChannel changes the channel of a local station. It refuses a
+// channel another known local station is on, since two stations on one
+// channel disturb each other's tracking.
+func (m *Manager) SetStationChannel(ctx context.Context, address string, channel int) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+	if other := m.stationOnChannel(channel, stationPtr); other != nil {
+		name := other.Name
+		if renamed, ok := m.config.StationName(other.Name); ok {
+			name = renamed
+		}
+		return fmt.Errorf("%w: %s is already on channel %d", ErrChannelConflict, name, channel)
+	}
+	if err := m.waitAdapter(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+	defer cancel()
+	if err := bluetooth.SetChannel(ctx, stationPtr, channel); err != nil {
+		return err
+	}
+	log.Printf("Manager: %s is now on channel %d", stationPtr.Name, channel)
+	m.CheckChannelConflicts()
+	m.publishDelta(m.GetStationInfo())
+	return nil
+}
+
+// stationOnChannel returns a station other than except that is on channel, or nil.
+func (m *Manager) stationOnChannel(channel int, except *bluetooth.BaseStation) *bluetooth.BaseStation {
+	m.stationsMutex.RLock()
+	defer m.stationsMutex.RUnlock()
+	for _, s := range m.stations {
+		if s != nil && s != except && channel != bluetooth.ChannelUnknown && s.Channel() == channel {
+			return s
+		}
+	}
+	return nil
+}
+
+// ChannelConflict is a pair of local stations on the same channel.
+type ChannelConflict struct {
+	Channel   int       `json:"channel"`
+	Addresses [2]string `json:"addresses"`
+	Names     [2]string `json:"names"`
+}
+
+// CheckChannelConflicts returns every pair of known local stations that are
+// on the same channel, ordered by channel and address. Stations whose channel
+// is unknown are left out. When the conflicts differ from the last check and
+// there are any, a "channel-conflict" event with them is emitted.
+func (m *Manager) CheckChannelConflicts() []ChannelConflict {
+	m.stationsMutex.RLock()
+	stations := make([]*bluetooth.BaseStation, 0, len(m.stations))
+	for _, s := range m.stations {
+		if s != nil && s.Channel() != bluetooth.ChannelUnknown {
+			stations = append(stations, s)
+		}
+	}
+	m.stationsMutex.RUnlock()
+	sort.Slice(stations, func(i, j int) bool {
+		if stations[i].Channel() != stations[j].Channel() {
+			return stations[i].Channel() < stations[j].Channel()
+		}
+		return stations[i].Address.String() < stations[j].Address.String()
+	})
+
+	name := func(s *bluetooth.BaseStation) string {
+		if renamed, ok := m.config.StationName(s.Name); ok {
+			return renamed
+		}
+		return s.Name
+	}
+	conflicts := []ChannelConflict{}
+	for i, a := range stations {
+		for _, b := range stations[i+1:] {
+			if a.Channel() != b.Channel() {
+				break
+			}
+			conflicts = append(conflicts, ChannelConflict{
+				Channel:   a.Channel(),
+				Addresses: [2]string{a.Address.String(), b.Address.String()},
+				Names:     [2]string{name(a), name(b)},
+			})
+		}
+	}
+
+	m.conflictsMutex.Lock()
+	changed := !slices.Equal(conflicts, m.conflicts)
+	m.conflicts = conflicts
+	m.conflictsMutex.Unlock()
+	if changed && len(conflicts) > 0 {
+		for _, c := range conflicts {
+			log.Printf("Manager: %s and %s are both on channel %d", c.Names[0], c.Names[1], c.Channel)
+		}
+		m.emit("channel-conflict", conflicts)
+	}
+	return conflicts
+}
+
+// channelCountsLocked returns how many local stations are on each known
+// channel. Assumes the caller holds stationsMutex.
+func (m *Manager) channelCountsLocked() map[int]int {
+	counts := make(map[int]int)
+	for _, s := range m.stations {
+		if s != nil && s.Channel() != bluetooth.ChannelUnknown {
+			counts[s.Channel()]++
+		}
+	}
+	return counts
+}
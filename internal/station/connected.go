@@ -0,0 +1,77 @@
+package station
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// connectedFetchTimeout bounds reading the state of the stations found
+// connected at startup.
+const connectedFetchTimeout = 7 * time.Second
+
+// withConnectedStations adds the unknown stations the system is already
+// connected to that a scan didn't see to its results. Connected stations stop
+// advertising, so without this they'd only show up once something else
+// disconnects them. Known stations are left out, their scan data would be
+// replaced with none.
+func (m *Manager) withConnectedStations(discovered []*bluetooth.BaseStation) []*bluetooth.BaseStation {
+	for _, connected := range bluetooth.ListConnectedStations(m.scanFilter()) {
+		address := connected.Address.String()
+		if m.lookup(address) != nil || containsAddress(discovered, address) {
+			continue
+		}
+		log.Printf("Manager: %s is connected to the system but didn't advertise, adding it", connected.Name)
+		discovered = append(discovered, connected)
+	}
+	return discovered
+}
+
+func containsAddress(stations []*bluetooth.BaseStation, address string) bool {
+	for _, s := range stations {
+		if bluetooth.SameAddress(s.Address.String(), address) {
+			return true
+		}
+	}
+	return false
+}
+
+// adoptConnectedStations adds the stations the system is already connected to
+// once the adapter is ready and reads their state, so they can be controlled
+// before the first scan.
+func (m *Manager) adoptConnectedStations() {
+	connectedStations := bluetooth.ListConnectedStations(m.scanFilter())
+	added := make([]*bluetooth.BaseStation, 0)
+	m.stationsMutex.Lock()
+	for _, connected := range connectedStations {
+		address := connected.Address.String()
+		if _, known := m.stations[address]; known {
+			continue
+		}
+		m.stations[address] = connected
+		added = append(added, connected)
+	}
+	m.stationsMutex.Unlock()
+	if len(added) == 0 {
+		return
+	}
+	log.Printf("Manager: Adding %d station(s) already connected to the system", len(added))
+
+	ctx, cancel := clock.WithTimeout(m.ctx, m.clock, connectedFetchTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, stationPtr := range added {
+		wg.Add(1)
+		go func(ptr *bluetooth.BaseStation) {
+			defer wg.Done()
+			_ = bluetooth.FetchInitialPowerState(ctx, ptr)
+		}(stationPtr)
+	}
+	if !waitContext(ctx, &wg) {
+		log.Println("Warning: Timed out reading the state of already connected stations.")
+	}
+	m.publishStations("stations-updated", m.GetStationInfo())
+}
@@ -0,0 +1,97 @@
+package station
+
+import (
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// StationConnection is a station's connection record for diagnostics.
+type StationConnection struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	bluetooth.ConnectionDiagnostics
+}
+
+// ConnectionReport relates the configured connection parameters to how
+// connections went, for bug reports.
+type ConnectionReport struct {
+	Supported  bluetooth.ParamSupport     `json:"supported"`
+	Configured bluetooth.ConnectionParams `json:"configured"`
+	Effective  bluetooth.ConnectionParams `json:"effective"` // Without the parameters this platform ignores
+	Stations   []StationConnection        `json:"stations"`
+}
+
+// ApplyConnectionParams passes the configured connection parameters,
+// operation and discovery timeouts, connect limit, retry policy and disconnect-after-command
+// mode to the bluetooth layer. The parameters apply to connections made from now on.
+// Turning the mode on drops the connections that are open.
+func (m *Manager) ApplyConnectionParams() {
+	settings := m.config.GetConnectionSettings()
+	bluetooth.SetConnectionParams(m.configuredParams())
+	bluetooth.SetOperationTimeout(time.Duration(settings.OperationTimeoutSeconds) * time.Second)
+	bluetooth.SetDiscoveryTimeout(time.Duration(settings.DiscoveryTimeoutSeconds) * time.Second)
+	bluetooth.SetMaxConnects(settings.MaxConcurrent)
+	bluetooth.SetRetryPolicy(bluetooth.RetryPolicy{
+		Attempts:       settings.Retry.Attempts,
+		InitialDelay:   time.Duration(settings.Retry.InitialDelayMs) * time.Millisecond,
+		Multiplier:     settings.Retry.Multiplier,
+		AttemptTimeout: time.Duration(settings.Retry.AttemptTimeoutMs) * time.Millisecond,
+	})
+	if settings.DisconnectAfterCommand && !bluetooth.DisconnectAfterCommand() && bluetooth.ConnectedCount() > 0 {
+		go func() {
+			bluetooth.DisconnectAllStations()
+			m.publishStations("stations-updated", m.GetStationInfo())
+		}()
+	}
+	bluetooth.SetDisconnectAfterCommand(settings.DisconnectAfterCommand)
+}
+
+// SetDisconnectAfterCommand turns the disconnect-after-command mode on or
+// off, applies it and saves the config.
+func (m *Manager) SetDisconnectAfterCommand(enabled bool) error {
+	settings := m.config.GetConnectionSettings()
+	settings.DisconnectAfterCommand = enabled
+	if err := m.config.SetConnectionSettings(settings); err != nil {
+		return err
+	}
+	m.ApplyConnectionParams()
+	return m.config.Save()
+}
+
+func (m *Manager) configuredParams() bluetooth.ConnectionParams {
+	p := m.config.GetConnectionSettings().Params
+	return bluetooth.ConnectionParams{
+		ConnectTimeoutMs:     p.ConnectTimeoutMs,
+		MinIntervalMs:        p.MinIntervalMs,
+		MaxIntervalMs:        p.MaxIntervalMs,
+		SupervisionTimeoutMs: p.SupervisionTimeoutMs,
+	}
+}
+
+// ConnectionReport returns the connection parameters and per-station
+// connection counters since start.
+func (m *Manager) ConnectionReport() ConnectionReport {
+	report := ConnectionReport{
+		Supported:  bluetooth.SupportedParams(),
+		Configured: m.configuredParams(),
+		Effective:  bluetooth.CurrentConnectionParams(),
+		Stations:   []StationConnection{},
+	}
+	m.stationsMutex.RLock()
+	defer m.stationsMutex.RUnlock()
+	for address, stationPtr := range m.stations {
+		name := stationPtr.Name
+		if renamed, ok := m.config.StationName(stationPtr.Name); ok {
+			name = renamed
+		}
+		report.Stations = append(report.Stations, StationConnection{
+			ID:                    stationPtr.ID(),
+			Name:                  name,
+			Address:               address,
+			ConnectionDiagnostics: stationPtr.ConnectionDiagnostics(),
+		})
+	}
+	return report
+}
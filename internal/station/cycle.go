@@ -0,0 +1,127 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// Steps of a power cycle after the bluetooth ones, which a CycleEvent also carries
+const (
+	CycleDone   = "done"
+	CycleFailed = "failed"
+)
+
+// CycleEvent reports the progress of a power cycle with the "station-cycle" event.
+type CycleEvent struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Step    string `json:"step"`            // bluetooth.CycleOffSent, CycleWaiting, CycleOnSent, CycleDone or CycleFailed
+	Error   string `json:"error,omitempty"` // Set for CycleFailed
+}
+
+// PowerCycleStation turns a local station off, waits offDuration, or
+// bluetooth.DefaultCycleOffDuration if it is 0, and turns it back on. The
+// station's command queue is held for the whole cycle, so power commands
+// sent meanwhile run after it; a station with a command in progress fails
+// with bluetooth.ErrBusy.
+func (m *Manager) PowerCycleStation(ctx context.Context, address string, offDuration time.Duration) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+	return m.powerCycle(ctx, stationPtr, offDuration)
+}
+
+// PowerCycleAllStations power cycles every managed local station at once, like
+// PowerCycleStation, and returns the joined errors of those that failed.
+func (m *Manager) PowerCycleAllStations(ctx context.Context, offDuration time.Duration) error {
+	stations := m.managedStations()
+	errs := make([]error, len(stations))
+	var wg sync.WaitGroup
+	for i, s := range stations {
+		wg.Add(1)
+		go func(i int, s *bluetooth.BaseStation) {
+			defer wg.Done()
+			errs[i] = m.powerCycle(ctx, s, offDuration)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("encountered %d error(s) during PowerCycleAllStations: %w", len(failed), errors.Join(failed...))
+}
+
+func (m *Manager) powerCycle(ctx context.Context, s *bluetooth.BaseStation, offDuration time.Duration) error {
+	if offDuration <= 0 {
+		offDuration = bluetooth.DefaultCycleOffDuration
+	}
+	name := s.Name
+	if renamed, ok := m.config.StationName(s.Name); ok {
+		name = renamed
+	}
+	event := CycleEvent{Address: s.Address.String(), Name: name}
+
+	if err := m.waitAdapter(ctx); err != nil {
+		return err
+	}
+	q, ok := m.claimQueue(s.ID())
+	if !ok {
+		return fmt.Errorf("%w: %s has a power command in progress", bluetooth.ErrBusy, name)
+	}
+	defer func() { go m.drainQueue(s, q) }()
+
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, 2*commandTimeout+offDuration)
+	defer cancel()
+	err := bluetooth.PowerCycle(ctx, s, offDuration, func(step string) {
+		event.Step = step
+		m.emit("station-cycle", event)
+		if step != bluetooth.CycleWaiting {
+			m.publishDelta(m.GetStationInfo())
+		}
+	})
+	event.Step = CycleDone
+	if err != nil {
+		log.Printf("Manager: Power cycle of %s failed: %v", event.Address, err)
+		event.Step = CycleFailed
+		event.Error = err.Error()
+	} else {
+		m.verifyAfterCommand(s, bluetooth.PowerStateOn)
+	}
+	m.emit("station-cycle", event)
+	m.publishDelta(m.GetStationInfo())
+	return err
+}
+
+// claimQueue marks a station's command queue as running for an operation
+// other than a single command, so commands sent meanwhile wait as pending.
+// It fails if a command is already running. The caller drains the queue
+// when done.
+func (m *Manager) claimQueue(id string) (*commandQueue, bool) {
+	m.queuesMutex.Lock()
+	defer m.queuesMutex.Unlock()
+	q, ok := m.queues[id]
+	if !ok {
+		q = &commandQueue{}
+		m.queues[id] = q
+	}
+	if q.running {
+		return nil, false
+	}
+	q.running = true
+	return q, true
+}
@@ -0,0 +1,128 @@
+package station
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// snapshotEvery forces a full snapshot event after this many deltas, so
+// consumers that silently missed one resynchronize on their own.
+const snapshotEvery = 50
+
+// StationsDelta holds the stations that changed since the previous delta or snapshot.
+type StationsDelta struct {
+	Seq     uint64        `json:"seq"`
+	Changed []StationInfo `json:"changed"`
+	Removed []string      `json:"removed"` // Addresses
+}
+
+// StationsSnapshot is the full station list at a sequence number. The next
+// delta has Seq+1.
+type StationsSnapshot struct {
+	Seq      uint64        `json:"seq"`
+	Stations []StationInfo `json:"stations"`
+}
+
+// deltaTracker remembers the last published station list and computes deltas against it.
+type deltaTracker struct {
+	mu            sync.Mutex
+	seq           uint64
+	last          map[string]StationInfo
+	sinceSnapshot int
+}
+
+// update records stations and returns the delta to publish, if anything changed,
+// and whether a full snapshot is due.
+func (t *deltaTracker) update(stations []StationInfo) (delta StationsDelta, changed, snapshotDue bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[string]StationInfo, len(stations))
+	delta = StationsDelta{Changed: []StationInfo{}, Removed: []string{}}
+	for _, s := range stations {
+		current[s.Address] = s
+		if previous, ok := t.last[s.Address]; !ok || stationChanged(previous, s) {
+			delta.Changed = append(delta.Changed, s)
+		}
+	}
+	for address := range t.last {
+		if _, ok := current[address]; !ok {
+			delta.Removed = append(delta.Removed, address)
+		}
+	}
+	t.last = current
+	if len(delta.Changed) == 0 && len(delta.Removed) == 0 {
+		return delta, false, false
+	}
+
+	sort.Slice(delta.Changed, func(i, j int) bool { return delta.Changed[i].Address < delta.Changed[j].Address })
+	sort.Strings(delta.Removed)
+	t.seq++
+	delta.Seq = t.seq
+	t.sinceSnapshot++
+	if t.sinceSnapshot >= snapshotEvery {
+		t.sinceSnapshot = 0
+		snapshotDue = true
+	}
+	return delta, true, snapshotDue
+}
+
+// stationChanged reports whether b differs from a in anything but the time of
+// the last state update, so routine reads don't count as changes. Stale
+// turning on or off still does.
+func stationChanged(a, b StationInfo) bool {
+	a.LastStateUpdate, b.LastStateUpdate = time.Time{}, time.Time{}
+	return a != b
+}
+
+// snapshot returns the last recorded list with its sequence number.
+func (t *deltaTracker) snapshot() StationsSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stations := make([]StationInfo, 0, len(t.last))
+	for _, s := range t.last {
+		stations = append(stations, s)
+	}
+	sort.Slice(stations, func(i, j int) bool { return stations[i].Address < stations[j].Address })
+	return StationsSnapshot{Seq: t.seq, Stations: stations}
+}
+
+// publishStations emits the full list under eventName and a "stations-delta"
+// event with the entries that changed since the last publish.
+func (m *Manager) publishStations(eventName string, stations []StationInfo) {
+	m.emit(eventName, stations)
+	m.publishDelta(stations)
+}
+
+// publishChanges is publishStations for routine refreshes: it emits nothing
+// unless the list changed since the last publish.
+func (m *Manager) publishChanges(eventName string, stations []StationInfo) {
+	if m.publishDelta(stations) {
+		m.emit(eventName, stations)
+	}
+}
+
+// publishDelta emits a "stations-delta" event if the station list changed,
+// followed by a "stations-snapshot" event when one is due, and reports
+// whether it changed. Booting stations in the list are polled until they
+// settle, see followBooting.
+func (m *Manager) publishDelta(stations []StationInfo) bool {
+	delta, changed, snapshotDue := m.deltas.update(stations)
+	if !changed {
+		return false
+	}
+	m.followBooting(stations)
+	m.emit("stations-delta", delta)
+	if snapshotDue {
+		m.emit("stations-snapshot", m.deltas.snapshot())
+	}
+	return true
+}
+
+// StationSnapshot returns the current station list with the sequence number
+// of the last delta, for consumers that need to resynchronize.
+func (m *Manager) StationSnapshot() StationsSnapshot {
+	m.publishDelta(m.GetStationInfo())
+	return m.deltas.snapshot()
+}
@@ -0,0 +1,98 @@
+package station
+
+import (
+	"context"
+	"fmt"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/pkg/apiclient"
+)
+
+// RawPowerReading is the last raw power value of a station, shared with the
+// API client.
+type RawPowerReading = apiclient.RawPowerReading
+
+// VerboseStationInfo is a station's list entry with its raw power value.
+type VerboseStationInfo = apiclient.VerboseStationInfo
+
+// StationDetails is what GetStationDetails returns: a station's list entry
+// plus information that is too costly to read for every status check.
+type StationDetails struct {
+	Station          StationInfo     `json:"station"`
+	FirmwareRevision string          `json:"firmwareRevision"` // Empty if the station doesn't report it
+	HardwareRevision string          `json:"hardwareRevision"`
+	RawPower         RawPowerReading `json:"rawPower"` // Last value of the power characteristic, for diagnostics
+	// Failure counters since start or the last ResetStationHealth
+	Health bluetooth.HealthCounters `json:"health"`
+}
+
+// StationDetails returns the details of a local station. The device
+// information is read once, connecting if necessary, and cached after.
+func (m *Manager) StationDetails(ctx context.Context, address string) (StationDetails, error) {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return StationDetails{}, fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+
+	info, ok := stationPtr.DeviceInfo()
+	if !ok {
+		if err := m.waitAdapter(ctx); err != nil {
+			return StationDetails{}, err
+		}
+		ctx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+		defer cancel()
+		var err error
+		if info, err = bluetooth.ReadDeviceInfo(ctx, stationPtr); err != nil {
+			return StationDetails{}, err
+		}
+	}
+
+	details := StationDetails{
+		FirmwareRevision: info.FirmwareRevision,
+		HardwareRevision: info.HardwareRevision,
+		RawPower:         rawPowerReading(stationPtr.LastRawPower()),
+		Health:           stationPtr.HealthCounters(),
+	}
+	id := stationPtr.ID()
+	for _, s := range m.GetStationInfo() {
+		if s.ID == id {
+			details.Station = s
+			break
+		}
+	}
+	return details, nil
+}
+
+// ResetStationHealth clears the failure counters of a local station.
+func (m *Manager) ResetStationHealth(address string) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+	stationPtr.ResetHealth()
+	m.publishDelta(m.GetStationInfo())
+	return nil
+}
+
+// VerboseStationInfo returns the local stations like GetStationInfo, each
+// with the raw power value it last reported.
+func (m *Manager) VerboseStationInfo() []VerboseStationInfo {
+	stations := m.GetStationInfo()
+	verbose := make([]VerboseStationInfo, 0, len(stations))
+	for _, info := range stations {
+		entry := VerboseStationInfo{StationInfo: info, RawPower: RawPowerReading{Value: -1}}
+		if stationPtr := m.lookup(info.Address); stationPtr != nil {
+			entry.RawPower = rawPowerReading(stationPtr.LastRawPower())
+		}
+		verbose = append(verbose, entry)
+	}
+	return verbose
+}
+
+func rawPowerReading(r bluetooth.RawPowerReading) RawPowerReading {
+	if r.At.IsZero() {
+		return RawPowerReading{Value: -1} // Never read
+	}
+	return RawPowerReading{Value: r.Value, Bytes: r.Bytes, At: r.At, Source: r.Source, Error: r.Error}
+}
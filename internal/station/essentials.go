@@ -0,0 +1,60 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+)
+
+// SetEssential marks a station, by address or ID, as essential or not and
+// saves the config.
+func (m *Manager) SetEssential(key string, essential bool) error {
+	id, ok := m.StationID(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, key)
+	}
+	settings := m.config.GetStationSettings(id)
+	settings.Essential = essential
+	m.config.SetStationSettings(id, settings)
+	if err := m.config.Save(); err != nil {
+		return err
+	}
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return nil
+}
+
+// PowerOnEssentials turns on the stations marked as essential and leaves the
+// others alone. It does nothing if no station is marked.
+func (m *Manager) PowerOnEssentials(ctx context.Context, src Source) []RestoreResult {
+	return m.applyToEssentials(ctx, true, bluetooth.PowerStateOn, src)
+}
+
+// PowerOffNonEssentials turns off the managed stations not marked as
+// essential. It does nothing if no station is marked, rather than turning
+// everything off.
+func (m *Manager) PowerOffNonEssentials(ctx context.Context, src Source) []RestoreResult {
+	return m.applyToEssentials(ctx, false, bluetooth.PowerStateOff, src)
+}
+
+// applyToEssentials sets the power state of the stations whose essential
+// flag equals essential, with per-station results.
+func (m *Manager) applyToEssentials(ctx context.Context, essential bool, powerState int, src Source) []RestoreResult {
+	var targets []config.SnapshotStation
+	marked := 0
+	for _, info := range m.GetStationInfo() {
+		if info.Essential {
+			marked++
+		}
+		if info.Essential == essential && (essential || !info.Unmanaged) {
+			targets = append(targets, config.SnapshotStation{ID: info.ID, Name: info.Name, PowerState: powerState})
+		}
+	}
+	if marked == 0 {
+		log.Printf("Manager: No essential stations marked, nothing to turn %s", actionName(powerState))
+		return []RestoreResult{}
+	}
+	return m.ApplyStates(ctx, targets, src)
+}
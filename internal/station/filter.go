@@ -0,0 +1,15 @@
+package station
+
+import (
+	"lhcontrol/internal/bluetooth"
+)
+
+// scanFilter returns the configured scan filter.
+func (m *Manager) scanFilter() bluetooth.ScanFilter {
+	configured := m.config.GetFilterConfig()
+	filter := bluetooth.ScanFilter{Prefixes: configured.Prefixes}
+	for _, address := range configured.Addresses {
+		filter.Addresses = append(filter.Addresses, bluetooth.Address(address))
+	}
+	return filter
+}
@@ -0,0 +1,68 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/internal/config"
+)
+
+// ForgetStation removes a local station from the list, e.g. a neighbour's
+// that a scan picked up. A command waiting in its queue fails with
+// ErrStationNotFound; one already running is waited for, up to
+// commandTimeout, before the station is disconnected, and after that the
+// disconnect happens in the background once it is done. Its custom name, its
+// settings, its group membership and its known station entry are removed and
+// the config is saved. A later scan that finds the station lists it again.
+func (m *Manager) ForgetStation(ctx context.Context, address string) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+	key := stationPtr.Address.String()
+	m.stationsMutex.Lock()
+	if m.stations[key] != stationPtr {
+		m.stationsMutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address) // Forgotten or replaced meanwhile
+	}
+	delete(m.stations, key)
+	delete(m.missedScans, key)
+	m.stationsMutex.Unlock()
+
+	id := stationPtr.ID()
+	m.queuesMutex.Lock()
+	if q, ok := m.queues[id]; ok {
+		if q.pending != nil {
+			q.pending.err = fmt.Errorf("%w: %s was forgotten", ErrStationNotFound, key)
+			close(q.pending.done)
+			q.pending = nil
+		}
+		if !q.running {
+			delete(m.queues, id)
+		}
+	}
+	m.queuesMutex.Unlock()
+	m.verifyMutex.Lock()
+	if v, ok := m.verifications[id]; ok {
+		v.cancel()
+	}
+	m.verifyMutex.Unlock()
+
+	waitCtx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+	defer cancel()
+	if err := bluetooth.DisconnectStationWait(waitCtx, stationPtr); err != nil {
+		log.Printf("Manager: %s is still busy, disconnecting it once it is done: %v", key, err)
+		go func() { _ = bluetooth.DisconnectStationWait(m.ctx, stationPtr) }()
+	}
+
+	m.config.SetStationName(stationPtr.Name, "")
+	m.config.SetStationSettings(id, config.StationSettings{})
+	m.config.SetStationGroup(id, "")
+	m.forgetStations([]string{key})
+	log.Printf("Manager: Forgot %s (%s)", stationPtr.Name, key)
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return m.config.Save()
+}
@@ -0,0 +1,105 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+)
+
+var (
+	// ErrGroupNotFound is returned for an unknown group name.
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrGroupExists is returned when creating a group whose name is taken.
+	ErrGroupExists = errors.New("group already exists")
+)
+
+// Groups returns the groups with the IDs of their stations.
+func (m *Manager) Groups() map[string][]string {
+	return m.config.GetGroups()
+}
+
+// CreateGroup adds an empty group and saves the config.
+func (m *Manager) CreateGroup(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("group name must not be empty")
+	}
+	if !m.config.CreateGroup(name) {
+		return fmt.Errorf("%w: %s", ErrGroupExists, name)
+	}
+	if err := m.config.Save(); err != nil {
+		return err
+	}
+	log.Printf("Manager: Created group '%s'", name)
+	return nil
+}
+
+// DeleteGroup removes a group, leaving its stations without one, and saves
+// the config.
+func (m *Manager) DeleteGroup(name string) error {
+	if !m.config.DeleteGroup(name) {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+	if err := m.config.Save(); err != nil {
+		return err
+	}
+	log.Printf("Manager: Deleted group '%s'", name)
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return nil
+}
+
+// AssignStationToGroup moves a station, by address or ID, into a group and
+// saves the config. A station is in at most one group; an empty group name
+// removes it from its group. Membership is kept by station ID, so it
+// survives renames and address changes.
+func (m *Manager) AssignStationToGroup(key, group string) error {
+	id, ok := m.StationID(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, key)
+	}
+	if !m.config.SetStationGroup(id, group) {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, group)
+	}
+	if err := m.config.Save(); err != nil {
+		return err
+	}
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return nil
+}
+
+// PowerOnGroup turns on the stations of a group, in parallel, with
+// per-station results. Members no scan has found are skipped.
+func (m *Manager) PowerOnGroup(ctx context.Context, name string, src Source) ([]RestoreResult, error) {
+	return m.powerGroup(ctx, name, bluetooth.PowerStateOn, src)
+}
+
+// PowerOffGroup turns off the stations of a group, see PowerOnGroup.
+func (m *Manager) PowerOffGroup(ctx context.Context, name string, src Source) ([]RestoreResult, error) {
+	return m.powerGroup(ctx, name, bluetooth.PowerStateOff, src)
+}
+
+func (m *Manager) powerGroup(ctx context.Context, name string, powerState int, src Source) ([]RestoreResult, error) {
+	ids, ok := m.config.GetGroups()[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, name)
+	}
+	names := make(map[string]string)
+	for _, info := range m.GetStationInfo() {
+		names[info.ID] = info.Name
+	}
+	targets := make([]config.SnapshotStation, len(ids))
+	for i, id := range ids {
+		stationName, ok := names[id]
+		if !ok {
+			stationName = id
+		}
+		targets[i] = config.SnapshotStation{ID: id, Name: stationName, PowerState: powerState}
+	}
+	log.Printf("Manager: Turning group '%s' %s (%d station(s))", name, actionName(powerState), len(targets))
+	return m.ApplyStates(ctx, targets, src), nil
+}
@@ -0,0 +1,204 @@
+package station
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+)
+
+// ImportEntry is a station in an import file. Imports use the JSON export
+// format; fields other than these are ignored.
+type ImportEntry struct {
+	Address      string `json:"address"`
+	OriginalName string `json:"originalName"`
+	Name         string `json:"name"`
+	Origin       string `json:"origin"`
+}
+
+// ImportConflict is an entry that disagrees with a station already known.
+type ImportConflict struct {
+	Address string `json:"address"`
+	Reason  string `json:"reason"`
+}
+
+// ImportInvalid is an entry that couldn't be imported.
+type ImportInvalid struct {
+	Index int    `json:"index"` // Position in the import file
+	Error string `json:"error"`
+}
+
+// ImportReport is the outcome of an import. Importing the same file twice
+// reports every entry as unchanged the second time.
+type ImportReport struct {
+	Added     []string         `json:"added"`
+	Updated   []string         `json:"updated"`
+	Unchanged []string         `json:"unchanged"`
+	Skipped   []ImportConflict `json:"skipped"` // Conflicts left alone because overwrite wasn't set
+	Invalid   []ImportInvalid  `json:"invalid"`
+}
+
+// ParseImport decodes an import file.
+func ParseImport(data []byte) ([]ImportEntry, error) {
+	var entries []ImportEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("import must be a JSON array of stations as written by the export: %w", err)
+	}
+	return entries, nil
+}
+
+// ImportStations adds the entries to the known stations, so they can be
+// controlled before a scan finds them, and applies their custom names.
+// Entries that disagree with a known station about its name are skipped
+// unless overwrite is set. The config is only saved if something changed.
+func (m *Manager) ImportStations(entries []ImportEntry, overwrite bool) (ImportReport, error) {
+	report := ImportReport{
+		Added:     []string{},
+		Updated:   []string{},
+		Unchanged: []string{},
+		Skipped:   []ImportConflict{},
+		Invalid:   []ImportInvalid{},
+	}
+	known := m.config.GetKnownStations()
+	index := make(map[string]int, len(known))
+	for i, s := range known {
+		index[knownAddress(s)] = i
+	}
+	seen := make(map[string]bool, len(entries))
+	changed := false
+
+	for i, entry := range entries {
+		normalized, addressErr := bluetooth.NormalizeAddress(entry.Address)
+		address := normalized.String()
+		originalName := strings.TrimSpace(entry.OriginalName)
+		name := strings.TrimSpace(entry.Name)
+		if entry.Origin != "" && entry.Origin != OriginLocal {
+			report.Invalid = append(report.Invalid, ImportInvalid{Index: i, Error: fmt.Sprintf("station is reached through agent '%s', not this machine", entry.Origin)})
+			continue
+		}
+		if addressErr != nil {
+			report.Invalid = append(report.Invalid, ImportInvalid{Index: i, Error: addressErr.Error()})
+			continue
+		}
+		if originalName == "" {
+			report.Invalid = append(report.Invalid, ImportInvalid{Index: i, Error: "originalName is missing"})
+			continue
+		}
+		if seen[address] {
+			report.Invalid = append(report.Invalid, ImportInvalid{Index: i, Error: fmt.Sprintf("%s is listed more than once", address)})
+			continue
+		}
+		seen[address] = true
+
+		var conflicts []string
+		current := originalName
+		if j, ok := index[address]; ok {
+			current = known[j].OriginalName
+		} else if existing := m.lookup(address); existing != nil {
+			current = existing.Name
+		}
+		if current != originalName {
+			conflicts = append(conflicts, fmt.Sprintf("known as '%s', not '%s'", current, originalName))
+		}
+		customName, renamed := m.config.StationName(originalName)
+		if name == originalName {
+			name = ""
+		}
+		if name != "" && renamed && customName != name {
+			conflicts = append(conflicts, fmt.Sprintf("already named '%s', not '%s'", customName, name))
+		}
+		if len(conflicts) > 0 && !overwrite {
+			report.Skipped = append(report.Skipped, ImportConflict{Address: address, Reason: strings.Join(conflicts, "; ")})
+			continue
+		}
+
+		entryChanged := false
+		j, ok := index[address]
+		switch {
+		case !ok:
+			index[address] = len(known)
+			known = append(known, config.KnownStation{Address: address, OriginalName: originalName})
+			entryChanged = true
+		case known[j].OriginalName != originalName:
+			known[j].OriginalName = originalName
+			entryChanged = true
+		}
+		if ok && known[j].Address != address {
+			known[j].Address = address
+			changed = true
+		}
+		if name != "" && customName != name {
+			m.config.SetStationName(originalName, name)
+			entryChanged = true
+		}
+
+		switch {
+		case !ok && m.lookup(address) == nil:
+			report.Added = append(report.Added, address)
+		case entryChanged:
+			report.Updated = append(report.Updated, address)
+		default:
+			report.Unchanged = append(report.Unchanged, address)
+		}
+		changed = changed || entryChanged
+	}
+
+	if !changed {
+		return report, nil
+	}
+	if err := m.config.SetKnownStations(known); err != nil {
+		return report, err
+	}
+	if err := m.config.Save(); err != nil {
+		return report, err
+	}
+	m.SeedStations()
+	log.Printf("Manager: Imported stations, %d added, %d updated, %d unchanged, %d skipped, %d invalid",
+		len(report.Added), len(report.Updated), len(report.Unchanged), len(report.Skipped), len(report.Invalid))
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return report, nil
+}
+
+// SeedStations adds the known stations that no scan has found yet to the
+// station list, with an unknown power state.
+func (m *Manager) SeedStations() {
+	m.stationsMutex.Lock()
+	defer m.stationsMutex.Unlock()
+	for _, known := range m.config.GetKnownStations() {
+		address := bluetooth.Address(knownAddress(known))
+		if existing, ok := m.stations[address.String()]; ok {
+			existing.Name = known.OriginalName
+			existing.Protocol = bluetooth.ProtocolForName(known.OriginalName)
+			continue
+		}
+		if m.findByIDLocked(bluetooth.StationID(known.OriginalName, address)) != nil {
+			continue
+		}
+		m.stations[address.String()] = bluetooth.NewStation(known.OriginalName, address)
+	}
+}
+
+// NormalizeConfigAddresses rewrites the station addresses stored in the config
+// to the form scans report, collapsing entries that only differed in case or
+// separators. It reports whether anything changed; the caller saves the config.
+func (m *Manager) NormalizeConfigAddresses() bool {
+	return m.config.NormalizeStationAddresses(func(address string) (string, bool) {
+		normalized, err := bluetooth.NormalizeAddress(address)
+		if err != nil {
+			return "", false
+		}
+		return normalized.String(), true
+	})
+}
+
+// knownAddress returns the address of a known station in the form scans
+// report it. Files written by older versions may differ in case.
+func knownAddress(known config.KnownStation) string {
+	if address, err := bluetooth.NormalizeAddress(known.Address); err == nil {
+		return address.String()
+	}
+	return known.Address
+}
@@ -0,0 +1,45 @@
+package station
+
+import (
+	"fmt"
+	"log"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// SetManaged includes a station, by address or ID, in the commands to all
+// stations or leaves it out of them, and saves the config. Commands to the
+// station itself work either way.
+func (m *Manager) SetManaged(key string, managed bool) error {
+	id, ok := m.StationID(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, key)
+	}
+	settings := m.config.GetStationSettings(id)
+	settings.Unmanaged = !managed
+	m.config.SetStationSettings(id, settings)
+	if err := m.config.Save(); err != nil {
+		return err
+	}
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return nil
+}
+
+// managedStations returns the local stations that commands to all stations
+// go to, leaving out the unmanaged ones.
+func (m *Manager) managedStations() []*bluetooth.BaseStation {
+	m.stationsMutex.RLock()
+	defer m.stationsMutex.RUnlock()
+	stations := make([]*bluetooth.BaseStation, 0, len(m.stations))
+	for _, stationPtr := range m.stations {
+		if stationPtr == nil {
+			continue
+		}
+		if m.config.GetStationSettings(stationPtr.ID()).Unmanaged {
+			log.Printf("Manager: Leaving out %s, it isn't managed", stationPtr.Name)
+			continue
+		}
+		stations = append(stations, stationPtr)
+	}
+	return stations
+}
@@ -1,44 +1,189 @@
 package station
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
 	"lhcontrol/internal/config"
+	"lhcontrol/pkg/apiclient"
 )
 
-// StationInfo is a simplified representation of a BaseStation for the frontend.
-type StationInfo struct {
-	Name         string `json:"name"`
-	OriginalName string `json:"originalName"`
-	Address      string `json:"address"`
-	PowerState   int    `json:"powerState"`
+// StationInfo is a simplified representation of a BaseStation for the frontend
+// and the API. It is defined in the API client package so both sides share it.
+type StationInfo = apiclient.StationInfo
+
+var (
+	// ErrStationNotFound is returned for commands to an address that no scan has found.
+	ErrStationNotFound = errors.New("station not found")
+	// ErrScanInProgress is returned when a scan is requested while one is running.
+	ErrScanInProgress = errors.New("scan already in progress")
+)
+
+// StationReaddressedEvent reports a known station that reappeared under a new
+// address, e.g. after a Bluetooth reset on macOS.
+type StationReaddressedEvent struct {
+	ID         string `json:"id"`
+	OldAddress string `json:"oldAddress"`
+	NewAddress string `json:"newAddress"`
 }
 
+// OriginLocal marks stations reached through this machine's own Bluetooth adapter.
+const OriginLocal = "local"
+
+// commandTimeout bounds a single power command including its connect, discovery and write retries.
+const commandTimeout = 20 * time.Second
+
+// linkDropDelay gives the adapter time to tear down links after disconnecting before a scan.
+const linkDropDelay = 1 * time.Second
+
+// adapterSettleTimeout caps how long a scan waits for earlier adapter operations to finish.
+const adapterSettleTimeout = 3 * time.Second
+
 type Manager struct {
 	stations      map[string]*bluetooth.BaseStation
 	stationsMutex sync.RWMutex
 	config        *config.Config
 	isScanning    bool
+	scanDone      chan struct{} // Closed when the running scan ends, guarded by stationsMutex
+	emit          func(eventName string, data ...interface{})
+	onEmptyScan   func(openConnections int)
+	clock         clock.Clock
+
+	readinessMutex sync.Mutex
+	readiness      *readinessWatch
+
+	adapterMutex sync.Mutex
+	adapterState string
+	adapterErr   error
+	adapterDone  chan struct{} // Closed once initialization succeeded or failed
+	probing      atomic.Bool
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	// ctx lives as long as the manager and is canceled by Shutdown. Work
+	// that isn't tied to one caller, like prewarming, runs under it.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	prewarming atomic.Bool
+	deltas     deltaTracker
+
+	missedScans map[string]int // Scans in a row that didn't see a station, by address, guarded by stationsMutex
+
+	bootingMutex sync.Mutex
+	booting      map[string]bool // Addresses of the booting stations being polled
+
+	reconnectMutex sync.Mutex
+	reconnecting   map[string]bool // Addresses with a reconnect in progress
+
+	queuesMutex sync.Mutex
+	queues      map[string]*commandQueue
+
+	verifyMutex     sync.Mutex
+	verifications   map[string]*verification
+	onStateReverted func(StateRevertedEvent)
+	onCommand       func(CommandRecord)
+	errorCode       func(error) string
+
+	statusMutex    sync.Mutex
+	statusInflight *statusCheck // Check running right now, joined by concurrent callers
+	statusLast     *statusCheck // Last completed check, reused within the cache window
+
+	selfTesting   atomic.Bool
+	selfTestMutex sync.Mutex
+	lastSelfTest  *SelfTestReport
+
+	conflictsMutex sync.Mutex
+	conflicts      []ChannelConflict // Found by the last CheckChannelConflicts
+
+	pollMutex  sync.Mutex
+	pollCancel context.CancelFunc // Stops the running poller, nil if there is none
+	pollDone   chan struct{}      // Closed when the running poller returned
 }
 
-func NewManager(cfg *config.Config) *Manager {
+// NewManager creates a manager that talks to stations through backend, or
+// through the active one if it is nil. There is one backend per process, so
+// it replaces whatever bluetooth.SetBackend installed before.
+func NewManager(cfg *config.Config, backend bluetooth.Backend) *Manager {
+	if backend != nil {
+		bluetooth.SetBackend(backend)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Manager{
-		stations: make(map[string]*bluetooth.BaseStation),
-		config:   cfg,
+		ctx:             ctx,
+		cancel:          cancel,
+		stations:        make(map[string]*bluetooth.BaseStation),
+		missedScans:     make(map[string]int),
+		queues:          make(map[string]*commandQueue),
+		verifications:   make(map[string]*verification),
+		reconnecting:    make(map[string]bool),
+		booting:         make(map[string]bool),
+		onStateReverted: func(StateRevertedEvent) {},
+		onCommand:       func(CommandRecord) {},
+		errorCode:       func(error) string { return "" },
+		adapterState:    AdapterInitializing,
+		adapterDone:     make(chan struct{}),
+		stopChan:        make(chan struct{}),
+		config:          cfg,
+		emit:            func(string, ...interface{}) {},
+		onEmptyScan:     func(int) {},
+		clock:           clock.Real,
 	}
 }
 
-// Initialize should be called at app startup
-func (m *Manager) Initialize() error {
-	return bluetooth.Initialize()
+// SetClock replaces the clock used for waits and timeouts, here and in the
+// bluetooth layer. Intended for tests.
+func (m *Manager) SetClock(c clock.Clock) {
+	m.clock = c
+	bluetooth.SetClock(c)
+}
+
+// SetEventEmitter sets the function used to publish manager events to the frontend.
+func (m *Manager) SetEventEmitter(emit func(eventName string, data ...interface{})) {
+	m.emit = emit
+}
+
+// SetEmptyScanHandler sets the function called when a scan found nothing while
+// connections were open, which usually means the adapter can't scan and hold
+// connections at the same time.
+func (m *Manager) SetEmptyScanHandler(handler func(openConnections int)) {
+	m.onEmptyScan = handler
+}
+
+// SetStateRevertedHandler sets the function called when state verification finds
+// a station switched back after a command.
+func (m *Manager) SetStateRevertedHandler(handler func(StateRevertedEvent)) {
+	m.onStateReverted = handler
+}
+
+// SetCommandHandler sets the function called after every power command with
+// its outcome. It runs on the command path and must not block.
+func (m *Manager) SetCommandHandler(handler func(CommandRecord)) {
+	m.onCommand = handler
+}
+
+// SetErrorCoder sets the function that turns a failed command's error into
+// the code recorded in its RestoreResult. The codes live with the user
+// messages, which depend on this package.
+func (m *Manager) SetErrorCoder(code func(error) string) {
+	m.errorCode = code
 }
 
 // GetStationInfo returns the current state of the stations map.
 func (m *Manager) GetStationInfo() []StationInfo {
+	// Stations can't be reached while the adapter is unusable. Read before
+	// taking the list lock, so a slow adapter operation never holds it up.
+	adapterState := m.AdapterStatus().State
+	degraded := adapterState == AdapterDisabled || adapterState == AdapterMissing || adapterState == AdapterFailed
+	onChannel := m.channelCountsLocked()
+
 	m.stationsMutex.RLock()
 	defer m.stationsMutex.RUnlock()
 
@@ -46,93 +191,238 @@ func (m *Manager) GetStationInfo() []StationInfo {
 	for _, stationPtr := range m.stations {
 		if stationPtr != nil {
 			var name string
-			if renamedName, ok := m.config.RenamedStations[stationPtr.Name]; ok {
+			if renamedName, ok := m.config.StationName(stationPtr.Name); ok {
 				name = renamedName
 			} else {
 				name = stationPtr.Name
 			}
+			id := stationPtr.ID()
+			settings := m.config.GetStationSettings(id)
+			deviceInfo, _ := stationPtr.DeviceInfo()
+			lastUpdate := stationPtr.LastUpdate()
 			stationInfos = append(stationInfos, StationInfo{
+				ID:           id,
 				Name:         name,
 				OriginalName: stationPtr.Name,
 				Address:      stationPtr.Address.String(),
 				PowerState:   stationPtr.GetPowerState(),
+				Ready:        stationPtr.IsReady(),
+				Connected:    stationPtr.IsConnected(),
+				Generation:   int(stationPtr.Protocol),
+				Channel:      stationPtr.Channel(),
+				SerialNumber: deviceInfo.SerialNumber,
+				ModelNumber:  deviceInfo.ModelNumber,
+				RSSI:         int(stationPtr.RSSI),
+				FirstSeen:    stationPtr.FirstSeen,
+				LastSeen:     stationPtr.LastSeen,
+				Origin:       OriginLocal,
+				Essential:    settings.Essential,
+				Degraded:     degraded,
+				Reconnecting: m.isReconnecting(stationPtr.Address.String()),
+				NoReconnect:  settings.NoReconnect,
+				Unmanaged:    settings.Unmanaged,
+
+				LastStateUpdate: lastUpdate,
+				Stale:           lastUpdate.IsZero() || m.clock.Now().Sub(lastUpdate) > apiclient.StaleStateAfter,
+				ChannelConflict: stationPtr.Channel() != bluetooth.ChannelUnknown && onChannel[stationPtr.Channel()] > 1,
+				Health:          stationPtr.Health(),
+				Group:           m.config.StationGroup(id),
 			})
 		}
 	}
 	return stationInfos
 }
 
-func (m *Manager) ScanAndFetchStations() ([]StationInfo, error) {
-	m.stationsMutex.Lock()
-	if m.isScanning {
-		m.stationsMutex.Unlock()
-		return m.GetStationInfo(), fmt.Errorf("scan already in progress")
+// LastSeen returns when each station's power state was last read, by address,
+// like StationInfo.LastStateUpdate.
+func (m *Manager) LastSeen() map[string]time.Time {
+	m.stationsMutex.RLock()
+	defer m.stationsMutex.RUnlock()
+	seen := make(map[string]time.Time, len(m.stations))
+	for address, stationPtr := range m.stations {
+		if last := stationPtr.LastUpdate(); !last.IsZero() {
+			seen[address] = last
+		}
 	}
-	m.isScanning = true
-	m.stationsMutex.Unlock()
+	return seen
+}
 
-	defer func() {
-		m.stationsMutex.Lock()
-		m.isScanning = false
-		m.stationsMutex.Unlock()
-	}()
+// ScanAndFetchStations scans for stations and reads the state of those that
+// aren't connected. Canceling ctx stops the scan and the state reads early.
+func (m *Manager) ScanAndFetchStations(ctx context.Context) ([]StationInfo, error) {
+	if !m.beginScan() {
+		return m.GetStationInfo(), ErrScanInProgress
+	}
+	defer m.endScan()
+
+	if err := m.waitAdapter(ctx); err != nil {
+		return m.GetStationInfo(), err
+	}
 
 	scanDuration := 5 * time.Second
 	fetchWaitDuration := 7 * time.Second
 
-	// Using time.Sleep inside a method is generally not ideal for testing,
-	// but preserving original logic for now.
-	time.Sleep(1 * time.Second)
+	// Starting a scan while a previous scan is still stopping or a connect is
+	// in flight fails on some Windows adapters, so wait for them to finish.
+	select {
+	case <-bluetooth.AdapterIdle():
+	case <-m.clock.After(adapterSettleTimeout):
+		log.Println("Warning: Bluetooth adapter still busy, scanning anyway.")
+	case <-ctx.Done():
+		return m.GetStationInfo(), ctx.Err()
+	}
+
+	openConnections := bluetooth.ConnectedCount()
+	disconnected := openConnections > 0 && m.config.GetConnectionSettings().DisconnectBeforeScan
+	if disconnected {
+		log.Printf("Manager: Disconnecting %d station(s) before scanning", openConnections)
+		bluetooth.DisconnectAllStations()
+		if err := clock.Sleep(ctx, m.clock, linkDropDelay); err != nil {
+			go m.Prewarm()
+			return m.GetStationInfo(), err
+		}
+	}
 
-	discoveredValues, err := bluetooth.ScanForDuration(scanDuration)
+	discovered, err := bluetooth.ScanForDuration(ctx, scanDuration, m.scanFilter())
+	if errors.Is(err, bluetooth.ErrNoAdapter) {
+		m.adapterLost(err)
+	} else if errors.Is(err, bluetooth.ErrAdapterDisabled) && m.AdapterStatus().State == AdapterReady {
+		m.adapterDisabled(err)
+	}
 	if err != nil {
+		if disconnected {
+			go m.Prewarm()
+		}
 		return m.GetStationInfo(), fmt.Errorf("bluetooth scan failed: %w", err)
 	}
+	if len(discovered) == 0 && openConnections > 0 && !disconnected {
+		log.Printf("Warning: Scan found no stations while %d connection(s) were open.", openConnections)
+		m.onEmptyScan(openConnections)
+	}
+	discovered = m.withConnectedStations(discovered)
 
 	stationsToFetch := make([]*bluetooth.BaseStation, 0)
+	fetching := make(map[*bluetooth.BaseStation]bool)
+	var readdressed []StationReaddressedEvent
 	m.stationsMutex.Lock()
-	for _, currentScanStation := range discoveredValues {
+	for _, currentScanStation := range discovered {
 		addrStr := currentScanStation.Address.String()
 		if existingStation, found := m.stations[addrStr]; found {
 			if existingStation.Name != currentScanStation.Name {
 				existingStation.Name = currentScanStation.Name
+				existingStation.Protocol = currentScanStation.Protocol
 			}
-			if !existingStation.IsConnected() {
+			existingStation.RSSI = currentScanStation.RSSI
+			existingStation.LastSeen = currentScanStation.LastSeen
+			if existingStation.FirstSeen.IsZero() {
+				existingStation.FirstSeen = currentScanStation.FirstSeen
+			}
+			if !existingStation.IsConnected() && !disconnected {
 				stationsToFetch = append(stationsToFetch, existingStation)
+				fetching[existingStation] = true
 			}
 		} else {
-			newStationPtr := new(bluetooth.BaseStation)
-			*newStationPtr = currentScanStation
+			newStationPtr := currentScanStation
+			if previous := m.findByIDLocked(newStationPtr.ID()); previous != nil {
+				// Same station under a new address, replace the runtime entry
+				// instead of listing it twice. The old handle is stale.
+				oldAddress := previous.Address.String()
+				if !previous.FirstSeen.IsZero() {
+					newStationPtr.FirstSeen = previous.FirstSeen
+				}
+				delete(m.stations, oldAddress)
+				delete(m.missedScans, oldAddress)
+				go bluetooth.DisconnectStation(previous)
+				readdressed = append(readdressed, StationReaddressedEvent{ID: newStationPtr.ID(), OldAddress: oldAddress, NewAddress: addrStr})
+			}
 			m.stations[addrStr] = newStationPtr
 			stationsToFetch = append(stationsToFetch, newStationPtr)
+			fetching[newStationPtr] = true
+		}
+	}
+	m.countMissedScansLocked(discovered)
+	if disconnected {
+		// Reconnect known stations even if they didn't advertise during this scan
+		for _, stationPtr := range m.stations {
+			if !fetching[stationPtr] {
+				stationsToFetch = append(stationsToFetch, stationPtr)
+			}
 		}
 	}
 	m.stationsMutex.Unlock()
 
+	for _, event := range readdressed {
+		log.Printf("Manager: Station %s moved from %s to %s", event.ID, event.OldAddress, event.NewAddress)
+		m.emit("station-readdressed", event)
+	}
+
 	if len(stationsToFetch) > 0 {
+		ctx, cancel := clock.WithTimeout(ctx, m.clock, fetchWaitDuration)
+		defer cancel()
+
 		var wg sync.WaitGroup
 		for _, stationToFetch := range stationsToFetch {
 			wg.Add(1)
 			go func(ptr *bluetooth.BaseStation) {
 				defer wg.Done()
-				_ = bluetooth.FetchInitialPowerState(ptr)
+				_ = bluetooth.FetchInitialPowerState(ctx, ptr)
 			}(stationToFetch)
 		}
 
-		waitChan := make(chan struct{})
-		go func() {
-			wg.Wait()
-			close(waitChan)
-		}()
-
-		select {
-		case <-waitChan:
-		case <-time.After(fetchWaitDuration):
+		if !waitContext(ctx, &wg) {
 			log.Println("Warning: Timed out waiting for state fetch routines.")
 		}
 	}
 
-	return m.GetStationInfo(), nil
+	m.rememberStations(discovered)
+	if missed := m.config.GetConnectionSettings().PruneAfterMissedScans; missed > 0 {
+		m.pruneMissed(missed)
+	}
+
+	stations := m.GetStationInfo()
+	m.publishStations("scan-completed", stations)
+	if m.config.GetConnectionSettings().Prewarm {
+		go m.Prewarm()
+	}
+	return stations, nil
+}
+
+// waitContext waits for wg until ctx is done and reports whether all workers finished.
+// The workers must observe the same ctx: once it is done they stop at their next
+// step boundary, so the helper goroutine below exits shortly after instead of
+// piling up behind stations that stopped responding.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// beginScan marks a scan as running, unless one already is.
+func (m *Manager) beginScan() bool {
+	m.stationsMutex.Lock()
+	defer m.stationsMutex.Unlock()
+	if m.isScanning {
+		return false
+	}
+	m.isScanning = true
+	m.scanDone = make(chan struct{})
+	return true
+}
+
+// endScan marks the running scan as over and releases the commands waiting for it.
+func (m *Manager) endScan() {
+	m.stationsMutex.Lock()
+	defer m.stationsMutex.Unlock()
+	m.isScanning = false
+	close(m.scanDone)
 }
 
 func (m *Manager) IsScanning() bool {
@@ -141,8 +431,31 @@ func (m *Manager) IsScanning() bool {
 	return m.isScanning
 }
 
-func (m *Manager) CheckAllStationStatuses() ([]StationInfo, error) {
+// WaitScan blocks until no scan runs, or until ctx is done.
+func (m *Manager) WaitScan(ctx context.Context) error {
+	for {
+		m.stationsMutex.RLock()
+		scanning, done := m.isScanning, m.scanDone
+		m.stationsMutex.RUnlock()
+		if !scanning {
+			return nil
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkAllStationStatuses reads the power state of every station, connecting
+// to those that aren't connected. See CheckAllStationStatuses.
+func (m *Manager) checkAllStationStatuses(ctx context.Context) ([]StationInfo, error) {
 	statusCheckTimeout := 4 * time.Second
+	if bluetooth.DisconnectAfterCommand() {
+		// No station is kept connected, every read connects first
+		statusCheckTimeout = 10 * time.Second
+	}
 
 	stationsToRead := make([]*bluetooth.BaseStation, 0)
 	stationsToFetch := make([]*bluetooth.BaseStation, 0)
@@ -163,6 +476,12 @@ func (m *Manager) CheckAllStationStatuses() ([]StationInfo, error) {
 	if len(stationsToRead) == 0 && len(stationsToFetch) == 0 {
 		return m.GetStationInfo(), nil
 	}
+	if err := m.waitAdapter(ctx); err != nil {
+		return m.GetStationInfo(), err
+	}
+
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, statusCheckTimeout)
+	defer cancel()
 
 	var wg sync.WaitGroup
 
@@ -170,7 +489,13 @@ func (m *Manager) CheckAllStationStatuses() ([]StationInfo, error) {
 		wg.Add(1)
 		go func(ptr *bluetooth.BaseStation) {
 			defer wg.Done()
-			_ = bluetooth.ReadPowerState(ptr)
+			// Notified state is current, unless a notification may have been missed
+			if ptr.Notifying() && m.clock.Now().Sub(ptr.LastUpdate()) < bluetooth.NotificationStaleAfter {
+				return
+			}
+			if bluetooth.ReadPowerState(ctx, ptr) == nil {
+				_ = bluetooth.ReadChannel(ctx, ptr)
+			}
 		}(stationToRead)
 	}
 
@@ -178,126 +503,177 @@ func (m *Manager) CheckAllStationStatuses() ([]StationInfo, error) {
 		wg.Add(1)
 		go func(ptr *bluetooth.BaseStation) {
 			defer wg.Done()
-			_ = bluetooth.FetchInitialPowerState(ptr)
+			_ = bluetooth.FetchInitialPowerState(ctx, ptr)
 		}(stationToFetch)
 	}
 
-	waitChan := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(waitChan)
-	}()
-
-	select {
-	case <-waitChan:
-	case <-time.After(statusCheckTimeout):
+	if !waitContext(ctx, &wg) {
 		log.Println("Warning: Timed out waiting for status check routines.")
 	}
 
-	return m.GetStationInfo(), nil
+	m.CheckChannelConflicts()
+	stations := m.GetStationInfo()
+	// Checks run every few seconds, only tell listeners about actual changes
+	m.publishChanges("stations-updated", stations)
+	return stations, nil
 }
 
-func (m *Manager) PowerOnStation(address string) error {
-	m.stationsMutex.RLock()
-	stationPtr, ok := m.stations[address]
-	m.stationsMutex.RUnlock()
-
-	if !ok || stationPtr == nil {
-		return fmt.Errorf("station with address %s not found", address)
-	}
-	return bluetooth.PowerOn(stationPtr)
+// onPowerNotification publishes a power state a station pushed. It is called
+// on the BLE stack's goroutine, so the events are sent from another one.
+func (m *Manager) onPowerNotification(station *bluetooth.BaseStation) {
+	go func() {
+		address := station.Address.String()
+		stations := m.GetStationInfo()
+		for _, info := range stations {
+			if info.Address == address {
+				m.emit("station-state-changed", info)
+				break
+			}
+		}
+		m.publishDelta(stations)
+	}()
 }
 
-func (m *Manager) PowerOffStation(address string) error {
+// lookup returns the station with the given address, in any format
+// NormalizeAddress accepts, or ID, or nil.
+func (m *Manager) lookup(key string) *bluetooth.BaseStation {
 	m.stationsMutex.RLock()
-	stationPtr, ok := m.stations[address]
-	m.stationsMutex.RUnlock()
-
-	if !ok || stationPtr == nil {
-		return fmt.Errorf("station with address %s not found", address)
+	defer m.stationsMutex.RUnlock()
+	if stationPtr, ok := m.stations[key]; ok {
+		return stationPtr
 	}
-	return bluetooth.PowerOff(stationPtr)
+	// Addresses typed by users may differ in case and separators
+	if address, err := bluetooth.NormalizeAddress(key); err == nil {
+		if stationPtr, ok := m.stations[address.String()]; ok {
+			return stationPtr
+		}
+	}
+	return m.findByIDLocked(key)
 }
 
-func (m *Manager) PowerOnAllStations() error {
-	m.stationsMutex.RLock()
-	stationsToToggle := make([]*bluetooth.BaseStation, 0, len(m.stations))
+// findByIDLocked returns the station with the given ID, or nil. Assumes the
+// caller holds stationsMutex.
+func (m *Manager) findByIDLocked(id string) *bluetooth.BaseStation {
 	for _, stationPtr := range m.stations {
-		if stationPtr != nil {
-			stationsToToggle = append(stationsToToggle, stationPtr)
+		if stationPtr != nil && stationPtr.ID() == id {
+			return stationPtr
 		}
 	}
-	m.stationsMutex.RUnlock()
-
-	var wg sync.WaitGroup
-	errors := make(map[string]error)
-	var errorMutex sync.Mutex
+	return nil
+}
 
-	for _, stationPtr := range stationsToToggle {
-		wg.Add(1)
-		go func(s *bluetooth.BaseStation) {
-			defer wg.Done()
-			err := bluetooth.PowerOn(s)
-			if err != nil {
-				errorMutex.Lock()
-				errors[s.Address.String()] = err
-				errorMutex.Unlock()
-			}
-		}(stationPtr)
+// StationID returns the stable ID of the station with the given address or
+// ID, for keys that must outlive address changes.
+func (m *Manager) StationID(key string) (string, bool) {
+	stationPtr := m.lookup(key)
+	if stationPtr == nil {
+		return "", false
 	}
+	return stationPtr.ID(), true
+}
 
-	wg.Wait()
-
-	if len(errors) > 0 {
-		return fmt.Errorf("encountered %d error(s) during PowerOnAllStations", len(errors))
+func (m *Manager) PowerOnStation(ctx context.Context, address string, src Source) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
 	}
-	return nil
+	return m.runCommand(ctx, stationPtr, bluetooth.PowerStateOn, src)
 }
 
-func (m *Manager) PowerOffAllStations() error {
-	m.stationsMutex.RLock()
-	stationsToToggle := make([]*bluetooth.BaseStation, 0, len(m.stations))
-	for _, stationPtr := range m.stations {
-		if stationPtr != nil {
-			stationsToToggle = append(stationsToToggle, stationPtr)
-		}
+func (m *Manager) PowerOffStation(ctx context.Context, address string, src Source) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
 	}
-	m.stationsMutex.RUnlock()
-
-	var wg sync.WaitGroup
-	errors := make(map[string]error)
-	var errorMutex sync.Mutex
+	return m.runCommand(ctx, stationPtr, bluetooth.PowerStateOff, src)
+}
 
-	for _, stationPtr := range stationsToToggle {
-		wg.Add(1)
-		go func(s *bluetooth.BaseStation) {
-			defer wg.Done()
-			err := bluetooth.PowerOff(s)
-			if err != nil {
-				errorMutex.Lock()
-				errors[s.Address.String()] = err
-				errorMutex.Unlock()
-			}
-		}(stationPtr)
+// StandbyStation puts a station into standby. SteamVR 1.0 stations have no
+// standby and fail.
+func (m *Manager) StandbyStation(ctx context.Context, address string, src Source) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
 	}
+	return m.runCommand(ctx, stationPtr, bluetooth.PowerStateStandby, src)
+}
+
+// PowerOnAllStations turns on every managed local station and returns what
+// happened to each of them, after the second pass. If any failed, the error
+// is a *BulkError that joins their errors, so errors.Is sees each of them.
+func (m *Manager) PowerOnAllStations(ctx context.Context, src Source) ([]StationOperationResult, error) {
+	results := m.powerAll(ctx, bluetooth.PowerStateOn, src)
+	return operationResults(results), bulkError("PowerOnAllStations", results)
+}
 
-	wg.Wait()
+// PowerOffAllStations turns off every local station, like PowerOnAllStations.
+func (m *Manager) PowerOffAllStations(ctx context.Context, src Source) ([]StationOperationResult, error) {
+	results := m.powerAll(ctx, bluetooth.PowerStateOff, src)
+	return operationResults(results), bulkError("PowerOffAllStations", results)
+}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("encountered %d error(s) during PowerOffAllStations", len(errors))
-	}
-	return nil
+// StandbyAllStations puts every local station into standby, like
+// PowerOnAllStations.
+func (m *Manager) StandbyAllStations(ctx context.Context, src Source) ([]StationOperationResult, error) {
+	results := m.powerAll(ctx, bluetooth.PowerStateStandby, src)
+	return operationResults(results), bulkError("StandbyAllStations", results)
 }
 
 func (m *Manager) RenameStation(originalName string, newName string) error {
-	if newName == "" {
-		delete(m.config.RenamedStations, originalName)
-	} else {
-		m.config.RenamedStations[originalName] = newName
-	}
+	m.config.SetStationName(originalName, newName)
 	return m.config.Save()
 }
 
+// Shutdown stops the poller, cancels the operations still running, stops
+// probing for the adapter, ends the readiness watch and disconnects all
+// stations. A canceled scan stops
+// the adapter scan right away; Shutdown waits up to adapterSettleTimeout for
+// it to wind down, so no connect of its fetch races the disconnects.
 func (m *Manager) Shutdown() {
+	m.StopPolling()
+	m.cancel()
+	m.stopOnce.Do(func() { close(m.stopChan) })
+
+	m.stationsMutex.RLock()
+	scanning, done := m.isScanning, m.scanDone
+	m.stationsMutex.RUnlock()
+	if scanning {
+		log.Println("Manager: Waiting for the canceled scan to stop...")
+		select {
+		case <-done:
+		case <-m.clock.After(adapterSettleTimeout):
+			log.Println("Manager: Scan still running, disconnecting anyway.")
+		}
+	}
+	m.stopReadiness()
 	bluetooth.DisconnectAllStations()
 }
+
+// IdentifyStation makes a station's status LED blink, see bluetooth.Identify.
+func (m *Manager) IdentifyStation(ctx context.Context, address string) error {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+	if err := m.waitAdapter(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+	defer cancel()
+	return bluetooth.Identify(ctx, stationPtr)
+}
+
+// WriteRawCharacteristic writes a raw payload to a station characteristic, see
+// bluetooth.WriteRawCharacteristic. Callers must gate it behind debug mode.
+func (m *Manager) WriteRawCharacteristic(ctx context.Context, address, serviceUUID, charUUID string, payload []byte, withResponse bool) (bluetooth.RawWriteResult, error) {
+	stationPtr := m.lookup(address)
+	if stationPtr == nil {
+		return bluetooth.RawWriteResult{}, fmt.Errorf("%w: %s", ErrStationNotFound, address)
+	}
+	if err := m.waitAdapter(ctx); err != nil {
+		return bluetooth.RawWriteResult{}, err
+	}
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+	defer cancel()
+	return bluetooth.WriteRawCharacteristic(ctx, stationPtr, serviceUUID, charUUID, payload, withResponse)
+}
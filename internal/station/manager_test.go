@@ -0,0 +1,245 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/internal/config"
+)
+
+// fakeStation returns a V2 station of the fake backend that starts asleep.
+func fakeStation(n int) bluetooth.FakeStation {
+	return bluetooth.FakeStation{
+		Address:  bluetooth.Address(fmt.Sprintf("02:00:00:00:00:%02X", n)),
+		Name:     fmt.Sprintf("LHB-0000%04X", n),
+		RSSI:     -50,
+		RawPower: bluetooth.RawPowerStateSleep,
+		Channel:  byte(n),
+	}
+}
+
+// newTestManager returns a manager with an initialized adapter that talks to
+// a fake backend with the given stations. They are known from the config, so
+// they are listed without a scan.
+func newTestManager(t *testing.T, stations ...bluetooth.FakeStation) (*Manager, *bluetooth.FakeBackend) {
+	t.Helper()
+	fake := bluetooth.NewFakeBackend(stations...)
+	m := newUninitializedManager(t, fake, stations...)
+	m.InitializeAsync()
+	if err := m.waitAdapter(context.Background()); err != nil {
+		t.Fatalf("adapter didn't initialize: %v", err)
+	}
+	return m, fake
+}
+
+// newUninitializedManager returns a manager that talks to backend and knows
+// the given stations, before InitializeAsync. The config is saved to a
+// temporary directory, and the manager is shut down when the test ends.
+func newUninitializedManager(t *testing.T, backend bluetooth.Backend, stations ...bluetooth.FakeStation) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(env, dir)
+	}
+
+	cfg := config.NewConfig()
+	known := make([]config.KnownStation, 0, len(stations))
+	for _, s := range stations {
+		known = append(known, config.KnownStation{Address: s.Address.String(), OriginalName: s.Name})
+	}
+	if err := cfg.SetKnownStations(known); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := bluetooth.ActiveBackend()
+	m := NewManager(cfg, backend)
+	t.Cleanup(func() {
+		m.Shutdown()
+		bluetooth.SetBackend(previous)
+	})
+	m.SeedStations()
+	return m
+}
+
+// useFakeClock makes a fake clock drive both m and the bluetooth package for
+// the test, so scan windows and retry delays take no real time.
+func useFakeClock(t *testing.T, m *Manager) *clock.Fake {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	bluetooth.SetClock(fake)
+	m.SetClock(fake)
+	t.Cleanup(func() { bluetooth.SetClock(clock.Real) })
+	return fake
+}
+
+// newFakeClockManager is newTestManager with a fake clock, see useFakeClock.
+// The adapter initializes once the clock is advanced, e.g. by runAdvancing.
+func newFakeClockManager(t *testing.T, stations ...bluetooth.FakeStation) (*Manager, *bluetooth.FakeBackend, *clock.Fake) {
+	t.Helper()
+	fake := bluetooth.NewFakeBackend(stations...)
+	m := newUninitializedManager(t, fake, stations...)
+	clk := useFakeClock(t, m)
+	m.InitializeAsync()
+	return m, fake, clk
+}
+
+// runAdvancing runs f while advancing the fake clock, and returns its error.
+func runAdvancing(t *testing.T, fake *clock.Fake, f func() error) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- f() }()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("operation didn't finish")
+		}
+		fake.Advance(100 * time.Millisecond)
+	}
+}
+
+// scanStations scans and fetches the stations, and returns them by address.
+func scanStations(t *testing.T, m *Manager, fake *clock.Fake) map[string]StationInfo {
+	t.Helper()
+	var infos []StationInfo
+	err := runAdvancing(t, fake, func() error {
+		var scanErr error
+		infos, scanErr = m.ScanAndFetchStations(context.Background())
+		return scanErr
+	})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	byAddress := make(map[string]StationInfo, len(infos))
+	for _, info := range infos {
+		byAddress[info.Address] = info
+	}
+	return byAddress
+}
+
+// timeCall fails the test if f takes longer than limit.
+func timeCall(t *testing.T, what string, limit time.Duration, f func()) {
+	t.Helper()
+	start := time.Now()
+	f()
+	if took := time.Since(start); took > limit {
+		t.Fatalf("%s took %v, want less than %v", what, took, limit)
+	}
+}
+
+func TestGetStationInfoWhileCommandingDeadStation(t *testing.T) {
+	dead := fakeStation(1)
+	dead.Unreachable = true
+	dead.Latency = 300 * time.Millisecond
+	m, _ := newTestManager(t, dead, fakeStation(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- m.PowerOnStation(ctx, dead.Address.String(), Source{Kind: SourceUI})
+	}()
+
+	// Connects to the dead station fail slowly and are retried, the list
+	// must not wait for them
+	for i := 0; i < 20; i++ {
+		time.Sleep(25 * time.Millisecond)
+		var infos []StationInfo
+		timeCall(t, "GetStationInfo", 50*time.Millisecond, func() {
+			infos = m.GetStationInfo()
+		})
+		if len(infos) != 2 {
+			t.Fatalf("got %d stations, want 2", len(infos))
+		}
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("command to the dead station finished early: %v", err)
+	default:
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("command to the dead station succeeded")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("command didn't stop after it was canceled")
+	}
+}
+
+// Every step of a command to a slow station takes a while, and the station
+// isn't locked for any of them.
+func TestGetStationInfoDuringSlowCommand(t *testing.T) {
+	slow := fakeStation(1)
+	slow.Latency = 200 * time.Millisecond
+	m, _ := newTestManager(t, slow)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.PowerOnStation(context.Background(), slow.Address.String(), Source{Kind: SourceUI})
+	}()
+
+	polls := 0
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("power on: %v", err)
+			}
+			if polls < 10 {
+				t.Fatalf("command finished after %d polls, want it to be slow", polls)
+			}
+			if state := m.GetStationInfo()[0].PowerState; !bluetooth.IsPoweredOn(state) {
+				t.Errorf("station is in state %d after power on", state)
+			}
+			return
+		case <-time.After(25 * time.Millisecond):
+		}
+		timeCall(t, "GetStationInfo", 50*time.Millisecond, func() {
+			if infos := m.GetStationInfo(); len(infos) != 1 {
+				t.Fatalf("got %d stations, want 1", len(infos))
+			}
+		})
+		polls++
+	}
+}
+
+func TestWaitScan(t *testing.T) {
+	m, _, fake := newFakeClockManager(t, fakeStation(1))
+	if err := m.WaitScan(context.Background()); err != nil {
+		t.Fatalf("wait without a scan: %v", err)
+	}
+
+	scanned := make(chan struct{})
+	go func() {
+		defer close(scanned)
+		_, _ = m.ScanAndFetchStations(context.Background())
+	}()
+	for !m.IsScanning() {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.WaitScan(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("wait that timed out during the scan: got %v, want context.DeadlineExceeded", err)
+	}
+
+	if err := runAdvancing(t, fake, func() error {
+		return m.WaitScan(context.Background())
+	}); err != nil {
+		t.Fatalf("wait for the scan: %v", err)
+	}
+	if m.IsScanning() {
+		t.Error("scan still runs after the wait")
+	}
+	<-scanned
+}
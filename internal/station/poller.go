@@ -0,0 +1,97 @@
+package station
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"lhcontrol/internal/clock"
+)
+
+// pollDisabledRecheck is how often a poller whose interval is 0 looks whether
+// polling was turned back on.
+const pollDisabledRecheck = 15 * time.Second
+
+// StartPolling starts refreshing the station states in the background, every
+// PollingSettings.IntervalSeconds, so the API serves current data while the
+// window is idle. Changes are published as a "stations-updated" event; polls
+// that found nothing new stay silent. allow, if set, is asked before every
+// poll and can keep the radio quiet, like the window's own polling. Starting
+// a running poller does nothing.
+func (m *Manager) StartPolling(allow func() bool) {
+	m.pollMutex.Lock()
+	defer m.pollMutex.Unlock()
+	if m.pollCancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	done := make(chan struct{})
+	m.pollCancel, m.pollDone = cancel, done
+	go m.poll(ctx, done, allow)
+}
+
+// StopPolling stops the poller and waits until a poll it started has let go.
+// The status check itself keeps running for other callers that joined it.
+func (m *Manager) StopPolling() {
+	m.pollMutex.Lock()
+	cancel, done := m.pollCancel, m.pollDone
+	m.pollCancel, m.pollDone = nil, nil
+	m.pollMutex.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (m *Manager) poll(ctx context.Context, done chan struct{}, allow func() bool) {
+	defer close(done)
+	log.Println("Manager: Background polling started.")
+	defer log.Println("Manager: Background polling stopped.")
+	for {
+		// Read every round, so settings changes apply without a restart
+		interval := time.Duration(m.config.GetPollingSettings().IntervalSeconds) * time.Second
+		wait := interval
+		if interval == 0 {
+			wait = pollDisabledRecheck
+		}
+		if err := clock.Sleep(ctx, m.clock, wait); err != nil {
+			return
+		}
+		if interval == 0 || !m.pollDue(interval) {
+			continue
+		}
+		if allow != nil && !allow() {
+			continue
+		}
+		if _, err := m.CheckAllStationStatuses(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Manager: Background status check failed: %v", err)
+		}
+	}
+}
+
+// pollDue reports whether a background poll should read the stations now.
+// It skips rounds while a scan or a power command runs, as both update the
+// states themselves and compete for the radio, while the adapter isn't
+// usable, and when another caller checked within the interval anyway.
+func (m *Manager) pollDue(interval time.Duration) bool {
+	if m.IsScanning() || m.commandsRunning() {
+		return false
+	}
+	if m.AdapterStatus().State != AdapterReady {
+		return false
+	}
+	return m.clock.Now().Sub(m.LastStatusCheck()) >= interval
+}
+
+// commandsRunning reports whether a power command is running on any station.
+func (m *Manager) commandsRunning() bool {
+	m.queuesMutex.Lock()
+	defer m.queuesMutex.Unlock()
+	for _, q := range m.queues {
+		if q.running {
+			return true
+		}
+	}
+	return false
+}
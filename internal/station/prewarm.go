@@ -0,0 +1,95 @@
+package station
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// prewarmTimeout bounds the connect, discovery and read of one station during prewarm.
+const prewarmTimeout = 20 * time.Second
+
+// PrewarmProgress is emitted after each station prewarm attempt.
+type PrewarmProgress struct {
+	Address string `json:"address"`
+	Success bool   `json:"success"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+}
+
+// PrewarmResult is emitted when a prewarm run finishes.
+type PrewarmResult struct {
+	Connected []string `json:"connected"`
+	Failed    []string `json:"failed"`
+}
+
+// Prewarm connects to all known stations that aren't connected yet, so the
+// next power command finds the characteristic handle cached. It runs at most
+// MaxConcurrent connection attempts at once and does nothing if a run is
+// already in progress, or if connections aren't kept, see
+// bluetooth.SetDisconnectAfterCommand.
+func (m *Manager) Prewarm() {
+	if bluetooth.DisconnectAfterCommand() {
+		return
+	}
+	if !m.prewarming.CompareAndSwap(false, true) {
+		return
+	}
+	defer m.prewarming.Store(false)
+
+	m.stationsMutex.RLock()
+	targets := make([]*bluetooth.BaseStation, 0, len(m.stations))
+	for _, stationPtr := range m.stations {
+		if stationPtr != nil && !stationPtr.IsConnected() {
+			targets = append(targets, stationPtr)
+		}
+	}
+	m.stationsMutex.RUnlock()
+	if len(targets) == 0 {
+		return
+	}
+
+	log.Printf("Manager: Prewarming connections to %d station(s)", len(targets))
+	semaphore := make(chan struct{}, m.config.GetConnectionSettings().MaxConcurrent)
+	result := PrewarmResult{Connected: []string{}, Failed: []string{}}
+	var resultMutex sync.Mutex
+	var done atomic.Int32
+
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func(s *bluetooth.BaseStation) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			ctx, cancel := clock.WithTimeout(m.ctx, m.clock, prewarmTimeout)
+			defer cancel()
+			err := bluetooth.FetchInitialPowerState(ctx, s)
+
+			address := s.Address.String()
+			resultMutex.Lock()
+			if err != nil {
+				result.Failed = append(result.Failed, address)
+			} else {
+				result.Connected = append(result.Connected, address)
+			}
+			resultMutex.Unlock()
+
+			m.emit("prewarm-progress", PrewarmProgress{
+				Address: address,
+				Success: err == nil,
+				Done:    int(done.Add(1)),
+				Total:   len(targets),
+			})
+		}(target)
+	}
+	wg.Wait()
+
+	log.Printf("Manager: Prewarm finished, %d connected, %d failed", len(result.Connected), len(result.Failed))
+	m.emit("prewarm-completed", result)
+}
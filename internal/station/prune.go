@@ -0,0 +1,96 @@
+package station
+
+import (
+	"log"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// countMissedScansLocked counts another missed scan for every station the
+// scan didn't see, and resets the count of those it saw. Connected stations
+// stop advertising, so they don't count as missed. Assumes the caller holds
+// stationsMutex for writing.
+func (m *Manager) countMissedScansLocked(seen []*bluetooth.BaseStation) {
+	seenAddresses := make(map[string]bool, len(seen))
+	for _, station := range seen {
+		seenAddresses[station.Address.String()] = true
+	}
+	for address, stationPtr := range m.stations {
+		if seenAddresses[address] || stationPtr.IsConnected() {
+			delete(m.missedScans, address)
+			continue
+		}
+		m.missedScans[address]++
+	}
+}
+
+// pruneMissed removes the stations that the last threshold scans in a row
+// didn't see, also from the known stations.
+func (m *Manager) pruneMissed(threshold int) {
+	m.stationsMutex.Lock()
+	var stale []string
+	for address, missed := range m.missedScans {
+		if missed >= threshold {
+			stale = append(stale, address)
+		}
+	}
+	removed := m.removeStationsLocked(stale)
+	m.stationsMutex.Unlock()
+	addresses := make([]string, 0, len(removed))
+	for _, station := range removed {
+		log.Printf("Manager: Removing %s (%s), not seen in %d scans", station.Name, station.Address, threshold)
+		addresses = append(addresses, station.Address.String())
+	}
+	if m.forgetStations(addresses) {
+		m.saveConfig()
+	}
+}
+
+// PruneStaleStations removes the stations no scan saw within olderThan, also
+// from the known stations, and returns their addresses. Stations that are connected, or that no scan has
+// seen since start, like the known ones listed at startup, are kept.
+func (m *Manager) PruneStaleStations(olderThan time.Duration) []string {
+	cutoff := m.clock.Now().Add(-olderThan)
+	m.stationsMutex.Lock()
+	var stale []string
+	for address, stationPtr := range m.stations {
+		if !stationPtr.LastSeen.IsZero() && stationPtr.LastSeen.Before(cutoff) && !stationPtr.IsConnected() {
+			stale = append(stale, address)
+		}
+	}
+	removed := m.removeStationsLocked(stale)
+	m.stationsMutex.Unlock()
+
+	addresses := make([]string, 0, len(removed))
+	for _, station := range removed {
+		log.Printf("Manager: Removing %s (%s), last seen %s", station.Name, station.Address, station.LastSeen.Format(time.RFC3339))
+		addresses = append(addresses, station.Address.String())
+	}
+	if len(removed) > 0 {
+		if m.forgetStations(addresses) {
+			m.saveConfig()
+		}
+		m.publishStations("stations-updated", m.GetStationInfo())
+	}
+	return addresses
+}
+
+// removeStationsLocked removes the stations with the given addresses and
+// disconnects them in the background, which also drops them from the
+// bluetooth layer's connection tracking. Assumes the caller holds
+// stationsMutex for writing.
+func (m *Manager) removeStationsLocked(addresses []string) []*bluetooth.BaseStation {
+	removed := make([]*bluetooth.BaseStation, 0, len(addresses))
+	for _, address := range addresses {
+		stationPtr, ok := m.stations[address]
+		if !ok {
+			continue
+		}
+		delete(m.stations, address)
+		delete(m.missedScans, address)
+		removed = append(removed, stationPtr)
+		go bluetooth.DisconnectStation(stationPtr)
+	}
+	return removed
+}
@@ -0,0 +1,229 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/internal/config"
+)
+
+// ErrSuperseded is returned for a queued command that was replaced by an
+// opposite command before it started.
+var ErrSuperseded = errors.New("command superseded by a newer command")
+
+// CommandEvent describes a command that was coalesced with or replaced by another one.
+type CommandEvent struct {
+	Address string `json:"address"`
+	Action  string `json:"action"`       // The command that was dropped or replaced
+	By      string `json:"by,omitempty"` // The command that replaced it
+}
+
+// Command sources, recorded with every power command
+const (
+	SourceUI          = "ui"
+	SourceAPI         = "api"
+	SourceSchedule    = "schedule"     // A timer came due
+	SourceAutomation  = "automation"   // A process rule fired
+	SourcePowerSource = "power-source" // The machine switched between AC and battery
+	SourceSafety      = "safety"       // The maximum on-time was exceeded
+	SourceStartup     = "startup"      // The configured startup action
+	SourceShutdown    = "shutdown"     // The configured shutdown action
+	SourceCommand     = "command"      // A command URI from a jump list or notification
+	SourceVerify      = "verify"       // Re-sent by state verification
+)
+
+// Source tells who asked for a power command.
+type Source struct {
+	Kind     string `json:"kind"`               // One of the Source constants
+	ClientIP string `json:"clientIp,omitempty"` // Set for SourceAPI
+}
+
+// CommandRecord is the outcome of a power command, passed to the command handler.
+type CommandRecord struct {
+	ID      string
+	Name    string
+	Address string
+	Action  string // config.ActionOn, config.ActionOff or config.ActionStandby
+	Source  Source
+	Err     error
+}
+
+// command is a power command waiting in a station's queue.
+type command struct {
+	ctx   context.Context // Of the caller that queued it, the command runs under it
+	value int
+	done  chan struct{}
+	err   error
+}
+
+// commandQueue holds the command running on a station and at most one pending
+// command, which is the final intent to apply once the running one finishes.
+type commandQueue struct {
+	running bool
+	pending *command
+}
+
+func actionName(value int) string {
+	switch value {
+	case bluetooth.PowerStateOn:
+		return config.ActionOn
+	case bluetooth.PowerStateStandby:
+		return config.ActionStandby
+	}
+	return config.ActionOff
+}
+
+// runCommand sets a station's power state through its command queue. A command
+// identical to the pending one shares its result. An opposite command replaces
+// the pending one, whose callers get ErrSuperseded. The running command is
+// never interrupted. Every call is reported to the command handler.
+//
+// A caller whose ctx is done stops waiting and gets ctx's error. A pending
+// command runs under the ctx of the caller that queued it, so it fails
+// without touching the station if that caller gave up before it started.
+func (m *Manager) runCommand(ctx context.Context, s *bluetooth.BaseStation, value int, src Source) (err error) {
+	defer func() {
+		m.onCommand(CommandRecord{
+			ID:      s.ID(),
+			Name:    s.Name,
+			Address: s.Address.String(),
+			Action:  actionName(value),
+			Source:  src,
+			Err:     err,
+		})
+	}()
+	if err := m.waitAdapter(ctx); err != nil {
+		return err
+	}
+	address := s.Address.String()
+	id := s.ID() // Queues outlive address changes
+
+	m.queuesMutex.Lock()
+	q, ok := m.queues[id]
+	if !ok {
+		q = &commandQueue{}
+		m.queues[id] = q
+	}
+	if q.running {
+		if pending := q.pending; pending != nil {
+			if pending.value == value {
+				m.queuesMutex.Unlock()
+				log.Printf("Manager: Dropping duplicate %s command for %s", actionName(value), address)
+				m.emit("command-coalesced", CommandEvent{Address: address, Action: actionName(value)})
+				return waitCommand(ctx, pending)
+			}
+			pending.err = ErrSuperseded
+			close(pending.done)
+		}
+		superseded := q.pending
+		cmd := &command{ctx: ctx, value: value, done: make(chan struct{})}
+		q.pending = cmd
+		m.queuesMutex.Unlock()
+
+		if superseded != nil {
+			log.Printf("Manager: Queued %s command for %s superseded by %s", actionName(superseded.value), address, actionName(value))
+			m.emit("command-superseded", CommandEvent{Address: address, Action: actionName(superseded.value), By: actionName(value)})
+		}
+		return waitCommand(ctx, cmd)
+	}
+	q.running = true
+	m.queuesMutex.Unlock()
+
+	err = m.execute(ctx, s, value)
+	go m.drainQueue(s, q)
+	return err
+}
+
+// drainQueue runs the pending commands of a queue until it is empty.
+func (m *Manager) drainQueue(s *bluetooth.BaseStation, q *commandQueue) {
+	for {
+		m.queuesMutex.Lock()
+		next := q.pending
+		if next == nil {
+			q.running = false
+			m.queuesMutex.Unlock()
+			return
+		}
+		q.pending = nil
+		m.queuesMutex.Unlock()
+
+		next.err = m.execute(next.ctx, s, next.value)
+		close(next.done)
+	}
+}
+
+// waitCommand waits for a queued command to finish, or for ctx to be done.
+func waitCommand(ctx context.Context, cmd *command) error {
+	select {
+	case <-cmd.done:
+		return cmd.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitsForScan reports whether a power command issued now would be held
+// until the running scan is over.
+func (m *Manager) WaitsForScan() bool {
+	return m.config.GetConnectionSettings().WaitForScan && m.IsScanning()
+}
+
+// waitForScan holds a command while a scan runs, if enabled in the
+// connection settings. Connecting while the adapter scans usually fails.
+func (m *Manager) waitForScan(ctx context.Context, s *bluetooth.BaseStation, value int) error {
+	if !m.config.GetConnectionSettings().WaitForScan {
+		return nil
+	}
+	event := CommandEvent{Address: s.Address.String(), Action: actionName(value)}
+	waited := false
+	for {
+		m.stationsMutex.RLock()
+		scanning, done := m.isScanning, m.scanDone
+		m.stationsMutex.RUnlock()
+		if !scanning {
+			break
+		}
+		if !waited {
+			log.Printf("Manager: Holding %s command for %s until the scan is over", event.Action, event.Address)
+			m.emit("command-waiting-for-scan", event)
+			waited = true
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if waited {
+		log.Printf("Manager: Scan over, running %s command for %s", event.Action, event.Address)
+		m.emit("command-released-after-scan", event)
+	}
+	return nil
+}
+
+// execute runs a command on the station once any running scan is over. The
+// command timeout starts after the wait.
+func (m *Manager) execute(ctx context.Context, s *bluetooth.BaseStation, value int) error {
+	if err := m.waitForScan(ctx, s, value); err != nil {
+		return err
+	}
+	ctx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+	defer cancel()
+	var err error
+	switch value {
+	case bluetooth.PowerStateOn:
+		err = bluetooth.PowerOn(ctx, s)
+	case bluetooth.PowerStateStandby:
+		err = bluetooth.Standby(ctx, s)
+	default:
+		err = bluetooth.PowerOff(ctx, s)
+	}
+	if err == nil {
+		m.verifyAfterCommand(s, value)
+	}
+	m.publishDelta(m.GetStationInfo())
+	return err
+}
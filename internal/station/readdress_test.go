@@ -0,0 +1,98 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"lhcontrol/internal/bluetooth"
+)
+
+// A known station that comes back under a new address keeps its runtime entry,
+// name and identity instead of being listed twice.
+func TestStationReaddressed(t *testing.T) {
+	s := fakeStation(1)
+	fake := bluetooth.NewFakeBackend(s, fakeStation(2))
+	m := newUninitializedManager(t, fake, s, fakeStation(2))
+	clk := useFakeClock(t, m)
+	var mu sync.Mutex
+	var events []StationReaddressedEvent
+	m.SetEventEmitter(func(eventName string, data ...interface{}) {
+		if eventName != "station-readdressed" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, data[0].(StationReaddressedEvent))
+	})
+	m.InitializeAsync()
+
+	before := scanStations(t, m, clk)[s.Address.String()]
+	if err := m.RenameStation(s.Name, "Front left"); err != nil {
+		t.Fatal(err)
+	}
+
+	const moved = bluetooth.Address("6F1C2B0E-4D8A-4B4E-9F3A-2C7D5E8A1B90")
+	// Close the connection first, so the manager doesn't try to reconnect
+	// to the old address
+	bluetooth.DisconnectAllStations()
+	if err := fake.MoveStation(s.Address, moved); err != nil {
+		t.Fatal(err)
+	}
+	after := scanStations(t, m, clk)
+
+	if len(after) != 2 {
+		t.Fatalf("got %d stations after the move, want 2: %v", len(after), after)
+	}
+	if _, ok := after[s.Address.String()]; ok {
+		t.Errorf("station is still listed under its old address %s", s.Address)
+	}
+	info, ok := after[moved.String()]
+	if !ok {
+		t.Fatalf("station isn't listed under its new address %s", moved)
+	}
+	if info.ID != before.ID {
+		t.Errorf("ID changed from %s to %s", before.ID, info.ID)
+	}
+	if info.Name != "Front left" {
+		t.Errorf("station is named %q after the move, want its custom name", info.Name)
+	}
+	if info.PowerState != bluetooth.PowerStateOff {
+		t.Errorf("station is in state %d after the move, want %d", info.PowerState, bluetooth.PowerStateOff)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := StationReaddressedEvent{ID: before.ID, OldAddress: s.Address.String(), NewAddress: moved.String()}
+	if len(events) != 1 || events[0] != want {
+		t.Errorf("got events %+v, want %+v", events, want)
+	}
+}
+
+// A station that moved can be commanded under its new address.
+func TestStationReaddressedCommand(t *testing.T) {
+	s := fakeStation(1)
+	m, fake, clk := newFakeClockManager(t, s)
+	scanStations(t, m, clk)
+
+	const moved = bluetooth.Address("02:00:00:00:01:01")
+	// Close the connection first, so the manager doesn't try to reconnect
+	// to the old address
+	bluetooth.DisconnectAllStations()
+	if err := fake.MoveStation(s.Address, moved); err != nil {
+		t.Fatal(err)
+	}
+	scanStations(t, m, clk)
+
+	if err := runAdvancing(t, clk, func() error {
+		return m.PowerOnStation(context.Background(), moved.String(), Source{Kind: SourceUI})
+	}); err != nil {
+		t.Fatalf("power on under the new address: %v", err)
+	}
+	if err := runAdvancing(t, clk, func() error {
+		return m.PowerOnStation(context.Background(), s.Address.String(), Source{Kind: SourceUI})
+	}); !errors.Is(err, ErrStationNotFound) {
+		t.Errorf("power on under the old address: got %v, want ErrStationNotFound", err)
+	}
+}
@@ -0,0 +1,126 @@
+package station
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/pkg/apiclient"
+)
+
+const (
+	readinessPollInterval = 2 * time.Second
+	readinessTimeout      = 90 * time.Second
+)
+
+// ReadinessResult describes the outcome of waiting for stations to become
+// tracking-ready. It is shared with the API client.
+type ReadinessResult = apiclient.ReadinessResult
+
+// readinessWatch polls a set of stations after a power-on until they are all running.
+type readinessWatch struct {
+	stations []*bluetooth.BaseStation
+	done     chan struct{}
+	ctx      context.Context // Ends at the readiness timeout or when the watch is replaced
+	cancel   context.CancelFunc
+}
+
+// check returns the current readiness of the watched stations.
+func (w *readinessWatch) check() ReadinessResult {
+	pending := make([]string, 0, len(w.stations))
+	for _, s := range w.stations {
+		if !s.IsReady() {
+			pending = append(pending, s.Address.String())
+		}
+	}
+	return ReadinessResult{Ready: len(pending) == 0, Pending: pending}
+}
+
+// watchReadiness starts polling the given stations in the background,
+// replacing any watch that is still running. The watch ends with the manager,
+// see stopReadiness.
+func (m *Manager) watchReadiness(stations []*bluetooth.BaseStation) {
+	ctx, cancel := clock.WithTimeout(m.ctx, m.clock, readinessTimeout)
+	watch := &readinessWatch{
+		stations: stations,
+		done:     make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	m.readinessMutex.Lock()
+	if m.readiness != nil {
+		m.readiness.cancel()
+	}
+	m.readiness = watch
+	m.readinessMutex.Unlock()
+
+	go func() {
+		defer close(watch.done)
+		defer cancel()
+		for {
+			result := watch.check()
+			if result.Ready {
+				log.Printf("Manager: All %d targeted stations are ready", len(stations))
+				m.emit("all-stations-ready", result)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				if context.Cause(ctx) == context.DeadlineExceeded {
+					log.Printf("Manager: Timed out waiting for %d station(s) to become ready", len(result.Pending))
+					m.emit("stations-ready-timeout", result)
+				}
+				return
+			case <-m.clock.After(readinessPollInterval):
+			}
+
+			for _, s := range stations {
+				if s.IsReady() {
+					continue
+				}
+				if s.IsConnected() {
+					_ = bluetooth.ReadPowerState(ctx, s)
+				} else {
+					_ = bluetooth.FetchInitialPowerState(ctx, s)
+				}
+			}
+		}
+	}()
+}
+
+// stopReadiness cancels the running readiness watch and waits for it to end,
+// so it doesn't reconnect stations after shutdown disconnected them.
+func (m *Manager) stopReadiness() {
+	m.readinessMutex.Lock()
+	watch := m.readiness
+	m.readinessMutex.Unlock()
+	if watch == nil {
+		return
+	}
+	watch.cancel()
+	<-watch.done
+}
+
+// WaitForReadiness blocks until the latest readiness watch finishes, the
+// timeout passes or ctx is done.
+func (m *Manager) WaitForReadiness(ctx context.Context, timeout time.Duration) ReadinessResult {
+	m.readinessMutex.Lock()
+	watch := m.readiness
+	m.readinessMutex.Unlock()
+	if watch == nil {
+		return ReadinessResult{Ready: true, Pending: []string{}}
+	}
+
+	timer := m.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-watch.done:
+	case <-timer.C():
+	case <-ctx.Done():
+	}
+	return watch.check()
+}
@@ -0,0 +1,34 @@
+package station
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// A station that is still spinning up at shutdown isn't polled, and so
+// reconnected, after it.
+func TestShutdownEndsReadinessWatch(t *testing.T) {
+	s := fakeStation(1)
+	s.SpinUp = time.Hour
+	m, _ := newTestManager(t, s)
+	if _, err := m.PowerOnAllStations(context.Background(), Source{Kind: SourceUI}); err != nil {
+		t.Fatalf("power on: %v", err)
+	}
+	m.readinessMutex.Lock()
+	watch := m.readiness
+	m.readinessMutex.Unlock()
+	if watch == nil {
+		t.Fatal("power on started no readiness watch")
+	}
+
+	m.Shutdown()
+	select {
+	case <-watch.done:
+	default:
+		t.Fatal("readiness watch still runs after shutdown")
+	}
+	if m.GetStationInfo()[0].Connected {
+		t.Error("station is connected after shutdown")
+	}
+}
@@ -0,0 +1,171 @@
+package station
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// reconnectBackoff is how long to wait before each reconnect attempt. Later
+// attempts wait as long as the last entry.
+var reconnectBackoff = []time.Duration{5 * time.Second, 15 * time.Second, 60 * time.Second}
+
+// reconnectTimeout bounds one reconnect attempt, connect and read included.
+const reconnectTimeout = 20 * time.Second
+
+// ReconnectEvent is sent with "station-reconnect-failed" once a station
+// dropped its connection and all reconnect attempts failed.
+type ReconnectEvent struct {
+	Address  string `json:"address"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error"`
+}
+
+// onLinkLost sends a "station-disconnected" event with the station, so the
+// window shows it disconnected right away, and starts reconnecting to it
+// unless auto-reconnect is off for it or a reconnect is already running.
+func (m *Manager) onLinkLost(station *bluetooth.BaseStation) {
+	if m.ctx.Err() != nil {
+		return
+	}
+	address := station.Address.String()
+	if !m.reconnectEnabled(address) {
+		go m.publishDisconnected(address)
+		return
+	}
+	attempts := m.config.GetConnectionSettings().ReconnectAttempts
+
+	m.reconnectMutex.Lock()
+	if m.reconnecting[address] {
+		m.reconnectMutex.Unlock()
+		return
+	}
+	m.reconnecting[address] = true
+	m.reconnectMutex.Unlock()
+
+	go func() {
+		defer func() {
+			m.reconnectMutex.Lock()
+			delete(m.reconnecting, address)
+			m.reconnectMutex.Unlock()
+			m.publishDelta(m.GetStationInfo())
+		}()
+		m.publishDisconnected(address)
+		m.reconnect(station, attempts)
+	}()
+}
+
+// publishDisconnected sends the "station-disconnected" event and the changed
+// station list.
+func (m *Manager) publishDisconnected(address string) {
+	stations := m.GetStationInfo()
+	for _, info := range stations {
+		if info.Address == address {
+			m.emit("station-disconnected", info)
+			break
+		}
+	}
+	m.publishDelta(stations)
+}
+
+// reconnect tries to connect to a station up to attempts times, waiting
+// longer before each try. It stops early once something else connected the
+// station, the station was removed or auto-reconnect was turned off, and
+// leaves the station listed when it gives up.
+func (m *Manager) reconnect(station *bluetooth.BaseStation, attempts int) {
+	address := station.Address.String()
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		wait := reconnectBackoff[min(attempt, len(reconnectBackoff)-1)]
+		select {
+		case <-m.clock.After(wait):
+		case <-m.stopChan:
+			return
+		}
+
+		if m.lookup(address) != station || station.IsConnected() || !m.reconnectEnabled(address) {
+			return
+		}
+		if m.AdapterStatus().State != AdapterReady {
+			// Getting the adapter back reconnects all stations anyway
+			return
+		}
+		m.waitScanOver()
+
+		log.Printf("Manager: Reconnecting to %s, attempt %d/%d", station.Name, attempt+1, attempts)
+		ctx, cancel := clock.WithTimeout(m.ctx, m.clock, reconnectTimeout)
+		err = bluetooth.FetchInitialPowerState(ctx, station)
+		cancel()
+		if err == nil {
+			log.Printf("Manager: Reconnected to %s", station.Name)
+			m.onPowerNotification(station)
+			return
+		}
+		if m.ctx.Err() != nil {
+			return
+		}
+		log.Printf("Manager: Reconnect attempt %d/%d for %s failed: %v", attempt+1, attempts, station.Name, err)
+	}
+
+	log.Printf("Manager: Giving up reconnecting to %s after %d attempts", station.Name, attempts)
+	event := ReconnectEvent{Address: address, Attempts: attempts}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	m.emit("station-reconnect-failed", event)
+}
+
+// reconnectEnabled reports whether auto-reconnect is on, globally and for
+// the station. Connections that are released after every command aren't
+// reconnected either.
+func (m *Manager) reconnectEnabled(address string) bool {
+	settings := m.config.GetConnectionSettings()
+	if !settings.AutoReconnect || settings.DisconnectAfterCommand {
+		return false
+	}
+	id, ok := m.StationID(address)
+	return ok && !m.config.GetStationSettings(id).NoReconnect
+}
+
+// waitScanOver blocks until a running scan is over, connecting during a scan
+// fails on many adapters.
+func (m *Manager) waitScanOver() {
+	m.stationsMutex.RLock()
+	scanning, done := m.isScanning, m.scanDone
+	m.stationsMutex.RUnlock()
+	if !scanning {
+		return
+	}
+	select {
+	case <-done:
+	case <-m.stopChan:
+	}
+}
+
+// isReconnecting reports whether a reconnect to the station is pending.
+func (m *Manager) isReconnecting(address string) bool {
+	m.reconnectMutex.Lock()
+	defer m.reconnectMutex.Unlock()
+	return m.reconnecting[address]
+}
+
+// SetAutoReconnect turns auto-reconnect off or back on for a station, by
+// address or ID, and saves the config. Turning it off stops a reconnect in
+// progress before its next attempt.
+func (m *Manager) SetAutoReconnect(key string, enabled bool) error {
+	id, ok := m.StationID(key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStationNotFound, key)
+	}
+	settings := m.config.GetStationSettings(id)
+	settings.NoReconnect = !enabled
+	m.config.SetStationSettings(id, settings)
+	if err := m.config.Save(); err != nil {
+		return err
+	}
+	m.publishStations("stations-updated", m.GetStationInfo())
+	return nil
+}
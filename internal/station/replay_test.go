@@ -0,0 +1,139 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// Stations of the transcripts in testdata.
+const (
+	replayStationA = "F4:6B:2C:91:5E:07"
+	replayStationB = "D8:3A:77:0C:E2:B1"
+)
+
+// newReplayManager returns a manager that replays the named transcript from
+// testdata, found by scanning, driven by a fake clock.
+func newReplayManager(t *testing.T, name string) (*Manager, *clock.Fake) {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	replayer, err := bluetooth.NewReplayer(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newUninitializedManager(t, replayer)
+	fake := useFakeClock(t, m)
+	m.InitializeAsync()
+	return m, fake
+}
+
+// stationState returns the power state the manager reports for address.
+func stationState(t *testing.T, m *Manager, address string) int {
+	t.Helper()
+	for _, info := range m.GetStationInfo() {
+		if info.Address == address {
+			return info.PowerState
+		}
+	}
+	t.Fatalf("station %s isn't listed", address)
+	return 0
+}
+
+func TestReplayTwoStations(t *testing.T) {
+	m, fake := newReplayManager(t, "two_stations.jsonl")
+	stations := scanStations(t, m, fake)
+	if len(stations) != 2 {
+		t.Fatalf("scan found %d stations, want 2", len(stations))
+	}
+	for address, want := range map[string]StationInfo{
+		replayStationA: {Name: "LHB-91A45E07", PowerState: bluetooth.PowerStateOff, Channel: 1},
+		replayStationB: {Name: "LHB-0CE2B15F", PowerState: bluetooth.PowerStateOn, Channel: 2},
+	} {
+		got := stations[address]
+		if got.OriginalName != want.Name || got.PowerState != want.PowerState || got.Channel != want.Channel {
+			t.Errorf("station %s: got %s in state %d on channel %d, want %s in state %d on channel %d",
+				address, got.OriginalName, got.PowerState, got.Channel, want.Name, want.PowerState, want.Channel)
+		}
+	}
+
+	if err := runAdvancing(t, fake, func() error {
+		return m.PowerOnStation(context.Background(), replayStationA, Source{Kind: SourceUI})
+	}); err != nil {
+		t.Fatalf("power on: %v", err)
+	}
+	if state := stationState(t, m, replayStationA); !bluetooth.IsPoweredOn(state) {
+		t.Errorf("station is in state %d after power on", state)
+	}
+	if err := runAdvancing(t, fake, func() error {
+		return m.PowerOffStation(context.Background(), replayStationB, Source{Kind: SourceUI})
+	}); err != nil {
+		t.Fatalf("power off: %v", err)
+	}
+	if state := stationState(t, m, replayStationB); state != bluetooth.PowerStateOff {
+		t.Errorf("station is in state %d after power off", state)
+	}
+}
+
+// Some firmware pads the power characteristic to two bytes.
+func TestReplayPaddedRead(t *testing.T) {
+	m, fake := newReplayManager(t, "padded_read.jsonl")
+	if state := scanStations(t, m, fake)[replayStationA].PowerState; state != bluetooth.PowerStateOn {
+		t.Fatalf("padded read decoded to state %d, want %d", state, bluetooth.PowerStateOn)
+	}
+	if err := runAdvancing(t, fake, func() error {
+		return m.PowerOffStation(context.Background(), replayStationA, Source{Kind: SourceUI})
+	}); err != nil {
+		t.Fatalf("power off: %v", err)
+	}
+	if state := stationState(t, m, replayStationA); state != bluetooth.PowerStateOff {
+		t.Errorf("station is in state %d after power off", state)
+	}
+}
+
+// The first service discovery after connecting finds nothing and is retried.
+func TestReplayDiscoveryRetry(t *testing.T) {
+	m, fake := newReplayManager(t, "discovery_retry.jsonl")
+	if state := scanStations(t, m, fake)[replayStationA].PowerState; state != bluetooth.PowerStateStandby {
+		t.Fatalf("station is in state %d after the retried discovery, want %d", state, bluetooth.PowerStateStandby)
+	}
+	if err := runAdvancing(t, fake, func() error {
+		return m.PowerOnStation(context.Background(), replayStationA, Source{Kind: SourceUI})
+	}); err != nil {
+		t.Fatalf("power on: %v", err)
+	}
+}
+
+// The second station advertises but every connect to it fails: once while
+// fetching its state and twice for the power on.
+func TestReplayUnreachable(t *testing.T) {
+	m, fake := newReplayManager(t, "unreachable.jsonl")
+	stations := scanStations(t, m, fake)
+	if state := stations[replayStationA].PowerState; state != bluetooth.PowerStateOff {
+		t.Errorf("reachable station is in state %d, want %d", state, bluetooth.PowerStateOff)
+	}
+	if state := stations[replayStationB].PowerState; state != bluetooth.PowerStateUnknown {
+		t.Errorf("unreachable station is in state %d, want unknown", state)
+	}
+
+	if err := runAdvancing(t, fake, func() error {
+		return m.PowerOnStation(context.Background(), replayStationA, Source{Kind: SourceUI})
+	}); err != nil {
+		t.Fatalf("power on the reachable station: %v", err)
+	}
+	err := runAdvancing(t, fake, func() error {
+		return m.PowerOnStation(context.Background(), replayStationB, Source{Kind: SourceUI})
+	})
+	if !errors.Is(err, bluetooth.ErrConnect) {
+		t.Errorf("power on the unreachable station: got %v, want a connect failure", err)
+	}
+}
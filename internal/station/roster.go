@@ -0,0 +1,84 @@
+package station
+
+import (
+	"log"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+)
+
+// rememberStations adds the stations a scan found to the known stations, so
+// the next start lists them before scanning, and saves the config if that
+// changed anything. A station that moved to a new address replaces its entry.
+func (m *Manager) rememberStations(found []*bluetooth.BaseStation) {
+	known := m.config.GetKnownStations()
+	byAddress := make(map[string]int, len(known))
+	byID := make(map[string]int, len(known))
+	for i, s := range known {
+		address := knownAddress(s)
+		byAddress[address] = i
+		byID[bluetooth.StationID(s.OriginalName, bluetooth.Address(address))] = i
+	}
+
+	changed := false
+	for _, s := range found {
+		if s.Name == "" {
+			continue
+		}
+		entry := config.KnownStation{Address: s.Address.String(), OriginalName: s.Name}
+		i, ok := byAddress[entry.Address]
+		if !ok {
+			i, ok = byID[s.ID()]
+		}
+		switch {
+		case !ok:
+			byAddress[entry.Address] = len(known)
+			known = append(known, entry)
+			changed = true
+		case known[i] != entry:
+			delete(byAddress, knownAddress(known[i]))
+			byAddress[entry.Address] = i
+			known[i] = entry
+			changed = true
+		}
+	}
+	if changed {
+		m.saveKnownStations(known)
+	}
+}
+
+// forgetStations removes the stations with the given addresses from the known
+// stations and reports whether any was known. The caller saves the config.
+func (m *Manager) forgetStations(addresses []string) bool {
+	forget := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		forget[address] = true
+	}
+	known := m.config.GetKnownStations()
+	kept := make([]config.KnownStation, 0, len(known))
+	for _, s := range known {
+		if !forget[knownAddress(s)] {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == len(known) {
+		return false
+	}
+	// Removing entries can't make the list invalid
+	_ = m.config.SetKnownStations(kept)
+	return true
+}
+
+func (m *Manager) saveKnownStations(known []config.KnownStation) {
+	if err := m.config.SetKnownStations(known); err != nil {
+		log.Printf("Manager: Not saving the known stations: %v", err)
+		return
+	}
+	m.saveConfig()
+}
+
+func (m *Manager) saveConfig() {
+	if err := m.config.Save(); err != nil {
+		log.Printf("Manager: Error saving the known stations: %v", err)
+	}
+}
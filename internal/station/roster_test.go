@@ -0,0 +1,66 @@
+package station
+
+import (
+	"context"
+	"testing"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+)
+
+// loadConfig returns the config saved to the config file.
+func loadConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := config.NewConfig()
+	if err := cfg.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// Stations a scan found are listed at the next start without a scan, until
+// they are forgotten.
+func TestRosterPersisted(t *testing.T) {
+	first, second := fakeStation(1), fakeStation(2)
+	backend := bluetooth.NewFakeBackend(first, second)
+	m := newUninitializedManager(t, backend)
+	fake := useFakeClock(t, m)
+	m.InitializeAsync()
+	if len(m.GetStationInfo()) != 0 {
+		t.Fatal("stations are listed before the first scan")
+	}
+	if found := scanStations(t, m, fake); len(found) != 2 {
+		t.Fatalf("scan found %d stations, want 2", len(found))
+	}
+
+	cfg := loadConfig(t)
+	if known := cfg.GetKnownStations(); len(known) != 2 {
+		t.Fatalf("%d stations were saved, want 2: %+v", len(known), known)
+	}
+	restarted := NewManager(cfg, backend)
+	restarted.SeedStations()
+	infos := restarted.GetStationInfo()
+	if len(infos) != 2 {
+		t.Fatalf("%d stations are listed after a restart, want 2", len(infos))
+	}
+	names := map[string]string{first.Address.String(): first.Name, second.Address.String(): second.Name}
+	for _, info := range infos {
+		if info.PowerState != bluetooth.PowerStateUnknown || info.Connected {
+			t.Errorf("restored station %s is in state %d, connected %v, want an unknown state and no connection",
+				info.Address, info.PowerState, info.Connected)
+		}
+		if want := names[info.Address]; info.OriginalName != want {
+			t.Errorf("restored station %s is named %q, want %q", info.Address, info.OriginalName, want)
+		}
+	}
+
+	if err := runAdvancing(t, fake, func() error {
+		return m.ForgetStation(context.Background(), first.Address.String())
+	}); err != nil {
+		t.Fatalf("forget: %v", err)
+	}
+	known := loadConfig(t).GetKnownStations()
+	if len(known) != 1 || known[0].Address != second.Address.String() {
+		t.Errorf("saved stations after forgetting one are %+v, want only %s", known, second.Address)
+	}
+}
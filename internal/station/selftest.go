@@ -0,0 +1,261 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+)
+
+// Self-test steps, in the order they run
+const (
+	SelfTestAdapter = "adapter"
+	SelfTestScan    = "scan"
+	SelfTestConnect = "connect"
+	SelfTestRead    = "read"
+)
+
+// Self-test step states
+const (
+	StepPending = "pending"
+	StepRunning = "running"
+	StepPassed  = "passed"
+	StepFailed  = "failed"
+	StepSkipped = "skipped" // An earlier step failed
+)
+
+const (
+	// selfTestScanDuration matches the scan of the station list
+	selfTestScanDuration = 5 * time.Second
+	// selfTestCandidates is how many stations the connect step tries when no
+	// address was chosen
+	selfTestCandidates = 3
+)
+
+// ErrSelfTestRunning is returned when a self-test is requested while one runs.
+var ErrSelfTestRunning = errors.New("self-test already running")
+
+// SelfTestStep is the outcome of one self-test step.
+type SelfTestStep struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+	Detail     string `json:"detail,omitempty"` // What the step found, e.g. how many stations a scan saw
+	Error      string `json:"error,omitempty"`
+}
+
+// SelfTestReport is the result of RunSelfTest. It is also published while the
+// test runs, as "selftest-progress" events.
+type SelfTestReport struct {
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt"` // Zero while running
+	Address    string         `json:"address"`    // Station tested, empty if none was reached
+	Station    string         `json:"station"`
+	Passed     bool           `json:"passed"`
+	Steps      []SelfTestStep `json:"steps"`
+}
+
+// RunSelfTest checks, step by step, that the adapter works, that a scan sees
+// stations, and that a station can be connected to and its state read. It
+// uses the station at address, or with an empty address the strongest
+// stations the scan found until one connects. Each step is published as it
+// completes and the report is kept for LastSelfTest. Once ctx is done the
+// running step fails and the remaining ones are skipped.
+func (m *Manager) RunSelfTest(ctx context.Context, address string) (SelfTestReport, error) {
+	if !m.selfTesting.CompareAndSwap(false, true) {
+		return SelfTestReport{}, ErrSelfTestRunning
+	}
+	defer m.selfTesting.Store(false)
+
+	report := SelfTestReport{StartedAt: m.clock.Now()}
+	for _, name := range []string{SelfTestAdapter, SelfTestScan, SelfTestConnect, SelfTestRead} {
+		report.Steps = append(report.Steps, SelfTestStep{Name: name, Status: StepPending})
+	}
+	log.Printf("Manager: Running self-test (station: %q)", address)
+	// publish sends a copy, the steps keep changing after the event is queued
+	publish := func() {
+		progress := report
+		progress.Steps = append([]SelfTestStep(nil), report.Steps...)
+		m.emit("selftest-progress", progress)
+	}
+
+	// run executes the step at index i, unless an earlier step failed
+	run := func(i int, step func() (string, error)) {
+		if i > 0 && report.Steps[i-1].Status != StepPassed {
+			report.Steps[i].Status = StepSkipped
+			return
+		}
+		report.Steps[i].Status = StepRunning
+		publish()
+		started := m.clock.Now()
+		detail, err := step()
+		report.Steps[i].DurationMs = m.clock.Now().Sub(started).Milliseconds()
+		report.Steps[i].Detail = detail
+		if err != nil {
+			report.Steps[i].Status = StepFailed
+			report.Steps[i].Error = err.Error()
+			log.Printf("Manager: Self-test step %s failed: %v", report.Steps[i].Name, err)
+		} else {
+			report.Steps[i].Status = StepPassed
+		}
+		publish()
+	}
+
+	var scanned []*bluetooth.BaseStation
+	var target *bluetooth.BaseStation
+	var temporary bool // target isn't in the station list and is disconnected afterwards
+
+	run(0, func() (string, error) {
+		if err := m.waitAdapter(ctx); err != nil {
+			return "", err
+		}
+		return "adapter enabled", nil
+	})
+	run(1, func() (string, error) {
+		var err error
+		scanned, err = m.selfTestScan(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(scanned) == 0 {
+			return "", fmt.Errorf("the scan saw no base stations in %v", selfTestScanDuration)
+		}
+		return fmt.Sprintf("%d station(s) found", len(scanned)), nil
+	})
+	run(2, func() (string, error) {
+		var err error
+		var detail string
+		target, temporary, detail, err = m.selfTestConnect(ctx, address, scanned)
+		if target != nil {
+			report.Address = target.Address.String()
+			report.Station = target.Name
+		}
+		return detail, err
+	})
+	run(3, func() (string, error) {
+		ctx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+		defer cancel()
+		if err := bluetooth.ReadPowerState(ctx, target); err != nil {
+			return "", err
+		}
+		switch target.GetPowerState() {
+		case bluetooth.PowerStateOn:
+			return "station is on", nil
+		case bluetooth.PowerStateBooting:
+			return "station is spinning up", nil
+		case bluetooth.PowerStateOff:
+			return "station is off", nil
+		case bluetooth.PowerStateStandby:
+			return "station is in standby", nil
+		}
+		return "station state is unknown", nil
+	})
+
+	if temporary && target != nil {
+		bluetooth.DisconnectStation(target)
+	}
+	report.FinishedAt = m.clock.Now()
+	report.Passed = report.Steps[len(report.Steps)-1].Status == StepPassed
+	log.Printf("Manager: Self-test finished, passed: %v", report.Passed)
+
+	m.selfTestMutex.Lock()
+	m.lastSelfTest = &report
+	m.selfTestMutex.Unlock()
+	publish()
+	return report, nil
+}
+
+// LastSelfTest returns the report of the last completed self-test, or nil.
+func (m *Manager) LastSelfTest() *SelfTestReport {
+	m.selfTestMutex.Lock()
+	defer m.selfTestMutex.Unlock()
+	return m.lastSelfTest
+}
+
+// selfTestScan scans without updating the station list. It counts as a scan
+// in progress, so it doesn't overlap with a regular one.
+func (m *Manager) selfTestScan(ctx context.Context) ([]*bluetooth.BaseStation, error) {
+	if !m.beginScan() {
+		return nil, ErrScanInProgress
+	}
+	defer m.endScan()
+
+	select {
+	case <-bluetooth.AdapterIdle():
+	case <-m.clock.After(adapterSettleTimeout):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	found, err := bluetooth.ScanForDuration(ctx, selfTestScanDuration, m.scanFilter())
+	if errors.Is(err, bluetooth.ErrNoAdapter) {
+		m.adapterLost(err)
+	}
+	return found, err
+}
+
+// selfTestConnect connects to the station at address, or to the first of the
+// strongest scanned stations that connects. Stations in the station list are
+// used as they are; others are temporary and should be disconnected after
+// the test.
+func (m *Manager) selfTestConnect(ctx context.Context, address string, scanned []*bluetooth.BaseStation) (target *bluetooth.BaseStation, temporary bool, detail string, err error) {
+	var candidates []*bluetooth.BaseStation
+	temporaries := make(map[*bluetooth.BaseStation]bool)
+	candidate := func(s *bluetooth.BaseStation) *bluetooth.BaseStation {
+		if existing := m.lookup(s.Address.String()); existing != nil {
+			return existing
+		}
+		station := bluetooth.NewStation(s.Name, s.Address)
+		temporaries[station] = true
+		return station
+	}
+
+	if address != "" {
+		if existing := m.lookup(address); existing != nil {
+			candidates = append(candidates, existing)
+		} else {
+			for _, s := range scanned {
+				if bluetooth.SameAddress(s.Address.String(), address) {
+					candidates = append(candidates, candidate(s))
+					break
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, false, "", fmt.Errorf("%w: %s", ErrStationNotFound, address)
+		}
+	} else {
+		sort.Slice(scanned, func(i, j int) bool { return scanned[i].RSSI > scanned[j].RSSI })
+		for _, s := range scanned {
+			if len(candidates) == selfTestCandidates {
+				break
+			}
+			candidates = append(candidates, candidate(s))
+		}
+	}
+
+	for i, station := range candidates {
+		if station.IsConnected() {
+			return station, false, fmt.Sprintf("%s was already connected", station.Name), nil
+		}
+		connectCtx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+		err = bluetooth.Connect(connectCtx, station)
+		cancel()
+		if err == nil {
+			detail = fmt.Sprintf("connected to %s", station.Name)
+			if i > 0 {
+				detail += fmt.Sprintf(" after %d other station(s) failed", i)
+			}
+			return station, temporaries[station], detail, nil
+		}
+		log.Printf("Manager: Self-test couldn't connect to %s: %v", station.Name, err)
+		if ctx.Err() != nil {
+			return nil, false, "", ctx.Err()
+		}
+	}
+	return nil, false, fmt.Sprintf("tried %d station(s)", len(candidates)), err
+}
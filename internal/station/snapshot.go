@@ -0,0 +1,123 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+)
+
+var (
+	// ErrSnapshotNotFound is returned when restoring or deleting an unknown power snapshot.
+	ErrSnapshotNotFound = errors.New("power snapshot not found")
+	// ErrNothingToSnapshot is returned when no station has a known power state.
+	ErrNothingToSnapshot = errors.New("no station with a known power state")
+)
+
+// Restore result statuses
+const (
+	RestoreOK      = "ok"
+	RestoreFailed  = "failed"
+	RestoreSkipped = "skipped" // The station isn't known any more
+)
+
+// RestoreResult is the outcome of one station's part of a command on several
+// stations: a snapshot restore, a group or all stations.
+type RestoreResult struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Address    string `json:"address,omitempty"`
+	Action     string `json:"action"` // config.ActionOn, config.ActionOff or config.ActionStandby
+	Status     string `json:"status"` // RestoreOK, RestoreFailed or RestoreSkipped
+	Error      string `json:"error,omitempty"`
+	Code       string `json:"code,omitempty"` // Kind of failure, one of the API error codes
+	Note       string `json:"note,omitempty"`
+	Attempts   int    `json:"attempts"`   // Commands sent, 2 if the second pass retried the station
+	DurationMs int64  `json:"durationMs"` // Time the commands took, over all attempts
+
+	err error
+}
+
+// SnapshotStates records the power state of every known station under name,
+// replacing an earlier snapshot with the same name, and saves the config.
+// Stations whose state is unknown are left out.
+func (m *Manager) SnapshotStates(name string) (config.PowerSnapshot, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return config.PowerSnapshot{}, fmt.Errorf("snapshot name must not be empty")
+	}
+	snapshot := config.PowerSnapshot{Name: name, CreatedAt: m.clock.Now()}
+	for _, info := range m.GetStationInfo() {
+		if info.PowerState == bluetooth.PowerStateUnknown {
+			log.Printf("Manager: Leaving %s out of snapshot '%s', its state is unknown", info.Name, name)
+			continue
+		}
+		snapshot.Stations = append(snapshot.Stations, config.SnapshotStation{
+			ID:         info.ID,
+			Name:       info.Name,
+			PowerState: info.PowerState,
+		})
+	}
+	if len(snapshot.Stations) == 0 {
+		return config.PowerSnapshot{}, ErrNothingToSnapshot
+	}
+	if err := m.config.SetPowerSnapshot(snapshot); err != nil {
+		return config.PowerSnapshot{}, err
+	}
+	if err := m.config.Save(); err != nil {
+		return config.PowerSnapshot{}, err
+	}
+	log.Printf("Manager: Saved snapshot '%s' with %d station(s)", name, len(snapshot.Stations))
+	return snapshot, nil
+}
+
+// RestoreSnapshot powers the stations of a snapshot on or off to match it.
+// Stations that are no longer known are skipped. The results are in snapshot order.
+func (m *Manager) RestoreSnapshot(ctx context.Context, name string, src Source) ([]RestoreResult, error) {
+	snapshot, ok := m.config.GetPowerSnapshot(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	results := m.ApplyStates(ctx, snapshot.Stations, src)
+	log.Printf("Manager: Restored snapshot '%s'", name)
+	return results, nil
+}
+
+// ApplyStates powers the given stations on or off, in parallel, and returns
+// the results in the same order. Stations that are no longer known are
+// skipped. Failures get a second pass, see runBulk.
+func (m *Manager) ApplyStates(ctx context.Context, stations []config.SnapshotStation, src Source) []RestoreResult {
+	results := make([]RestoreResult, len(stations))
+	var commands []bulkCommand
+	for i, s := range stations {
+		results[i] = RestoreResult{ID: s.ID, Name: s.Name, Action: actionName(s.PowerState)}
+		stationPtr := m.lookup(s.ID)
+		if stationPtr == nil {
+			results[i].Status = RestoreSkipped
+			results[i].Note = "station is no longer known, run a scan if it is still around"
+			continue
+		}
+		results[i].Address = stationPtr.Address.String()
+		commands = append(commands, bulkCommand{station: stationPtr, value: s.PowerState, result: &results[i]})
+	}
+	m.runBulk(ctx, commands, src)
+	return results
+}
+
+// PowerSnapshots returns the saved power snapshots.
+func (m *Manager) PowerSnapshots() []config.PowerSnapshot {
+	return m.config.GetPowerSnapshots()
+}
+
+// DeleteSnapshot removes a power snapshot and saves the config.
+func (m *Manager) DeleteSnapshot(name string) error {
+	if !m.config.DeletePowerSnapshot(name) {
+		return fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+	return m.config.Save()
+}
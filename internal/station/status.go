@@ -0,0 +1,83 @@
+package station
+
+import (
+	"context"
+	"time"
+)
+
+// StatusCheck is the result of CheckAllStationStatuses.
+type StatusCheck struct {
+	Stations  []StationInfo `json:"stations"`
+	CheckedAt time.Time     `json:"checkedAt"` // When the states were read from the stations
+	Shared    bool          `json:"shared"`    // Joined a check that was already running, or reused a recent one
+}
+
+// statusCheck is a status check in flight or just completed.
+type statusCheck struct {
+	done      chan struct{} // Closed once the check completed
+	stations  []StationInfo
+	err       error
+	checkedAt time.Time
+}
+
+// CheckAllStationStatuses reads the power state of every station. Callers
+// that arrive while a check runs share its result instead of starting
+// another, and a check that completed within the configured cache window is
+// reused without touching the radio. Shared results have the stations'
+// current state, which power commands keep up to date, and the time of the
+// check they came from.
+//
+// The check itself isn't owned by any one caller and runs until it
+// completes or the manager shuts down. A caller whose ctx is done stops
+// waiting for it and gets ctx's error; the others still get the result.
+func (m *Manager) CheckAllStationStatuses(ctx context.Context) (StatusCheck, error) {
+	window := time.Duration(m.config.GetPollingSettings().StatusCacheMs) * time.Millisecond
+
+	m.statusMutex.Lock()
+	if last := m.statusLast; last != nil && last.err == nil && m.clock.Now().Sub(last.checkedAt) < window {
+		m.statusMutex.Unlock()
+		return StatusCheck{Stations: m.GetStationInfo(), CheckedAt: last.checkedAt, Shared: true}, nil
+	}
+	check := m.statusInflight
+	shared := check != nil
+	if !shared {
+		check = &statusCheck{done: make(chan struct{})}
+		m.statusInflight = check
+		go m.runStatusCheck(check)
+	}
+	m.statusMutex.Unlock()
+
+	select {
+	case <-check.done:
+	case <-ctx.Done():
+		return StatusCheck{Stations: m.GetStationInfo()}, ctx.Err()
+	}
+	return StatusCheck{
+		Stations:  append([]StationInfo(nil), check.stations...),
+		CheckedAt: check.checkedAt,
+		Shared:    shared,
+	}, check.err
+}
+
+// runStatusCheck runs check and publishes it as the last one.
+func (m *Manager) runStatusCheck(check *statusCheck) {
+	check.stations, check.err = m.checkAllStationStatuses(m.ctx)
+	check.checkedAt = m.clock.Now()
+
+	m.statusMutex.Lock()
+	m.statusInflight = nil
+	m.statusLast = check
+	m.statusMutex.Unlock()
+	close(check.done)
+}
+
+// LastStatusCheck returns when the station states were last checked, zero
+// if they never were.
+func (m *Manager) LastStatusCheck() time.Time {
+	m.statusMutex.Lock()
+	defer m.statusMutex.Unlock()
+	if m.statusLast == nil {
+		return time.Time{}
+	}
+	return m.statusLast.checkedAt
+}
@@ -0,0 +1,132 @@
+package station
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/clock"
+	"lhcontrol/internal/config"
+)
+
+// StateRevertedEvent is emitted when a station left the state it was commanded to.
+type StateRevertedEvent struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Expected int    `json:"expected"`
+	Actual   int    `json:"actual"`
+}
+
+// verification watches one station after a successful power command.
+type verification struct {
+	value      int
+	cancel     context.CancelFunc
+	reapplying bool // Set while the verification itself re-sends the command
+}
+
+// verifyAfterCommand starts watching a station that was just set to value,
+// replacing any earlier verification of the same station.
+func (m *Manager) verifyAfterCommand(s *bluetooth.BaseStation, value int) {
+	settings := m.config.GetVerificationSettings()
+	if !settings.Enabled {
+		return
+	}
+	id := s.ID()
+
+	m.verifyMutex.Lock()
+	if v, ok := m.verifications[id]; ok {
+		if v.reapplying && v.value == value {
+			// Our own re-send, the running verification carries on
+			m.verifyMutex.Unlock()
+			return
+		}
+		v.cancel()
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	v := &verification{value: value, cancel: cancel}
+	m.verifications[id] = v
+	m.verifyMutex.Unlock()
+
+	go m.verify(ctx, s, v, settings)
+}
+
+func (m *Manager) verify(ctx context.Context, s *bluetooth.BaseStation, v *verification, settings config.VerificationSettings) {
+	address := s.Address.String()
+	id := s.ID()
+	defer func() {
+		m.verifyMutex.Lock()
+		if m.verifications[id] == v {
+			delete(m.verifications, id)
+		}
+		m.verifyMutex.Unlock()
+		v.cancel()
+	}()
+
+	interval := time.Duration(settings.WindowSeconds) * time.Second / time.Duration(settings.Checks)
+	reapplied := false
+	for i := 0; i < settings.Checks; i++ {
+		if clock.Sleep(ctx, m.clock, interval) != nil {
+			return
+		}
+		if m.commandQueued(id) {
+			// The state is in flux, a newer command will start its own verification
+			continue
+		}
+
+		readCtx, cancel := clock.WithTimeout(ctx, m.clock, commandTimeout)
+		err := bluetooth.ReadPowerState(readCtx, s)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("Manager: Verification read failed for %s: %v", address, err)
+			continue
+		}
+		actual := s.GetPowerState()
+		if actual == bluetooth.PowerStateBooting && v.value == bluetooth.PowerStateOn {
+			actual = bluetooth.PowerStateOn // Still spinning up
+		}
+		if actual == v.value || actual == bluetooth.PowerStateUnknown {
+			continue
+		}
+
+		if settings.Reapply && !reapplied {
+			log.Printf("Manager: %s left the %s state, re-sending the command", address, actionName(v.value))
+			reapplied = true
+			m.verifyMutex.Lock()
+			v.reapplying = true
+			m.verifyMutex.Unlock()
+			err := m.runCommand(ctx, s, v.value, Source{Kind: SourceVerify})
+			m.verifyMutex.Lock()
+			v.reapplying = false
+			m.verifyMutex.Unlock()
+			m.emit("state-reapplied", map[string]interface{}{
+				"address": address,
+				"action":  actionName(v.value),
+				"success": err == nil,
+			})
+			continue
+		}
+
+		log.Printf("Manager: %s was switched back from %s externally", address, actionName(v.value))
+		event := StateRevertedEvent{
+			Address:  address,
+			Name:     s.Name,
+			Expected: v.value,
+			Actual:   actual,
+		}
+		m.emit("state-reverted", event)
+		m.onStateReverted(event)
+		return
+	}
+}
+
+// commandQueued reports whether a power command is running or waiting for a station.
+func (m *Manager) commandQueued(id string) bool {
+	m.queuesMutex.Lock()
+	defer m.queuesMutex.Unlock()
+	q, ok := m.queues[id]
+	return ok && q.running
+}
@@ -0,0 +1,188 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/paths"
+)
+
+const (
+	statsFileName = "stats.json"
+
+	// offResetAfter is how long a station must stay off before its continuous on-time restarts
+	offResetAfter = time.Minute
+	// maxSampleGap caps the on-time credited between two observations so app downtime isn't counted
+	maxSampleGap = 5 * time.Minute
+)
+
+// StationStats holds persisted usage data for one station.
+type StationStats struct {
+	OnSince        time.Time `json:"onSince,omitempty"`  // Start of the current continuous on period
+	OffSince       time.Time `json:"offSince,omitempty"` // Start of the current off period, zero while on
+	LastSeenOn     time.Time `json:"lastSeenOn,omitempty"`
+	TotalOnSeconds float64   `json:"totalOnSeconds"`
+}
+
+// Tracker records power state observations and persists them to the state directory.
+type Tracker struct {
+	mu       sync.RWMutex
+	stations map[string]*StationStats
+	dirty    bool
+
+	saveMu sync.Mutex // Serializes saves, so older stats can't replace newer ones
+}
+
+// NewTracker creates an empty Tracker. Call Load to restore persisted data.
+func NewTracker() *Tracker {
+	return &Tracker{
+		stations: make(map[string]*StationStats),
+	}
+}
+
+// Load reads persisted stats from disk
+func (t *Tracker) Load() error {
+	statsFilePath, err := paths.StateFile(statsFileName)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(statsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading stats file '%s': %w", statsFilePath, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := json.Unmarshal(data, &t.stations); err != nil {
+		return fmt.Errorf("error unmarshalling stats: %w", err)
+	}
+	if t.stations == nil {
+		t.stations = make(map[string]*StationStats)
+	}
+	return nil
+}
+
+// Save writes the stats to disk if anything changed since the last save.
+func (t *Tracker) Save() error {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(t.stations, "", "  ")
+	t.dirty = false
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error marshalling stats: %w", err)
+	}
+
+	statsFilePath, err := paths.StateFile(statsFileName)
+	if err != nil {
+		return err
+	}
+	// Write a temporary file and rename it, so a crash mid-write can't lose
+	// the on-time the safety monitor relies on
+	tmpPath := statsFilePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file '%s': %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, statsFilePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace stats file '%s': %w", statsFilePath, err)
+	}
+	return nil
+}
+
+// Rekey moves the stats recorded under from to to, unless to already has
+// stats. Used to carry stats kept by address over to the station's stable ID.
+func (t *Tracker) Rekey(from, to string) {
+	if from == to {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stations[from]
+	if !ok {
+		return
+	}
+	if _, exists := t.stations[to]; !exists {
+		t.stations[to] = s
+	}
+	delete(t.stations, from)
+	t.dirty = true
+}
+
+// Observe records the power state of a station seen at the given time.
+// Unknown states are ignored.
+func (t *Tracker) Observe(id string, powerState int, at time.Time) {
+	if powerState == bluetooth.PowerStateUnknown {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stations[id]
+	if !ok {
+		s = &StationStats{}
+		t.stations[id] = s
+	}
+
+	// The rotor doesn't spin in standby, so it counts as off
+	if powerState == bluetooth.PowerStateOff || powerState == bluetooth.PowerStateStandby {
+		if s.OffSince.IsZero() {
+			s.OffSince = at
+			t.dirty = true
+		}
+		return
+	}
+
+	// Station is on
+	if !s.OffSince.IsZero() {
+		if s.OnSince.IsZero() || at.Sub(s.OffSince) > offResetAfter {
+			log.Printf("Stats: %s turned on, starting a new on period", id)
+			s.OnSince = at
+		}
+		s.OffSince = time.Time{}
+	} else if s.OnSince.IsZero() {
+		s.OnSince = at
+	}
+	if !s.LastSeenOn.IsZero() {
+		if gap := at.Sub(s.LastSeenOn); gap > 0 && gap <= maxSampleGap {
+			s.TotalOnSeconds += gap.Seconds()
+		}
+	}
+	s.LastSeenOn = at
+	t.dirty = true
+}
+
+// ContinuousOn returns how long the station has been on without a long enough off period.
+func (t *Tracker) ContinuousOn(id string, now time.Time) time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	s, ok := t.stations[id]
+	if !ok || s.OnSince.IsZero() || !s.OffSince.IsZero() {
+		return 0
+	}
+	return now.Sub(s.OnSince)
+}
+
+// Get returns a copy of the stats for a station.
+func (t *Tracker) Get(id string) (StationStats, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	s, ok := t.stations[id]
+	if !ok {
+		return StationStats{}, false
+	}
+	return *s, true
+}
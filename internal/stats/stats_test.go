@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/paths"
+)
+
+// useTempState points the state directory at a temporary directory.
+func useTempState(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "APPDATA", "LOCALAPPDATA"} {
+		t.Setenv(env, dir)
+	}
+}
+
+// Saved stats are loaded back, and the save leaves no temporary file behind.
+func TestSaveAndLoad(t *testing.T) {
+	useTempState(t)
+	const id = "02:00:00:00:00:01"
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tracker := NewTracker()
+	tracker.Observe(id, bluetooth.PowerStateOn, start)
+	tracker.Observe(id, bluetooth.PowerStateOn, start.Add(time.Minute))
+	if err := tracker.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	statsFilePath, err := paths.StateFile(statsFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(statsFilePath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("the temporary file is left behind: %v", err)
+	}
+
+	loaded := NewTracker()
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s, ok := loaded.Get(id)
+	if !ok {
+		t.Fatal("the saved station wasn't loaded")
+	}
+	if !s.OnSince.Equal(start) || s.TotalOnSeconds != 60 {
+		t.Errorf("loaded on since %v with %v seconds on, want %v with 60", s.OnSince, s.TotalOnSeconds, start)
+	}
+}
@@ -0,0 +1,149 @@
+//go:build linux
+
+package systemd
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/instance"
+)
+
+// unitName returns the unit file name, one per instance.
+func unitName() string {
+	return "lhcontrol" + instance.Suffix() + ".service"
+}
+
+// Notify sends a state string (e.g. "READY=1") to the systemd notify socket.
+// It is a no-op returning false when NOTIFY_SOCKET is not set.
+func Notify(state string) (bool, error) {
+	socketAddr := os.Getenv("NOTIFY_SOCKET")
+	if socketAddr == "" {
+		return false, nil
+	}
+	// Abstract namespace sockets are announced with a leading '@'
+	if strings.HasPrefix(socketAddr, "@") {
+		socketAddr = "\x00" + socketAddr[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketAddr, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to dial notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to write to notify socket: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout requested by systemd,
+// or zero if the watchdog is not enabled for this process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	// WATCHDOG_PID is optional, but if present it must match us
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil || pid != os.Getpid() {
+			return 0
+		}
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// StartWatchdog pings the systemd watchdog at half the requested interval
+// until the returned stop function is called. It does nothing when the
+// watchdog is not enabled.
+func StartWatchdog() (stop func()) {
+	interval := WatchdogInterval()
+	if interval == 0 {
+		return func() {}
+	}
+
+	log.Printf("systemd: Watchdog enabled, pinging every %v", interval/2)
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval / 2)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := Notify("WATCHDOG=1"); err != nil {
+					log.Printf("systemd: Watchdog ping failed: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// UnitFile renders a user unit that starts lhcontrol with the graphical
+// session. command is the executable and its arguments.
+func UnitFile(command string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Lighthouse Control (SteamVR base station power manager)
+PartOf=graphical-session.target
+After=graphical-session.target bluetooth.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+Restart=on-failure
+RestartSec=5
+TimeoutStopSec=20
+WatchdogSec=60
+
+[Install]
+WantedBy=graphical-session.target
+`, command)
+}
+
+// InstallUserService writes the example unit file into the user's systemd
+// directory and returns the path it was written to.
+func InstallUserService() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	exePath, err = filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config dir: %w", err)
+	}
+	unitDir := filepath.Join(configDir, "systemd", "user")
+	if err := os.MkdirAll(unitDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create unit dir '%s': %w", unitDir, err)
+	}
+
+	command := exePath
+	if name := instance.Name(); name != "" {
+		command += " -instance " + name
+	}
+	unitPath := filepath.Join(unitDir, unitName())
+	if err := os.WriteFile(unitPath, []byte(UnitFile(command)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write unit file '%s': %w", unitPath, err)
+	}
+	return unitPath, nil
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package systemd
+
+import (
+	"fmt"
+	"time"
+)
+
+// Notify is a no-op on non-Linux platforms.
+func Notify(state string) (bool, error) {
+	return false, nil
+}
+
+// WatchdogInterval always reports a disabled watchdog on non-Linux platforms.
+func WatchdogInterval() time.Duration {
+	return 0
+}
+
+// StartWatchdog is a no-op on non-Linux platforms.
+func StartWatchdog() (stop func()) {
+	return func() {}
+}
+
+// InstallUserService is only supported on Linux.
+func InstallUserService() (string, error) {
+	return "", fmt.Errorf("systemd services are only supported on Linux")
+}
@@ -0,0 +1,260 @@
+package timers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/paths"
+)
+
+const timersFileName = "timers.json"
+
+// Timer is a one-shot power action at a fixed time.
+type Timer struct {
+	ID        string    `json:"id"`
+	Action    string    `json:"action"`  // config.ActionOn, config.ActionOff or config.ActionStandby
+	Address   string    `json:"address"` // Target station address or ID, empty for all stations
+	At        time.Time `json:"at"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Skipped is a restored timer that was dropped instead of fired.
+type Skipped struct {
+	Timer  Timer  `json:"timer"`
+	Reason string `json:"reason"`
+}
+
+// RestoreReport describes what happened to the persisted timers on startup.
+type RestoreReport struct {
+	Restored []Timer   `json:"restored"` // Still pending
+	Fired    []Timer   `json:"fired"`    // Missed while the app was down and fired now
+	Skipped  []Skipped `json:"skipped"`
+}
+
+// Manager keeps the pending timers, persists them to the state directory and fires them when due.
+type Manager struct {
+	cfg    *config.Config
+	onFire func(Timer)
+
+	mu     sync.Mutex
+	timers map[string]Timer
+
+	saveMu sync.Mutex // Serializes saves, so an older list can't replace a newer one
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func NewManager(cfg *config.Config, onFire func(Timer)) *Manager {
+	return &Manager{
+		cfg:      cfg,
+		onFire:   onFire,
+		timers:   make(map[string]Timer),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Restore loads persisted timers. Timers whose time passed while the app was
+// down are fired once Start is called, or skipped, according to the timer settings.
+func (m *Manager) Restore() (RestoreReport, error) {
+	report := RestoreReport{Restored: []Timer{}, Fired: []Timer{}, Skipped: []Skipped{}}
+
+	timersFilePath, err := paths.StateFile(timersFileName)
+	if err != nil {
+		return report, err
+	}
+	data, err := os.ReadFile(timersFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return report, nil
+		}
+		return report, fmt.Errorf("error reading timers file '%s': %w", timersFilePath, err)
+	}
+	var persisted []Timer
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return report, fmt.Errorf("error unmarshalling timers: %w", err)
+	}
+
+	settings := m.cfg.GetTimerSettings()
+	now := time.Now()
+
+	m.mu.Lock()
+	for _, t := range persisted {
+		if t.At.After(now) {
+			m.timers[t.ID] = t
+			report.Restored = append(report.Restored, t)
+			continue
+		}
+		if reason := missedReason(t, settings, now); reason != "" {
+			report.Skipped = append(report.Skipped, Skipped{Timer: t, Reason: reason})
+			continue
+		}
+		// Kept as due, the loop fires it on its first tick after Start
+		log.Printf("Timers: Timer %s (%s) was missed by %v, firing it", t.ID, t.Action, now.Sub(t.At).Round(time.Second))
+		m.timers[t.ID] = t
+		report.Fired = append(report.Fired, t)
+	}
+	m.mu.Unlock()
+
+	if len(report.Skipped) > 0 {
+		if err := m.save(); err != nil {
+			log.Printf("Timers: Failed to save timers: %v", err)
+		}
+	}
+	return report, nil
+}
+
+// missedReason returns why a missed timer should be skipped, or "" to fire it.
+// Turning stations off late is always safe, turning them on is only sensible
+// shortly after the intended time.
+func missedReason(t Timer, settings config.TimerSettings, now time.Time) string {
+	if settings.MissedPolicy == config.MissedPolicySkip {
+		return "missed while the app was not running"
+	}
+	late := now.Sub(t.At)
+	if t.Action != config.ActionOff && late > time.Duration(settings.MissedGraceMinutes)*time.Minute {
+		return fmt.Sprintf("missed by %v, too late to turn stations on", late.Round(time.Minute))
+	}
+	return ""
+}
+
+func (m *Manager) save() error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	m.mu.Lock()
+	data, err := json.MarshalIndent(m.listLocked(), "", "  ")
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error marshalling timers: %w", err)
+	}
+
+	timersFilePath, err := paths.StateFile(timersFileName)
+	if err != nil {
+		return err
+	}
+	// Write a temporary file and rename it, so a crash mid-write can't lose
+	// the pending timers
+	tmpPath := timersFilePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write timers file '%s': %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, timersFilePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace timers file '%s': %w", timersFilePath, err)
+	}
+	return nil
+}
+
+func newID() string {
+	buf := make([]byte, 6)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// Add schedules an action after delay. An empty address targets all stations.
+func (m *Manager) Add(action, address string, delay time.Duration) (Timer, error) {
+	if action != config.ActionOn && action != config.ActionOff && action != config.ActionStandby {
+		return Timer{}, fmt.Errorf("invalid timer action '%s'", action)
+	}
+	if delay <= 0 {
+		return Timer{}, fmt.Errorf("timer delay must be positive")
+	}
+	now := time.Now()
+	t := Timer{
+		ID:        newID(),
+		Action:    action,
+		Address:   address,
+		At:        now.Add(delay),
+		CreatedAt: now,
+	}
+
+	m.mu.Lock()
+	m.timers[t.ID] = t
+	m.mu.Unlock()
+	return t, m.save()
+}
+
+// Cancel removes a pending timer.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	if _, ok := m.timers[id]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("timer '%s' not found", id)
+	}
+	delete(m.timers, id)
+	m.mu.Unlock()
+	return m.save()
+}
+
+// List returns the pending timers ordered by due time.
+func (m *Manager) List() []Timer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.listLocked()
+}
+
+func (m *Manager) listLocked() []Timer {
+	list := make([]Timer, 0, len(m.timers))
+	for _, t := range m.timers {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].At.Before(list[j].At) })
+	return list
+}
+
+// Start fires due timers in the background.
+func (m *Manager) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.fireDue()
+			case <-m.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background loop and waits for it to exit.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() { close(m.stopChan) })
+	m.wg.Wait()
+}
+
+func (m *Manager) fireDue() {
+	now := time.Now()
+	var due []Timer
+	m.mu.Lock()
+	for id, t := range m.timers {
+		if !t.At.After(now) {
+			due = append(due, t)
+			delete(m.timers, id)
+		}
+	}
+	m.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	if err := m.save(); err != nil {
+		log.Printf("Timers: Failed to save timers: %v", err)
+	}
+	for _, t := range due {
+		log.Printf("Timers: Timer %s fired (%s)", t.ID, t.Action)
+		m.onFire(t)
+	}
+}
@@ -0,0 +1,57 @@
+package timers
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/paths"
+)
+
+// useTempState points the config and state directories at a temporary directory.
+func useTempState(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME", "APPDATA", "LOCALAPPDATA"} {
+		t.Setenv(env, dir)
+	}
+}
+
+// Pending timers are restored at the next start, and saving them leaves no
+// temporary file behind.
+func TestRestoreSaved(t *testing.T) {
+	useTempState(t)
+	cfg := config.NewConfig()
+	m := NewManager(cfg, func(Timer) {})
+	kept, err := m.Add(config.ActionOff, "", time.Hour)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	cancelled, err := m.Add(config.ActionOn, "02:00:00:00:00:01", time.Hour)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := m.Cancel(cancelled.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	timersFilePath, err := paths.StateFile(timersFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(timersFilePath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("the temporary file is left behind: %v", err)
+	}
+
+	restarted := NewManager(cfg, func(Timer) {})
+	report, err := restarted.Restore()
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if len(report.Restored) != 1 || report.Restored[0].ID != kept.ID {
+		t.Errorf("restored %+v, want only %s", report.Restored, kept.ID)
+	}
+	if len(report.Fired) != 0 || len(report.Skipped) != 0 {
+		t.Errorf("fired %+v and skipped %+v, want neither", report.Fired, report.Skipped)
+	}
+}
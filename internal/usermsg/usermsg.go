@@ -0,0 +1,172 @@
+package usermsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/session"
+	"lhcontrol/internal/station"
+	"lhcontrol/pkg/apiclient"
+)
+
+// Error codes, stable identifiers for API clients and the frontend. They
+// are defined with the API client, which returns them in its errors.
+const (
+	CodeSuperseded          = apiclient.CodeSuperseded
+	CodeAdapterInitializing = apiclient.CodeAdapterInitializing
+	CodeAdapterUnavailable  = apiclient.CodeAdapterUnavailable
+	CodeNoAdapter           = apiclient.CodeNoAdapter
+	CodeAdapterDisabled     = apiclient.CodeAdapterDisabled
+	CodeStationNotFound     = apiclient.CodeStationNotFound
+	CodeScanInProgress      = apiclient.CodeScanInProgress
+	CodeScanFailed          = apiclient.CodeScanFailed
+	CodeSnapshotNotFound    = apiclient.CodeSnapshotNotFound
+	CodeGroupNotFound       = apiclient.CodeGroupNotFound
+	CodeGroupExists         = apiclient.CodeGroupExists
+	CodeNothingToSnapshot   = apiclient.CodeNothingToSnapshot
+	CodeNoSession           = apiclient.CodeNoSession
+	CodeBusy                = apiclient.CodeBusy
+	CodeUnreachable         = apiclient.CodeUnreachable
+	CodeNotConnected        = apiclient.CodeNotConnected
+	CodeNotABaseStation     = apiclient.CodeNotABaseStation
+	CodeReadFailed          = apiclient.CodeReadFailed
+	CodeWriteFailed         = apiclient.CodeWriteFailed
+	CodeUnsupported         = apiclient.CodeUnsupported
+	CodeStateMismatch       = apiclient.CodeStateMismatch
+	CodeInvalidChannel      = apiclient.CodeInvalidChannel
+	CodeChannelConflict     = apiclient.CodeChannelConflict
+	CodeTimeout             = apiclient.CodeTimeout
+	CodeCanceled            = apiclient.CodeCanceled
+	CodeUnknown             = apiclient.CodeUnknown
+)
+
+// mapping ties an error kind to its code. The first match wins, so more
+// specific kinds come first.
+var mapping = []struct {
+	kind error
+	code string
+}{
+	{station.ErrSuperseded, CodeSuperseded},
+	{station.ErrAdapterInitializing, CodeAdapterInitializing},
+	{station.ErrNoAdapter, CodeNoAdapter},
+	{bluetooth.ErrNoAdapter, CodeNoAdapter},
+	{bluetooth.ErrAdapterDisabled, CodeAdapterDisabled},
+	{station.ErrAdapterUnavailable, CodeAdapterUnavailable},
+	{bluetooth.ErrAdapter, CodeAdapterUnavailable},
+	{station.ErrStationNotFound, CodeStationNotFound},
+	{station.ErrScanInProgress, CodeScanInProgress},
+	{bluetooth.ErrScan, CodeScanFailed},
+	{station.ErrSnapshotNotFound, CodeSnapshotNotFound},
+	{station.ErrGroupNotFound, CodeGroupNotFound},
+	{station.ErrGroupExists, CodeGroupExists},
+	{bluetooth.ErrInvalidChannel, CodeInvalidChannel},
+	{station.ErrChannelConflict, CodeChannelConflict},
+	{station.ErrNothingToSnapshot, CodeNothingToSnapshot},
+	{session.ErrNoSession, CodeNoSession},
+	{bluetooth.ErrTimeout, CodeTimeout}, // Before the kinds of the operations it interrupted
+	{bluetooth.ErrBusy, CodeBusy},
+	{bluetooth.ErrConnect, CodeUnreachable},
+	{bluetooth.ErrNotConnected, CodeNotConnected},
+	{bluetooth.ErrDiscovery, CodeNotABaseStation},
+	{bluetooth.ErrCharacteristicMissing, CodeNotABaseStation},
+	{bluetooth.ErrRead, CodeReadFailed},
+	{bluetooth.ErrWrite, CodeWriteFailed},
+	{bluetooth.ErrUnsupported, CodeUnsupported},
+	{bluetooth.ErrStateMismatch, CodeStateMismatch},
+	{context.DeadlineExceeded, CodeTimeout},
+	{context.Canceled, CodeCanceled},
+}
+
+// messages holds the user-facing text for every code. %s is the station name.
+var messages = map[string]string{
+	CodeSuperseded:          "A newer command for %s replaced this one.",
+	CodeAdapterInitializing: "Bluetooth is still starting up. Try again in a moment.",
+	CodeAdapterUnavailable:  "Bluetooth isn't available. Check that the adapter is plugged in and turned on.",
+	CodeNoAdapter:           "No Bluetooth adapter found. Plug in a Bluetooth LE dongle.",
+	CodeAdapterDisabled:     "Bluetooth is turned off. Turn it on and try again.",
+	CodeStationNotFound:     "That base station isn't known yet. Run a scan first.",
+	CodeScanInProgress:      "A scan is already running.",
+	CodeSnapshotNotFound:    "That snapshot doesn't exist any more.",
+	CodeGroupNotFound:       "That group doesn't exist any more.",
+	CodeGroupExists:         "A group with that name already exists.",
+	CodeNothingToSnapshot:   "No base station has a known power state yet. Run a scan first.",
+	CodeNoSession:           "There is no previous session to resume yet.",
+	CodeScanFailed:          "The Bluetooth scan failed. Check that Bluetooth is turned on.",
+	CodeBusy:                "%s is busy with another command. Try again in a moment.",
+	CodeUnreachable:         "Couldn't reach %s. It may be unplugged or out of range.",
+	CodeNotConnected:        "%s isn't connected. Run a scan or send a command to connect it.",
+	CodeNotABaseStation:     "%s connected but didn't answer like a base station. Try again, or remove it from the system's Bluetooth devices.",
+	CodeReadFailed:          "Couldn't read the power state of %s.",
+	CodeWriteFailed:         "Couldn't send the command to %s.",
+	CodeUnsupported:         "%s doesn't support this command.",
+	CodeStateMismatch:       "%s received the command but reports another power state.",
+	CodeInvalidChannel:      "Channels go from 1 to 16.",
+	CodeChannelConflict:     "Another base station already uses that channel.",
+	CodeTimeout:             "%s didn't respond in time.",
+	CodeCanceled:            "The operation was canceled because lhcontrol is shutting down.",
+	CodeUnknown:             "Something went wrong. The log has the details.",
+}
+
+// Message is an error translated for users. Detail keeps the technical error
+// for the log and diagnostics.
+type Message struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail"`
+
+	err error
+}
+
+// Error returns the user-facing message, so a Message can be returned from
+// Wails bindings as is.
+func (m *Message) Error() string {
+	return m.Message
+}
+
+func (m *Message) Unwrap() error {
+	return m.err
+}
+
+// Code returns the code for err.
+func Code(err error) string {
+	for _, m := range mapping {
+		if errors.Is(err, m.kind) {
+			return m.code
+		}
+	}
+	return CodeUnknown
+}
+
+// Translate turns err into a user-facing message. It returns nil for a nil
+// error and err itself if it already is a *Message.
+func Translate(err error) *Message {
+	if err == nil {
+		return nil
+	}
+	var msg *Message
+	if errors.As(err, &msg) {
+		return msg
+	}
+	code := Code(err)
+	name := bluetooth.StationName(err)
+	if name == "" {
+		name = "the base station"
+	}
+	text := messages[code]
+	if text == "" {
+		text = messages[CodeUnknown]
+	}
+	if strings.Contains(text, "%s") {
+		text = fmt.Sprintf(text, name)
+		text = strings.ToUpper(text[:1]) + text[1:]
+	}
+	// A bulk command tells what happened to each station instead
+	var bulk *station.BulkError
+	if errors.As(err, &bulk) {
+		text = bulk.Summary() + "."
+	}
+	return &Message{Code: code, Message: text, Detail: err.Error(), err: err}
+}
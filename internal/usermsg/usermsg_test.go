@@ -0,0 +1,70 @@
+package usermsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/station"
+)
+
+func TestEveryCodeHasAMessage(t *testing.T) {
+	codes := []string{CodeUnknown}
+	for _, m := range mapping {
+		codes = append(codes, m.code)
+	}
+	for _, code := range codes {
+		t.Run(code, func(t *testing.T) {
+			text, ok := messages[code]
+			if !ok || text == "" {
+				t.Fatalf("code %q has no message", code)
+			}
+			if n := strings.Count(text, "%"); n > 1 || (n == 1 && !strings.Contains(text, "%s")) {
+				t.Errorf("message %q may only use %%s, once", text)
+			}
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	stationErr := func(kind error) error {
+		return fmt.Errorf("command: %w", &bluetooth.Error{Station: "LHB-1A2B3C4D", Kind: kind, Err: errors.New("detail")})
+	}
+	tests := []struct {
+		name    string
+		err     error
+		code    string
+		message string
+	}{
+		{"unreachable", stationErr(bluetooth.ErrConnect), CodeUnreachable, "Couldn't reach LHB-1A2B3C4D. It may be unplugged or out of range."},
+		// ErrTimeout wins over the operation it interrupted
+		{"timeout", stationErr(bluetooth.ErrTimeout), CodeTimeout, "LHB-1A2B3C4D didn't respond in time."},
+		{"no station name", fmt.Errorf("%w: 02:00:00:00:00:01", station.ErrStationNotFound), CodeStationNotFound, "That base station isn't known yet. Run a scan first."},
+		{"no adapter", fmt.Errorf("%w: %w", station.ErrNoAdapter, bluetooth.ErrNoAdapter), CodeNoAdapter, "No Bluetooth adapter found. Plug in a Bluetooth LE dongle."},
+		{"canceled", context.Canceled, CodeCanceled, messages[CodeCanceled]},
+		{"unknown", errors.New("something odd"), CodeUnknown, messages[CodeUnknown]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := Translate(tt.err)
+			if msg.Code != tt.code || msg.Message != tt.message {
+				t.Errorf("got %q %q, want %q %q", msg.Code, msg.Message, tt.code, tt.message)
+			}
+			if msg.Detail != tt.err.Error() {
+				t.Errorf("detail is %q, want the technical error", msg.Detail)
+			}
+			if !errors.Is(msg, tt.err) {
+				t.Error("the message doesn't wrap the error")
+			}
+			if again := Translate(msg); again != msg {
+				t.Error("translating a message again changed it")
+			}
+		})
+	}
+	if Translate(nil) != nil {
+		t.Error("Translate(nil) isn't nil")
+	}
+}
@@ -0,0 +1,8 @@
+// Package webui holds the small mobile control page served by the HTTP API.
+// It is intentionally kept to a single static file, separate from the Wails frontend.
+package webui
+
+import _ "embed"
+
+//go:embed index.html
+var Index []byte
@@ -41,22 +41,41 @@ var (
 	procFlashWindowEx       = user32.NewProc("FlashWindowEx")
 )
 
-// FindWindow finds a window by title.
+// proc is the part of *syscall.LazyProc used by the wrappers, so they can be exercised with a fake.
+type proc interface {
+	Call(a ...uintptr) (r1, r2 uintptr, lastErr error)
+}
+
+// lastError interprets the error returned by proc.Call for a call that
+// reported failure through its return value. Call always returns a non-nil
+// error, holding the thread's last error code, which is zero when the
+// function failed without setting one.
+func lastError(err error) error {
+	if errno, ok := err.(syscall.Errno); ok && errno == 0 {
+		return nil
+	}
+	return err
+}
+
+// FindWindow finds a window by title. It returns 0 and no error when no such window exists.
 func FindWindow(title string) (syscall.Handle, error) {
+	return findWindowWith(procFindWindowW, title)
+}
+
+func findWindowWith(p proc, title string) (syscall.Handle, error) {
 	titlePtr, err := syscall.UTF16PtrFromString(title)
 	if err != nil {
 		return 0, err
 	}
-	hwnd, _, err := procFindWindowW.Call(0, uintptr(unsafe.Pointer(titlePtr)))
-	// Check for specific error "Invalid window handle." which means not found
-	if err != nil && err.Error() != "The operation completed successfully." {
-		// Check if error indicates not found (this might vary, often it's just HWND=0 with success error)
-		if hwnd == 0 { // Best check is often just if handle is zero
-			return 0, nil // Not found, but not an API error
-		}
-		return 0, err // Actual API error
+	hwnd, _, err := p.Call(0, uintptr(unsafe.Pointer(titlePtr)))
+	if hwnd != 0 {
+		return syscall.Handle(hwnd), nil
+	}
+	// Not found is reported as zero without a last error (or ERROR_FILE_NOT_FOUND on some versions)
+	if err = lastError(err); err == nil || err == syscall.ERROR_FILE_NOT_FOUND {
+		return 0, nil
 	}
-	return syscall.Handle(hwnd), nil
+	return 0, err
 }
 
 // SetForegroundWindow brings a window to the foreground.
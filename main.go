@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strings"
+	"syscall"
+	"time"
 
+	"lhcontrol/internal/bluetooth"
+	"lhcontrol/internal/config"
+	"lhcontrol/internal/discovery"
+	"lhcontrol/internal/instance"
+	"lhcontrol/internal/notify"
+	"lhcontrol/internal/paths"
 	"lhcontrol/internal/platform"
+	"lhcontrol/internal/redact"
+	"lhcontrol/internal/systemd"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -21,19 +33,28 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
-const lockPort = "34115"     // Port used for single instance check
 const appTitle = "lhcontrol" // Define app title constant
 
+// instanceFlagUsage describes the -instance flag of the app and its subcommands
+const instanceFlagUsage = "Run a named instance with its own config, state, logs, API port and lock, e.g. one per Bluetooth adapter"
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// shutdownDeadline bounds how long a signal-triggered shutdown may take before the process is killed
+const shutdownDeadline = 15 * time.Second
+
+// consoleCloseDeadline stays below the five seconds Windows allows a console control handler
+const consoleCloseDeadline = 4 * time.Second
+
 // setupLogging configures logging to write to both console and a file.
 // Assumes it's only called when file logging is desired.
 func setupLogging() (*os.File, error) {
-	exePath, err := os.Executable()
+	logFilePath, err := paths.LogFile()
 	if err != nil {
-		log.Printf("ERROR getting executable path: %v", err)
+		log.Printf("ERROR resolving log file path: %v", err)
 		return nil, err
 	}
-	exeDir := filepath.Dir(exePath)
-	logFilePath := filepath.Join(exeDir, "lhcontrol.log")
 
 	logFile, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
 	if err != nil {
@@ -43,7 +64,7 @@ func setupLogging() (*os.File, error) {
 
 	// Write logs to both Stdout and the log file
 	logWriter := io.MultiWriter(os.Stdout, logFile)
-	log.SetOutput(logWriter)
+	log.SetOutput(redact.Writer(logWriter))
 	// Flags are set in main before calling this
 
 	log.Println("-----------------------------------------")
@@ -53,13 +74,260 @@ func setupLogging() (*os.File, error) {
 	return logFile, nil
 }
 
+// setupBLEBackend simulates stations or replays a transcript instead of using
+// the adapter when simulate or replayPath is set, and records every BLE
+// operation in debug mode. The returned file, if any, must be closed on exit.
+func setupBLEBackend(debug bool, replayPath string, simulate int) (*os.File, error) {
+	if simulate > 0 {
+		bluetooth.SetBackend(bluetooth.NewFakeBackend(bluetooth.SimulatedStations(simulate)...))
+		log.Printf("Simulating %d stations instead of using the adapter", simulate)
+		return nil, nil
+	}
+	if replayPath != "" {
+		f, err := os.Open(replayPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open transcript: %w", err)
+		}
+		defer f.Close()
+		replayer, err := bluetooth.NewReplayer(f)
+		if err != nil {
+			return nil, err
+		}
+		bluetooth.SetBackend(replayer)
+		log.Printf("Replaying BLE transcript %s", replayPath)
+		return nil, nil
+	}
+	if !debug {
+		return nil, nil
+	}
+	transcriptPath, err := paths.TranscriptFile(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Create(transcriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript: %w", err)
+	}
+	bluetooth.SetBackend(bluetooth.NewRecorder(bluetooth.DefaultBackend(), f))
+	log.Printf("Recording BLE transcript to %s", transcriptPath)
+	return f, nil
+}
+
+// setupBLETrace wraps the BLE backend with a tracer that logs every operation
+// to the trace file. The returned tracer must be closed on exit.
+func setupBLETrace() (*bluetooth.Tracer, error) {
+	tracePath, err := paths.TraceFile()
+	if err != nil {
+		return nil, err
+	}
+	tracer, err := bluetooth.NewTracer(bluetooth.ActiveBackend(), tracePath)
+	if err != nil {
+		return nil, err
+	}
+	bluetooth.SetBackend(tracer)
+	log.Printf("Tracing BLE operations to %s", tracePath)
+	return tracer, nil
+}
+
+// selectInstance switches to a named instance, exiting on an invalid name.
+func selectInstance(name string) {
+	if err := instance.SetName(name); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// installService handles the "install-service" subcommand.
+func installService(args []string) {
+	fs := flag.NewFlagSet("install-service", flag.ExitOnError)
+	user := fs.Bool("user", false, "Install as a systemd user unit")
+	instanceName := fs.String("instance", "", instanceFlagUsage)
+	fs.Parse(args)
+	selectInstance(*instanceName)
+
+	if !*user {
+		fmt.Fprintln(os.Stderr, "Only user units are supported, run: lhcontrol install-service --user")
+		os.Exit(2)
+	}
+
+	unitPath, err := systemd.InstallUserService()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to install service: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Unit file written to %s\n", unitPath)
+	fmt.Printf("Enable it with: systemctl --user daemon-reload && systemctl --user enable --now %s\n", filepath.Base(unitPath))
+}
+
+// lanAddress returns the first non-loopback IPv4 address of this machine.
+func lanAddress() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return ""
+}
+
+// agentPair handles the "agent-pair" subcommand. It makes sure this machine
+// has an API token and prints what the primary instance needs to register it.
+func agentPair(args []string) {
+	fs := flag.NewFlagSet("agent-pair", flag.ExitOnError)
+	name := fs.String("name", "", "Agent name shown on the primary instance (defaults to the host name)")
+	regenerate := fs.Bool("regenerate", false, "Replace the existing token, unpairing all primaries")
+	instanceName := fs.String("instance", "", instanceFlagUsage)
+	fs.Parse(args)
+	selectInstance(*instanceName)
+
+	cfg := config.NewConfig()
+	if err := cfg.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	token, err := ensureAPIToken(cfg, *regenerate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up token: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *name == "" {
+		*name, _ = os.Hostname()
+	}
+	host := lanAddress()
+	if host == "" {
+		host = "<this-machine>"
+	}
+	// Prefer the port a running agent actually bound
+	address := agentListenAddress()
+	if info, err := discovery.Read(); err == nil {
+		address = info.Address
+	}
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		port = "7575"
+	}
+
+	agentCfg := config.AgentConfig{
+		Name:  *name,
+		URL:   fmt.Sprintf("http://%s:%s", host, port),
+		Token: token,
+	}
+	snippet, _ := json.MarshalIndent(agentCfg, "", "  ")
+	fmt.Printf("Agent URL:   %s\n", agentCfg.URL)
+	fmt.Printf("Agent token: %s\n\n", token)
+	fmt.Println("Start this machine with: lhcontrol -agent")
+	fmt.Println("Then add this entry to \"agents\" in the primary instance's config.json:")
+	fmt.Println(string(snippet))
+}
+
+// runAgent runs the app headless until it receives a quit request.
+func runAgent(app *App) {
+	ctx, cancel := context.WithCancel(context.Background())
+	app.cancel = cancel
+	app.startup(ctx)
+	<-ctx.Done()
+	app.shutdown(ctx)
+}
+
+// unregisterShell removes everything lhcontrol registers with the Windows shell,
+// for use by uninstallers.
+func unregisterShell() {
+	failed := false
+	if err := platform.RemoveJumpList(notify.AppUserModelID); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove jump list: %v\n", err)
+		failed = true
+	}
+	if err := notify.Unregister(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove notification registration: %v\n", err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("Unregistered.")
+}
+
+// handleSignals runs the shutdown sequence on SIGINT, SIGTERM, SIGHUP and on
+// console close, and force-exits if it does not complete within the deadline.
+func handleSignals(app *App) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		sig := <-sigChan
+		log.Printf("Received signal %v, shutting down...", sig)
+		shutdownWithDeadline(app, shutdownDeadline)
+		app.requestQuit()
+	}()
+
+	err := platform.OnConsoleClose(func() {
+		log.Println("Console closing, shutting down...")
+		shutdownWithDeadline(app, consoleCloseDeadline)
+	})
+	if err != nil {
+		log.Printf("Error installing console control handler: %v", err)
+	}
+}
+
+// shutdownWithDeadline runs the app's shutdown sequence and waits at most
+// deadline for it. A timer force-exits the process if the sequence hangs, so
+// the caller never waits for half-open BLE connections forever.
+func shutdownWithDeadline(app *App, deadline time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		app.shutdown(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(deadline):
+		log.Printf("FATAL: Shutdown did not complete within %v, exiting.", deadline)
+		os.Exit(1)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "install-service":
+			installService(os.Args[2:])
+			return
+		case "agent-pair":
+			agentPair(os.Args[2:])
+			return
+		}
+	}
+
 	// Define command-line flag for logging
-	logToFile := flag.Bool("log", false, "Enable file logging to lhcontrol.log")
+	logToFile := flag.Bool("log", false, "Enable file logging to lhcontrol.log in the log directory")
+	agentMode := flag.Bool("agent", false, "Run headless as an agent driven by another lhcontrol instance")
+	listenAddr := flag.String("listen", "", "Override the API listen address")
+	unregister := flag.Bool("unregister", false, "Remove the jump list and notification registration, then exit")
+	debug := flag.Bool("debug", false, "Enable raw characteristic writes and record a BLE transcript for protocol debugging")
+	replay := flag.String("replay", "", "Run against a recorded BLE transcript instead of the adapter")
+	simulate := flag.Int("simulate", 0, "Run against this many simulated stations instead of the adapter")
+	instanceName := flag.String("instance", "", instanceFlagUsage)
+	bleTrace := flag.Bool("bletrace", false, "Log every BLE operation with payloads and timings to ble-trace.log in the log directory")
 	flag.Parse() // Parse command line arguments
+	selectInstance(*instanceName)
+
+	if *unregister {
+		unregisterShell()
+		return
+	}
 
 	// Setup standard logger flags (applies to console and potentially file)
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
+	// Addresses are replaced once the privacy setting is loaded
+	log.SetOutput(redact.Writer(os.Stderr))
+
+	// Move files left in old locations by previous versions
+	if err := paths.Migrate(); err != nil {
+		log.Printf("Error migrating files from legacy locations: %v", err)
+	}
 
 	// Setup file logging only if requested
 	var logFile *os.File
@@ -81,19 +349,54 @@ func main() {
 		log.Println("File logging disabled. Use -log flag to enable.")
 	}
 
+	transcript, err := setupBLEBackend(*debug, *replay, *simulate)
+	if err != nil {
+		log.Printf("FATAL: Failed to set up the BLE backend: %v", err)
+		os.Exit(1)
+	}
+	if transcript != nil {
+		defer transcript.Close()
+	}
+	if *bleTrace {
+		tracer, err := setupBLETrace()
+		if err != nil {
+			log.Printf("Error setting up BLE tracing, continuing without it: %v", err)
+		} else {
+			defer tracer.Close()
+		}
+	}
+
 	// Attempt to acquire the instance lock
-	lockAddr := fmt.Sprintf("127.0.0.1:%s", lockPort)
+	lockPort := instance.LockPort()
+	lockAddr := fmt.Sprintf("127.0.0.1:%d", lockPort)
 	listener, err := net.Listen("tcp", lockAddr)
 	if err != nil {
-		if strings.Contains(err.Error(), "address already in use") || strings.Contains(err.Error(), "bind: address already in use") || strings.Contains(err.Error(), "bind: Only one usage of each socket address") {
-			log.Println("Application is already running. Bringing existing window to front...")
-			platform.BringWindowToFront(appTitle)
+		if instance.IsAddrInUse(err) {
+			if *agentMode {
+				log.Println("FATAL: Another instance is already running.")
+				os.Exit(1)
+			}
+			if len(flag.Args()) > 0 {
+				log.Println("Application is already running. Forwarding arguments...")
+				if err := instance.Forward(lockAddr, flag.Args()); err != nil {
+					log.Printf("Error forwarding arguments: %v", err)
+				}
+			} else {
+				log.Println("Application is already running. Bringing existing window to front...")
+				platform.BringWindowToFront(instance.Title(appTitle))
+			}
 			if logFile != nil {
 				logFile.Sync()
 			} // Sync before exit, only if file exists
 			os.Exit(0)
+		} else if instance.IsAccessDenied(err) {
+			log.Printf("FATAL: Not permitted to use instance lock port %d (reserved or blocked by policy): %v", lockPort, err)
+			if logFile != nil {
+				logFile.Sync()
+			} // Sync before exit, only if file exists
+			os.Exit(1)
 		} else {
-			log.Printf("FATAL: Failed to acquire instance lock on port %s: %v", lockPort, err)
+			log.Printf("FATAL: Failed to acquire instance lock on port %d: %v", lockPort, err)
 			if logFile != nil {
 				logFile.Sync()
 			} // Sync before exit, only if file exists
@@ -101,13 +404,34 @@ func main() {
 		}
 	}
 	defer listener.Close()
-	log.Printf("Acquired instance lock on port %s", lockPort)
+	if name := instance.Name(); name != "" {
+		log.Printf("Acquired instance lock for instance '%s' on port %d", name, lockPort)
+	} else {
+		log.Printf("Acquired instance lock on port %d", lockPort)
+	}
 
 	// Create app
 	app := NewApp()
+	app.agentMode = *agentMode
+	app.listenAddr = *listenAddr
+	app.debug = *debug
+	if *debug {
+		log.Println("WARNING: Debug mode enabled, raw characteristic writes are allowed.")
+	}
+	app.pendingArgs = flag.Args()
+	handleSignals(app)
+
+	// Run commands forwarded by later launches, e.g. notification buttons
+	go instance.Serve(listener, app.runArgs)
+
+	if *agentMode {
+		runAgent(app)
+		log.Println("Agent exited cleanly.")
+		return
+	}
 
 	err = wails.Run(&options.App{
-		Title:         appTitle, // Use constant
+		Title:         instance.Title(appTitle),
 		Width:         512,
 		Height:        800,
 		DisableResize: true,
@@ -116,6 +440,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        app.startup,
+		OnBeforeClose:    app.beforeClose,
 		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
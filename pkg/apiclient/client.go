@@ -0,0 +1,259 @@
+package apiclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultTimeout allows for power commands, which run synchronously and
+// include BLE retries.
+const defaultTimeout = 60 * time.Second
+
+// Client talks to the HTTP API of an lhcontrol instance.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+	stream  *http.Client // Without a timeout, event streams stay open
+}
+
+// NewClient creates a client for the API at baseURL, e.g.
+// "http://127.0.0.1:8080". token may be empty if the API has none.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: defaultTimeout},
+		stream:  &http.Client{},
+	}
+}
+
+// SetHTTPClient replaces the HTTP client used for requests other than the
+// event stream, e.g. to change the timeout or the transport.
+func (c *Client) SetHTTPClient(client *http.Client) {
+	c.http = client
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
+
+// do sends a request and decodes the response into out unless it is nil
+// or the response has no body.
+// Error statuses are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// Commands answer 200 without a body when they completed
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response to %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// responseError reads the error envelope of a failed response.
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(body, &apiErr.ErrorResponse); err != nil || apiErr.ErrorResponse.Error == "" {
+		apiErr.ErrorResponse = ErrorResponse{Error: strings.TrimSpace(string(body))}
+	}
+	return apiErr
+}
+
+// ListStations returns the stations and their last known states.
+func (c *Client) ListStations(ctx context.Context) ([]StationInfo, error) {
+	var stations []StationInfo
+	if err := c.do(ctx, http.MethodGet, "/status", &stations); err != nil {
+		return nil, err
+	}
+	return stations, nil
+}
+
+// ListStationsVerbose returns the stations like ListStations, each with the
+// raw power value it last reported, see GET /status?verbose=1.
+func (c *Client) ListStationsVerbose(ctx context.Context) ([]VerboseStationInfo, error) {
+	var stations []VerboseStationInfo
+	if err := c.do(ctx, http.MethodGet, "/status?verbose=1", &stations); err != nil {
+		return nil, err
+	}
+	return stations, nil
+}
+
+// CheckStations reads the current state of every station before listing
+// them. Concurrent checks share one read, see GET /status?refresh=true.
+func (c *Client) CheckStations(ctx context.Context) ([]StationInfo, error) {
+	var stations []StationInfo
+	if err := c.do(ctx, http.MethodGet, "/status?refresh=true", &stations); err != nil {
+		return nil, err
+	}
+	return stations, nil
+}
+
+// PowerStation turns a station, by address or ID, on or off and waits for
+// the command. If a scan is running the server holds the command until it
+// is over and PowerStation returns right away with waiting set.
+func (c *Client) PowerStation(ctx context.Context, address string, on bool) (waiting bool, err error) {
+	action := "off"
+	if on {
+		action = "on"
+	}
+	return c.stationCommand(ctx, address, action)
+}
+
+// StandbyStation puts a station into standby, like PowerStation.
+func (c *Client) StandbyStation(ctx context.Context, address string) (waiting bool, err error) {
+	return c.stationCommand(ctx, address, "standby")
+}
+
+func (c *Client) stationCommand(ctx context.Context, address, action string) (waiting bool, err error) {
+	var status CommandStatus
+	if err := c.do(ctx, http.MethodPost, "/station/"+url.PathEscape(address)+"/"+action, &status); err != nil {
+		return false, err
+	}
+	return status.Status == StatusWaitingForScan, nil
+}
+
+// PowerAll turns all stations on or off. With wait it returns once the
+// commands completed, otherwise as soon as they were started.
+func (c *Client) PowerAll(ctx context.Context, on bool, wait bool) error {
+	path := "/alloff"
+	if on {
+		path = "/allon"
+	}
+	if wait {
+		path += "?wait=true"
+	}
+	return c.do(ctx, http.MethodPost, path, nil)
+}
+
+// StandbyAll puts all stations into standby, like PowerAll.
+func (c *Client) StandbyAll(ctx context.Context, wait bool) error {
+	path := "/allstandby"
+	if wait {
+		path += "?wait=true"
+	}
+	return c.do(ctx, http.MethodPost, path, nil)
+}
+
+// PowerOnAllReady turns all stations on and waits until they report they
+// are tracking-ready, for at most timeout. A result that isn't ready comes
+// with an *Error with status 504.
+func (c *Client) PowerOnAllReady(ctx context.Context, timeout time.Duration) (ReadinessResult, error) {
+	var result ReadinessResult
+	path := fmt.Sprintf("/allon?ready=true&timeout=%d", int(timeout.Seconds()))
+	err := c.do(ctx, http.MethodPost, path, &result)
+	return result, err
+}
+
+// Scan starts a scan. With wait it returns the stations once the scan
+// completed, otherwise it returns right away with no stations and the
+// results arrive with the "external-scan-completed" event.
+func (c *Client) Scan(ctx context.Context, wait bool) ([]StationInfo, error) {
+	if !wait {
+		return nil, c.do(ctx, http.MethodPost, "/scan", nil)
+	}
+	var stations []StationInfo
+	if err := c.do(ctx, http.MethodPost, "/scan?wait=true", &stations); err != nil {
+		return nil, err
+	}
+	return stations, nil
+}
+
+// EventStream is an open connection to the event stream.
+type EventStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Events opens the event stream, GET /events/stream. It starts with a
+// "stations" event, or with delta a "stations-snapshot" event followed by
+// "stations-delta" events instead of full lists. The stream ends when ctx is
+// done or Close is called.
+func (c *Client) Events(ctx context.Context, delta bool) (*EventStream, error) {
+	path := "/events/stream"
+	if delta {
+		path += "?mode=delta"
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.stream.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, responseError(resp)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 4<<20) // Station lists can be long lines
+	return &EventStream{body: resp.Body, scanner: scanner}, nil
+}
+
+// Next blocks until the next event arrives. It returns io.EOF when the
+// server closed the stream.
+func (s *EventStream) Next() (Event, error) {
+	var event Event
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		switch {
+		case line == "":
+			if event.Name != "" {
+				return event, nil
+			}
+		case strings.HasPrefix(line, ":"):
+			// Keepalive comment
+		case strings.HasPrefix(line, "event:"):
+			event.Name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			event.Data = append(event.Data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+		}
+	}
+	if err := s.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// Close ends the stream.
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
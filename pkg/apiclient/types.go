@@ -0,0 +1,160 @@
+// Package apiclient is a client for the lhcontrol HTTP API. It also holds the
+// request and response types the API server itself uses, so the two can't
+// drift apart.
+package apiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Power states reported in StationInfo.PowerState
+const (
+	PowerStateUnknown = -1
+	PowerStateOff     = 0
+	PowerStateOn      = 1
+	PowerStateStandby = 2
+	PowerStateBooting = 3 // Spinning up after power on, not tracking-ready yet
+)
+
+// StationInfo is a base station as listed by GET /status.
+type StationInfo struct {
+	ID           string    `json:"id"` // Stable identity derived from the serial in the name, see bluetooth.StationID
+	Name         string    `json:"name"`
+	OriginalName string    `json:"originalName"`
+	Address      string    `json:"address"`
+	PowerState   int       `json:"powerState"`
+	Ready        bool      `json:"ready"`                  // Fully running and tracking-ready
+	Connected    bool      `json:"connected"`              // A BLE connection is open, false once the station dropped it
+	Generation   int       `json:"generation"`             // 1 for HTC SteamVR 1.0 stations, 2 for Valve SteamVR 2.0 stations
+	Channel      int       `json:"channel"`                // Configured channel, -1 if unknown
+	SerialNumber string    `json:"serialNumber,omitempty"` // Printed on the unit, empty until read on the first connection
+	ModelNumber  string    `json:"modelNumber,omitempty"`
+	RSSI         int       `json:"rssi"`                   // Strongest signal in dBm during the last scan that saw the station
+	FirstSeen    time.Time `json:"firstSeen,omitempty"`    // When a scan first saw the station since start, zero if none did
+	LastSeen     time.Time `json:"lastSeen,omitempty"`     // When the last advertisement arrived, zero if no scan saw the station
+	Origin       string    `json:"origin"`                 // OriginLocal, or the name of the agent reporting the station
+	Essential    bool      `json:"essential"`              // Included in the essentials quick actions
+	Degraded     bool      `json:"degraded,omitempty"`     // Bluetooth is off or the adapter is unusable, so the station can't be reached
+	Reconnecting bool      `json:"reconnecting,omitempty"` // Dropped its connection, reconnect attempts are pending
+	NoReconnect  bool      `json:"noReconnect,omitempty"`  // Not reconnected automatically after dropping its connection
+	Unmanaged    bool      `json:"unmanaged,omitempty"`    // Left out of commands to all stations, commands to it alone still work
+	// When PowerState was last read from the station or pushed by it, zero
+	// if it never was. Stale is set if that is zero or longer ago than
+	// StaleStateAfter, so PowerState may no longer be accurate.
+	LastStateUpdate time.Time `json:"lastStateUpdate,omitempty"`
+	Stale           bool      `json:"stale"`
+	// Set if another station of the same host is on the same channel.
+	// Stations whose channel is unknown are never flagged.
+	ChannelConflict bool `json:"channelConflict,omitempty"`
+	// "ok", "degraded" or "failing", by the failure ratio of the station's
+	// recent connects, power writes and power reads
+	Health string `json:"health,omitempty"`
+	// Name of the group the station is in, empty if none
+	Group string `json:"group,omitempty"`
+}
+
+// StaleStateAfter is how old a station's last state update may be before
+// StationInfo.Stale is set.
+const StaleStateAfter = 30 * time.Second
+
+// RawPowerReading is the last value a station reported on its power
+// characteristic, see VerboseStationInfo.
+type RawPowerReading struct {
+	Value  int       `json:"value"`           // First byte, -1 if the read failed or was empty
+	Bytes  string    `json:"bytes,omitempty"` // Everything received, in hex
+	At     time.Time `json:"at"`              // Zero if nothing was read yet
+	Source string    `json:"source"`          // "read" or "notification"
+	Error  string    `json:"error,omitempty"` // Why the read failed
+}
+
+// VerboseStationInfo is a station as listed by GET /status?verbose=1, with
+// the raw power value for diagnostics.
+type VerboseStationInfo struct {
+	StationInfo
+	RawPower RawPowerReading `json:"rawPower"`
+}
+
+// ReadinessResult is returned by POST /allon?ready=true.
+type ReadinessResult struct {
+	Ready   bool     `json:"ready"`
+	Pending []string `json:"pending"` // Addresses that were not ready when the wait ended
+}
+
+// CommandStatus is the body of a power command the server accepted but
+// didn't run yet.
+type CommandStatus struct {
+	Status string `json:"status"` // StatusWaitingForScan
+}
+
+// StatusWaitingForScan means the command runs once the running scan is over.
+const StatusWaitingForScan = "waiting-for-scan"
+
+// Error codes, stable identifiers for API clients and the frontend
+const (
+	CodeSuperseded          = "superseded"
+	CodeAdapterInitializing = "adapter_initializing"
+	CodeAdapterUnavailable  = "adapter_unavailable"
+	CodeNoAdapter           = "no_adapter"
+	CodeAdapterDisabled     = "adapter_disabled"
+	CodeStationNotFound     = "station_not_found"
+	CodeScanInProgress      = "scan_in_progress"
+	CodeScanFailed          = "scan_failed"
+	CodeSnapshotNotFound    = "snapshot_not_found"
+	CodeGroupNotFound       = "group_not_found"
+	CodeGroupExists         = "group_exists"
+	CodeNothingToSnapshot   = "nothing_to_snapshot"
+	CodeNoSession           = "no_session"
+	CodeBusy                = "busy"
+	CodeUnreachable         = "unreachable"
+	CodeNotConnected        = "not_connected"
+	CodeNotABaseStation     = "not_a_base_station"
+	CodeReadFailed          = "read_failed"
+	CodeWriteFailed         = "write_failed"
+	CodeUnsupported         = "unsupported"
+	CodeStateMismatch       = "state_mismatch"
+	CodeInvalidChannel      = "invalid_channel"
+	CodeChannelConflict     = "channel_conflict"
+	CodeTimeout             = "timeout"
+	CodeCanceled            = "canceled"
+	CodeUnknown             = "unknown"
+)
+
+// ErrorResponse is the envelope of a failed request. Code and Message are
+// only set for failed commands; other errors just have Error.
+type ErrorResponse struct {
+	Error   string `json:"error"`             // Technical message for logs
+	Code    string `json:"code,omitempty"`    // One of the Code constants
+	Message string `json:"message,omitempty"` // Short text that can be shown to users
+}
+
+// Event is one event from the event stream. Data is the JSON payload.
+type Event struct {
+	Name string          `json:"name"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Error is returned for responses with an error status.
+type Error struct {
+	StatusCode int
+	ErrorResponse
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("lhcontrol API returned %d [%s]: %s", e.StatusCode, e.Code, e.ErrorResponse.Error)
+	}
+	return fmt.Sprintf("lhcontrol API returned %d: %s", e.StatusCode, e.ErrorResponse.Error)
+}
+
+// ErrorCode returns the API error code of err, or "" if err didn't come
+// from the API or has no code.
+func ErrorCode(err error) string {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return ""
+}